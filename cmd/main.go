@@ -27,7 +27,7 @@ func main() {
 	}
 
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: main [server|migrate]")
+		fmt.Println("Usage: main [server|migrate|purge-accounts|reindex]")
 		return
 	}
 
@@ -36,7 +36,11 @@ func main() {
 		StartServer(env)
 	case "migrate":
 		RunMigration(env)
+	case "purge-accounts":
+		RunAccountPurge(env)
+	case "reindex":
+		RunReindex(env)
 	default:
-		slog.Error("Unknown command. Use 'server' or 'migrate'.")
+		slog.Error("Unknown command. Use 'server', 'migrate', 'purge-accounts' or 'reindex'.")
 	}
 }
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"strings"
 
 	"github.com/go-backend-template/internal/di"
 	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
 )
 
 // RunMigration is responsible for executing database migrations.
@@ -27,6 +29,20 @@ func RunMigration(env string) {
 		err = diContainer.DB.Set("gorm:table_options", "CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci").AutoMigrate(
 			&models.User{},
 			&models.UserProvider{},
+			&models.Session{},
+			&models.APIKey{},
+			&models.Permission{},
+			&models.Role{},
+			&models.UserRole{},
+			&models.UserMFA{},
+			&models.MFARecoveryCode{},
+			&models.Passkey{},
+			&models.LockoutEvent{},
+			&models.LoginEvent{},
+			&models.UserBan{},
+			&models.ImpersonationEvent{},
+			&models.OAuthClient{},
+			&models.OAuthConsent{},
 			&models.Category{},
 			&models.Product{},
 			&models.ProductCategory{},
@@ -39,6 +55,20 @@ func RunMigration(env string) {
 		err = diContainer.DB.AutoMigrate(
 			&models.User{},
 			&models.UserProvider{},
+			&models.Session{},
+			&models.APIKey{},
+			&models.Permission{},
+			&models.Role{},
+			&models.UserRole{},
+			&models.UserMFA{},
+			&models.MFARecoveryCode{},
+			&models.Passkey{},
+			&models.LockoutEvent{},
+			&models.LoginEvent{},
+			&models.UserBan{},
+			&models.ImpersonationEvent{},
+			&models.OAuthClient{},
+			&models.OAuthConsent{},
 			&models.Category{},
 			&models.Product{},
 			&models.ProductCategory{},
@@ -56,5 +86,17 @@ func RunMigration(env string) {
 		return
 	}
 
+	// Create the full-text search index of products.
+	if err := repositories.MigrateProductSearch(context.Background(), diContainer.DB); err != nil {
+		slog.Error("Failed to migrate product search", "error", err)
+		return
+	}
+
+	// Sync the permission catalog and the built-in admin role.
+	if err := diContainer.RoleService.SyncPermissions(context.Background()); err != nil {
+		slog.Error("Failed to sync permissions", "error", err)
+		return
+	}
+
 	slog.Info("Database migration completed successfully!")
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-backend-template/internal/di"
+	"github.com/go-backend-template/internal/services"
+)
+
+// RunAccountPurge permanently deletes the accounts whose deletion grace period has ended.
+// Run it periodically (e.g. from cron) when the server does not purge accounts itself.
+func RunAccountPurge(env string) {
+	// Initialize DI Container.
+	diContainer := di.NewContainer(env)
+
+	purged, err := diContainer.AccountService.PurgeDueAccounts(context.Background())
+	if err != nil {
+		slog.Error("Account purge failed", "error", err, "purged", purged)
+		return
+	}
+	slog.Info("Account purge completed", "purged", purged)
+}
+
+// startAccountPurgeWorker purges accounts due for deletion in the background at the given interval.
+func startAccountPurgeWorker(accountService services.AccountService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purged, err := accountService.PurgeDueAccounts(context.Background())
+			if err != nil {
+				slog.Error("Background account purge failed", "error", err, "purged", purged)
+				continue
+			}
+			if purged > 0 {
+				slog.Info("Background account purge completed", "purged", purged)
+			}
+		}
+	}()
+	slog.Info("Account purge worker started", "interval", interval.String())
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-backend-template/internal/di"
+	"github.com/go-backend-template/internal/search"
+	"github.com/go-backend-template/internal/services"
+)
+
+// RunReindex rebuilds the product search index from the database.
+// Run it after switching search engines, or to repair an index that has drifted from the database.
+func RunReindex(env string) {
+	// Initialize DI Container.
+	diContainer := di.NewContainer(env)
+
+	indexed, err := diContainer.ProductSearchService.Reindex(context.Background())
+	if err != nil {
+		slog.Error("Reindex failed", "error", err, "indexed", indexed)
+		return
+	}
+	slog.Info("Reindex completed", "engine", diContainer.Config.Search.Engine, "indexed", indexed)
+}
+
+// startSearchIndexSyncWorker saves the embedded search index to its snapshot file in the background at the
+// given interval, and loads the snapshot when the reindex command has replaced it.
+func startSearchIndexSyncWorker(index *search.MemoryIndex, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := index.Sync(); err != nil {
+				slog.Error("Search index sync failed", "error", err)
+			}
+		}
+	}()
+	slog.Info("Search index sync worker started", "interval", interval.String())
+}
+
+// buildSearchIndex builds the embedded search index in the background when it has no snapshot yet.
+func buildSearchIndex(searchService services.ProductSearchService) {
+	go func() {
+		indexed, err := searchService.Reindex(context.Background())
+		if err != nil {
+			slog.Error("Search index build failed", "error", err, "indexed", indexed)
+			return
+		}
+		slog.Info("Search index built", "indexed", indexed)
+	}()
+}
@@ -3,10 +3,12 @@ package main
 import (
 	"log/slog"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-backend-template/internal/di"
 	"github.com/go-backend-template/internal/routes"
+	"github.com/go-backend-template/internal/search"
 )
 
 func StartServer(env string) {
@@ -26,6 +28,23 @@ func StartServer(env string) {
 	// Initialize routes.
 	routes.InitRoutes(r, diContainer)
 
+	// Purge accounts whose deletion grace period has ended, unless this is left to the purge-accounts command.
+	if minutes := diContainer.Config.AccountDeletion.PurgeIntervalMinutes; minutes > 0 {
+		startAccountPurgeWorker(diContainer.AccountService, time.Duration(minutes)*time.Minute)
+	}
+
+	// Keep the embedded search index saved, building it first if there is no snapshot yet.
+	if index, ok := diContainer.SearchIndex.(*search.MemoryIndex); ok {
+		if index.Len() == 0 {
+			buildSearchIndex(diContainer.ProductSearchService)
+		}
+		seconds := diContainer.Config.Search.SyncIntervalSeconds
+		if seconds <= 0 {
+			seconds = 30
+		}
+		startSearchIndexSyncWorker(index, time.Duration(seconds)*time.Second)
+	}
+
 	// Use configured port.
 	port := strconv.Itoa(diContainer.Config.Server.Port)
 	slog.Info("Server starting", "port", port, "env", env)
@@ -19,6 +19,31 @@ type DatabaseConfig struct {
 	MaxOpenConns int    `mapstructure:"max_open_conns"` // 最大打开连接数
 }
 
+// JWTKeyConfig JWT 非对称密钥配置，PEM 可通过文件路径或直接内联提供
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`               // 密钥ID，写入Token头部的 kid
+	Algorithm      string `mapstructure:"algorithm"`        // 签名算法: RS256, EdDSA
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM 私钥文件路径（仅签名密钥需要）
+	PrivateKey     string `mapstructure:"private_key"`      // PEM 私钥内容
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // PEM 公钥文件路径（仅用于验证的旧密钥）
+	PublicKey      string `mapstructure:"public_key"`       // PEM 公钥内容
+}
+
+// OAuthClientConfig 第三方登录的客户端配置
+type OAuthClientConfig struct {
+	ClientID     string   `mapstructure:"client_id"`     // 客户端ID（微信为 AppID，Apple 为 Bundle ID 或 Services ID）
+	ClientSecret string   `mapstructure:"client_secret"` // 客户端密钥（微信为 AppSecret），仅授权码模式需要
+	RedirectURLs []string `mapstructure:"redirect_urls"` // 授权码模式允许的回调地址
+}
+
+// OAuthProviderConfig 第三方登录提供商配置
+type OAuthProviderConfig struct {
+	Type    string                       `mapstructure:"type"`     // 提供商类型: oidc（默认，任意 OpenID Connect 提供商，如 Google、Apple、Keycloak、Azure AD、Okta）, wechat
+	Issuer  string                       `mapstructure:"issuer"`   // OIDC 签发方，通过 {issuer}/.well-known/openid-configuration 发现端点，并校验 ID Token 的 iss
+	JWKSURL string                       `mapstructure:"jwks_url"` // 可选，覆盖发现文档中的公钥地址；仅校验 ID Token 时可跳过服务发现
+	Clients map[string]OAuthClientConfig `mapstructure:"clients"`  // 按客户端类型（请求中的 client_type，如 web、ios、app）区分的配置
+}
+
 // Config 结构体，映射到 YAML 配置
 type Config struct {
 	Server struct {
@@ -28,37 +53,90 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 
 	JWT struct {
-		Secret      string `mapstructure:"secret"`
-		ExpireHours int    `mapstructure:"expire_hours"`
+		Secret            string         `mapstructure:"secret"` // HS256 密钥（未配置 active_key_id 时用于签名与验证）
+		ExpireHours       int            `mapstructure:"expire_hours"`
+		ActiveKeyID       string         `mapstructure:"active_key_id"`       // 当前用于签名的密钥ID (kid)，为空时使用 HS256
+		Keys              []JWTKeyConfig `mapstructure:"keys"`                // 非对称密钥列表，轮换后保留旧公钥以验证已签发的Token
+		AcceptLegacyHS256 bool           `mapstructure:"accept_legacy_hs256"` // 配置 active_key_id 后是否仍接受 HS256 Token，默认否；仅在切换期间开启，旧Token过期后关闭并删除 secret
 	} `mapstructure:"jwt"`
 
-	// Google OAuth2 配置
-	Google struct {
-		// iOS客户端配置
-		IOS struct {
-			ClientID     string   `mapstructure:"client_id"`
-			ClientSecret string   `mapstructure:"client_secret"`
-			RedirectURLs []string `mapstructure:"redirect_urls"`
-		} `mapstructure:"ios"`
-		// Web客户端配置
-		Web struct {
-			ClientID     string   `mapstructure:"client_id"`
-			ClientSecret string   `mapstructure:"client_secret"`
-			RedirectURLs []string `mapstructure:"redirect_urls"`
-		} `mapstructure:"web"`
-	} `mapstructure:"google"`
-
-	// 微信OAuth2配置
-	Wechat struct {
-		Web struct {
-			AppID  string `mapstructure:"appid"`
-			Secret string `mapstructure:"secret"`
-		} `mapstructure:"web"`
-		App struct {
-			AppID  string `mapstructure:"appid"`
-			Secret string `mapstructure:"secret"`
-		} `mapstructure:"app"`
-	} `mapstructure:"wechat"`
+	// 两步验证配置
+	MFA struct {
+		Issuer           string `mapstructure:"issuer"`             // 身份验证器 App 中显示的发行方名称
+		RequireForAdmins bool   `mapstructure:"require_for_admins"` // 管理员访问管理接口时是否必须已完成两步验证
+	} `mapstructure:"mfa"`
+
+	// WebAuthn（通行密钥）配置
+	WebAuthn struct {
+		RPID    string   `mapstructure:"rp_id"`   // 依赖方ID，即站点的可注册域名，如 example.com
+		RPName  string   `mapstructure:"rp_name"` // 认证器中显示的名称
+		Origins []string `mapstructure:"origins"` // 允许发起通行密钥请求的来源，如 https://example.com
+	} `mapstructure:"webauthn"`
+
+	// 登录防暴力破解配置（未配置的项使用默认值）
+	LoginProtection struct {
+		MaxAccountFailures   int `mapstructure:"max_account_failures"`   // 同一账号在计数窗口内允许的失败次数，达到后临时锁定，默认5
+		MaxIPFailures        int `mapstructure:"max_ip_failures"`        // 同一IP在计数窗口内允许的失败次数，达到后临时锁定，默认20
+		DelayAfterFailures   int `mapstructure:"delay_after_failures"`   // 账号连续失败多少次后开始要求等待（1s、2s、4s…递增），默认3
+		FailureWindowMinutes int `mapstructure:"failure_window_minutes"` // 失败计数窗口（分钟），默认15
+		LockoutMinutes       int `mapstructure:"lockout_minutes"`        // 锁定时长（分钟），默认15
+	} `mapstructure:"login_protection"`
+
+	// 密码策略与密码哈希配置（未配置的项使用默认值）
+	Password struct {
+		MinLength        int    `mapstructure:"min_length"`         // 最小长度，默认8
+		MaxLength        int    `mapstructure:"max_length"`         // 最大长度，默认128
+		RequireUppercase bool   `mapstructure:"require_uppercase"`  // 是否必须包含大写字母
+		RequireLowercase bool   `mapstructure:"require_lowercase"`  // 是否必须包含小写字母
+		RequireDigit     bool   `mapstructure:"require_digit"`      // 是否必须包含数字
+		RequireSymbol    bool   `mapstructure:"require_symbol"`     // 是否必须包含特殊字符
+		BreachedListFile string `mapstructure:"breached_list_file"` // 泄露密码列表文件，每行一个密码，为空时不检查
+		Hash             struct {
+			Algorithm   string `mapstructure:"algorithm"`   // 新密码使用的哈希算法: argon2id（默认）, bcrypt
+			Memory      uint32 `mapstructure:"memory"`      // argon2id 内存（KiB），默认65536
+			Iterations  uint32 `mapstructure:"iterations"`  // argon2id 迭代次数，默认3
+			Parallelism uint8  `mapstructure:"parallelism"` // argon2id 并行度，默认2
+			BcryptCost  int    `mapstructure:"bcrypt_cost"` // bcrypt 成本，默认12
+		} `mapstructure:"hash"`
+	} `mapstructure:"password"`
+
+	// 账号注销配置（未配置的项使用默认值）
+	AccountDeletion struct {
+		GracePeriodDays      int `mapstructure:"grace_period_days"`      // 申请注销后保留账号的天数，期间重新登录即撤销注销，默认30
+		PurgeIntervalMinutes int `mapstructure:"purge_interval_minutes"` // 服务进程内清除到期账号的间隔（分钟），为0时不在服务进程内清除，需定时执行 purge-accounts 命令
+	} `mapstructure:"account_deletion"`
+
+	// API密钥配置（未配置的项使用默认值）
+	APIKeys struct {
+		MaxPerUser        int `mapstructure:"max_per_user"`        // 每个用户最多可同时拥有的有效密钥数量，默认20
+		DefaultExpireDays int `mapstructure:"default_expire_days"` // 创建时未指定有效期时的默认有效期（天），默认90
+		MaxExpireDays     int `mapstructure:"max_expire_days"`     // 允许的最长有效期（天），默认365
+	} `mapstructure:"api_keys"`
+
+	// 产品搜索配置（未配置的项使用默认值）
+	Search struct {
+		Engine              string `mapstructure:"engine"`                // 搜索引擎: memory（默认，内嵌于服务进程，适合单实例部署）, database（数据库全文索引）, meilisearch（外部 Meilisearch 服务）
+		IndexPath           string `mapstructure:"index_path"`            // memory 引擎的索引快照文件，默认 data/search/products.idx
+		SyncIntervalSeconds int    `mapstructure:"sync_interval_seconds"` // memory 引擎保存快照、并加载 reindex 命令重建的快照的间隔（秒），默认30
+		MaxHits             int    `mapstructure:"max_hits"`              // 一次搜索最多匹配的产品数量，默认1000；超出时列表分页信息返回 truncated
+		// Meilisearch配置 (当engine为meilisearch时使用)
+		Meilisearch struct {
+			URL    string `mapstructure:"url"`     // 服务地址，如 http://localhost:7700
+			APIKey string `mapstructure:"api_key"` // API Key
+			Index  string `mapstructure:"index"`   // 索引名称，默认 products
+		} `mapstructure:"meilisearch"`
+	} `mapstructure:"search"`
+
+	// OAuth2 授权服务器配置，供第三方应用经用户授权后访问接口（未配置的项使用默认值）
+	OAuthServer struct {
+		AccessTokenMinutes   int               `mapstructure:"access_token_minutes"`  // 签发给第三方应用的 access token 有效期（分钟），默认60；refresh token 有效期与登录会话一致
+		IntrospectionClients map[string]string `mapstructure:"introspection_clients"` // 可调用令牌内省接口 /oauth/introspect 的内部服务，键为服务ID，值为服务密钥
+	} `mapstructure:"oauth_server"`
+
+	// 第三方登录配置，键为提供商名称（即路由 /auth/:provider/token 中的 provider，并记录在用户绑定关系中）
+	OAuth struct {
+		Providers map[string]OAuthProviderConfig `mapstructure:"providers"`
+	} `mapstructure:"oauth"`
 
 	// 邮件服务配置
 	Email struct {
@@ -66,6 +144,7 @@ type Config struct {
 		SendGridAPIKey string `mapstructure:"sendgrid_api_key"` // SendGrid API Key
 		FromEmail      string `mapstructure:"from_email"`       // 发送邮箱地址
 		FromName       string `mapstructure:"from_name"`        // 发送者名称
+		MagicLinkURL   string `mapstructure:"magic_link_url"`   // 前端处理登录链接的页面地址，邮件中的链接会附带 token 参数
 		// SMTP配置 (当provider为smtp时使用)
 		SMTP struct {
 			Host     string `mapstructure:"host"`
@@ -76,6 +155,26 @@ type Config struct {
 		} `mapstructure:"smtp"`
 	} `mapstructure:"email"`
 
+	// 短信服务配置
+	SMS struct {
+		Provider string `mapstructure:"provider"`  // 短信服务提供商: disabled（不发送短信，未设置时相同）, log（仅写日志）, file（写入文件）, twilio, aliyun；log与file只能用于开发配置
+		FilePath string `mapstructure:"file_path"` // provider为file时短信写入的文件路径
+		AppName  string `mapstructure:"app_name"`  // 短信内容中的应用名称
+		// Twilio配置 (当provider为twilio时使用)
+		Twilio struct {
+			AccountSID string `mapstructure:"account_sid"`
+			AuthToken  string `mapstructure:"auth_token"`
+			FromNumber string `mapstructure:"from_number"` // 发送号码，E.164 格式
+		} `mapstructure:"twilio"`
+		// 阿里云短信配置 (当provider为aliyun时使用，仅支持中国大陆号码模板)
+		Aliyun struct {
+			AccessKeyID     string `mapstructure:"access_key_id"`
+			AccessKeySecret string `mapstructure:"access_key_secret"`
+			SignName        string `mapstructure:"sign_name"`     // 短信签名
+			TemplateCode    string `mapstructure:"template_code"` // 验证码模板，模板变量为 ${code}
+		} `mapstructure:"aliyun"`
+	} `mapstructure:"sms"`
+
 	// Redis配置
 	Redis struct {
 		Host     string `mapstructure:"host"`
@@ -6,7 +6,9 @@ import (
 	"github.com/go-backend-template/internal/handlers/admin_handlers"
 	"github.com/go-backend-template/internal/infra"
 	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/internal/search"
 	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/pkg/jwt"
 	"github.com/openai/openai-go" // imported as openai
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -21,33 +23,74 @@ type Container struct {
 	OpenAIClient   *openai.Client
 	MoonshotClient *openai.Client
 	DeepSeekClient *openai.Client
+	JWTKeys        *jwt.KeySet
+	SearchIndex    search.SearchIndex
 
 	// Service Layer (Core Services)
 	EmailService        services.EmailService
+	SmsService          services.SmsService
 	VerificationService services.VerificationService
-	GoogleOAuthService  services.GoogleOAuthService
+	RefreshTokenService services.RefreshTokenService
+	OAuthProviders      services.OAuthProviderRegistry
+	PasswordService     services.PasswordService
 
 	// Repository Layer
-	UserRepository            repositories.UserRepository
-	CategoryRepository        repositories.CategoryRepository
-	ProductRepository         repositories.ProductRepository
-	UserInteractionRepository repositories.UserInteractionRepository
+	UserRepository               repositories.UserRepository
+	CategoryRepository           repositories.CategoryRepository
+	ProductRepository            repositories.ProductRepository
+	UserInteractionRepository    repositories.UserInteractionRepository
+	SessionRepository            repositories.SessionRepository
+	APIKeyRepository             repositories.APIKeyRepository
+	RoleRepository               repositories.RoleRepository
+	MFARepository                repositories.MFARepository
+	PasskeyRepository            repositories.PasskeyRepository
+	LockoutEventRepository       repositories.LockoutEventRepository
+	LoginEventRepository         repositories.LoginEventRepository
+	ImpersonationEventRepository repositories.ImpersonationEventRepository
+	AccountRepository            repositories.AccountRepository
+	OAuthClientRepository        repositories.OAuthClientRepository
+	UserBanRepository            repositories.UserBanRepository
 
 	// Service Layer (Business Services)
 	UserService            services.UserService
 	CategoryService        services.CategoryService
 	ProductService         services.ProductService
 	UserInteractionService services.UserInteractionService
+	SessionService         services.SessionService
+	APIKeyService          services.APIKeyService
+	RoleService            services.RoleService
+	MFAService             services.MFAService
+	PasskeyService         services.PasskeyService
+	LoginProtectionService services.LoginProtectionService
+	LoginHistoryService    services.LoginHistoryService
+	BanService             services.BanService
+	ImpersonationService   services.ImpersonationService
+	AccountService         services.AccountService
+	OAuthServerService     services.OAuthServerService
+	ProductSearchService   services.ProductSearchService
 
 	// Handler Layer
 	AuthHandler            *handlers.AuthHandler
 	CategoryHandler        *handlers.CategoryHandler
 	ProductHandler         *handlers.ProductHandler
 	UserInteractionHandler *handlers.UserInteractionHandler
+	JWKSHandler            *handlers.JWKSHandler
+	SessionHandler         *handlers.SessionHandler
+	LoginHistoryHandler    *handlers.LoginHistoryHandler
+	APIKeyHandler          *handlers.APIKeyHandler
+	MFAHandler             *handlers.MFAHandler
+	PasskeyHandler         *handlers.PasskeyHandler
+	AccountHandler         *handlers.AccountHandler
+	OAuthServerHandler     *handlers.OAuthServerHandler
 
 	// Admin Handler Layer
-	UserHandlerForAdmin    *admin_handlers.UserHandler
-	ProductHandlerForAdmin *admin_handlers.ProductHandler
+	UserHandlerForAdmin          *admin_handlers.UserHandler
+	ProductHandlerForAdmin       *admin_handlers.ProductHandler
+	SecurityHandlerForAdmin      *admin_handlers.SecurityHandler
+	APIKeyHandlerForAdmin        *admin_handlers.APIKeyHandler
+	RoleHandlerForAdmin          *admin_handlers.RoleHandler
+	ImpersonationHandlerForAdmin *admin_handlers.ImpersonationHandler
+	OAuthClientHandlerForAdmin   *admin_handlers.OAuthClientHandler
 }
 
 // NewContainer creates a new dependency injection container.
@@ -64,6 +107,8 @@ func NewContainer(env string) *Container {
 	openaiClient := infra.InitOpenAIClient(cfg)
 	moonshotClient := infra.InitMoonshotClient(cfg)
 	deepSeekClient := infra.InitDeepSeekClient(cfg)
+	jwtKeys := infra.InitJWTKeySet(cfg)
+	searchIndex := infra.InitSearchIndex(cfg, db)
 
 	// Set configuration and database connections.
 	container.Config = cfg
@@ -72,11 +117,28 @@ func NewContainer(env string) *Container {
 	container.OpenAIClient = openaiClient
 	container.MoonshotClient = moonshotClient
 	container.DeepSeekClient = deepSeekClient
+	container.JWTKeys = jwtKeys
+	container.SearchIndex = searchIndex
 
 	// Initialize core services.
 	container.EmailService = services.NewEmailService(cfg)
+	smsService, err := services.NewSmsService(cfg, env)
+	if err != nil {
+		panic("Failed to initialize SMS service: " + err.Error())
+	}
+	container.SmsService = smsService
 	container.VerificationService = services.NewVerificationService(redis)
-	container.GoogleOAuthService = services.NewGoogleOAuthService(cfg)
+	container.RefreshTokenService = services.NewRefreshTokenService(redis)
+	passwordService, err := services.NewPasswordService(cfg)
+	if err != nil {
+		panic("Failed to initialize password service: " + err.Error())
+	}
+	container.PasswordService = passwordService
+	oauthProviders, err := services.NewOAuthProviderRegistry(cfg)
+	if err != nil {
+		panic("Failed to initialize OAuth providers: " + err.Error())
+	}
+	container.OAuthProviders = oauthProviders
 
 	// Initialize repository layer.
 	container.initRepositoryLayer(db)
@@ -96,14 +158,37 @@ func (c *Container) initRepositoryLayer(db *gorm.DB) {
 	c.CategoryRepository = repositories.NewCategoryRepository(db)
 	c.ProductRepository = repositories.NewProductRepository(db, c.CategoryRepository)
 	c.UserInteractionRepository = repositories.NewUserInteractionRepository(db, c.CategoryRepository)
+	c.SessionRepository = repositories.NewSessionRepository(db)
+	c.APIKeyRepository = repositories.NewAPIKeyRepository(db)
+	c.RoleRepository = repositories.NewRoleRepository(db)
+	c.MFARepository = repositories.NewMFARepository(db)
+	c.PasskeyRepository = repositories.NewPasskeyRepository(db)
+	c.LockoutEventRepository = repositories.NewLockoutEventRepository(db)
+	c.LoginEventRepository = repositories.NewLoginEventRepository(db)
+	c.ImpersonationEventRepository = repositories.NewImpersonationEventRepository(db)
+	c.AccountRepository = repositories.NewAccountRepository(db)
+	c.OAuthClientRepository = repositories.NewOAuthClientRepository(db)
+	c.UserBanRepository = repositories.NewUserBanRepository(db)
 }
 
 // initServiceLayer initializes the service layer.
 func (c *Container) initServiceLayer(cfg *config.Config) {
-	c.UserService = services.NewUserService(cfg, c.UserRepository, c.GoogleOAuthService, c.EmailService, c.VerificationService)
-	c.CategoryService = services.NewCategoryService(c.CategoryRepository)
-	c.ProductService = services.NewProductService(c.ProductRepository, c.CategoryRepository)
-	c.UserInteractionService = services.NewUserInteractionService(c.UserInteractionRepository, c.ProductRepository)
+	c.SessionService = services.NewSessionService(c.SessionRepository, c.RefreshTokenService)
+	c.RoleService = services.NewRoleService(c.RoleRepository, c.UserRepository)
+	c.APIKeyService = services.NewAPIKeyService(cfg, c.APIKeyRepository, c.RoleService)
+	c.LoginProtectionService = services.NewLoginProtectionService(cfg, c.Redis, c.LockoutEventRepository)
+	c.MFAService = services.NewMFAService(cfg, c.MFARepository, c.LoginProtectionService)
+	c.PasskeyService = services.NewPasskeyService(cfg, c.Redis, c.PasskeyRepository)
+	c.LoginHistoryService = services.NewLoginHistoryService(c.LoginEventRepository, c.EmailService)
+	c.BanService = services.NewBanService(c.UserRepository, c.UserBanRepository)
+	c.ImpersonationService = services.NewImpersonationService(c.JWTKeys, c.UserRepository, c.ImpersonationEventRepository, c.RoleService)
+	c.UserService = services.NewUserService(cfg, c.JWTKeys, c.UserRepository, c.OAuthProviders, c.EmailService, c.SmsService, c.VerificationService, c.RefreshTokenService, c.SessionService, c.MFAService, c.PasskeyService, c.LoginProtectionService, c.LoginHistoryService, c.BanService, c.PasswordService)
+	c.AccountService = services.NewAccountService(cfg, c.UserRepository, c.AccountRepository, c.SessionService, c.PasswordService)
+	c.OAuthServerService = services.NewOAuthServerService(cfg, c.JWTKeys, c.Redis, c.OAuthClientRepository, c.UserRepository, c.SessionService, c.RefreshTokenService, c.BanService, c.RoleService)
+	c.ProductSearchService = services.NewProductSearchService(cfg, c.SearchIndex, c.ProductRepository)
+	c.CategoryService = services.NewCategoryService(c.CategoryRepository, c.ProductSearchService)
+	c.ProductService = services.NewProductService(c.ProductRepository, c.CategoryRepository, c.ProductSearchService)
+	c.UserInteractionService = services.NewUserInteractionService(c.UserInteractionRepository, c.ProductRepository, c.ProductSearchService)
 }
 
 // initHandlerLayer initializes the handler layer.
@@ -113,8 +198,21 @@ func (c *Container) initHandlerLayer() {
 	c.CategoryHandler = handlers.NewCategoryHandler(c.CategoryService)
 	c.ProductHandler = handlers.NewProductHandler(c.ProductService, c.UserInteractionService)
 	c.UserInteractionHandler = handlers.NewUserInteractionHandler(c.UserInteractionService)
+	c.JWKSHandler = handlers.NewJWKSHandler(c.JWTKeys)
+	c.SessionHandler = handlers.NewSessionHandler(c.SessionService)
+	c.LoginHistoryHandler = handlers.NewLoginHistoryHandler(c.LoginHistoryService)
+	c.APIKeyHandler = handlers.NewAPIKeyHandler(c.APIKeyService)
+	c.MFAHandler = handlers.NewMFAHandler(c.MFAService)
+	c.PasskeyHandler = handlers.NewPasskeyHandler(c.PasskeyService, c.UserService)
+	c.AccountHandler = handlers.NewAccountHandler(c.AccountService)
+	c.OAuthServerHandler = handlers.NewOAuthServerHandler(c.OAuthServerService)
 
 	// Admin handlers
-	c.UserHandlerForAdmin = admin_handlers.NewUserHandler(c.UserService)
+	c.UserHandlerForAdmin = admin_handlers.NewUserHandler(c.UserService, c.BanService)
 	c.ProductHandlerForAdmin = admin_handlers.NewProductHandler(c.ProductService)
+	c.SecurityHandlerForAdmin = admin_handlers.NewSecurityHandler(c.LoginProtectionService, c.LoginHistoryService)
+	c.APIKeyHandlerForAdmin = admin_handlers.NewAPIKeyHandler(c.APIKeyService)
+	c.RoleHandlerForAdmin = admin_handlers.NewRoleHandler(c.RoleService)
+	c.ImpersonationHandlerForAdmin = admin_handlers.NewImpersonationHandler(c.ImpersonationService)
+	c.OAuthClientHandlerForAdmin = admin_handlers.NewOAuthClientHandler(c.OAuthServerService)
 }
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+/* Response DTOs */
+
+// AccountDeletionDTO is returned when the user requests account deletion.
+type AccountDeletionDTO struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"` // Log in again before this time to cancel the deletion
+}
+
+// AccountExportProviderDTO is a bound login provider in an account export. OAuth tokens are not exported.
+type AccountExportProviderDTO struct {
+	Provider      string    `json:"provider"`
+	ProviderUID   string    `json:"provider_uid"`
+	WechatUnionID *string   `json:"wechat_union_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ToAccountExportProviderDTOs converts UserProvider models to AccountExportProviderDTOs.
+func ToAccountExportProviderDTOs(userProviders []models.UserProvider) []AccountExportProviderDTO {
+	result := make([]AccountExportProviderDTO, 0, len(userProviders))
+	for _, userProvider := range userProviders {
+		result = append(result, AccountExportProviderDTO{
+			Provider:      userProvider.Provider,
+			ProviderUID:   userProvider.ProviderUID,
+			WechatUnionID: userProvider.WechatUnionID,
+			CreatedAt:     userProvider.CreatedAt,
+		})
+	}
+	return result
+}
+
+// AccountExportInteractionDTO is a liked or favorited product in an account export.
+type AccountExportInteractionDTO struct {
+	ProductID uint      `json:"product_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+/* Request DTOs */
+
+// DeleteAccountRequest is the request for deleting one's own account.
+// The current password is required if the account has one.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
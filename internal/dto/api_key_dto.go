@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+/* Response DTOs */
+
+// APIKeyDTO represents an API key. The key itself is only returned once, on creation.
+type APIKeyDTO struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Identifies the key, e.g. in logs and key lists
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyDTO is returned when an API key is created.
+type CreatedAPIKeyDTO struct {
+	APIKeyDTO
+	Key string `json:"key"` // The full key, shown only once
+}
+
+// ToAPIKeyDTO converts an APIKey model to an APIKeyDTO.
+func ToAPIKeyDTO(apiKey *models.APIKey) APIKeyDTO {
+	return APIKeyDTO{
+		ID:         apiKey.ID,
+		UserID:     apiKey.UserID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.ScopeList(),
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		LastUsedIP: apiKey.LastUsedIP,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// ToAPIKeyDTOs converts APIKey models to APIKeyDTOs.
+func ToAPIKeyDTOs(apiKeys []models.APIKey) []APIKeyDTO {
+	result := make([]APIKeyDTO, 0, len(apiKeys))
+	for i := range apiKeys {
+		result = append(result, ToAPIKeyDTO(&apiKeys[i]))
+	}
+	return result
+}
+
+/* Request DTOs */
+
+// CreateAPIKeyRequest is the request for creating an API key.
+// ExpiresInDays defaults to api_keys.default_expire_days and is capped by api_keys.max_expire_days.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=profile products admin"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1"`
+}
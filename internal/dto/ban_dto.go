@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+/* Response DTOs */
+
+// BanDetailsDTO describes the active ban of a user. It is returned to the banned user with the user_banned
+// error; users banned before ban records existed only get expires_at.
+type BanDetailsDTO struct {
+	Reason    string     `json:"reason,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at"` // null for a permanent ban
+}
+
+// ToBanDetailsDTO converts the active UserBan model of a user to a BanDetailsDTO. ban may be nil.
+func ToBanDetailsDTO(user *models.User, ban *models.UserBan) BanDetailsDTO {
+	details := BanDetailsDTO{ExpiresAt: user.BannedUntil}
+	if ban != nil {
+		details.Reason = ban.Reason
+		details.Message = ban.Message
+		details.BannedAt = &ban.BannedAt
+		details.ExpiresAt = ban.ExpiresAt
+	}
+	return details
+}
+
+/* Request DTOs */
+
+// BanUserRequest is the request for banning or unbanning a user (admin). A ban without expires_at or
+// duration_hours is permanent.
+type BanUserRequest struct {
+	IsBanned      bool       `json:"is_banned"`
+	Reason        string     `json:"reason" validate:"required_if=IsBanned true,max=255"` // Returned to the banned user
+	Message       string     `json:"message" validate:"max=1000"`                         // Shown to the banned user
+	ExpiresAt     *time.Time `json:"expires_at" validate:"excluded_with=DurationHours"`
+	DurationHours int        `json:"duration_hours" validate:"omitempty,min=1,max=87600"`
+}
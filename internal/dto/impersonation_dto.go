@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+/* Response DTOs */
+
+// ImpersonationTokenDTO is the access token issued to an admin impersonating a user.
+// It cannot be refreshed; the admin requests a new one after it expires.
+type ImpersonationTokenDTO struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      uint      `json:"user_id"` // The impersonated user
+}
+
+/* Request DTOs */
+
+// ImpersonateRequest is the request for impersonating a user. The reason is recorded in the audit trail.
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+/* Response DTOs */
+
+// LoginEventDTO represents a login attempt in the login history.
+type LoginEventDTO struct {
+	ID            uint      `json:"id"`
+	Method        string    `json:"method"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"` // Error code of a failed attempt, e.g. invalid_password
+	MFA           bool      `json:"mfa"`
+	IPAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	DeviceName    string    `json:"device_name"`
+	NewDevice     bool      `json:"new_device"` // A login from a device the user had not used before
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ToLoginEventDTOs converts LoginEvent models to LoginEventDTOs.
+func ToLoginEventDTOs(events []models.LoginEvent) []LoginEventDTO {
+	result := make([]LoginEventDTO, 0, len(events))
+	for _, event := range events {
+		result = append(result, LoginEventDTO{
+			ID:            event.ID,
+			Method:        event.Method,
+			Success:       event.Success,
+			FailureReason: event.FailureReason,
+			MFA:           event.MFA,
+			IPAddress:     event.IPAddress,
+			UserAgent:     event.UserAgent,
+			DeviceName:    event.DeviceName,
+			NewDevice:     event.NewDevice,
+			CreatedAt:     event.CreatedAt,
+		})
+	}
+	return result
+}
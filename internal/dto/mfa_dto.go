@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"time"
+)
+
+/* Response DTOs */
+
+// MFAStatusDTO represents the two-factor authentication status of a user.
+type MFAStatusDTO struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollmentDTO contains the data an authenticator app needs to be set up.
+type TOTPEnrollmentDTO struct {
+	Secret          string `json:"secret"`           // Base32 secret for manual entry
+	ProvisioningURI string `json:"provisioning_uri"` // otpauth:// URI to render as a QR code
+}
+
+// RecoveryCodesDTO contains newly generated recovery codes (shown only once).
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+/* Request DTOs */
+
+// MFACodeRequest is the request carrying a TOTP code or a recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=20"` // 6-digit TOTP code or recovery code
+}
+
+// MFALoginRequest is the request for completing a login with a second factor.
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`         // Token returned by the first login step
+	Code     string `json:"code" validate:"required,min=6,max=20"` // 6-digit TOTP code or recovery code
+}
@@ -0,0 +1,254 @@
+package dto
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+/* Response DTOs */
+
+// OAuthClientDTO represents a registered OAuth client (admin).
+type OAuthClientDTO struct {
+	ID           uint      `json:"id"`
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	LogoURL      string    `json:"logo_url"`
+	Confidential bool      `json:"confidential"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CreatedOAuthClientDTO is returned when a client is registered or its secret is rotated.
+type CreatedOAuthClientDTO struct {
+	OAuthClientDTO
+	ClientSecret string `json:"client_secret,omitempty"` // Only for confidential clients, shown only once
+}
+
+// ToOAuthClientDTO converts an OAuthClient model to an OAuthClientDTO.
+func ToOAuthClientDTO(client *models.OAuthClient) OAuthClientDTO {
+	return OAuthClientDTO{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		LogoURL:      client.LogoURL,
+		Confidential: client.Confidential,
+		RedirectURIs: client.RedirectURIList(),
+		Scopes:       client.ScopeList(),
+		CreatedBy:    client.CreatedBy,
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+	}
+}
+
+// ToOAuthClientDTOs converts OAuthClient models to OAuthClientDTOs.
+func ToOAuthClientDTOs(clients []models.OAuthClient) []OAuthClientDTO {
+	result := make([]OAuthClientDTO, 0, len(clients))
+	for i := range clients {
+		result = append(result, ToOAuthClientDTO(&clients[i]))
+	}
+	return result
+}
+
+// OAuthScopeDTO describes a scope on the consent screen.
+type OAuthScopeDTO struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// OAuthAppDTO is the public information of a client shown to users.
+type OAuthAppDTO struct {
+	ClientID string `json:"client_id"`
+	Name     string `json:"name"`
+	LogoURL  string `json:"logo_url"`
+}
+
+// OAuthAuthorizationDTO is the content of the consent screen for an authorization request.
+type OAuthAuthorizationDTO struct {
+	App         OAuthAppDTO     `json:"app"`
+	Scopes      []OAuthScopeDTO `json:"scopes"` // The requested scopes
+	RedirectURI string          `json:"redirect_uri"`
+	Consented   bool            `json:"consented"` // Whether the user has already consented to all requested scopes
+}
+
+// OAuthRedirectDTO is returned after the user's decision on the consent screen.
+type OAuthRedirectDTO struct {
+	RedirectURI string `json:"redirect_uri"` // The client's redirect URI with the code (or the error) and the state
+}
+
+// AuthorizedAppDTO represents an application the user has authorized.
+type AuthorizedAppDTO struct {
+	OAuthAppDTO
+	Scopes       []string  `json:"scopes"`
+	AuthorizedAt time.Time `json:"authorized_at"` // First authorization
+	UpdatedAt    time.Time `json:"updated_at"`    // Most recent authorization
+}
+
+// ToAuthorizedAppDTOs converts the user's OAuthConsent models, with their clients loaded, to AuthorizedAppDTOs.
+func ToAuthorizedAppDTOs(consents []models.OAuthConsent) []AuthorizedAppDTO {
+	result := make([]AuthorizedAppDTO, 0, len(consents))
+	for i := range consents {
+		consent := &consents[i]
+		result = append(result, AuthorizedAppDTO{
+			OAuthAppDTO: OAuthAppDTO{
+				ClientID: consent.OAuthClient.ClientID,
+				Name:     consent.OAuthClient.Name,
+				LogoURL:  consent.OAuthClient.LogoURL,
+			},
+			Scopes:       consent.ScopeList(),
+			AuthorizedAt: consent.CreatedAt,
+			UpdatedAt:    consent.UpdatedAt,
+		})
+	}
+	return result
+}
+
+// OAuthTokenResponse is the response of the token endpoint (RFC 6749 section 5.1).
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// TokenIntrospectionResponse is the response of the introspection endpoint (RFC 7662 section 2.2).
+// Inactive tokens only have "active": false.
+type TokenIntrospectionResponse struct {
+	Active         bool     `json:"active"`
+	TokenType      string   `json:"token_type,omitempty"`
+	Subject        string   `json:"sub,omitempty"`         // The user ID as a string
+	UserID         uint     `json:"user_id,omitempty"`     // The user ID
+	Roles          []string `json:"roles,omitempty"`       // Names of the user's current roles
+	Permissions    []string `json:"permissions,omitempty"` // Names of the permissions the roles grant
+	SessionID      string   `json:"sid,omitempty"`
+	ExpiresAt      int64    `json:"exp,omitempty"` // Unix time
+	TokenID        string   `json:"jti,omitempty"`
+	MFA            bool     `json:"mfa,omitempty"`
+	ClientID       string   `json:"client_id,omitempty"`       // Only for tokens issued to third-party OAuth clients
+	Scope          string   `json:"scope,omitempty"`           // Only for tokens issued to third-party OAuth clients
+	ImpersonatorID uint     `json:"impersonator_id,omitempty"` // Only for impersonation tokens
+}
+
+// UserInfoDTO is the response of the userinfo endpoint, with OpenID Connect standard claims
+// (OpenID Connect Core 1.0 section 5.1). Claims without a value are omitted.
+type UserInfoDTO struct {
+	Subject             string  `json:"sub"`
+	Name                string  `json:"name,omitempty"`
+	Picture             *string `json:"picture,omitempty"`
+	Gender              string  `json:"gender,omitempty"`
+	Birthdate           string  `json:"birthdate,omitempty"` // YYYY-MM-DD
+	Locale              string  `json:"locale,omitempty"`
+	Email               *string `json:"email,omitempty"`
+	EmailVerified       *bool   `json:"email_verified,omitempty"`
+	PhoneNumber         *string `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool   `json:"phone_number_verified,omitempty"`
+	UpdatedAt           int64   `json:"updated_at"` // Unix time
+}
+
+// ToUserInfoDTO converts a User model to a UserInfoDTO.
+func ToUserInfoDTO(user *models.User) *UserInfoDTO {
+	info := &UserInfoDTO{
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		Name:      user.Name,
+		Locale:    user.Locale,
+		UpdatedAt: user.UpdatedAt.Unix(),
+	}
+	if user.AvatarURL != nil && *user.AvatarURL != "" {
+		info.Picture = user.AvatarURL
+	}
+	if user.Gender != models.PREFER_NOT_TO_SAY && !user.Gender.IsEmpty() {
+		info.Gender = strings.ToLower(string(user.Gender))
+	}
+	if user.BirthDate != nil {
+		info.Birthdate = user.BirthDate.Format("2006-01-02")
+	}
+	if user.Email != nil && *user.Email != "" {
+		info.Email = user.Email
+		info.EmailVerified = &user.IsEmailVerified
+	}
+	if user.Phone != nil && *user.Phone != "" {
+		info.PhoneNumber = user.Phone
+		info.PhoneNumberVerified = &user.IsPhoneVerified
+	}
+	return info
+}
+
+// OAuthErrorResponse is the error response of the token and revocation endpoints (RFC 6749 section 5.2).
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+/* Request DTOs */
+
+// CreateOAuthClientRequest is the request for registering an OAuth client (admin).
+// Confidential clients get a secret; public clients (mobile and single-page apps) rely on PKCE alone.
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	LogoURL      string   `json:"logo_url" validate:"omitempty,url,max=500"`
+	Confidential bool     `json:"confidential"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1,max=10,unique,dive,required,max=500"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,unique,dive,oneof=profile products"`
+}
+
+// UpdateOAuthClientRequest is the request for updating an OAuth client (admin). Omitted fields are unchanged.
+type UpdateOAuthClientRequest struct {
+	Name         *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	LogoURL      *string  `json:"logo_url" validate:"omitempty,url,max=500"`
+	RedirectURIs []string `json:"redirect_uris" validate:"omitempty,min=1,max=10,unique,dive,required,max=500"`
+	Scopes       []string `json:"scopes" validate:"omitempty,min=1,unique,dive,oneof=profile products"`
+}
+
+// OAuthAuthorizeRequest holds the parameters of an authorization request (RFC 6749 section 4.1.1, RFC 7636).
+// The frontend passes on the query parameters it was opened with.
+type OAuthAuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" validate:"required"`
+	ClientID            string `form:"client_id" json:"client_id" validate:"required,max=64"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri" validate:"required,max=500"`
+	Scope               string `form:"scope" json:"scope" validate:"max=200"` // Space-separated, defaults to all scopes of the client
+	State               string `form:"state" json:"state" validate:"max=500"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// OAuthAuthorizeDecisionRequest is the user's decision on the consent screen.
+type OAuthAuthorizeDecisionRequest struct {
+	OAuthAuthorizeRequest
+	Approve bool `json:"approve"`
+}
+
+// OAuthTokenGrantRequest is a request to the token endpoint of this server, sent as application/x-www-form-urlencoded.
+// Client credentials may also be sent with HTTP Basic authentication.
+type OAuthTokenGrantRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"` // Optional narrower scope when refreshing
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenIntrospectionRequest is a request to the introspection endpoint (RFC 7662), sent by an internal
+// service with its credentials from oauth_server.introspection_clients.
+type TokenIntrospectionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// OAuthRevokeRequest is a request to the revocation endpoint (RFC 7009).
+type OAuthRevokeRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
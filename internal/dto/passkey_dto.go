@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/pkg/webauthn"
+)
+
+/* Response DTOs */
+
+// PasskeyDTO represents a registered passkey.
+type PasskeyDTO struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	AAGUID         string     `json:"aaguid"`
+	Transports     []string   `json:"transports"`
+	BackupEligible bool       `json:"backup_eligible"` // Synced passkey (e.g. iCloud Keychain)
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ToPasskeyDTOs converts Passkey models to PasskeyDTOs.
+func ToPasskeyDTOs(passkeys []models.Passkey) []PasskeyDTO {
+	result := make([]PasskeyDTO, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		transports := []string{}
+		if passkey.Transports != "" {
+			transports = strings.Split(passkey.Transports, ",")
+		}
+		result = append(result, PasskeyDTO{
+			ID:             passkey.ID,
+			Name:           passkey.Name,
+			AAGUID:         passkey.AAGUID,
+			Transports:     transports,
+			BackupEligible: passkey.BackupEligible,
+			LastUsedAt:     passkey.LastUsedAt,
+			CreatedAt:      passkey.CreatedAt,
+		})
+	}
+	return result
+}
+
+/* Request DTOs */
+
+// FinishPasskeyRegistrationRequest is the request for completing passkey registration.
+// Credential is the result of navigator.credentials.create() serialized with PublicKeyCredential.toJSON().
+type FinishPasskeyRegistrationRequest struct {
+	Name       string                       `json:"name" validate:"omitempty,max=100"`
+	Credential webauthn.AttestationResponse `json:"credential"`
+}
+
+// FinishPasskeyLoginRequest is the request for completing a passkey login.
+// Credential is the result of navigator.credentials.get() serialized with PublicKeyCredential.toJSON().
+type FinishPasskeyLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+}
@@ -93,6 +93,18 @@ type CategoryDTO struct {
 	Name string `json:"name"`
 }
 
+// ProductFacetsDTO holds the requested facets of a product list, by facet name.
+type ProductFacetsDTO map[string][]FacetValueDTO
+
+// FacetValueDTO is the number of matching products with a value of a facet.
+type FacetValueDTO struct {
+	Value    interface{} `json:"value"`               // Category ID, or barcode type
+	Name     string      `json:"name,omitempty"`      // Category name
+	NameZH   string      `json:"name_zh,omitempty"`   // Category name in Chinese
+	ParentID *uint       `json:"parent_id,omitempty"` // Parent category, to show the counts as a tree
+	Count    int         `json:"count"`
+}
+
 // UserProductImageDTO is the product image DTO for user-facing APIs.
 type UserProductImageDTO struct {
 	ImageURL string `json:"image_url"`
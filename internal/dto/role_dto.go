@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+/* Response DTOs */
+
+// RoleDTO represents a role with the names of its permissions.
+type RoleDTO struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"is_system"` // Built-in role with every permission, cannot be modified or deleted
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToRoleDTO converts a Role model to a RoleDTO.
+func ToRoleDTO(role *models.Role) RoleDTO {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+	return RoleDTO{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// ToRoleDTOs converts Role models to RoleDTOs.
+func ToRoleDTOs(roles []models.Role) []RoleDTO {
+	result := make([]RoleDTO, 0, len(roles))
+	for i := range roles {
+		result = append(result, ToRoleDTO(&roles[i]))
+	}
+	return result
+}
+
+/* Request DTOs */
+
+// CreateRoleRequest is the request for creating a role.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50"`
+	Description string   `json:"description" validate:"omitempty,max=200"`
+	Permissions []string `json:"permissions" validate:"omitempty,unique"`
+}
+
+// UpdateRoleRequest is the request for updating a role. Omitted fields are left unchanged;
+// permissions, if present, replaces the role's permissions.
+type UpdateRoleRequest struct {
+	Description *string  `json:"description" validate:"omitempty,max=200"`
+	Permissions []string `json:"permissions" validate:"omitempty,unique"`
+}
+
+// AssignRoleRequest is the request for assigning a role to a user.
+type AssignRoleRequest struct {
+	RoleID uint `json:"role_id" validate:"required"`
+}
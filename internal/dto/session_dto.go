@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+// ClientInfo describes the device a login request comes from.
+type ClientInfo struct {
+	DeviceName string // From the X-Device-Name header
+	DeviceID   string // From the X-Device-ID header, a stable ID generated by the app, used as the device fingerprint
+	UserAgent  string
+	IPAddress  string
+}
+
+/* Response DTOs */
+
+// SessionDTO represents a login session (device).
+type SessionDTO struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	IsCurrent  bool      `json:"is_current"` // Whether this is the session of the current request
+}
+
+// ToSessionDTOs converts Session models to SessionDTOs, marking the current session.
+func ToSessionDTOs(sessions []models.Session, currentSessionID string) []SessionDTO {
+	result := make([]SessionDTO, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionDTO{
+			ID:         session.ID,
+			Provider:   session.Provider,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			CreatedAt:  session.CreatedAt,
+			IsCurrent:  session.ID == currentSessionID,
+		})
+	}
+	return result
+}
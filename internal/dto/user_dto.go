@@ -18,6 +18,7 @@ type UserProfileDTO struct {
 	Email           *string           `json:"email"`
 	IsEmailVerified bool              `json:"is_email_verified"`
 	Phone           *string           `json:"phone"`
+	IsPhoneVerified bool              `json:"is_phone_verified"`
 	BirthDate       *time.Time        `json:"birth_date"`
 	Locale          string            `json:"locale"`
 
@@ -39,6 +40,7 @@ func ToUserProfileDTO(user *models.User) *UserProfileDTO {
 		Email:           user.Email,
 		IsEmailVerified: user.IsEmailVerified,
 		Phone:           user.Phone,
+		IsPhoneVerified: user.IsPhoneVerified,
 		BirthDate:       user.BirthDate,
 		Locale:          user.Locale,
 		CreatedAt:       user.CreatedAt,
@@ -50,7 +52,7 @@ func ToUserProfileDTO(user *models.User) *UserProfileDTO {
 
 // RegisterWithPasswordRequest is the request for registering a new user with a password.
 type RegisterWithPasswordRequest struct {
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"` // Checked against the password policy
 	Email    string `json:"email" validate:"required,email"`
 
 	// Optional fields for user profile
@@ -147,8 +149,8 @@ func (r *UpdateProfileRequest) ToUpdatesMap() map[string]interface{} {
 
 // UpdatePasswordRequest defines the DTO for updating a user's password.
 type UpdatePasswordRequest struct {
-	CurrentPassword string `json:"current_password" validate:"required,min=8"` // Current password
-	NewPassword     string `json:"new_password" validate:"required,min=8"`     // New password
+	CurrentPassword string `json:"current_password" validate:"required"` // Current password
+	NewPassword     string `json:"new_password" validate:"required"`     // New password, checked against the password policy
 }
 
 // LoginWithPasswordRequest is the request for logging in with a password.
@@ -176,6 +178,32 @@ type VerifyEmailRequest struct {
 	Code  string `json:"code" validate:"required,len=6"`  // 6-digit verification code
 }
 
+// DTOs related to changing the email address
+
+// EmailChangeRequest DTO for requesting a change of the email address.
+type EmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email,max=100"` // New email address
+	Password string `json:"password"`                                    // Current password, required if the account has one
+}
+
+// ConfirmEmailChangeRequest DTO for confirming a change of the email address with the code sent to the new address.
+type ConfirmEmailChangeRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email,max=100"` // New email address
+	Code     string `json:"code" validate:"required,len=6"`              // 6-digit verification code
+}
+
+// PhoneChangeRequest DTO for requesting a change of the phone number.
+type PhoneChangeRequest struct {
+	NewPhone string `json:"new_phone" validate:"required,e164"` // New phone number (E.164 format)
+	Password string `json:"password"`                           // Current password, required if the account has one
+}
+
+// ConfirmPhoneChangeRequest DTO for confirming a change of the phone number with the code sent to the new number.
+type ConfirmPhoneChangeRequest struct {
+	NewPhone string `json:"new_phone" validate:"required,e164"` // New phone number (E.164 format)
+	Code     string `json:"code" validate:"required,len=6"`     // 6-digit verification code
+}
+
 // PasswordResetRequest DTO for requesting a password reset.
 type PasswordResetRequest struct {
 	Email string `json:"email" validate:"required,email"` // Email address
@@ -183,9 +211,40 @@ type PasswordResetRequest struct {
 
 // PasswordResetConfirmRequest DTO for confirming a password reset.
 type PasswordResetConfirmRequest struct {
-	Email       string `json:"email" validate:"required,email"`        // Email address
-	ResetToken  string `json:"reset_token" validate:"required,len=8"`  // 8-digit reset token
-	NewPassword string `json:"new_password" validate:"required,min=8"` // New password
+	Email       string `json:"email" validate:"required,email"`       // Email address
+	ResetToken  string `json:"reset_token" validate:"required,len=8"` // 8-digit reset token
+	NewPassword string `json:"new_password" validate:"required"`      // New password, checked against the password policy
+}
+
+// DTOs related to magic link (email login)
+
+// SendMagicLinkRequest DTO for requesting a login link by email.
+type SendMagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"` // Email address
+}
+
+// MagicLinkLoginRequest DTO for redeeming a login link.
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" validate:"required"` // Token from the link
+}
+
+// DTOs related to phone verification and login
+
+// SendPhoneCodeRequest DTO for requesting a phone verification or login code.
+type SendPhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required,e164"` // E.164 phone number format
+}
+
+// VerifyPhoneRequest DTO for verifying a phone number with a code.
+type VerifyPhoneRequest struct {
+	Phone string `json:"phone" validate:"required,e164"` // E.164 phone number format
+	Code  string `json:"code" validate:"required,len=6"` // 6-digit verification code
+}
+
+// LoginWithPhoneCodeRequest DTO for logging in with a phone number and a code sent by SMS.
+type LoginWithPhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required,e164"` // E.164 phone number format
+	Code  string `json:"code" validate:"required,len=6"` // 6-digit verification code
 }
 
 // DTOs related to WeChat Mini Program
@@ -221,28 +280,23 @@ func (r *RegisterFromWechatMiniProgramRequest) ToModel() *models.User {
 
 // DTOs related to OAuth2
 
-// WechatOAuthRequest is the unified request for WeChat OAuth2 (login or registration).
-type WechatOAuthRequest struct {
-	Code       string `json:"code" validate:"required"`                      // WeChat OAuth authorization code
-	ClientType string `json:"client_type" validate:"required,oneof=web app"` // Client type: web or app
-}
-
-// GoogleOAuthRequest is the unified request for Google OAuth2 (login or registration).
-type GoogleOAuthRequest struct {
-	Code         string `json:"code" validate:"required"`                      // OAuth authorization code
-	CodeVerifier string `json:"code_verifier" validate:"required"`             // PKCE code verifier
-	RedirectURI  string `json:"redirect_uri" validate:"required,url"`          // Redirect URI, must match configuration
-	ClientType   string `json:"client_type" validate:"required,oneof=ios web"` // Client type: ios or web
+// OAuthTokenRequest is the unified request for logging in or registering with an OAuth provider.
+// Either an authorization code (authorization code flow, with PKCE for public clients) or an ID token
+// obtained by a native SDK (e.g. Sign in with Apple) must be provided.
+type OAuthTokenRequest struct {
+	ClientType    string  `json:"client_type" validate:"omitempty,max=32"`                            // Client type configured for the provider, e.g. web, ios, app
+	Code          string  `json:"code" validate:"required_without=IdentityToken,omitempty,max=2048"`  // OAuth authorization code
+	CodeVerifier  string  `json:"code_verifier" validate:"omitempty,min=43,max=128"`                  // PKCE code verifier
+	RedirectURI   string  `json:"redirect_uri" validate:"omitempty,max=500"`                          // Redirect URI of the authorization request, must match configuration (not used by WeChat)
+	IdentityToken string  `json:"identity_token" validate:"required_without=Code,omitempty,max=8192"` // ID token (JWT) from the provider
+	Nonce         string  `json:"nonce" validate:"omitempty,max=128"`                                 // Raw nonce used for the authorization request
+	GivenName     *string `json:"given_name" validate:"omitempty,max=50"`                             // Only used for new users if the provider does not return a name (Apple)
+	FamilyName    *string `json:"family_name" validate:"omitempty,max=50"`                            // Only used for new users if the provider does not return a name (Apple)
 }
 
 // DTOs related to account binding
 
-// BindWechatAccountRequest is the request for binding a WeChat account.
-type BindWechatAccountRequest struct {
-	WechatOAuthRequest // Directly use WechatOAuthRequest for binding request
-}
-
-// BindGoogleAccountRequest is the request for binding a Google account.
-type BindGoogleAccountRequest struct {
-	GoogleOAuthRequest // Directly use GoogleOAuthRequest for binding request
+// BindOAuthAccountRequest is the request for binding an OAuth provider account.
+type BindOAuthAccountRequest struct {
+	OAuthTokenRequest // Directly use OAuthTokenRequest for binding request
 }
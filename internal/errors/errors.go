@@ -17,6 +17,9 @@ var (
 	ErrInvalidCredentials = NewAppError("invalid_credentials", "Invalid credentials", http.StatusUnauthorized)
 	ErrNoValidUpdates     = NewAppError("no_valid_updates", "No valid fields to update", http.StatusBadRequest)
 	ErrInvalidDateFormat  = NewAppError("invalid_date_format", "Invalid date format", http.StatusBadRequest)
+	ErrInvalidCursor      = NewAppError("invalid_cursor", "Invalid cursor, or cursor used with a different sort", http.StatusBadRequest)
+	ErrInvalidSort        = NewAppError("invalid_sort", "The list cannot be sorted by this field", http.StatusBadRequest)
+	ErrInvalidFilter      = NewAppError("invalid_filter", "The list cannot be filtered by this field, operator or value", http.StatusBadRequest)
 
 	// User related errors
 	ErrUserNotFound            = NewAppError("user_not_found", "User not found", http.StatusNotFound)
@@ -31,18 +34,91 @@ var (
 	ErrPasswordTooShort        = NewAppError("password_too_short", "Password must be at least 8 characters", http.StatusBadRequest)
 	ErrPasswordTooWeak         = NewAppError("password_too_weak", "Password is too weak", http.StatusBadRequest)
 	ErrUserBanned              = NewAppError("user_banned", "User is banned", http.StatusForbidden)
+	ErrCannotBanSelf           = NewAppError("cannot_ban_self", "You cannot ban yourself", http.StatusBadRequest)
+	ErrInvalidBanExpiry        = NewAppError("invalid_ban_expiry", "Ban expiry must be in the future", http.StatusBadRequest)
+	ErrAccountPendingDeletion  = NewAppError("account_pending_deletion", "Account is scheduled for deletion", http.StatusForbidden)
 	ErrInvalidToken            = NewAppError("invalid_token", "Invalid or expired token", http.StatusUnauthorized)
+	ErrRefreshTokenReused      = NewAppError("refresh_token_reused", "Refresh token has already been used, please log in again", http.StatusUnauthorized)
 	ErrInvalidVerificationCode = NewAppError("invalid_verification_code", "Invalid verification code", http.StatusBadRequest)
 	ErrVerificationCodeExpired = NewAppError("verification_code_expired", "Verification code expired", http.StatusBadRequest)
+	ErrLoginThrottled          = NewAppError("login_throttled", "Too many failed login attempts, please wait before trying again", http.StatusTooManyRequests)
+	ErrAccountLocked           = NewAppError("account_locked", "Too many failed login attempts, login is temporarily locked", http.StatusTooManyRequests)
+
+	// Session related errors
+	ErrSessionNotFound = NewAppError("session_not_found", "Session not found", http.StatusNotFound)
+	ErrSessionRevoked  = NewAppError("session_revoked", "Session has been logged out", http.StatusUnauthorized)
+
+	// API key related errors
+	ErrAPIKeyNotFound      = NewAppError("api_key_not_found", "API key not found", http.StatusNotFound)
+	ErrInvalidAPIKey       = NewAppError("invalid_api_key", "Invalid, expired or revoked API key", http.StatusUnauthorized)
+	ErrAPIKeyScopeDenied   = NewAppError("api_key_scope_denied", "API key is not allowed to access this resource", http.StatusForbidden)
+	ErrAPIKeyLimitExceeded = NewAppError("api_key_limit_exceeded", "Maximum number of active API keys reached", http.StatusConflict)
+	ErrInvalidAPIKeyExpiry = NewAppError("invalid_api_key_expiry", "Invalid API key expiration", http.StatusBadRequest)
+
+	// Role and permission related errors
+	ErrRoleNotFound         = NewAppError("role_not_found", "Role not found", http.StatusNotFound)
+	ErrRoleAlreadyExists    = NewAppError("role_already_exists", "Role already exists", http.StatusConflict)
+	ErrRoleNotAssigned      = NewAppError("role_not_assigned", "Role is not assigned to the user", http.StatusNotFound)
+	ErrInvalidRoleName      = NewAppError("invalid_role_name", "Role name may only contain lowercase letters, digits, '_' and '-'", http.StatusBadRequest)
+	ErrUnknownPermission    = NewAppError("unknown_permission", "Unknown permission", http.StatusBadRequest)
+	ErrSystemRoleImmutable  = NewAppError("system_role_immutable", "System roles cannot be modified or deleted", http.StatusBadRequest)
+	ErrPermissionEscalation = NewAppError("permission_escalation", "Cannot grant permissions you do not have", http.StatusForbidden)
+	ErrLastSystemAdmin      = NewAppError("last_system_admin", "Cannot remove the admin role from the last admin", http.StatusBadRequest)
+
+	// Impersonation related errors
+	ErrCannotImpersonate      = NewAppError("cannot_impersonate", "Cannot impersonate yourself or another admin", http.StatusBadRequest)
+	ErrImpersonationForbidden = NewAppError("impersonation_forbidden", "This operation is not allowed while impersonating a user", http.StatusForbidden)
+
+	// OAuth2 authorization server related errors
+	ErrOAuthClientNotFound   = NewAppError("oauth_client_not_found", "OAuth client not found", http.StatusNotFound)
+	ErrOAuthClientPublic     = NewAppError("oauth_client_public", "Public OAuth clients have no secret", http.StatusBadRequest)
+	ErrInvalidRedirectURI    = NewAppError("invalid_redirect_uri", "Redirect URIs must use https, a loopback http address or a private-use scheme such as com.example.app, and have no fragment", http.StatusBadRequest)
+	ErrOAuthAppNotAuthorized = NewAppError("oauth_app_not_authorized", "The application has not been authorized", http.StatusNotFound)
+	ErrInsufficientScope     = NewAppError("insufficient_scope", "The access token does not have the scope required by this resource", http.StatusForbidden)
+	// Errors of the authorization, token and revocation endpoints use the error codes of RFC 6749.
+	ErrOAuthInvalidRequest          = NewAppError("invalid_request", "The request is missing a required parameter or is otherwise malformed", http.StatusBadRequest)
+	ErrOAuthPKCERequired            = NewAppError("invalid_request", "A code_challenge with code_challenge_method S256 is required", http.StatusBadRequest)
+	ErrOAuthInvalidClient           = NewAppError("invalid_client", "Unknown client or client authentication failed", http.StatusUnauthorized)
+	ErrOAuthInvalidRedirect         = NewAppError("invalid_request", "The redirect_uri is not registered for this client", http.StatusBadRequest)
+	ErrOAuthInvalidGrant            = NewAppError("invalid_grant", "The authorization code or refresh token is invalid, expired or revoked", http.StatusBadRequest)
+	ErrOAuthInvalidScope            = NewAppError("invalid_scope", "The requested scope is invalid or not allowed for this client", http.StatusBadRequest)
+	ErrOAuthUnsupportedGrantType    = NewAppError("unsupported_grant_type", "Only the authorization_code and refresh_token grant types are supported", http.StatusBadRequest)
+	ErrOAuthUnsupportedResponseType = NewAppError("unsupported_response_type", "Only the authorization code flow (response_type=code) is supported", http.StatusBadRequest)
+
+	// Two-factor authentication related errors
+	ErrMFARequired       = NewAppError("mfa_required", "Two-factor authentication is required", http.StatusForbidden)
+	ErrInvalidMFACode    = NewAppError("invalid_mfa_code", "Invalid two-factor authentication code", http.StatusUnauthorized)
+	ErrMFAAlreadyEnabled = NewAppError("mfa_already_enabled", "Two-factor authentication is already enabled", http.StatusConflict)
+	ErrMFANotEnabled     = NewAppError("mfa_not_enabled", "Two-factor authentication is not enabled", http.StatusBadRequest)
+	ErrMFANotEnrolled    = NewAppError("mfa_not_enrolled", "Two-factor authentication enrollment has not been started", http.StatusBadRequest)
+
+	// Passkey related errors
+	ErrPasskeyNotFound           = NewAppError("passkey_not_found", "Passkey not found", http.StatusNotFound)
+	ErrPasskeyAlreadyRegistered  = NewAppError("passkey_already_registered", "Passkey is already registered", http.StatusConflict)
+	ErrPasskeyVerificationFailed = NewAppError("passkey_verification_failed", "Passkey verification failed", http.StatusUnauthorized)
 
 	// Email verification related errors
 	ErrEmailNotVerified            = NewAppError("email_not_verified", "Email address is not verified", http.StatusUnauthorized)
 	ErrEmailAlreadyVerified        = NewAppError("email_already_verified", "Email address is already verified", http.StatusBadRequest)
 	ErrTooManyVerificationRequests = NewAppError("too_many_verification_requests", "Too many verification requests. Please wait before requesting again", http.StatusTooManyRequests)
 
+	// Email change related errors
+	ErrEmailChangeRequired = NewAppError("email_change_required", "The email address can only be changed through the email change confirmation", http.StatusBadRequest)
+	ErrEmailUnchanged      = NewAppError("email_unchanged", "The new email address is the same as the current one", http.StatusBadRequest)
+
+	// Phone verification related errors
+	ErrPhoneNotVerified     = NewAppError("phone_not_verified", "Phone number is not verified", http.StatusUnauthorized)
+	ErrPhoneAlreadyVerified = NewAppError("phone_already_verified", "Phone number is already verified", http.StatusBadRequest)
+	ErrSMSDisabled          = NewAppError("sms_disabled", "SMS is not available on this server", http.StatusServiceUnavailable)
+
+	// Phone change related errors
+	ErrPhoneChangeRequired = NewAppError("phone_change_required", "The phone number can only be changed through the phone change confirmation", http.StatusBadRequest)
+	ErrPhoneUnchanged      = NewAppError("phone_unchanged", "The new phone number is the same as the current one", http.StatusBadRequest)
+
 	// Account binding related errors
 	ErrProviderAlreadyBound = NewAppError("provider_already_bound", "Account is already bound to this or another user", http.StatusConflict)
 	ErrProviderNotBound     = NewAppError("provider_not_bound", "Account is not bound", http.StatusNotFound)
+	ErrLastLoginMethod      = NewAppError("last_login_method", "Cannot unbind the only sign-in method of the account", http.StatusBadRequest)
 
 	// Product related errors
 	ErrProductNotFound   = NewAppError("product_not_found", "Product not found", http.StatusNotFound)
@@ -51,6 +127,7 @@ var (
 	ErrBarcodeExists     = NewAppError("barcode_exists", "Product with this barcode already exists", http.StatusConflict)
 	ErrCategoryNotFound  = NewAppError("category_not_found", "Category not found", http.StatusNotFound)
 	ErrProductImageEmpty = NewAppError("product_image_empty", "Product image cannot be empty", http.StatusBadRequest)
+	ErrInvalidFacet      = NewAppError("invalid_facet", "Unknown facet, supported facets are categories and barcode_type", http.StatusBadRequest)
 
 	// Moderation related errors
 	ErrModeratorNotFound = NewAppError("moderator_not_found", "Moderator not found", http.StatusNotFound)
@@ -70,13 +147,14 @@ var (
 	ErrAIModelNotAvailable = NewAppError("ai_model_not_available", "AI model is not available", http.StatusServiceUnavailable)
 	ErrNoRecognitionResult = NewAppError("no_recognition_result", "No recognition result", http.StatusNotFound)
 
-	// Google OAuth2 related errors
-	ErrInvalidOAuthCode         = NewAppError("invalid_oauth_code", "Invalid OAuth authorization code", http.StatusBadRequest)
-	ErrOAuthTokenExchange       = NewAppError("oauth_token_exchange", "Failed to exchange OAuth code for token", http.StatusBadRequest)
-	ErrOAuthUserInfoFetch       = NewAppError("oauth_user_info_fetch", "Failed to fetch user info from OAuth provider", http.StatusBadRequest)
-	ErrInvalidRedirectURL       = NewAppError("invalid_redirect_url", "Invalid redirect URL", http.StatusBadRequest)
-	ErrInvalidClientType        = NewAppError("invalid_client_type", "Invalid client type for OAuth", http.StatusBadRequest)
-	ErrGoogleUserInfoIncomplete = NewAppError("google_user_info_incomplete", "Google user info is incomplete", http.StatusBadRequest)
+	// OAuth2 / OpenID Connect related errors
+	ErrOAuthProviderNotFound = NewAppError("oauth_provider_not_found", "OAuth provider not found", http.StatusNotFound)
+	ErrInvalidOAuthCode      = NewAppError("invalid_oauth_code", "Invalid OAuth authorization code", http.StatusBadRequest)
+	ErrOAuthTokenExchange    = NewAppError("oauth_token_exchange", "Failed to exchange OAuth code for token", http.StatusBadRequest)
+	ErrOAuthUserInfoFetch    = NewAppError("oauth_user_info_fetch", "Failed to fetch user info from OAuth provider", http.StatusBadRequest)
+	ErrInvalidRedirectURL    = NewAppError("invalid_redirect_url", "Invalid redirect URL", http.StatusBadRequest)
+	ErrInvalidClientType     = NewAppError("invalid_client_type", "Invalid client type for OAuth", http.StatusBadRequest)
+	ErrInvalidIdentityToken  = NewAppError("invalid_identity_token", "Invalid or expired identity token", http.StatusUnauthorized)
 
 	// Feedback related errors
 	ErrFeedbackNotFound         = NewAppError("feedback_not_found", "Feedback not found", http.StatusNotFound)
@@ -95,10 +173,11 @@ var (
 
 // AppError defines a custom application error.
 type AppError struct {
-	Code    string // Error code, e.g., "user_not_found"
-	Message string // User-friendly error message
-	Status  int    // HTTP status code
-	Err     error  // Original underlying error, if any
+	Code    string      // Error code, e.g., "user_not_found"
+	Message string      // User-friendly error message
+	Status  int         // HTTP status code
+	Err     error       // Original underlying error, if any
+	Data    interface{} // Extra details returned to the client, e.g. retry_after
 }
 
 // Error implements the error interface.
@@ -111,6 +190,19 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether target is an AppError with the same code, so copies made by WithData still match.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// WithData returns a copy of the error carrying extra details for the client.
+func (e *AppError) WithData(data interface{}) *AppError {
+	copied := *e
+	copied.Data = data
+	return &copied
+}
+
 // NewAppError creates a new application error.
 func NewAppError(code, message string, status int) *AppError {
 	return &AppError{
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// AccountHandler handles HTTP requests for deleting the current user's account and exporting their data.
+type AccountHandler struct {
+	AccountService services.AccountService
+}
+
+// NewAccountHandler creates a new AccountHandler.
+func NewAccountHandler(accountService services.AccountService) *AccountHandler {
+	return &AccountHandler{
+		AccountService: accountService,
+	}
+}
+
+// DeleteAccount schedules the deletion of the current user's account and logs the user out everywhere.
+func (h *AccountHandler) DeleteAccount(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body. Accounts without a password may send no body.
+	var payload dto.DeleteAccountRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil && err != io.EOF {
+		logger.Warn(ctx, "Invalid delete account request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Call service layer to schedule the deletion.
+	scheduledAt, err := h.AccountService.ScheduleDeletion(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.AccountDeletionDTO{DeletionScheduledAt: scheduledAt}, "Account scheduled for deletion, log in again before the deletion date to cancel"))
+}
+
+// ExportAccountData returns a zip archive of the current user's data.
+func (h *AccountHandler) ExportAccountData(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to build the archive.
+	archive, err := h.AccountService.ExportData(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK with the archive as a download.
+	filename := fmt.Sprintf("account-export-%d-%s.zip", authenticatedUser.ID, time.Now().Format("20060102"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "application/zip", archive)
+}
@@ -0,0 +1,60 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+type APIKeyHandler struct {
+	APIKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService: apiKeyService,
+	}
+}
+
+// ListUserAPIKeys lists a user's API keys.
+func (h *APIKeyHandler) ListUserAPIKeys(ctx *gin.Context) {
+	// Get user ID from path parameters.
+	userID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to list keys.
+	apiKeys, err := h.APIKeyService.ListAPIKeys(ctx.Request.Context(), uint(userID)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToAPIKeyDTOs(apiKeys), ""))
+}
+
+// RevokeAPIKey revokes any API key.
+func (h *APIKeyHandler) RevokeAPIKey(ctx *gin.Context) {
+	// Get key ID from path parameters.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to revoke the key.
+	if err := h.APIKeyService.RevokeAPIKeyForAdmin(ctx.Request.Context(), uint(id)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "API key revoked by admin", "apiKeyId", id)
+	ctx.JSON(http.StatusNoContent, nil)
+}
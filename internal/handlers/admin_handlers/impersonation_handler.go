@@ -0,0 +1,92 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/query_params"
+	"github.com/go-backend-template/pkg/response"
+)
+
+type ImpersonationHandler struct {
+	ImpersonationService services.ImpersonationService
+}
+
+func NewImpersonationHandler(impersonationService services.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		ImpersonationService: impersonationService,
+	}
+}
+
+// Impersonate issues a short-lived access token that lets the admin act as a user.
+func (h *ImpersonationHandler) Impersonate(ctx *gin.Context) {
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Impersonation is for admins at the console, not for API keys.
+	if handler_utils.GetAPIKeyID(ctx) != 0 {
+		handler_utils.HandleError(ctx, errors.ErrAPIKeyScopeDenied)
+		return
+	}
+
+	// Parse user ID.
+	userID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Parse request body to DTO.
+	var impersonateReq dto.ImpersonateRequest
+	if err := ctx.ShouldBindJSON(&impersonateReq); err != nil {
+		logger.Warn(ctx, "Invalid impersonation request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&impersonateReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for Impersonate", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to issue the impersonation token.
+	token, err := h.ImpersonationService.Impersonate(ctx.Request.Context(), authenticatedUser, uint(userID), &impersonateReq, handler_utils.GetClientInfo(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(token, ""))
+}
+
+// ListImpersonationEvents retrieves a list of impersonation events.
+func (h *ImpersonationHandler) ListImpersonationEvents(ctx *gin.Context) {
+	// Get parsed query parameters from context.
+	params, _ := ctx.Get("queryParams")
+	queryParams, ok := params.(*query_params.QueryParams)
+	if !ok {
+		logger.Warn(ctx, "Invalid query parameters type", "params", params)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid query parameters type"))
+		return
+	}
+
+	// Get impersonation event list.
+	events, pagination, err := h.ImpersonationService.ListImpersonationEvents(ctx.Request.Context(), queryParams) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(events, "", *pagination))
+}
@@ -0,0 +1,145 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+type OAuthClientHandler struct {
+	OAuthServerService services.OAuthServerService
+}
+
+func NewOAuthClientHandler(oauthServerService services.OAuthServerService) *OAuthClientHandler {
+	return &OAuthClientHandler{
+		OAuthServerService: oauthServerService,
+	}
+}
+
+// ListOAuthClients lists the registered OAuth clients.
+func (h *OAuthClientHandler) ListOAuthClients(ctx *gin.Context) {
+	clients, err := h.OAuthServerService.ListClients(ctx.Request.Context()) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToOAuthClientDTOs(clients), ""))
+}
+
+// CreateOAuthClient registers an OAuth client. The secret of a confidential client is only returned in this response.
+func (h *OAuthClientHandler) CreateOAuthClient(ctx *gin.Context) {
+	// Get current authenticated admin.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body to DTO.
+	var createReq dto.CreateOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&createReq); err != nil {
+		logger.Warn(ctx, "Invalid OAuth client creation request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&createReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for CreateOAuthClient", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to register the client.
+	client, secret, err := h.OAuthServerService.CreateClient(ctx.Request.Context(), authenticatedUser, &createReq) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 201 Created.
+	message := ""
+	if secret != "" {
+		message = "Store the client secret now, it will not be shown again"
+	}
+	ctx.JSON(http.StatusCreated, response.NewSuccessResponse(dto.CreatedOAuthClientDTO{OAuthClientDTO: dto.ToOAuthClientDTO(client), ClientSecret: secret}, message))
+}
+
+// UpdateOAuthClient updates an OAuth client.
+func (h *OAuthClientHandler) UpdateOAuthClient(ctx *gin.Context) {
+	// Parse client ID.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Parse request body to DTO.
+	var updateReq dto.UpdateOAuthClientRequest
+	if err := ctx.ShouldBindJSON(&updateReq); err != nil {
+		logger.Warn(ctx, "Invalid OAuth client update request", "oauthClientId", id, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&updateReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for UpdateOAuthClient", "oauthClientId", id, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to update the client.
+	client, err := h.OAuthServerService.UpdateClient(ctx.Request.Context(), uint(id), &updateReq) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToOAuthClientDTO(client), ""))
+}
+
+// RotateOAuthClientSecret replaces the secret of a confidential OAuth client.
+func (h *OAuthClientHandler) RotateOAuthClientSecret(ctx *gin.Context) {
+	// Parse client ID.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to rotate the secret.
+	client, secret, err := h.OAuthServerService.RotateClientSecret(ctx.Request.Context(), uint(id)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.CreatedOAuthClientDTO{OAuthClientDTO: dto.ToOAuthClientDTO(client), ClientSecret: secret}, "Store the client secret now, it will not be shown again"))
+}
+
+// DeleteOAuthClient deletes an OAuth client and revokes all of its grants.
+func (h *OAuthClientHandler) DeleteOAuthClient(ctx *gin.Context) {
+	// Parse client ID.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to delete the client.
+	if err := h.OAuthServerService.DeleteClient(ctx.Request.Context(), uint(id)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "OAuth client deleted by admin", "oauthClientId", id)
+	ctx.JSON(http.StatusNoContent, nil)
+}
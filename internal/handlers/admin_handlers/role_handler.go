@@ -0,0 +1,210 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+type RoleHandler struct {
+	RoleService services.RoleService
+}
+
+func NewRoleHandler(roleService services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		RoleService: roleService,
+	}
+}
+
+// ListPermissions lists all permissions that can be granted to roles.
+func (h *RoleHandler) ListPermissions(ctx *gin.Context) {
+	permissions, err := h.RoleService.ListPermissions(ctx.Request.Context()) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(permissions, ""))
+}
+
+// ListRoles lists all roles.
+func (h *RoleHandler) ListRoles(ctx *gin.Context) {
+	roles, err := h.RoleService.ListRoles(ctx.Request.Context()) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToRoleDTOs(roles), ""))
+}
+
+// CreateRole creates a role.
+func (h *RoleHandler) CreateRole(ctx *gin.Context) {
+	// Parse request body to DTO.
+	var createReq dto.CreateRoleRequest
+	if err := ctx.ShouldBindJSON(&createReq); err != nil {
+		logger.Warn(ctx, "Invalid role creation request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&createReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for CreateRole", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to create the role.
+	role, err := h.RoleService.CreateRole(ctx.Request.Context(), &createReq, handler_utils.GetPermissions(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 201 Created.
+	logger.Info(ctx, "Role created successfully", "roleId", role.ID)
+	ctx.JSON(http.StatusCreated, response.NewSuccessResponse(dto.ToRoleDTO(role), ""))
+}
+
+// UpdateRole updates a role's description and permissions.
+func (h *RoleHandler) UpdateRole(ctx *gin.Context) {
+	// Parse role ID.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Parse request body to DTO.
+	var updateReq dto.UpdateRoleRequest
+	if err := ctx.ShouldBindJSON(&updateReq); err != nil {
+		logger.Warn(ctx, "Invalid role update request", "roleId", id, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&updateReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for UpdateRole", "roleId", id, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to update the role.
+	role, err := h.RoleService.UpdateRole(ctx.Request.Context(), uint(id), &updateReq, handler_utils.GetPermissions(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToRoleDTO(role), ""))
+}
+
+// DeleteRole deletes a role and removes it from all users.
+func (h *RoleHandler) DeleteRole(ctx *gin.Context) {
+	// Parse role ID.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to delete the role.
+	if err := h.RoleService.DeleteRole(ctx.Request.Context(), uint(id), handler_utils.GetPermissions(ctx)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "Role deleted", "roleId", id)
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// ListUserRoles lists the roles assigned to a user.
+func (h *RoleHandler) ListUserRoles(ctx *gin.Context) {
+	// Parse user ID.
+	userID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to list the user's roles.
+	roles, err := h.RoleService.ListUserRoles(ctx.Request.Context(), uint(userID)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToRoleDTOs(roles), ""))
+}
+
+// AssignRole assigns a role to a user.
+func (h *RoleHandler) AssignRole(ctx *gin.Context) {
+	// Get current authenticated admin.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse user ID.
+	userID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Parse request body to DTO.
+	var assignReq dto.AssignRoleRequest
+	if err := ctx.ShouldBindJSON(&assignReq); err != nil {
+		logger.Warn(ctx, "Invalid role assignment request", "userId", userID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&assignReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for AssignRole", "userId", userID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to assign the role.
+	err = h.RoleService.AssignRole(ctx.Request.Context(), uint(userID), assignReq.RoleID, authenticatedUser.ID, handler_utils.GetPermissions(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// RemoveRole removes a role from a user.
+func (h *RoleHandler) RemoveRole(ctx *gin.Context) {
+	// Parse user ID and role ID.
+	userID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+	roleID, err := handler_utils.ParseUintParam(ctx, "role_id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to remove the role.
+	if err := h.RoleService.RemoveRole(ctx.Request.Context(), uint(userID), uint(roleID), handler_utils.GetPermissions(ctx)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	ctx.JSON(http.StatusNoContent, nil)
+}
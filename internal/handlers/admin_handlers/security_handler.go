@@ -0,0 +1,74 @@
+package admin_handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/query_params"
+	"github.com/go-backend-template/pkg/response"
+)
+
+type SecurityHandler struct {
+	LoginProtectionService services.LoginProtectionService
+	LoginHistoryService    services.LoginHistoryService
+}
+
+func NewSecurityHandler(loginProtectionService services.LoginProtectionService, loginHistoryService services.LoginHistoryService) *SecurityHandler {
+	return &SecurityHandler{
+		LoginProtectionService: loginProtectionService,
+		LoginHistoryService:    loginHistoryService,
+	}
+}
+
+// ListLockoutEvents retrieves a list of login lockout events.
+func (h *SecurityHandler) ListLockoutEvents(ctx *gin.Context) {
+	// Get parsed query parameters from context.
+	params, _ := ctx.Get("queryParams")
+	queryParams, ok := params.(*query_params.QueryParams)
+	if !ok {
+		logger.Warn(ctx, "Invalid query parameters type", "params", params)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid query parameters type"))
+		return
+	}
+
+	// Get lockout event list.
+	events, pagination, err := h.LoginProtectionService.ListLockoutEvents(ctx.Request.Context(), queryParams) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(events, "", *pagination))
+}
+
+// ListUserLoginHistory retrieves a user's login history.
+func (h *SecurityHandler) ListUserLoginHistory(ctx *gin.Context) {
+	// Get user ID from path parameters.
+	userID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Get parsed query parameters from context.
+	params, _ := ctx.Get("queryParams")
+	queryParams, ok := params.(*query_params.QueryParams)
+	if !ok {
+		logger.Warn(ctx, "Invalid query parameters type", "params", params)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid query parameters type"))
+		return
+	}
+
+	// Get login event list.
+	events, pagination, err := h.LoginHistoryService.ListLoginEvents(ctx.Request.Context(), uint(userID), queryParams) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(events, "", *pagination))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-backend-template/internal/dto"
 	"github.com/go-backend-template/internal/handlers/handler_utils"
 	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
 	"github.com/go-backend-template/pkg/logger"
 	"github.com/go-backend-template/pkg/query_params"
 	"github.com/go-backend-template/pkg/response"
@@ -14,11 +15,13 @@ import (
 
 type UserHandler struct {
 	UserService services.UserService
+	BanService  services.BanService
 }
 
-func NewUserHandler(UserService services.UserService) *UserHandler {
+func NewUserHandler(UserService services.UserService, banService services.BanService) *UserHandler {
 	return &UserHandler{
 		UserService: UserService,
+		BanService:  banService,
 	}
 }
 
@@ -166,28 +169,73 @@ func (h *UserHandler) RestoreUser(ctx *gin.Context) {
 Custom interfaces
 */
 
-// BanUser bans or unbans a user.
+// BanUser bans or unbans a user. A ban replaces the user's current ban, if there is one.
 func (h *UserHandler) BanUser(ctx *gin.Context) {
+	// Get current authenticated admin.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
 	// Parse user ID.
 	id, err := handler_utils.ParseUintParam(ctx, "id")
 	if err != nil {
 		return
 	}
 
-	// Parse whether to ban or unban from the request body, defaults to ban.
-	var payload struct {
-		IsBanned bool `json:"is_banned"`
+	// Parse request body to DTO.
+	var banReq dto.BanUserRequest
+	if err := ctx.ShouldBindJSON(&banReq); err != nil {
+		logger.Warn(ctx, "Invalid ban request", "userId", id, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&banReq); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for BanUser", "userId", id, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
 	}
-	// If no value is provided, it can default to ban (or handle as needed).
-	ctx.ShouldBindJSON(&payload)
 
-	// Call service layer to ban/unban the user.
-	err = h.UserService.BanUser(ctx.Request.Context(), uint(id), payload.IsBanned) // Pass context
+	// Unban the user.
+	if !banReq.IsBanned {
+		if err := h.BanService.UnbanUser(ctx.Request.Context(), authenticatedUser, uint(id)); err != nil { // Pass context
+			handler_utils.HandleError(ctx, err)
+			return
+		}
+
+		// Return 204 No Content.
+		ctx.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	// Call service layer to ban the user.
+	ban, err := h.BanService.BanUser(ctx.Request.Context(), authenticatedUser, uint(id), &banReq) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
-	logger.Info(ctx, "User ban status updated", "userId", id, "banned", payload.IsBanned)
-	ctx.JSON(http.StatusNoContent, nil)
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(ban, ""))
+}
+
+// ListUserBans retrieves a user's ban history.
+func (h *UserHandler) ListUserBans(ctx *gin.Context) {
+	// Parse user ID.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to list the bans.
+	bans, err := h.BanService.ListBans(ctx.Request.Context(), uint(id)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(bans, ""))
 }
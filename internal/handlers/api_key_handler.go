@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// APIKeyHandler handles HTTP requests related to the current user's API keys.
+type APIKeyHandler struct {
+	APIKeyService services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		APIKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKey creates an API key. The full key is only returned in this response.
+func (h *APIKeyHandler) CreateAPIKey(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body.
+	var payload dto.CreateAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid create API key request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for CreateAPIKey", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to create the key.
+	apiKey, key, err := h.APIKeyService.CreateAPIKey(ctx.Request.Context(), authenticatedUser, handler_utils.IsMFAAuthenticated(ctx), &payload) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 201 Created.
+	ctx.JSON(http.StatusCreated, response.NewSuccessResponse(dto.CreatedAPIKeyDTO{APIKeyDTO: dto.ToAPIKeyDTO(apiKey), Key: key}, "Store this key now, it will not be shown again"))
+}
+
+// ListAPIKeys lists the current user's API keys.
+func (h *APIKeyHandler) ListAPIKeys(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to list keys.
+	apiKeys, err := h.APIKeyService.ListAPIKeys(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToAPIKeyDTOs(apiKeys), ""))
+}
+
+// RevokeAPIKey revokes one of the current user's API keys.
+func (h *APIKeyHandler) RevokeAPIKey(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse key ID from path.
+	id, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to revoke the key.
+	if err := h.APIKeyService.RevokeAPIKey(ctx.Request.Context(), authenticatedUser.ID, uint(id)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "API key revoked", "requesterId", authenticatedUser.ID, "apiKeyId", id)
+	ctx.JSON(http.StatusNoContent, nil)
+}
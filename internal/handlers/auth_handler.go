@@ -9,6 +9,7 @@ import (
 	"github.com/go-backend-template/internal/handlers/handler_utils"
 	"github.com/go-backend-template/internal/services"
 	"github.com/go-backend-template/internal/utils" // Added validator utility
+	"github.com/go-backend-template/pkg/jwt"
 	"github.com/go-backend-template/pkg/logger"
 	"github.com/go-backend-template/pkg/response"
 )
@@ -71,6 +72,18 @@ func (h *AuthHandler) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 
+	// The email address is changed through the email change flow, which confirms the new address first.
+	if payload.Email != nil && *payload.Email != "" && (authenticatedUser.Email == nil || *authenticatedUser.Email != *payload.Email) {
+		handler_utils.HandleError(ctx, errors.ErrEmailChangeRequired)
+		return
+	}
+
+	// The phone number is changed through the phone change flow, which confirms the new number first.
+	if payload.Phone != nil && *payload.Phone != "" && (authenticatedUser.Phone == nil || *authenticatedUser.Phone != *payload.Phone) {
+		handler_utils.HandleError(ctx, errors.ErrPhoneChangeRequired)
+		return
+	}
+
 	// Call service layer to update user.
 	err := h.UserService.UpdateUser(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
 	if err != nil {
@@ -165,7 +178,50 @@ func (h *AuthHandler) LoginWithPassword(ctx *gin.Context) {
 	}
 
 	// Call service layer to authenticate and get tokens.
-	accessToken, refreshToken, err := h.UserService.LoginWithPassword(ctx.Request.Context(), payload.EmailOrPhone, payload.Password) // Pass context
+	accessToken, refreshToken, mfaToken, err := h.UserService.LoginWithPassword(ctx.Request.Context(), payload.EmailOrPhone, payload.Password, handler_utils.GetClientInfo(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Two-factor authentication required: the client must call /auth/mfa/login with the MFA token.
+	if mfaToken != "" {
+		ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(jwt.MFAPendingTokenDuration.Seconds()),
+		}, "Two-factor authentication required"))
+		return
+	}
+
+	// Return tokens.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    3600 * 24 * 7, // Assuming 7 days expiration
+	}, ""))
+}
+
+// CompleteMFALogin completes a login waiting for a second factor with a TOTP code or recovery code.
+func (h *AuthHandler) CompleteMFALogin(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.MFALoginRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid MFA login request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for CompleteMFALogin", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to verify the second factor and get tokens.
+	accessToken, refreshToken, err := h.UserService.CompleteMFALogin(ctx.Request.Context(), payload.MFAToken, payload.Code, handler_utils.GetClientInfo(ctx)) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
@@ -271,118 +327,139 @@ func (h *AuthHandler) VerifyEmail(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Email verified successfully"))
 }
 
-// SendPasswordReset sends a password reset email.
-func (h *AuthHandler) SendPasswordReset(ctx *gin.Context) {
+// RequestEmailChange sends a code to the new email address and a notice to the current one.
+func (h *AuthHandler) RequestEmailChange(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
 	// Parse request body.
-	var payload dto.PasswordResetRequest
+	var payload dto.EmailChangeRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid password reset request", "error", err)
+		logger.Warn(ctx, "Invalid email change request", "requesterId", authenticatedUser.ID, "error", err)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for SendPasswordReset", "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for RequestEmailChange", "requesterId", authenticatedUser.ID, "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
-	// Call service layer to send password reset email.
-	err := h.UserService.SendPasswordReset(ctx.Request.Context(), payload.Email) // Pass context
+	// Call service layer to request the email change.
+	err := h.UserService.RequestEmailChange(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
 	// Return success response.
-	logger.Info(ctx, "Password reset email sent", "email", payload.Email)
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Password reset email sent successfully"))
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Verification code sent to the new email address"))
 }
 
-// ResetPassword resets the user's password.
-func (h *AuthHandler) ResetPassword(ctx *gin.Context) {
+// ConfirmEmailChange changes the email address after the code sent to the new address is confirmed.
+func (h *AuthHandler) ConfirmEmailChange(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
 	// Parse request body.
-	var payload dto.PasswordResetConfirmRequest
+	var payload dto.ConfirmEmailChangeRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid password reset confirm request", "error", err)
+		logger.Warn(ctx, "Invalid confirm email change request", "requesterId", authenticatedUser.ID, "error", err)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for ResetPassword", "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for ConfirmEmailChange", "requesterId", authenticatedUser.ID, "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
-	// Call service layer to reset password.
-	err := h.UserService.ResetPassword(ctx.Request.Context(), payload.Email, payload.ResetToken, payload.NewPassword) // Pass context
+	// Call service layer to change the email address.
+	err := h.UserService.ConfirmEmailChange(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
 	// Return success response.
-	logger.Info(ctx, "Password reset successfully", "email", payload.Email)
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Password reset successfully"))
+	logger.Info(ctx, "Email changed successfully", "requesterId", authenticatedUser.ID)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Email changed successfully"))
 }
 
-// RegisterFromWechatMiniProgram handles registration from WeChat Mini Program.
-func (h *AuthHandler) RegisterFromWechatMiniProgram(ctx *gin.Context) {
-	// Get and validate OpenID and UnionID.
-	openID, unionID, ok := handler_utils.GetWechatIDs(ctx)
-	if !ok {
-		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(errors.ErrOpenIDNotProvided.Message))
-		return
-	}
-
+// SendMagicLink sends a one-time login link by email.
+func (h *AuthHandler) SendMagicLink(ctx *gin.Context) {
 	// Parse request body.
-	var payload dto.RegisterFromWechatMiniProgramRequest
+	var payload dto.SendMagicLinkRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid user creation request", "error", err)
+		logger.Warn(ctx, "Invalid magic link request", "error", err)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
-	// Validate UpdateProfileRequest part of the payload as RegisterFromWechatMiniProgramRequest embeds it.
+	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for RegisterFromWechatMiniProgram (ProfileData)", "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for SendMagicLink", "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
-	// Call service layer to create user.
-	createdUserID, err := h.UserService.RegisterFromWechatMiniProgram(ctx.Request.Context(), &payload, unionID, openID) // Pass context
+	// Call service layer to send the login link.
+	err := h.UserService.SendMagicLink(ctx.Request.Context(), payload.Email) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
-	// Return 201 Created.
-	logger.Info(ctx, "User created", "userId", createdUserID)
-	ctx.JSON(http.StatusCreated, response.NewSuccessResponse(gin.H{"id": createdUserID}, ""))
+	// Return success response.
+	logger.Info(ctx, "Magic link sent", "email", payload.Email)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Login link sent successfully"))
 }
 
-// LoginFromWechatMiniProgram handles login from WeChat Mini Program.
-func (h *AuthHandler) LoginFromWechatMiniProgram(ctx *gin.Context) {
-	// Extract openID and unionID from header.
-	openID, unionID, ok := handler_utils.GetWechatIDs(ctx)
-	if !ok {
-		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Missing WeChat credentials"))
+// LoginWithMagicLink handles login with the token from a magic link.
+func (h *AuthHandler) LoginWithMagicLink(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.MagicLinkLoginRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid magic link login request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
-	// Call Service layer to authenticate and get token.
-	accessToken, refreshToken, err := h.UserService.LoginFromWechatMiniProgram(ctx.Request.Context(), unionID, openID) // Pass context
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for LoginWithMagicLink", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
 
+	// Call service layer to redeem the link and get tokens.
+	accessToken, refreshToken, mfaToken, err := h.UserService.LoginWithMagicLink(ctx.Request.Context(), payload.Token, handler_utils.GetClientInfo(ctx)) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
-	// Return token.
+	// Two-factor authentication required: the client must call /auth/mfa/login with the MFA token.
+	if mfaToken != "" {
+		ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(jwt.MFAPendingTokenDuration.Seconds()),
+		}, "Two-factor authentication required"))
+		return
+	}
+
+	// Return tokens.
 	ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
@@ -391,44 +468,109 @@ func (h *AuthHandler) LoginFromWechatMiniProgram(ctx *gin.Context) {
 	}, ""))
 }
 
-// ExchangeWechatOAuth handles WeChat OAuth code exchange (auto determines login/registration).
-func (h *AuthHandler) ExchangeWechatOAuth(ctx *gin.Context) {
-	var payload dto.WechatOAuthRequest
+// SendPhoneCode sends a verification or login code by SMS.
+func (h *AuthHandler) SendPhoneCode(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.SendPhoneCodeRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid Wechat OAuth request", "error", err)
-		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid parameters: "+err.Error())) // "参数错误: " -> "Invalid parameters: "
+		logger.Warn(ctx, "Invalid phone code request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for ExchangeWechatOAuth", "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for SendPhoneCode", "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
-	accessToken, refreshToken, isNewUser, err := h.UserService.ExchangeWechatOAuth(ctx.Request.Context(), &payload) // Pass context
+	// Call service layer to send the code.
+	err := h.UserService.SendPhoneCode(ctx.Request.Context(), payload.Phone) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
-	responseData := gin.H{
+	// Return success response.
+	logger.Info(ctx, "Phone code sent", "phone", payload.Phone)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Verification code sent successfully"))
+}
+
+// VerifyPhone verifies a phone number.
+func (h *AuthHandler) VerifyPhone(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.VerifyPhoneRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid verify phone request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for VerifyPhone", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to verify the phone number.
+	err := h.UserService.VerifyPhone(ctx.Request.Context(), payload.Phone, payload.Code) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return success response.
+	logger.Info(ctx, "Phone verified successfully", "phone", payload.Phone)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Phone number verified successfully"))
+}
+
+// LoginWithPhoneCode handles login with a phone number and a code sent by SMS.
+func (h *AuthHandler) LoginWithPhoneCode(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.LoginWithPhoneCodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid phone login request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for LoginWithPhoneCode", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to verify the code and get tokens.
+	accessToken, refreshToken, mfaToken, err := h.UserService.LoginWithPhoneCode(ctx.Request.Context(), payload.Phone, payload.Code, handler_utils.GetClientInfo(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Two-factor authentication required: the client must call /auth/mfa/login with the MFA token.
+	if mfaToken != "" {
+		ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(jwt.MFAPendingTokenDuration.Seconds()),
+		}, "Two-factor authentication required"))
+		return
+	}
+
+	// Return tokens.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
 		"access_token":  accessToken,
 		"refresh_token": refreshToken,
 		"token_type":    "Bearer",
-		"expires_in":    3600 * 24 * 7,
-		"is_new_user":   isNewUser,
-	}
-	if isNewUser {
-		ctx.JSON(http.StatusCreated, response.NewSuccessResponse(responseData, "User registered and logged in successfully")) // "用户注册并登录成功" -> "User registered and logged in successfully"
-	} else {
-		ctx.JSON(http.StatusOK, response.NewSuccessResponse(responseData, "User logged in successfully")) // "用户登录成功" -> "User logged in successfully"
-	}
+		"expires_in":    3600 * 24 * 7, // Assuming 7 days expiration
+	}, ""))
 }
 
-// BindWechatAccount binds a WeChat account.
-func (h *AuthHandler) BindWechatAccount(ctx *gin.Context) {
+// RequestPhoneChange sends a code to the new phone number.
+func (h *AuthHandler) RequestPhoneChange(ctx *gin.Context) {
 	// Get current authenticated user.
 	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
 	if !ok {
@@ -436,74 +578,233 @@ func (h *AuthHandler) BindWechatAccount(ctx *gin.Context) {
 	}
 
 	// Parse request body.
-	var payload dto.BindWechatAccountRequest
+	var payload dto.PhoneChangeRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid bind WeChat account request", "error", err)
+		logger.Warn(ctx, "Invalid phone change request", "requesterId", authenticatedUser.ID, "error", err)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for BindWechatAccount", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for RequestPhoneChange", "requesterId", authenticatedUser.ID, "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
-	// Call service layer to bind WeChat account.
-	err := h.UserService.BindWechatAccount(ctx.Request.Context(), authenticatedUser.ID, &payload, authenticatedUser) // Pass context
+	// Call service layer to request the phone change.
+	err := h.UserService.RequestPhoneChange(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
 	// Return success response.
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "WeChat account bound successfully"))
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Verification code sent to the new phone number"))
 }
 
-// UnbindWechatAccount unbinds a WeChat account.
-func (h *AuthHandler) UnbindWechatAccount(ctx *gin.Context) {
+// ConfirmPhoneChange changes the phone number after the code sent to the new number is confirmed.
+func (h *AuthHandler) ConfirmPhoneChange(ctx *gin.Context) {
 	// Get current authenticated user.
 	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
 	if !ok {
 		return
 	}
 
-	// Call service layer to unbind WeChat account.
-	err := h.UserService.UnbindWechatAccount(ctx.Request.Context(), authenticatedUser.ID, authenticatedUser) // Pass context
+	// Parse request body.
+	var payload dto.ConfirmPhoneChangeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid confirm phone change request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for ConfirmPhoneChange", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to change the phone number.
+	err := h.UserService.ConfirmPhoneChange(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return success response.
+	logger.Info(ctx, "Phone changed successfully", "requesterId", authenticatedUser.ID)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Phone number changed successfully"))
+}
+
+// SendPasswordReset sends a password reset email.
+func (h *AuthHandler) SendPasswordReset(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.PasswordResetRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid password reset request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for SendPasswordReset", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to send password reset email.
+	err := h.UserService.SendPasswordReset(ctx.Request.Context(), payload.Email) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return success response.
+	logger.Info(ctx, "Password reset email sent", "email", payload.Email)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Password reset email sent successfully"))
+}
+
+// ResetPassword resets the user's password.
+func (h *AuthHandler) ResetPassword(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.PasswordResetConfirmRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid password reset confirm request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for ResetPassword", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to reset password.
+	err := h.UserService.ResetPassword(ctx.Request.Context(), payload.Email, payload.ResetToken, payload.NewPassword) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
 	// Return success response.
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "WeChat account unbound successfully"))
+	logger.Info(ctx, "Password reset successfully", "email", payload.Email)
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Password reset successfully"))
 }
 
-// ExchangeGoogleOAuth handles Google OAuth code exchange (auto determines login/registration).
-func (h *AuthHandler) ExchangeGoogleOAuth(ctx *gin.Context) {
+// RegisterFromWechatMiniProgram handles registration from WeChat Mini Program.
+func (h *AuthHandler) RegisterFromWechatMiniProgram(ctx *gin.Context) {
+	// Get and validate OpenID and UnionID.
+	openID, unionID, ok := handler_utils.GetWechatIDs(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(errors.ErrOpenIDNotProvided.Message))
+		return
+	}
+
 	// Parse request body.
-	var payload dto.GoogleOAuthRequest
+	var payload dto.RegisterFromWechatMiniProgramRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid Google OAuth request", "error", err)
+		logger.Warn(ctx, "Invalid user creation request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate UpdateProfileRequest part of the payload as RegisterFromWechatMiniProgramRequest embeds it.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for RegisterFromWechatMiniProgram (ProfileData)", "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to create user.
+	createdUserID, err := h.UserService.RegisterFromWechatMiniProgram(ctx.Request.Context(), &payload, unionID, openID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 201 Created.
+	logger.Info(ctx, "User created", "userId", createdUserID)
+	ctx.JSON(http.StatusCreated, response.NewSuccessResponse(gin.H{"id": createdUserID}, ""))
+}
+
+// LoginFromWechatMiniProgram handles login from WeChat Mini Program.
+func (h *AuthHandler) LoginFromWechatMiniProgram(ctx *gin.Context) {
+	// Extract openID and unionID from header.
+	openID, unionID, ok := handler_utils.GetWechatIDs(ctx)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Missing WeChat credentials"))
+		return
+	}
+
+	// Call Service layer to authenticate and get token.
+	accessToken, refreshToken, mfaToken, err := h.UserService.LoginFromWechatMiniProgram(ctx.Request.Context(), unionID, openID, handler_utils.GetClientInfo(ctx)) // Pass context
+
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Two-factor authentication required: the client must call /auth/mfa/login with the MFA token.
+	if mfaToken != "" {
+		ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(jwt.MFAPendingTokenDuration.Seconds()),
+		}, "Two-factor authentication required"))
+		return
+	}
+
+	// Return token.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    3600 * 24 * 7, // Assuming 7 days expiration
+	}, ""))
+}
+
+// ExchangeOAuth handles login with an OAuth provider (auto determines login/registration).
+func (h *AuthHandler) ExchangeOAuth(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	// Parse request body.
+	var payload dto.OAuthTokenRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid OAuth request", "provider", provider, "error", err)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for ExchangeGoogleOAuth", "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for ExchangeOAuth", "provider", provider, "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
 	// Call service layer to authenticate (auto determines login/registration).
-	accessToken, refreshToken, isNewUser, err := h.UserService.ExchangeGoogleOAuth(ctx.Request.Context(), &payload) // Pass context
+	accessToken, refreshToken, mfaToken, isNewUser, err := h.UserService.ExchangeOAuth(ctx.Request.Context(), provider, &payload, handler_utils.GetClientInfo(ctx)) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
+	// Two-factor authentication required: the client must call /auth/mfa/login with the MFA token.
+	// Newly registered users have no second factor yet.
+	if mfaToken != "" {
+		ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(jwt.MFAPendingTokenDuration.Seconds()),
+		}, "Two-factor authentication required"))
+		return
+	}
+
 	// Return token and user status.
 	responseData := gin.H{
 		"access_token":  accessToken,
@@ -521,8 +822,10 @@ func (h *AuthHandler) ExchangeGoogleOAuth(ctx *gin.Context) {
 	}
 }
 
-// BindGoogleAccount binds a Google account.
-func (h *AuthHandler) BindGoogleAccount(ctx *gin.Context) {
+// BindOAuthAccount binds an OAuth provider account.
+func (h *AuthHandler) BindOAuthAccount(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
 	// Get current authenticated user.
 	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
 	if !ok {
@@ -530,46 +833,48 @@ func (h *AuthHandler) BindGoogleAccount(ctx *gin.Context) {
 	}
 
 	// Parse request body.
-	var payload dto.BindGoogleAccountRequest
+	var payload dto.BindOAuthAccountRequest
 	if err := ctx.ShouldBindJSON(&payload); err != nil {
-		logger.Warn(ctx, "Invalid bind Google account request", "error", err)
+		logger.Warn(ctx, "Invalid bind account request", "provider", provider, "error", err)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
 		return
 	}
 
 	// Validate payload.
 	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
-		logger.Warn(ctx, "Validation failed for BindGoogleAccount", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		logger.Warn(ctx, "Validation failed for BindOAuthAccount", "provider", provider, "requesterId", authenticatedUser.ID, "errors", validationErrs)
 		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
 		return
 	}
 
-	// Call service layer to bind Google account.
-	err := h.UserService.BindGoogleAccount(ctx.Request.Context(), authenticatedUser.ID, &payload, authenticatedUser) // Pass context
+	// Call service layer to bind the account.
+	err := h.UserService.BindOAuthAccount(ctx.Request.Context(), authenticatedUser.ID, provider, &payload, authenticatedUser) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
 	// Return success response.
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Google account bound successfully"))
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Account bound successfully"))
 }
 
-// UnbindGoogleAccount unbinds a Google account.
-func (h *AuthHandler) UnbindGoogleAccount(ctx *gin.Context) {
+// UnbindOAuthAccount unbinds an OAuth provider account.
+func (h *AuthHandler) UnbindOAuthAccount(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
 	// Get current authenticated user.
 	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
 	if !ok {
 		return
 	}
 
-	// Call service layer to unbind Google account.
-	err := h.UserService.UnbindGoogleAccount(ctx.Request.Context(), authenticatedUser.ID, authenticatedUser) // Pass context
+	// Call service layer to unbind the account.
+	err := h.UserService.UnbindOAuthAccount(ctx.Request.Context(), authenticatedUser.ID, provider, authenticatedUser) // Pass context
 	if err != nil {
 		handler_utils.HandleError(ctx, err)
 		return
 	}
 
 	// Return success response.
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Google account unbound successfully"))
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(nil, "Account unbound successfully"))
 }
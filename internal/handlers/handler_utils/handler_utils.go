@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
 	"github.com/go-backend-template/internal/errors"
 	"github.com/go-backend-template/internal/models"
 	"github.com/go-backend-template/pkg/logger"
@@ -61,6 +62,36 @@ func GetAuthenticatedUser(ctx *gin.Context) (*models.User, bool) {
 	return nil, false
 }
 
+// GetSessionID 获取当前请求所属的登录会话ID（旧Token可能为空）
+func GetSessionID(ctx *gin.Context) string {
+	return ctx.GetString("sessionID")
+}
+
+// IsMFAAuthenticated 当前请求的登录是否通过了两步验证
+func IsMFAAuthenticated(ctx *gin.Context) bool {
+	return ctx.GetBool("mfa")
+}
+
+// GetAPIKeyID 获取当前请求使用的API密钥ID，未使用API密钥时为0
+func GetAPIKeyID(ctx *gin.Context) uint {
+	return ctx.GetUint("apiKeyID")
+}
+
+// GetPermissions 获取当前管理员拥有的权限（由 AdminAuthMiddleware 加载）
+func GetPermissions(ctx *gin.Context) []string {
+	return ctx.GetStringSlice("permissions")
+}
+
+// GetClientInfo 获取登录请求的设备信息
+func GetClientInfo(ctx *gin.Context) *dto.ClientInfo {
+	return &dto.ClientInfo{
+		DeviceName: ctx.GetHeader("X-Device-Name"),
+		DeviceID:   ctx.GetHeader("X-Device-ID"),
+		UserAgent:  ctx.Request.UserAgent(),
+		IPAddress:  ctx.ClientIP(),
+	}
+}
+
 // GetWechatIDs 获取微信相关ID (OpenID, UnionID)
 func GetWechatIDs(ctx *gin.Context) (*string, *string, bool) {
 	openIDStr := ctx.GetHeader("x-wx-openid")
@@ -88,6 +119,10 @@ func GetWechatIDs(ctx *gin.Context) (*string, *string, bool) {
 func HandleError(ctx *gin.Context, err error) {
 	// 处理 AppError 类型的错误
 	if appError, ok := err.(*errors.AppError); ok {
+		if appError.Data != nil {
+			ctx.JSON(appError.Status, response.NewErrorResponseWithData(appError.Message, appError.Data))
+			return
+		}
 		ctx.JSON(appError.Status, response.NewErrorResponse(appError.Message))
 		return
 	}
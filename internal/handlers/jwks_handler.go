@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/pkg/jwt"
+)
+
+// JWKSHandler publishes the public keys used to sign JWTs.
+type JWKSHandler struct {
+	JWTKeys *jwt.KeySet
+}
+
+// NewJWKSHandler creates a new JWKSHandler.
+func NewJWKSHandler(jwtKeys *jwt.KeySet) *JWKSHandler {
+	return &JWKSHandler{
+		JWTKeys: jwtKeys,
+	}
+}
+
+// GetJWKS returns the JSON Web Key Set.
+// The response is the bare RFC 7517 key set (not wrapped in the standard response format)
+// so that standard JWT libraries can consume it directly.
+func (h *JWKSHandler) GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=3600")
+	ctx.JSON(http.StatusOK, h.JWTKeys.JWKS())
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/query_params"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// LoginHistoryHandler handles HTTP requests related to the current user's login history.
+type LoginHistoryHandler struct {
+	LoginHistoryService services.LoginHistoryService
+}
+
+// NewLoginHistoryHandler creates a new LoginHistoryHandler.
+func NewLoginHistoryHandler(loginHistoryService services.LoginHistoryService) *LoginHistoryHandler {
+	return &LoginHistoryHandler{
+		LoginHistoryService: loginHistoryService,
+	}
+}
+
+// ListLoginHistory lists the current user's login attempts, newest first unless sorted otherwise.
+func (h *LoginHistoryHandler) ListLoginHistory(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Get parsed query parameters from context.
+	params, _ := ctx.Get("queryParams")
+	queryParams, ok := params.(*query_params.QueryParams)
+	if !ok {
+		logger.Warn(ctx, "Invalid query parameters type", "params", params)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid query parameters type"))
+		return
+	}
+
+	// Call service layer to list the login history.
+	events, pagination, err := h.LoginHistoryService.ListLoginEvents(ctx.Request.Context(), authenticatedUser.ID, queryParams) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToLoginEventDTOs(events), "", *pagination))
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// MFAHandler handles HTTP requests related to TOTP two-factor authentication.
+type MFAHandler struct {
+	MFAService services.MFAService
+}
+
+// NewMFAHandler creates a new MFAHandler.
+func NewMFAHandler(mfaService services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		MFAService: mfaService,
+	}
+}
+
+// GetStatus returns the current user's two-factor authentication status.
+func (h *MFAHandler) GetStatus(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to get the status.
+	status, err := h.MFAService.GetStatus(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(status, ""))
+}
+
+// EnrollTOTP starts TOTP enrollment and returns the secret and provisioning URI.
+func (h *MFAHandler) EnrollTOTP(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to start enrollment.
+	enrollment, err := h.MFAService.EnrollTOTP(ctx.Request.Context(), authenticatedUser) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(enrollment, ""))
+}
+
+// ConfirmTOTP completes TOTP enrollment and returns the recovery codes.
+func (h *MFAHandler) ConfirmTOTP(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body.
+	var payload dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid TOTP confirm request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for ConfirmTOTP", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to enable TOTP.
+	recoveryCodes, err := h.MFAService.ConfirmTOTP(ctx.Request.Context(), authenticatedUser.ID, payload.Code) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.RecoveryCodesDTO{RecoveryCodes: recoveryCodes}, "Two-factor authentication enabled"))
+}
+
+// DisableTOTP disables two-factor authentication.
+func (h *MFAHandler) DisableTOTP(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body.
+	var payload dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid TOTP disable request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for DisableTOTP", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to disable TOTP.
+	if err := h.MFAService.DisableTOTP(ctx.Request.Context(), authenticatedUser.ID, payload.Code, handler_utils.GetClientInfo(ctx)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes.
+func (h *MFAHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body.
+	var payload dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid recovery code regeneration request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for RegenerateRecoveryCodes", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to regenerate recovery codes.
+	recoveryCodes, err := h.MFAService.RegenerateRecoveryCodes(ctx.Request.Context(), authenticatedUser.ID, payload.Code, handler_utils.GetClientInfo(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.RecoveryCodesDTO{RecoveryCodes: recoveryCodes}, ""))
+}
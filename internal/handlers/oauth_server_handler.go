@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// OAuthServerHandler handles HTTP requests of the OAuth2 authorization server: the consent screen API for the
+// frontend, the user's authorized apps, and the token and revocation endpoints called by third-party apps.
+type OAuthServerHandler struct {
+	OAuthServerService services.OAuthServerService
+}
+
+// NewOAuthServerHandler creates a new OAuthServerHandler.
+func NewOAuthServerHandler(oauthServerService services.OAuthServerService) *OAuthServerHandler {
+	return &OAuthServerHandler{
+		OAuthServerService: oauthServerService,
+	}
+}
+
+// GetAuthorization validates an authorization request and returns the content of the consent screen.
+// The frontend calls it with the query parameters its authorization page was opened with.
+func (h *OAuthServerHandler) GetAuthorization(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse query parameters.
+	var query dto.OAuthAuthorizeRequest
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		logger.Warn(ctx, "Invalid OAuth authorization request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request parameters: "+err.Error()))
+		return
+	}
+
+	// Validate query parameters.
+	if validationErrs := customValidator.ValidateStruct(&query); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for GetAuthorization", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to validate the request.
+	authorization, err := h.OAuthServerService.GetAuthorization(ctx.Request.Context(), authenticatedUser, &query) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(authorization, ""))
+}
+
+// Authorize records the user's decision on the consent screen and returns the URI the frontend redirects to.
+func (h *OAuthServerHandler) Authorize(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body.
+	var payload dto.OAuthAuthorizeDecisionRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid OAuth authorization decision", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for Authorize", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to record the decision.
+	redirect, err := h.OAuthServerService.Authorize(ctx.Request.Context(), authenticatedUser, &payload) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(redirect, ""))
+}
+
+// ListAuthorizedApps lists the third-party apps the current user has authorized.
+func (h *OAuthServerHandler) ListAuthorizedApps(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to list the apps.
+	consents, err := h.OAuthServerService.ListAuthorizedApps(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToAuthorizedAppDTOs(consents), ""))
+}
+
+// RevokeAuthorizedApp withdraws the current user's authorization of an app and revokes its tokens.
+func (h *OAuthServerHandler) RevokeAuthorizedApp(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to revoke the app.
+	clientID := ctx.Param("client_id")
+	if err := h.OAuthServerService.RevokeAuthorizedApp(ctx.Request.Context(), authenticatedUser.ID, clientID); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "Authorized app revoked", "requesterId", authenticatedUser.ID, "clientId", clientID)
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// Token is the token endpoint (RFC 6749 section 3.2), called by third-party apps to redeem an authorization
+// code or a refresh token. Requests are form encoded and responses use the bare RFC 6749 format.
+func (h *OAuthServerHandler) Token(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+
+	// Parse the form.
+	var form dto.OAuthTokenGrantRequest
+	if err := ctx.ShouldBind(&form); err != nil {
+		logger.Warn(ctx, "Invalid OAuth token request", "error", err)
+		writeOAuthError(ctx, errors.ErrOAuthInvalidRequest)
+		return
+	}
+	if !applyClientCredentials(ctx, &form.ClientID, &form.ClientSecret) {
+		writeOAuthError(ctx, errors.ErrOAuthInvalidClient)
+		return
+	}
+
+	// Call service layer to issue the tokens.
+	tokens, err := h.OAuthServerService.Token(ctx.Request.Context(), &form, handler_utils.GetClientInfo(ctx)) // Pass context
+	if err != nil {
+		writeOAuthError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// Revoke is the revocation endpoint (RFC 7009). It responds with 200 OK whether or not the token was valid.
+func (h *OAuthServerHandler) Revoke(ctx *gin.Context) {
+	// Parse the form.
+	var form dto.OAuthRevokeRequest
+	if err := ctx.ShouldBind(&form); err != nil {
+		logger.Warn(ctx, "Invalid OAuth revocation request", "error", err)
+		writeOAuthError(ctx, errors.ErrOAuthInvalidRequest)
+		return
+	}
+	if !applyClientCredentials(ctx, &form.ClientID, &form.ClientSecret) {
+		writeOAuthError(ctx, errors.ErrOAuthInvalidClient)
+		return
+	}
+
+	// Call service layer to revoke the grant.
+	if err := h.OAuthServerService.Revoke(ctx.Request.Context(), &form); err != nil { // Pass context
+		writeOAuthError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.Status(http.StatusOK)
+}
+
+// Introspect is the introspection endpoint (RFC 7662), called by internal services to check an access token
+// instead of validating it themselves. The service authenticates with HTTP Basic authentication or form
+// parameters, like OAuth clients.
+func (h *OAuthServerHandler) Introspect(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	// Parse the form.
+	var form dto.TokenIntrospectionRequest
+	if err := ctx.ShouldBind(&form); err != nil {
+		logger.Warn(ctx, "Invalid token introspection request", "error", err)
+		writeOAuthError(ctx, errors.ErrOAuthInvalidRequest)
+		return
+	}
+	if !applyClientCredentials(ctx, &form.ClientID, &form.ClientSecret) {
+		writeOAuthError(ctx, errors.ErrOAuthInvalidClient)
+		return
+	}
+
+	// Call service layer to introspect the token.
+	introspection, err := h.OAuthServerService.Introspect(ctx.Request.Context(), &form) // Pass context
+	if err != nil {
+		writeOAuthError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, introspection)
+}
+
+// UserInfo is the OpenID Connect style userinfo endpoint. It returns the standard claims of the user the
+// access token was issued for, in the bare OpenID Connect format.
+func (h *OAuthServerHandler) UserInfo(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Return 200 OK.
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, dto.ToUserInfoDTO(authenticatedUser))
+}
+
+// applyClientCredentials takes the client credentials from the HTTP Basic authentication header, if present.
+// Per RFC 6749 section 2.3.1 they are form encoded, and they cannot be sent in both the header and the form.
+func applyClientCredentials(ctx *gin.Context, clientID, clientSecret *string) bool {
+	username, password, ok := ctx.Request.BasicAuth()
+	if !ok {
+		return true
+	}
+	if *clientSecret != "" {
+		return false
+	}
+
+	id, err := url.QueryUnescape(username)
+	if err != nil {
+		return false
+	}
+	secret, err := url.QueryUnescape(password)
+	if err != nil {
+		return false
+	}
+	if *clientID != "" && *clientID != id {
+		return false
+	}
+
+	*clientID = id
+	*clientSecret = secret
+	return true
+}
+
+// writeOAuthError writes an error of the token or revocation endpoint in the RFC 6749 format.
+func writeOAuthError(ctx *gin.Context, err error) {
+	appError, ok := err.(*errors.AppError)
+	if !ok {
+		logger.Error(ctx, "OAuth endpoint failed", "error", err)
+		ctx.JSON(http.StatusInternalServerError, dto.OAuthErrorResponse{Error: "server_error"})
+		return
+	}
+
+	if appError.Status == http.StatusUnauthorized {
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	ctx.JSON(appError.Status, dto.OAuthErrorResponse{Error: appError.Code, ErrorDescription: appError.Message})
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/internal/utils"
+	"github.com/go-backend-template/pkg/jwt"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// PasskeyHandler handles HTTP requests related to passkeys (WebAuthn).
+type PasskeyHandler struct {
+	PasskeyService services.PasskeyService
+	UserService    services.UserService
+}
+
+// NewPasskeyHandler creates a new PasskeyHandler.
+func NewPasskeyHandler(passkeyService services.PasskeyService, userService services.UserService) *PasskeyHandler {
+	return &PasskeyHandler{
+		PasskeyService: passkeyService,
+		UserService:    userService,
+	}
+}
+
+// BeginRegistration returns the options for creating a new passkey.
+func (h *PasskeyHandler) BeginRegistration(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to start registration.
+	options, err := h.PasskeyService.BeginRegistration(ctx.Request.Context(), authenticatedUser) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(options, ""))
+}
+
+// FinishRegistration verifies and stores a new passkey.
+func (h *PasskeyHandler) FinishRegistration(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse request body.
+	var payload dto.FinishPasskeyRegistrationRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid passkey registration request", "requesterId", authenticatedUser.ID, "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Validate payload.
+	if validationErrs := customValidator.ValidateStruct(&payload); validationErrs != nil {
+		logger.Warn(ctx, "Validation failed for FinishPasskeyRegistration", "requesterId", authenticatedUser.ID, "errors", validationErrs)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse(utils.FormatValidationErrors(validationErrs)))
+		return
+	}
+
+	// Call service layer to register the passkey.
+	passkey, err := h.PasskeyService.FinishRegistration(ctx.Request.Context(), authenticatedUser.ID, &payload) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 201 Created.
+	ctx.JSON(http.StatusCreated, response.NewSuccessResponse(dto.ToPasskeyDTOs([]models.Passkey{*passkey})[0], ""))
+}
+
+// BeginLogin returns the options for logging in with a passkey.
+func (h *PasskeyHandler) BeginLogin(ctx *gin.Context) {
+	// Call service layer to start authentication.
+	options, err := h.PasskeyService.BeginLogin(ctx.Request.Context()) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(options, ""))
+}
+
+// FinishLogin verifies a passkey assertion and returns tokens.
+func (h *PasskeyHandler) FinishLogin(ctx *gin.Context) {
+	// Parse request body.
+	var payload dto.FinishPasskeyLoginRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		logger.Warn(ctx, "Invalid passkey login request", "error", err)
+		ctx.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request body: "+err.Error()))
+		return
+	}
+
+	// Call service layer to authenticate and get tokens.
+	accessToken, refreshToken, mfaToken, err := h.UserService.LoginWithPasskey(ctx.Request.Context(), &payload.Credential, handler_utils.GetClientInfo(ctx)) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Two-factor authentication required: the client must call /auth/mfa/login with the MFA token.
+	if mfaToken != "" {
+		ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+			"expires_in":   int(jwt.MFAPendingTokenDuration.Seconds()),
+		}, "Two-factor authentication required"))
+		return
+	}
+
+	// Return tokens.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(gin.H{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "Bearer",
+		"expires_in":    3600 * 24 * 7, // Assuming 7 days expiration
+	}, ""))
+}
+
+// ListPasskeys lists the current user's passkeys.
+func (h *PasskeyHandler) ListPasskeys(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to list passkeys.
+	passkeys, err := h.PasskeyService.ListPasskeys(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToPasskeyDTOs(passkeys), ""))
+}
+
+// DeletePasskey deletes one of the current user's passkeys.
+func (h *PasskeyHandler) DeletePasskey(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse passkey ID from path.
+	passkeyID, err := handler_utils.ParseUintParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to delete the passkey.
+	if err := h.PasskeyService.DeletePasskey(ctx.Request.Context(), authenticatedUser.ID, uint(passkeyID)); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "Passkey deleted", "requesterId", authenticatedUser.ID, "passkeyId", passkeyID)
+	ctx.JSON(http.StatusNoContent, nil)
+}
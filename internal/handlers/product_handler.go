@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-backend-template/internal/dto"
@@ -35,6 +36,8 @@ func NewProductHandler(
 // Supports Query Parameters:
 // - is_liked: whether to fetch products liked by the user.
 // - is_favorited: whether to fetch products favorited by the user.
+// - facets: comma-separated facets to count the matching products by (categories, barcode_type), for the
+// list of all products.
 func (h *ProductHandler) ListProducts(ctx *gin.Context) {
 	// Get parsed Query Parameters from context.
 	params, _ := ctx.Get("queryParams")
@@ -77,6 +80,17 @@ func (h *ProductHandler) ListProducts(ctx *gin.Context) {
 		return
 	}
 
+	// Count the matching products by the requested facets. Facets are only supported on the list of all products.
+	var facets dto.ProductFacetsDTO
+	listsAll := !(isLiked && userID > 0) && !(isFavorited && userID > 0)
+	if requested := parseFacets(ctx.Query("facets")); len(requested) > 0 && listsAll {
+		facets, err = h.ProductService.GetProductFacets(ctx.Request.Context(), queryParams, requested) // Pass context
+		if err != nil {
+			handler_utils.HandleError(ctx, err)
+			return
+		}
+	}
+
 	// Convert to user DTO.
 	userProducts := make([]dto.UserProductDTO, 0, len(products))
 	var productIDs []uint
@@ -110,7 +124,22 @@ func (h *ProductHandler) ListProducts(ctx *gin.Context) {
 	}
 
 	// Return 200 OK.
-	ctx.JSON(http.StatusOK, response.NewSuccessResponse(userProducts, "", *pagination))
+	resp := response.NewSuccessResponse(userProducts, "", *pagination)
+	if facets != nil {
+		resp.Facets = facets
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// parseFacets splits the comma-separated facets query parameter.
+func parseFacets(value string) []string {
+	var facets []string
+	for _, facet := range strings.Split(value, ",") {
+		if facet = strings.TrimSpace(facet); facet != "" {
+			facets = append(facets, facet)
+		}
+	}
+	return facets
 }
 
 // GetProduct retrieves details for a single product.
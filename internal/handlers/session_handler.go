@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/handlers/handler_utils"
+	"github.com/go-backend-template/internal/services"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// SessionHandler handles HTTP requests related to login sessions (devices).
+type SessionHandler struct {
+	SessionService services.SessionService
+}
+
+// NewSessionHandler creates a new SessionHandler.
+func NewSessionHandler(sessionService services.SessionService) *SessionHandler {
+	return &SessionHandler{
+		SessionService: sessionService,
+	}
+}
+
+// ListSessions lists the current user's active sessions.
+func (h *SessionHandler) ListSessions(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Call service layer to list sessions.
+	sessions, err := h.SessionService.ListSessions(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+	if err != nil {
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 200 OK.
+	ctx.JSON(http.StatusOK, response.NewSuccessResponse(dto.ToSessionDTOs(sessions, handler_utils.GetSessionID(ctx)), ""))
+}
+
+// RevokeSession logs out one of the current user's sessions.
+func (h *SessionHandler) RevokeSession(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Parse session ID from path.
+	sessionID, err := handler_utils.ParseUUIDParam(ctx, "id")
+	if err != nil {
+		return
+	}
+
+	// Call service layer to revoke the session.
+	if err := h.SessionService.RevokeSession(ctx.Request.Context(), authenticatedUser.ID, sessionID); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "Session revoked", "requesterId", authenticatedUser.ID, "sessionId", sessionID)
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// RevokeAllSessions logs out everywhere.
+// With ?keep_current=true the session of the current request stays logged in.
+func (h *SessionHandler) RevokeAllSessions(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	exceptSessionID := ""
+	if ctx.Query("keep_current") == "true" {
+		exceptSessionID = handler_utils.GetSessionID(ctx)
+	}
+
+	// Call service layer to revoke all sessions.
+	if err := h.SessionService.RevokeAllSessions(ctx.Request.Context(), authenticatedUser.ID, exceptSessionID); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "All sessions revoked", "requesterId", authenticatedUser.ID, "keepCurrent", exceptSessionID != "")
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// Logout logs out the session of the current request.
+func (h *SessionHandler) Logout(ctx *gin.Context) {
+	// Get current authenticated user.
+	authenticatedUser, ok := handler_utils.GetAuthenticatedUser(ctx)
+	if !ok {
+		return
+	}
+
+	// Tokens issued before sessions existed cannot be logged out individually.
+	sessionID := handler_utils.GetSessionID(ctx)
+	if sessionID == "" {
+		ctx.JSON(http.StatusNoContent, nil)
+		return
+	}
+
+	// Call service layer to revoke the current session.
+	if err := h.SessionService.RevokeSession(ctx.Request.Context(), authenticatedUser.ID, sessionID); err != nil { // Pass context
+		handler_utils.HandleError(ctx, err)
+		return
+	}
+
+	// Return 204 No Content.
+	logger.Info(ctx, "User logged out", "requesterId", authenticatedUser.ID, "sessionId", sessionID)
+	ctx.JSON(http.StatusNoContent, nil)
+}
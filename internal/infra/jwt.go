@@ -0,0 +1,88 @@
+package infra
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/pkg/jwt"
+)
+
+// InitJWTKeySet 初始化JWT签名与验证密钥
+func InitJWTKeySet(cfg *config.Config) *jwt.KeySet {
+	keySet := jwt.NewKeySet(cfg.JWT.Secret)
+
+	for _, keyCfg := range cfg.JWT.Keys {
+		key, err := loadJWTKey(keyCfg)
+		if err != nil {
+			slog.Error("Failed to load JWT key", "kid", keyCfg.ID, "error", err)
+			panic(fmt.Sprintf("Failed to load JWT key %s: %v", keyCfg.ID, err))
+		}
+		if err := keySet.AddKey(key); err != nil {
+			slog.Error("Failed to add JWT key", "kid", keyCfg.ID, "error", err)
+			panic(fmt.Sprintf("Failed to add JWT key %s: %v", keyCfg.ID, err))
+		}
+	}
+
+	// 未配置签名密钥时退回到 HS256
+	if cfg.JWT.ActiveKeyID == "" {
+		if cfg.JWT.Secret == "" {
+			panic("JWT secret or active_key_id must be configured")
+		}
+		slog.Info("JWT signing with HS256 shared secret", "verificationKeys", len(cfg.JWT.Keys))
+		return keySet
+	}
+
+	if err := keySet.SetSigningKey(cfg.JWT.ActiveKeyID); err != nil {
+		slog.Error("Failed to set JWT signing key", "kid", cfg.JWT.ActiveKeyID, "error", err)
+		panic(fmt.Sprintf("Failed to set JWT signing key: %v", err))
+	}
+
+	// 切换到非对称密钥后，HS256 Token 仅在显式开启时继续有效
+	legacyHS256 := cfg.JWT.AcceptLegacyHS256 && cfg.JWT.Secret != ""
+	keySet.SetAcceptLegacyHS256(legacyHS256)
+	if legacyHS256 {
+		slog.Warn("JWT still accepts HS256 tokens signed with the shared secret; disable accept_legacy_hs256 and remove the secret once they have expired")
+	}
+
+	slog.Info("JWT key set initialized",
+		"activeKeyId", cfg.JWT.ActiveKeyID,
+		"keys", len(cfg.JWT.Keys),
+		"legacyHS256", legacyHS256)
+
+	return keySet
+}
+
+// loadJWTKey 从文件或内联PEM加载密钥，优先使用私钥
+func loadJWTKey(keyCfg config.JWTKeyConfig) (*jwt.Key, error) {
+	if keyCfg.PrivateKey != "" || keyCfg.PrivateKeyFile != "" {
+		data, err := readPEM(keyCfg.PrivateKey, keyCfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return jwt.ParsePrivateKeyPEM(keyCfg.ID, keyCfg.Algorithm, data)
+	}
+
+	if keyCfg.PublicKey != "" || keyCfg.PublicKeyFile != "" {
+		data, err := readPEM(keyCfg.PublicKey, keyCfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return jwt.ParsePublicKeyPEM(keyCfg.ID, keyCfg.Algorithm, data)
+	}
+
+	return nil, fmt.Errorf("no private or public key configured")
+}
+
+// readPEM 返回内联PEM内容，或读取PEM文件
+func readPEM(inline, path string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,39 @@
+package infra
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/internal/search"
+	"gorm.io/gorm"
+)
+
+// InitSearchIndex 根据配置初始化产品搜索索引
+func InitSearchIndex(cfg *config.Config, db *gorm.DB) search.SearchIndex {
+	switch cfg.Search.Engine {
+	case "", search.EngineMemory:
+		path := cfg.Search.IndexPath
+		if path == "" {
+			path = "data/search/products.idx"
+		}
+		index, err := search.NewMemoryIndex(path)
+		if err != nil {
+			slog.Error("Failed to load search index", "path", path, "error", err)
+			panic(fmt.Sprintf("Failed to load search index: %v", err))
+		}
+		slog.Info("Search index loaded", "engine", search.EngineMemory, "path", path, "documents", index.Len())
+		return index
+	case search.EngineDatabase:
+		return repositories.NewProductSearchIndex(db)
+	case search.EngineMeilisearch:
+		name := cfg.Search.Meilisearch.Index
+		if name == "" {
+			name = "products"
+		}
+		return search.NewMeilisearchIndex(cfg.Search.Meilisearch.URL, cfg.Search.Meilisearch.APIKey, name)
+	default:
+		panic(fmt.Sprintf("Unknown search engine: %s", cfg.Search.Engine))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	stderrors "errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -18,13 +19,11 @@ import (
 
 // RequiredAuthenticate middleware requires a valid authentication, otherwise returns 401.
 // It ensures that the user must be logged in.
-func RequiredAuthenticate(config *config.Config, userService services.UserService) gin.HandlerFunc {
+func RequiredAuthenticate(jwtKeys *jwt.KeySet, userService services.UserService, sessionService services.SessionService, apiKeyService services.APIKeyService, banService services.BanService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		auth, authenticated := authenticateWithJWT(ctx, config)
-
-		if !authenticated {
-			ctx.JSON(http.StatusUnauthorized, response.NewErrorResponse(errors.ErrUnauthorized.Message))
-			ctx.Abort()
+		auth, err := authenticateWithJWT(ctx, jwtKeys, sessionService, apiKeyService)
+		if err != nil {
+			abortWithAuthError(ctx, err)
 			return
 		}
 
@@ -44,17 +43,29 @@ func RequiredAuthenticate(config *config.Config, userService services.UserServic
 			return
 		}
 
+		// A ban takes effect immediately on all credentials. An expired ban is lifted here.
+		if err := banService.CheckBan(ctx.Request.Context(), authenticatedUser); err != nil { // Pass context
+			abortWithAuthError(ctx, err)
+			return
+		}
+		// API keys outlive access tokens, so they stop working while the account is scheduled for deletion.
+		if auth.APIKeyID != 0 && authenticatedUser.DeletionScheduledAt != nil {
+			abortWithAuthError(ctx, errors.ErrAccountPendingDeletion)
+			return
+		}
+
 		// Set authentication details in context.
-		setAuthContext(ctx, authenticatedUser)
+		setAuthContext(ctx, authenticatedUser, auth)
 		ctx.Next()
+		logImpersonatedRequest(ctx, auth)
 	}
 }
 
 // OptionalAuthenticate middleware attempts authentication but does not enforce it.
-// It tries to log in the user but proceeds even if authentication fails.
-func OptionalAuthenticate(config *config.Config, userService services.UserService) gin.HandlerFunc {
+// It tries to log in the user but proceeds even if authentication fails. Banned users proceed anonymously.
+func OptionalAuthenticate(jwtKeys *jwt.KeySet, userService services.UserService, sessionService services.SessionService, apiKeyService services.APIKeyService, banService services.BanService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if auth, authenticated := authenticateWithJWT(ctx, config); authenticated {
+		if auth, err := authenticateWithJWT(ctx, jwtKeys, sessionService, apiKeyService); err == nil {
 			// Get User details.
 			authenticatedUser, err := userService.GetUser(ctx.Request.Context(), auth.UserID) // Pass context
 			if err != nil {
@@ -65,24 +76,33 @@ func OptionalAuthenticate(config *config.Config, userService services.UserServic
 					logger.Error(ctx.Request.Context(), "Failed to get authenticated user for optional auth", "userId", auth.UserID, "error", err) // Pass context
 					// Do not abort here, proceed without authenticated user.
 				}
-			} else {
-				setAuthContext(ctx, authenticatedUser)
+			} else if banService.CheckBan(ctx.Request.Context(), authenticatedUser) == nil && (auth.APIKeyID == 0 || authenticatedUser.DeletionScheduledAt == nil) {
+				setAuthContext(ctx, authenticatedUser, auth)
+				ctx.Next()
+				logImpersonatedRequest(ctx, auth)
+				return
 			}
 		}
 		ctx.Next()
 	}
 }
 
-// AdminAuthMiddleware middleware requires a valid authentication with admin role.
-// It ensures that the user is logged in and is an administrator.
-func AdminAuthMiddleware(config *config.Config, userService services.UserService) gin.HandlerFunc {
+// AdminAuthMiddleware middleware requires a valid authentication of a user with at least one admin permission.
+// It ensures that the user is logged in and has been assigned a role, and loads the user's permissions
+// for RequirePermission.
+// If mfa.require_for_admins is enabled, the login session must have passed two-factor authentication.
+// API keys with the admin scope are accepted when the route group allows them (see AllowAPIKey).
+// Impersonation tokens are rejected: an admin acting as a user never has admin access.
+func AdminAuthMiddleware(cfg *config.Config, jwtKeys *jwt.KeySet, userService services.UserService, sessionService services.SessionService, apiKeyService services.APIKeyService, roleService services.RoleService, banService services.BanService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// For admin authentication, we only support JWT.
-		auth, authenticated := authenticateWithJWT(ctx, config)
-
-		if !authenticated {
-			ctx.JSON(http.StatusUnauthorized, response.NewErrorResponse(errors.ErrUnauthorized.Message))
-			ctx.Abort()
+		auth, err := authenticateWithJWT(ctx, jwtKeys, sessionService, apiKeyService)
+		if err != nil {
+			abortWithAuthError(ctx, err)
+			return
+		}
+		if auth.ImpersonatorID != 0 {
+			logger.Warn(ctx.Request.Context(), "Access denied - impersonation token used on the admin API", "userId", auth.UserID, "impersonatorId", auth.ImpersonatorID) // Pass context
+			abortWithAuthError(ctx, errors.ErrImpersonationForbidden)
 			return
 		}
 
@@ -101,48 +121,206 @@ func AdminAuthMiddleware(config *config.Config, userService services.UserService
 			return
 		}
 
-		// Check for admin role.
-		if authenticatedUser.Role != "admin" {
-			logger.Warn(ctx.Request.Context(), "Access denied - not an admin user", // Pass context
-				"userId", authenticatedUser.ID,
-				"role", authenticatedUser.Role,
-			)
+		// Check that the user has been granted admin permissions by a role.
+		permissions, err := roleService.GetUserPermissions(ctx.Request.Context(), authenticatedUser.ID) // Pass context
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, response.NewErrorResponse(errors.ErrInternalServer.Message))
+			ctx.Abort()
+			return
+		}
+		if len(permissions) == 0 {
+			logger.Warn(ctx.Request.Context(), "Access denied - user has no admin permissions", "userId", authenticatedUser.ID) // Pass context
 			ctx.JSON(http.StatusForbidden, response.NewErrorResponse(errors.ErrPermissionDenied.Message))
 			ctx.Abort()
 			return
 		}
 
+		// Banned admins lose access immediately, through their API keys as well.
+		if err := banService.CheckBan(ctx.Request.Context(), authenticatedUser); err != nil { // Pass context
+			abortWithAuthError(ctx, err)
+			return
+		}
+		if auth.APIKeyID != 0 && authenticatedUser.DeletionScheduledAt != nil {
+			abortWithAuthError(ctx, errors.ErrAccountPendingDeletion)
+			return
+		}
+
+		// Check for two-factor authentication if required for admins.
+		// Admin API keys can only be created from a login that passed it, so they are exempt.
+		if cfg.MFA.RequireForAdmins && !auth.MFA && auth.APIKeyID == 0 {
+			logger.Warn(ctx.Request.Context(), "Access denied - admin login without two-factor authentication", "userId", authenticatedUser.ID) // Pass context
+			ctx.JSON(http.StatusForbidden, response.NewErrorResponse(errors.ErrMFARequired.Message))
+			ctx.Abort()
+			return
+		}
+
 		// Set authentication details in context.
-		setAuthContext(ctx, authenticatedUser)
+		setAuthContext(ctx, authenticatedUser, auth)
+		ctx.Set(permissionsContextKey, permissions)
 		ctx.Next()
 	}
 }
 
+// RequirePermission middleware requires the admin to have the given permission, e.g. "users:ban".
+// It must run after AdminAuthMiddleware, which loads the permissions.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !slices.Contains(ctx.GetStringSlice(permissionsContextKey), permission) {
+			logger.Warn(ctx.Request.Context(), "Access denied - missing permission", "permission", permission, "path", ctx.FullPath()) // Pass context
+			ctx.JSON(http.StatusForbidden, response.NewErrorResponseWithData(errors.ErrPermissionDenied.Message, map[string]interface{}{"permission": permission}))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// permissionsContextKey is the context key of the admin permissions of the authenticated user.
+const permissionsContextKey = "permissions"
+
+// DenyImpersonation middleware rejects requests made with an impersonation token, so that an admin acting as
+// a user cannot change the user's credentials, bound accounts or other security settings.
+// It must run after the authentication middleware.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetUint(impersonatorIDContextKey) != 0 {
+			logger.Warn(ctx.Request.Context(), "Access denied - operation not allowed while impersonating", "path", ctx.FullPath()) // Pass context
+			abortWithAuthError(ctx, errors.ErrImpersonationForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// impersonatorIDContextKey is the context key of the ID of the admin impersonating the authenticated user.
+const impersonatorIDContextKey = "impersonatorID"
+
+// AllowAPIKey middleware allows API keys and OAuth access tokens with the given scope on the routes it is
+// applied to. It must run before the authentication middleware; routes without it only accept first-party JWTs.
+func AllowAPIKey(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(apiKeyScopeContextKey, scope)
+		ctx.Next()
+	}
+}
+
+// apiKeyScopeContextKey is the context key of the API key scope allowed on the current route.
+const apiKeyScopeContextKey = "apiKeyScope"
+
 // authenticateWithJWT attempts to authenticate using JWT token from the Authorization header.
-func authenticateWithJWT(ctx *gin.Context, config *config.Config) (*models.UserAuthDetails, bool) {
+// Tokens belonging to a revoked (logged out) session are rejected, and tokens issued to OAuth clients are
+// rejected on routes outside their scopes.
+// An API key in the "Authorization: ApiKey <key>" or "X-API-Key" header is accepted instead if the route
+// allows API keys and the key has the route's scope.
+func authenticateWithJWT(ctx *gin.Context, jwtKeys *jwt.KeySet, sessionService services.SessionService, apiKeyService services.APIKeyService) (*models.UserAuthDetails, error) {
 	authHeader := ctx.GetHeader("Authorization")
+	if apiKey := ctx.GetHeader("X-API-Key"); apiKey != "" {
+		return authenticateWithAPIKey(ctx, apiKeyService, apiKey)
+	}
+	if strings.HasPrefix(authHeader, "ApiKey ") {
+		return authenticateWithAPIKey(ctx, apiKeyService, authHeader[7:]) // Remove "ApiKey " prefix.
+	}
 	if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
-		return nil, false
+		return nil, errors.ErrUnauthorized
 	}
 
 	tokenString := authHeader[7:] // Remove "Bearer " prefix.
-	tokenDetails, err := jwt.ValidateToken(tokenString, config.JWT.Secret)
+	tokenDetails, err := jwt.ValidateToken(tokenString, jwtKeys)
 	if err != nil || tokenDetails.TokenType != jwt.AccessToken {
 		logger.Debug(ctx.Request.Context(), "JWT validation failed", "error", err) // Pass context
-		return nil, false
+		return nil, errors.ErrUnauthorized
+	}
+
+	// Check the login session (tokens issued before sessions existed carry no session ID).
+	if tokenDetails.SessionID != "" {
+		if err := sessionService.ValidateSession(ctx.Request.Context(), tokenDetails.SessionID, ctx.ClientIP()); err != nil {
+			logger.Debug(ctx.Request.Context(), "Session validation failed", "sessionId", tokenDetails.SessionID, "error", err) // Pass context
+			return nil, errors.ErrUnauthorized
+		}
+	}
+
+	// Access tokens of third-party OAuth clients are limited to the routes of their scopes, like API keys.
+	if tokenDetails.ClientID != "" {
+		scope := ctx.GetString(apiKeyScopeContextKey)
+		if scope == "" || !slices.Contains(strings.Fields(tokenDetails.Scope), scope) {
+			logger.Warn(ctx.Request.Context(), "OAuth access token is missing the required scope", "clientId", tokenDetails.ClientID, "scope", scope, "path", ctx.FullPath()) // Pass context
+			return nil, errors.ErrInsufficientScope
+		}
+	}
+
+	return &models.UserAuthDetails{
+		UserID:         tokenDetails.UserID,
+		Role:           tokenDetails.Role,
+		SessionID:      tokenDetails.SessionID,
+		MFA:            tokenDetails.MFA,
+		ImpersonatorID: tokenDetails.ImpersonatorID,
+	}, nil
+}
+
+// authenticateWithAPIKey authenticates with an API key, which must have the scope allowed on the route.
+func authenticateWithAPIKey(ctx *gin.Context, apiKeyService services.APIKeyService, key string) (*models.UserAuthDetails, error) {
+	scope := ctx.GetString(apiKeyScopeContextKey)
+	if scope == "" {
+		logger.Debug(ctx.Request.Context(), "API key used on a route that does not allow API keys", "path", ctx.FullPath()) // Pass context
+		return nil, errors.ErrAPIKeyScopeDenied
+	}
+
+	apiKey, err := apiKeyService.Authenticate(ctx.Request.Context(), strings.TrimSpace(key), ctx.ClientIP()) // Pass context
+	if err != nil {
+		logger.Debug(ctx.Request.Context(), "API key validation failed", "error", err) // Pass context
+		return nil, err
+	}
+	if !apiKey.HasScope(scope) {
+		logger.Warn(ctx.Request.Context(), "API key is missing the required scope", "apiKeyId", apiKey.ID, "scope", scope) // Pass context
+		return nil, errors.ErrAPIKeyScopeDenied
 	}
 
 	return &models.UserAuthDetails{
-		UserID: tokenDetails.UserID,
-		Role:   tokenDetails.Role,
-	}, true
+		UserID:   apiKey.UserID,
+		APIKeyID: apiKey.ID,
+	}, nil
+}
+
+// abortWithAuthError aborts the request with the response of an authentication error.
+func abortWithAuthError(ctx *gin.Context, err error) {
+	appError, ok := err.(*errors.AppError)
+	if !ok {
+		appError = errors.ErrInternalServer
+	}
+	if appError.Data != nil {
+		ctx.JSON(appError.Status, response.NewErrorResponseWithData(appError.Message, appError.Data))
+	} else {
+		ctx.JSON(appError.Status, response.NewErrorResponse(appError.Message))
+	}
+	ctx.Abort()
 }
 
 // setAuthContext sets user authentication details in the request context.
-func setAuthContext(ctx *gin.Context, authenticatedUser *models.User) {
+func setAuthContext(ctx *gin.Context, authenticatedUser *models.User, auth *models.UserAuthDetails) {
 	ctx.Set("authenticatedUser", authenticatedUser) // Store as pointer.
+	ctx.Set("sessionID", auth.SessionID)
+	ctx.Set("mfa", auth.MFA)
+	ctx.Set("apiKeyID", auth.APIKeyID)
+	ctx.Set(impersonatorIDContextKey, auth.ImpersonatorID)
 
 	// 同时将user_id添加到logger context中
 	updatedCtx := logger.WithUserID(ctx.Request.Context(), fmt.Sprintf("%d", authenticatedUser.ID))
+	// 模拟登录时同时记录执行模拟的管理员ID
+	if auth.ImpersonatorID != 0 {
+		updatedCtx = logger.WithImpersonatorID(updatedCtx, fmt.Sprintf("%d", auth.ImpersonatorID))
+	}
 	ctx.Request = ctx.Request.WithContext(updatedCtx)
 }
+
+// logImpersonatedRequest records every request made with an impersonation token in the audit log.
+func logImpersonatedRequest(ctx *gin.Context, auth *models.UserAuthDetails) {
+	if auth.ImpersonatorID == 0 {
+		return
+	}
+	logger.Info(ctx.Request.Context(), "Impersonated request", // Pass context
+		"method", ctx.Request.Method,
+		"path", ctx.Request.URL.Path,
+		"status", ctx.Writer.Status(),
+		"ip", ctx.ClientIP(),
+	)
+}
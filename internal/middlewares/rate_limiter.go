@@ -12,9 +12,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-backend-template/internal/models"
 	"github.com/redis/go-redis/v9"
 )
 
+// UserIdentifier is the identifier key that limits requests per authenticated user instead of per request field.
+const UserIdentifier = "authenticated_user"
+
 // RateLimiter provides rate limiting functionality
 type RateLimiter struct {
 	redisClient *redis.Client
@@ -39,6 +43,36 @@ func (rl *RateLimiter) PasswordResetRateLimit() gin.HandlerFunc {
 	return rl.RateLimit("password_reset", 2, 15*time.Minute, "email")
 }
 
+// MagicLinkRateLimit limits email login link requests
+// Allows 3 requests per email per 10 minutes
+func (rl *RateLimiter) MagicLinkRateLimit() gin.HandlerFunc {
+	return rl.RateLimit("magic_link", 3, 10*time.Minute, "email")
+}
+
+// EmailChangeRateLimit limits email change requests
+// Allows 3 requests per new email per 10 minutes
+func (rl *RateLimiter) EmailChangeRateLimit() gin.HandlerFunc {
+	return rl.RateLimit("email_change", 3, 10*time.Minute, "new_email")
+}
+
+// PhoneChangeRateLimit limits phone change requests
+// Allows 3 requests per new phone number per 10 minutes
+func (rl *RateLimiter) PhoneChangeRateLimit() gin.HandlerFunc {
+	return rl.RateLimit("phone_change", 3, 10*time.Minute, "new_phone")
+}
+
+// MFAManagementRateLimit limits requests that change the two-factor settings with a code
+// Allows 5 requests per user per 15 minutes
+func (rl *RateLimiter) MFAManagementRateLimit() gin.HandlerFunc {
+	return rl.RateLimit("mfa_management", 5, 15*time.Minute, UserIdentifier)
+}
+
+// PhoneCodeRateLimit limits SMS code requests
+// Allows 3 requests per phone number per 10 minutes
+func (rl *RateLimiter) PhoneCodeRateLimit() gin.HandlerFunc {
+	return rl.RateLimit("phone_code", 3, 10*time.Minute, "phone")
+}
+
 // RateLimit creates a generic rate limiting middleware
 func (rl *RateLimiter) RateLimit(keyPrefix string, maxRequests int, window time.Duration, identifierKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -108,6 +142,16 @@ func (rl *RateLimiter) RateLimit(keyPrefix string, maxRequests int, window time.
 
 // getIdentifier extracts the identifier from the request
 func (rl *RateLimiter) getIdentifier(c *gin.Context, identifierKey string) string {
+	// Authenticated routes are limited per user
+	if identifierKey == UserIdentifier {
+		if user, ok := c.Get("authenticatedUser"); ok {
+			if authenticatedUser, ok := user.(*models.User); ok {
+				return strconv.FormatUint(uint64(authenticatedUser.ID), 10)
+			}
+		}
+		return ""
+	}
+
 	// First try to get from request body (for POST requests)
 	if c.Request.Method == "POST" {
 		// Read the request body without consuming it
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey 供脚本和第三方集成调用接口的API密钥（个人访问令牌）
+// 密钥只在创建时返回一次，数据库中仅保存其 SHA-256 哈希
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"index;not null"`                       // 外键，指向users表，使用该密钥的请求以此用户身份执行
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`              // 密钥名称，便于用户区分用途
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16);uniqueIndex;not null"` // 密钥前缀（明文），用于查找密钥和在列表中展示
+	KeyHash    string     `json:"-" gorm:"type:varchar(64);not null"`                  // 完整密钥的 SHA-256 哈希（十六进制）
+	Scopes     string     `json:"scopes" gorm:"type:varchar(200);not null"`            // 授权范围，逗号分隔，如 profile,products
+	ExpiresAt  time.Time  `json:"expires_at"`                                          // 过期时间
+	LastUsedAt *time.Time `json:"last_used_at"`                                        // 最近使用时间
+	LastUsedIP string     `json:"last_used_ip" gorm:"type:varchar(45)"`                // 最近使用的IP地址
+	RevokedAt  *time.Time `json:"revoked_at"`                                          // 撤销时间，非空表示密钥已被撤销
+	CreatedAt  time.Time  `json:"created_at"`                                          // 创建时间
+	UpdatedAt  time.Time  `json:"updated_at"`                                          // 更新时间
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive 密钥未被撤销且未过期
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil && time.Now().Before(k.ExpiresAt)
+}
+
+// ScopeList 返回授权范围列表
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope 密钥是否拥有指定的授权范围
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
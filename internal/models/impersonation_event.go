@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// ImpersonationEvent 管理员模拟用户登录的审计记录，每签发一个模拟Token记录一条
+type ImpersonationEvent struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ImpersonatorID uint      `json:"impersonator_id" gorm:"not null;index"`                 // 执行模拟的管理员用户ID
+	UserID         uint      `json:"user_id" gorm:"not null;index"`                         // 被模拟的用户ID
+	Reason         string    `json:"reason" gorm:"type:varchar(500);not null"`              // 模拟原因，例如工单号
+	TokenID        string    `json:"token_id" gorm:"type:varchar(64);not null;uniqueIndex"` // 签发的访问Token的jti，用于关联请求日志
+	IPAddress      string    `json:"ip_address" gorm:"type:varchar(45)"`                    // 管理员的请求IP
+	UserAgent      string    `json:"user_agent" gorm:"type:varchar(500)"`                   // 管理员的User-Agent
+	ExpiresAt      time.Time `json:"expires_at"`                                            // 模拟Token过期时间
+	CreatedAt      time.Time `json:"created_at" gorm:"index"`                               // 签发时间
+}
+
+// TableName 指定表名
+func (ImpersonationEvent) TableName() string {
+	return "impersonation_events"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// LockoutEvent 登录失败次数过多导致的临时锁定记录
+type LockoutEvent struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Scope        string    `json:"scope" gorm:"type:varchar(20);not null;index"` // 锁定范围: account（账号）, ip（IP地址）
+	Identifier   string    `json:"identifier" gorm:"type:varchar(255);not null"` // 被锁定的账号（邮箱/手机号）或IP地址
+	UserID       *uint     `json:"user_id" gorm:"index"`                         // 对应的用户ID，账号不存在或按IP锁定时为空
+	IPAddress    string    `json:"ip_address" gorm:"type:varchar(45)"`           // 触发锁定的请求IP
+	FailureCount int       `json:"failure_count" gorm:"not null"`                // 锁定前的失败次数
+	LockedUntil  time.Time `json:"locked_until"`                                 // 锁定到期时间
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`                      // 锁定时间
+}
+
+// TableName 指定表名
+func (LockoutEvent) TableName() string {
+	return "lockout_events"
+}
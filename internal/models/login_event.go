@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginEvent 登录记录（每次登录尝试，无论成功或失败）
+type LoginEvent struct {
+	ID                uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID            *uint     `json:"user_id" gorm:"index:idx_login_events_user_created"`    // 对应的用户ID，账号不存在时为空
+	Identifier        string    `json:"identifier" gorm:"type:varchar(255)"`                   // 失败时尝试登录的账号（邮箱/手机号），成功时为空
+	Method            string    `json:"method" gorm:"type:varchar(50);not null"`               // 登录方式: password, phone, magic_link, passkey, google, wechat, wechat_mini_program 等
+	Success           bool      `json:"success" gorm:"not null"`                               // 是否登录成功
+	FailureReason     string    `json:"failure_reason" gorm:"type:varchar(50)"`                // 失败原因（错误码），如 invalid_password, user_banned
+	MFA               bool      `json:"mfa" gorm:"not null;default:false"`                     // 是否通过两步验证
+	SessionID         string    `json:"session_id" gorm:"type:varchar(36)"`                    // 成功登录创建的会话ID
+	IPAddress         string    `json:"ip_address" gorm:"type:varchar(45)"`                    // 请求IP
+	UserAgent         string    `json:"user_agent" gorm:"type:varchar(500)"`                   // 请求的 User-Agent
+	DeviceName        string    `json:"device_name" gorm:"type:varchar(100)"`                  // 设备名称，由客户端通过 X-Device-Name 提供
+	DeviceFingerprint string    `json:"device_fingerprint" gorm:"type:varchar(64);index"`      // 设备指纹（X-Device-ID 或 User-Agent 与设备名称的 SHA-256）
+	NewDevice         bool      `json:"new_device" gorm:"not null;default:false"`              // 是否为之前未出现过的设备（触发新设备登录提醒）
+	CreatedAt         time.Time `json:"created_at" gorm:"index:idx_login_events_user_created"` // 登录时间
+}
+
+// TableName 指定表名
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
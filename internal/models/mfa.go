@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// UserMFA 用户的 TOTP 两步验证配置
+type UserMFA struct {
+	UserID       uint       `json:"user_id" gorm:"primaryKey;autoIncrement:false"` // 外键，指向users表
+	TOTPSecret   string     `json:"-" gorm:"type:varchar(64);not null"`            // Base32 编码的 TOTP 密钥，不直接暴露
+	Enabled      bool       `json:"enabled" gorm:"default:false;not null"`         // 是否已完成验证并启用
+	LastUsedStep int64      `json:"-" gorm:"default:0;not null"`                   // 最近一次使用的时间步，防止验证码重放
+	EnabledAt    *time.Time `json:"enabled_at"`                                    // 启用时间
+	CreatedAt    time.Time  `json:"created_at"`                                    // 创建时间
+	UpdatedAt    time.Time  `json:"updated_at"`                                    // 更新时间
+}
+
+// TableName 指定表名
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// MFARecoveryCode 两步验证的一次性恢复码（仅存储哈希值）
+type MFARecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`      // 外键，指向users表
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"` // 恢复码的 SHA-256 哈希
+	UsedAt    *time.Time `json:"used_at"`                            // 使用时间，非空表示已使用
+	CreatedAt time.Time  `json:"created_at"`                         // 创建时间
+}
+
+// TableName 指定表名
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// OAuthClient 在本服务注册的第三方应用（OAuth2 客户端），经用户授权后以用户身份访问其授权范围内的接口
+// 回调地址与授权范围按 OAuth2 规范以空格分隔存储
+type OAuthClient struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ClientID     string    `json:"client_id" gorm:"type:varchar(64);uniqueIndex;not null"` // 公开的客户端ID
+	SecretHash   string    `json:"-" gorm:"type:varchar(64)"`                              // 客户端密钥的 SHA-256 哈希（十六进制），公开客户端为空
+	Confidential bool      `json:"confidential" gorm:"default:false;not null"`             // 是否为机密客户端（有服务端、能保管密钥），公开客户端（移动端、单页应用）只使用 PKCE
+	Name         string    `json:"name" gorm:"type:varchar(100);not null"`                 // 应用名称，显示在授权页面
+	LogoURL      string    `json:"logo_url" gorm:"type:varchar(500)"`                      // 应用图标地址，显示在授权页面
+	RedirectURIs string    `json:"redirect_uris" gorm:"type:text;not null"`                // 允许的回调地址，空格分隔，授权请求中的 redirect_uri 必须与其中之一完全一致
+	Scopes       string    `json:"scopes" gorm:"type:varchar(200);not null"`               // 允许申请的授权范围，空格分隔，如 profile products
+	CreatedBy    uint      `json:"created_by"`                                             // 注册该应用的管理员ID
+	CreatedAt    time.Time `json:"created_at"`                                             // 创建时间
+	UpdatedAt    time.Time `json:"updated_at"`                                             // 更新时间
+}
+
+// TableName 指定表名
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// RedirectURIList 返回回调地址列表
+func (c *OAuthClient) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+// ScopeList 返回允许申请的授权范围列表
+func (c *OAuthClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
+
+// HasRedirectURI 回调地址是否已注册（完全一致）
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIList(), uri)
+}
+
+// OAuthConsent 用户对第三方应用的授权记录，用户在授权页面同意后保存，可随时撤销
+type OAuthConsent struct {
+	ID            uint        `json:"id" gorm:"primaryKey"`
+	UserID        uint        `json:"user_id" gorm:"not null;uniqueIndex:idx_oauth_consent_user_client"`               // 外键，指向users表
+	OAuthClientID uint        `json:"oauth_client_id" gorm:"not null;uniqueIndex:idx_oauth_consent_user_client;index"` // 外键，指向oauth_clients表
+	OAuthClient   OAuthClient `json:"-" gorm:"foreignKey:OAuthClientID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`  // 关联的应用
+	Scopes        string      `json:"scopes" gorm:"type:varchar(200);not null"`                                        // 用户已同意的授权范围，空格分隔
+	CreatedAt     time.Time   `json:"created_at"`                                                                      // 首次授权时间
+	UpdatedAt     time.Time   `json:"updated_at"`                                                                      // 最近授权时间
+}
+
+// TableName 指定表名
+func (OAuthConsent) TableName() string {
+	return "oauth_consents"
+}
+
+// ScopeList 返回用户已同意的授权范围列表
+func (c *OAuthConsent) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Passkey 用户的 WebAuthn 凭证（通行密钥）
+type Passkey struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         uint       `json:"user_id" gorm:"index;not null"`                     // 外键，指向users表
+	CredentialID   string     `json:"-" gorm:"type:varchar(255);uniqueIndex;not null"`   // 凭证ID (base64url)
+	PublicKey      []byte     `json:"-" gorm:"not null"`                                 // COSE 编码的公钥
+	SignCount      uint32     `json:"-" gorm:"default:0;not null"`                       // 签名计数器，用于检测克隆的认证器
+	AAGUID         string     `json:"aaguid" gorm:"type:varchar(36)"`                    // 认证器型号标识
+	Transports     string     `json:"transports" gorm:"type:varchar(100)"`               // 支持的传输方式，逗号分隔，如 internal,hybrid
+	BackupEligible bool       `json:"backup_eligible" gorm:"default:false;not null"`     // 是否为可同步的通行密钥（如 iCloud 钥匙串）
+	Name           string     `json:"name" gorm:"type:varchar(100);not null;default:''"` // 用户自定义名称
+	LastUsedAt     *time.Time `json:"last_used_at"`                                      // 最近一次用于登录的时间
+	CreatedAt      time.Time  `json:"created_at"`                                        // 创建时间
+	UpdatedAt      time.Time  `json:"updated_at"`                                        // 更新时间
+}
+
+// TableName 指定表名
+func (Passkey) TableName() string {
+	return "passkeys"
+}
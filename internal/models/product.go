@@ -58,6 +58,9 @@ type Product struct {
 	// DescriptionStatus    DescriptionStatus `json:"description_status" gorm:"type:enum('PENDING', 'LOADING', 'LOADED', 'OUTDATED');default:'PENDING'"` // Description status (MySQL enum type)
 	DescriptionUpdatedAt *time.Time `json:"description_loaded_at"` // Timestamp of when the description was last updated/loaded
 
+	// Search fields
+	SearchText string `json:"-" gorm:"type:text"` // Searchable text (name, barcode, description text and category names), maintained by the repository for full-text search
+
 	// Timestamp fields
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
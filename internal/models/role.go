@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Permission 权限，如 users:ban。权限列表由代码定义，执行迁移时同步到数据库
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"type:varchar(50);uniqueIndex;not null"` // 权限名称，格式为 资源:操作
+	Description string    `json:"description" gorm:"type:varchar(200)"`              // 权限说明
+	CreatedAt   time.Time `json:"created_at"`                                        // 创建时间
+}
+
+// TableName 指定表名
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// Role 角色，包含一组权限，分配给用户后授予其访问相应管理接口的权限
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"type:varchar(50);uniqueIndex;not null"`                                          // 角色名称，如 admin、support
+	Description string       `json:"description" gorm:"type:varchar(200)"`                                                       // 角色说明
+	IsSystem    bool         `json:"is_system" gorm:"default:false;not null"`                                                    // 系统内置角色（admin），拥有全部权限，不可修改或删除
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 角色拥有的权限
+	CreatedAt   time.Time    `json:"created_at"`                                                                                 // 创建时间
+	UpdatedAt   time.Time    `json:"updated_at"`                                                                                 // 更新时间
+}
+
+// TableName 指定表名
+func (Role) TableName() string {
+	return "roles"
+}
+
+// UserRole 用户角色分配
+type UserRole struct {
+	UserID     uint      `json:"user_id" gorm:"primaryKey"`                                               // 联合主键，外键指向users表
+	RoleID     uint      `json:"role_id" gorm:"primaryKey;index"`                                         // 联合主键，外键指向roles表
+	Role       Role      `json:"-" gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 关联的角色
+	AssignedBy *uint     `json:"assigned_by"`                                                             // 分配该角色的管理员ID，迁移时自动分配为空
+	CreatedAt  time.Time `json:"created_at"`                                                              // 分配时间
+}
+
+// TableName 指定表名
+func (UserRole) TableName() string {
+	return "user_roles"
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Session 用户登录会话（每次登录对应一个设备会话）
+type Session struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(36)"`     // 会话ID (UUID)，同时作为 refresh token 家族ID
+	UserID        uint       `json:"user_id" gorm:"index;not null"`             // 外键，指向users表
+	Provider      string     `json:"provider" gorm:"type:varchar(50);not null"` // 登录方式: password, passkey, google, wechat, wechat_mini_program；第三方应用授权为 oauth
+	DeviceName    string     `json:"device_name" gorm:"type:varchar(100)"`      // 设备名称，由客户端通过 X-Device-Name 提供；第三方应用授权时为应用名称
+	OAuthClientID *uint      `json:"oauth_client_id" gorm:"index"`              // 第三方应用授权的会话为应用ID，普通登录为空
+	UserAgent     string     `json:"user_agent" gorm:"type:varchar(500)"`       // 登录时的 User-Agent
+	IPAddress     string     `json:"ip_address" gorm:"type:varchar(45)"`        // 最近一次活动的IP地址
+	LastSeenAt    time.Time  `json:"last_seen_at"`                              // 最近活跃时间
+	ExpiresAt     time.Time  `json:"expires_at"`                                // 会话过期时间（与 refresh token 生命周期一致）
+	RevokedAt     *time.Time `json:"revoked_at"`                                // 撤销时间，非空表示会话已被注销
+	CreatedAt     time.Time  `json:"created_at"`                                // 创建时间（登录时间）
+	UpdatedAt     time.Time  `json:"updated_at"`                                // 更新时间
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// IsActive 会话未被撤销且未过期
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
@@ -48,6 +48,7 @@ type User struct {
 	Email           *string `json:"email" gorm:"size:100;uniqueIndex:idx_email"`
 	IsEmailVerified bool    `json:"is_email_verified" gorm:"default:false;not null"` // 邮箱是否已验证
 	Phone           *string `json:"phone" gorm:"size:20;uniqueIndex:idx_phone"`      // 手机号遵循 E.164 格式
+	IsPhoneVerified bool    `json:"is_phone_verified" gorm:"default:false;not null"` // 手机号是否已验证
 	Password        *string `json:"-" gorm:"size:255"`                               // 密码字段，存储哈希值，不直接暴露
 
 	Name      string     `json:"name" gorm:"size:100;not null;default:''"` // 用户名，不能为空，默认值为空字符串
@@ -57,16 +58,21 @@ type User struct {
 	BirthDate *time.Time `json:"birth_date" gorm:"type:date"`                 // 显式指定为DATE类型而非默认的DATETIME
 	Locale    string     `gorm:"size:35;not null;default:'en'" json:"locale"` // 用户语言地区，遵循IETF BCP 47标准
 
-	Role      string     `json:"role" gorm:"size:20;default:'user';not null;index:idx_role"` // user, admin
+	Role      string     `json:"role" gorm:"size:20;default:'user';not null;index:idx_role"` // user, admin（管理接口权限由角色分配决定，见 UserRole；迁移时 admin 用户会被分配 admin 角色）
 	IsBanned  bool       `json:"is_banned" gorm:"default:false;not null"`                    // 新增字段：用户封禁状态，默认为false
 	LastLogin *time.Time `json:"last_login"`
 
+	BannedUntil *time.Time `json:"banned_until"` // 临时封禁的到期时间，到期后自动解封；永久封禁或未封禁时为空（封禁详情见 UserBan）
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at" gorm:"index"` // 用户申请注销后的计划删除时间，到期后账号数据被永久删除；期间重新登录即撤销注销
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at"`
 
 	// 关联字段 - 由GORM自动管理
 	UserProviders []UserProvider `json:"user_providers" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"` // 用户关联的第三方登录提供商
+	Passkeys      []Passkey      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`              // 用户注册的通行密钥
 
 	// 用户交互关联 - 使用复合主键的连接表
 	Favorites    []Product `json:"-" gorm:"many2many:user_product_favorites;joinForeignKey:user_id;joinReferences:product_id;constraint:OnDelete:CASCADE"`
@@ -80,6 +86,10 @@ func (User) TableName() string {
 
 // UserAuthDetails 包含用户认证信息
 type UserAuthDetails struct {
-	UserID uint
-	Role   string
+	UserID         uint
+	Role           string
+	SessionID      string // 登录会话ID，旧Token中可能为空
+	MFA            bool   // 本次登录是否通过了两步验证
+	APIKeyID       uint   // 通过API密钥认证时为密钥ID，否则为0
+	ImpersonatorID uint   // 管理员模拟登录时为管理员的用户ID，否则为0
 }
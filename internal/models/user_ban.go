@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Ban lift types
+const (
+	BanLiftExpired  = "expired"  // 到期自动解封
+	BanLiftUnbanned = "unbanned" // 管理员手动解封
+	BanLiftReplaced = "replaced" // 被新的封禁替代
+)
+
+// UserBan 用户封禁记录，每次封禁记录一条，解封后保留作为封禁历史
+type UserBan struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`            // 被封禁的用户ID
+	Reason    string     `json:"reason" gorm:"type:varchar(255);not null"` // 封禁原因，例如 spam、abuse，会返回给被封禁的用户
+	Message   string     `json:"message" gorm:"type:varchar(1000)"`        // 展示给被封禁用户的说明
+	BannedBy  *uint      `json:"banned_by" gorm:"index"`                   // 执行封禁的管理员用户ID，管理员账号被删除后为空
+	BannedAt  time.Time  `json:"banned_at" gorm:"not null"`                // 封禁开始时间
+	ExpiresAt *time.Time `json:"expires_at"`                               // 到期时间，为空表示永久封禁
+	LiftedAt  *time.Time `json:"lifted_at"`                                // 解封时间，为空表示封禁仍然有效
+	LiftedBy  *uint      `json:"lifted_by"`                                // 手动解封或替代该封禁的管理员用户ID
+	LiftType  string     `json:"lift_type" gorm:"type:varchar(20)"`        // 解封方式: expired（到期）, unbanned（手动解封）, replaced（被新的封禁替代）
+	CreatedAt time.Time  `json:"created_at"`                               // 创建时间
+	UpdatedAt time.Time  `json:"updated_at"`                               // 更新时间
+}
+
+// TableName 指定表名
+func (UserBan) TableName() string {
+	return "user_bans"
+}
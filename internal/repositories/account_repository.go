@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountRepository defines the interface for data access of self-service account operations:
+// exporting a user's data and permanently deleting accounts whose deletion grace period has ended.
+type AccountRepository interface {
+	ListLikes(ctx context.Context, userID uint) ([]models.UserProductLike, error)
+	ListFavorites(ctx context.Context, userID uint) ([]models.UserProductFavorite, error)
+	// ListUserIDsDueForDeletion returns up to limit users whose scheduled deletion time is before the given time.
+	ListUserIDsDueForDeletion(ctx context.Context, before time.Time, limit int) ([]uint, error)
+	// PurgeUser permanently deletes a user and the user's data if the deletion is still scheduled before the
+	// given time. It returns gorm.ErrRecordNotFound if the deletion has been cancelled in the meantime.
+	PurgeUser(ctx context.Context, userID uint, before time.Time) error
+}
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+// ListLikes lists the user's product likes, oldest first.
+func (r *accountRepository) ListLikes(ctx context.Context, userID uint) ([]models.UserProductLike, error) {
+	var likes []models.UserProductLike
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&likes).Error
+	return likes, err
+}
+
+// ListFavorites lists the user's product favorites, oldest first.
+func (r *accountRepository) ListFavorites(ctx context.Context, userID uint) ([]models.UserProductFavorite, error) {
+	var favorites []models.UserProductFavorite
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&favorites).Error
+	return favorites, err
+}
+
+// ListUserIDsDueForDeletion returns users whose deletion grace period has ended, including soft-deleted users.
+func (r *accountRepository) ListUserIDsDueForDeletion(ctx context.Context, before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", before).
+		Order("deletion_scheduled_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// PurgeUser permanently deletes a user together with everything that references the user.
+// Impersonation events are kept as the admin audit trail.
+func (r *accountRepository) PurgeUser(ctx context.Context, userID uint, before time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the user row so that a concurrent login cannot cancel the deletion halfway.
+		var user models.User
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", userID, before).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&models.Session{},
+			&models.APIKey{},
+			&models.UserRole{},
+			&models.UserMFA{},
+			&models.MFARecoveryCode{},
+			&models.Passkey{},
+			&models.UserProvider{},
+			&models.OAuthConsent{},
+			&models.UserProductLike{},
+			&models.UserProductFavorite{},
+			&models.LockoutEvent{},
+			&models.LoginEvent{},
+			&models.UserBan{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Keep the role assignments this user made as an admin, without the reference.
+		if err := tx.Model(&models.UserRole{}).Where("assigned_by = ?", userID).Update("assigned_by", nil).Error; err != nil {
+			return err
+		}
+		// Likewise for the bans this user issued or lifted.
+		if err := tx.Model(&models.UserBan{}).Where("banned_by = ?", userID).Update("banned_by", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserBan{}).Where("lifted_by = ?", userID).Update("lifted_by", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&models.User{}, userID).Error
+	})
+}
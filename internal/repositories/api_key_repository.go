@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the interface for API key data access operations.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, apiKey *models.APIKey) error
+	GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	// ListAPIKeys lists the user's keys that have not been revoked, newest first. Expired keys are included.
+	ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error)
+	// CountActiveAPIKeys counts the user's keys that are neither revoked nor expired.
+	CountActiveAPIKeys(ctx context.Context, userID uint) (int64, error)
+	UpdateAPIKey(ctx context.Context, id uint, updates map[string]interface{}) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// CreateAPIKey creates a new API key.
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(apiKey).Error
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (r *apiKeyRepository) GetAPIKey(ctx context.Context, id uint) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its prefix.
+func (r *apiKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.db.WithContext(ctx).Where("prefix = ?", prefix).First(&apiKey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// ListAPIKeys lists the user's keys that have not been revoked, newest first.
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&apiKeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// CountActiveAPIKeys counts the user's keys that are neither revoked nor expired.
+func (r *apiKeyRepository) CountActiveAPIKeys(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateAPIKey updates an API key.
+func (r *apiKeyRepository) UpdateAPIKey(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -10,7 +10,7 @@ import (
 // CategoryRepository defines the interface for category data access operations.
 type CategoryRepository interface {
 	// General CRUD queries
-	ListCategories(ctx context.Context, params *query_params.QueryParams) ([]models.Category, int, error)
+	ListCategories(ctx context.Context, params *query_params.QueryParams) ([]models.Category, int, string, error)
 	GetCategory(ctx context.Context, id uint) (*models.Category, error)
 	CreateCategory(ctx context.Context, category *models.Category) error
 	UpdateCategory(ctx context.Context, id uint, updates map[string]interface{}) error
@@ -19,13 +19,29 @@ type CategoryRepository interface {
 	// Custom queries
 	GetCategoryTree(ctx context.Context, depth int, enabledOnly bool) ([]models.Category, error)
 	GetChildCategories(ctx context.Context, parentID uint) ([]models.Category, error)
-	GetCategoryProducts(ctx context.Context, categoryID uint, params *query_params.QueryParams) ([]models.Product, int, error)
+	GetCategoriesByIDs(ctx context.Context, ids []uint) ([]models.Category, error)
+	GetCategoryProducts(ctx context.Context, categoryID uint, params *query_params.QueryParams, searchHits []uint) ([]models.Product, int, error)
 
 	// Utility methods for other repositories
 	ExpandCategoryIDsWithChildren(ctx context.Context, categoryIDs []uint) ([]uint, error)
 	GetAllChildCategoryIDs(ctx context.Context, parentID uint) ([]uint, error)
 }
 
+// categoryListSchema declares the fields category lists can be filtered and sorted by.
+var categoryListSchema = &listSchema{
+	model: &models.Category{},
+	table: "categories",
+	fields: map[string]listField{
+		"id":         {column: "id", typ: intField, filter: true, sort: true},
+		"name":       {column: "name", typ: stringField, filter: true, sort: true},
+		"name_zh":    {column: "name_zh", typ: stringField, filter: true, sort: true},
+		"parent_id":  {column: "parent_id", typ: intField, filter: true, sort: true, nullable: true},
+		"enabled":    {column: "enabled", typ: boolField, filter: true, sort: true},
+		"created_at": {column: "created_at", typ: timeField, filter: true, sort: true},
+		"updated_at": {column: "updated_at", typ: timeField, filter: true, sort: true},
+	},
+}
+
 type categoryRepository struct {
 	db *gorm.DB
 }
@@ -38,8 +54,8 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 General CRUD queries
 */
 
-// ListCategories retrieves a list of categories.
-func (r *categoryRepository) ListCategories(ctx context.Context, params *query_params.QueryParams) ([]models.Category, int, error) {
+// ListCategories retrieves a list of categories, and the cursor of the next page.
+func (r *categoryRepository) ListCategories(ctx context.Context, params *query_params.QueryParams) ([]models.Category, int, string, error) {
 	var categories []models.Category
 	var totalCount int64
 
@@ -53,32 +69,35 @@ func (r *categoryRepository) ListCategories(ctx context.Context, params *query_p
 	}
 
 	// Handle filters.
-	if params.Filter != nil {
-		for key, value := range params.Filter {
-			query = query.Where(key+" = ?", value)
-		}
+	query, _, err := categoryListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, "", err
 	}
 
 	// Handle sorting.
-	if params.Sort != "" {
-		query = query.Order(params.Sort)
-	} else {
-		query = query.Order("name ASC") // Default sort by name ascending.
+	keys, err := categoryListSchema.keyset(r.db, params.Sort, "name", false) // Default sort by name ascending.
+	if err != nil {
+		return nil, 0, "", err
 	}
 
 	// Get total count.
-	err := query.Count(&totalCount).Error
+	err = query.Count(&totalCount).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
-	// Apply pagination.
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+	// Apply sorting and pagination.
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
 	// Execute query.
-	err = query.Find(&categories).Error
-	return categories, int(totalCount), err
+	if err := query.Find(&categories).Error; err != nil {
+		return nil, 0, "", err
+	}
+	categories, nextCursor, err := nextPage(ctx, keys, categories, params.Limit)
+	return categories, int(totalCount), nextCursor, err
 }
 
 // GetCategory retrieves a single category by ID.
@@ -172,8 +191,19 @@ func (r *categoryRepository) GetChildCategories(ctx context.Context, parentID ui
 	return children, err
 }
 
-// GetCategoryProducts retrieves products under a category.
-func (r *categoryRepository) GetCategoryProducts(ctx context.Context, categoryID uint, params *query_params.QueryParams) ([]models.Product, int, error) {
+// GetCategoriesByIDs retrieves the categories with the given IDs.
+func (r *categoryRepository) GetCategoriesByIDs(ctx context.Context, ids []uint) ([]models.Category, error) {
+	var categories []models.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// GetCategoryProducts retrieves products under a category. If params.Search is set, the list is restricted
+// to searchHits.
+func (r *categoryRepository) GetCategoryProducts(ctx context.Context, categoryID uint, params *query_params.QueryParams, searchHits []uint) ([]models.Product, int, error) {
 	var products []models.Product
 	var totalCount int64
 
@@ -182,35 +212,38 @@ func (r *categoryRepository) GetCategoryProducts(ctx context.Context, categoryID
 		Joins("JOIN product_categories ON products.id = product_categories.product_id").
 		Where("product_categories.category_id = ?", categoryID)
 
-	// Handle search.
+	// Handle search, with the hits from the search index.
 	if params.Search != "" {
-		query = query.Where("products.name LIKE ? OR products.barcode LIKE ?",
-			"%"+params.Search+"%", "%"+params.Search+"%")
+		query = restrictToSearchHits(query, searchHits)
 	}
 
 	// Handle filters.
-	if params.Filter != nil {
-		for key, value := range params.Filter {
-			query = query.Where("products."+key+" = ?", value)
-		}
+	query, custom, err := productListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if query, err = filterByCategories(ctx, query, r, custom); err != nil {
+		return nil, 0, err
 	}
 
-	// Handle sorting.
-	if params.Sort != "" {
-		query = query.Order("products." + params.Sort)
-	} else {
-		query = query.Order("products.updated_at DESC") // Default sort by update time descending.
+	// Handle sorting. Default sort by update time descending.
+	keys, err := productKeyset(r.db, params, searchHits, nil)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Get total count.
-	err := query.Count(&totalCount).Error
+	err = query.Count(&totalCount).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Apply pagination.
+	// Apply sorting and pagination. This list returns no next cursor, so it accepts none.
+	if params.Cursor != nil {
+		return nil, 0, query_params.ErrInvalidCursor
+	}
 	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+	query = keys.order(query).Offset(offset).Limit(params.Limit)
 
 	// Preload associated data.
 	query = query.Preload("Images").Preload("Retailers").Preload("Categories")
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+)
+
+// impersonationEventListSchema declares the fields impersonation events can be filtered and sorted by.
+var impersonationEventListSchema = &listSchema{
+	model: &models.ImpersonationEvent{},
+	table: "impersonation_events",
+	fields: map[string]listField{
+		"id":              {column: "id", typ: intField, sort: true},
+		"impersonator_id": {column: "impersonator_id", typ: intField, filter: true},
+		"user_id":         {column: "user_id", typ: intField, filter: true},
+		"token_id":        {column: "token_id", typ: stringField, filter: true},
+		"ip_address":      {column: "ip_address", typ: stringField, filter: true},
+		"expires_at":      {column: "expires_at", typ: timeField, filter: true, sort: true},
+		"created_at":      {column: "created_at", typ: timeField, filter: true, sort: true},
+	},
+}
+
+// ImpersonationEventRepository defines the interface for impersonation event data access operations.
+type ImpersonationEventRepository interface {
+	CreateImpersonationEvent(ctx context.Context, event *models.ImpersonationEvent) error
+	ListImpersonationEvents(ctx context.Context, params *query_params.QueryParams) ([]models.ImpersonationEvent, int, string, error)
+}
+
+type impersonationEventRepository struct {
+	db *gorm.DB
+}
+
+func NewImpersonationEventRepository(db *gorm.DB) ImpersonationEventRepository {
+	return &impersonationEventRepository{db: db}
+}
+
+// CreateImpersonationEvent records an impersonation.
+func (r *impersonationEventRepository) CreateImpersonationEvent(ctx context.Context, event *models.ImpersonationEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ListImpersonationEvents retrieves impersonation events based on query parameters, newest first by default.
+func (r *impersonationEventRepository) ListImpersonationEvents(ctx context.Context, params *query_params.QueryParams) ([]models.ImpersonationEvent, int, string, error) {
+	var events []models.ImpersonationEvent
+	var totalCount int64
+
+	query := r.db.WithContext(ctx).Model(&models.ImpersonationEvent{})
+
+	// Handle search, filter, sort.
+	if params.Search != "" {
+		query = query.Where("reason LIKE ?", "%"+params.Search+"%")
+	}
+
+	query, _, err := impersonationEventListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	keys, err := impersonationEventListSchema.keyset(r.db, params.Sort, "created_at", true) // Default sort by creation time descending.
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	// Get total count of records.
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, "", err
+	}
+
+	// Apply sorting and pagination.
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, 0, "", err
+	}
+	events, nextCursor, err := nextPage(ctx, keys, events, params.Limit)
+	return events, int(totalCount), nextCursor, err
+}
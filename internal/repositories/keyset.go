@@ -0,0 +1,235 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+/*
+Keyset pagination
+
+Lists are ordered by a sort key (from the sort parameter, or the list's default) followed by the ID, so that
+the order is total. Besides the page parameter, lists accept a cursor: the sort key values of the last item
+of the previous page. The page after a cursor holds the items that come after those values, which stays fast
+on deep pages and neither skips nor repeats items when items are added or removed between requests.
+
+NULL values are sorted last in both directions, the same on every database.
+*/
+
+// sortKey is a key a list is ordered by.
+type sortKey struct {
+	sql      string        // Column, or SQL expression
+	vars     []interface{} // Variables of the SQL expression
+	desc     bool
+	nullable bool
+	typ      reflect.Type                                                       // Go type of the values, to decode cursors
+	valueOf  func(ctx context.Context, item reflect.Value) (interface{}, error) // Value of the key for a loaded item
+}
+
+// keyset is the order of a list, for ordering and paginating its query.
+type keyset struct {
+	sort string // The sort parameter the keyset was created for, stored in cursors
+	keys []sortKey
+}
+
+// sortSchemas caches the parsed schemas of the models lists are sorted on.
+var sortSchemas sync.Map
+
+// newKeyset creates the order of a list: the given key, then the ID column in the same direction.
+func newKeyset(sortBy string, key sortKey, idColumn string) *keyset {
+	if key.sql == idColumn {
+		return &keyset{sort: sortBy, keys: []sortKey{key}}
+	}
+	return &keyset{
+		sort: sortBy,
+		keys: []sortKey{key, {
+			sql:  idColumn,
+			desc: key.desc,
+			typ:  reflect.TypeOf(uint(0)),
+			valueOf: func(ctx context.Context, item reflect.Value) (interface{}, error) {
+				return item.FieldByName("ID").Interface(), nil
+			},
+		}},
+	}
+}
+
+// parseSort splits a sort parameter ("field", "field ASC" or "field DESC") into the field and whether the
+// order is descending.
+func parseSort(sortBy string) (string, bool, error) {
+	parts := strings.Fields(sortBy)
+	switch {
+	case len(parts) == 1:
+		return parts[0], false, nil
+	case len(parts) == 2 && strings.EqualFold(parts[1], "ASC"):
+		return parts[0], false, nil
+	case len(parts) == 2 && strings.EqualFold(parts[1], "DESC"):
+		return parts[0], true, nil
+	}
+	return "", false, query_params.ErrInvalidSort
+}
+
+// columnSortKey creates a sort key on a column of a model, looked up in the model's GORM schema. Only
+// columns of strings, numbers, booleans and times can be sorted by.
+func columnSortKey(db *gorm.DB, model interface{}, table, column string, desc bool) (sortKey, error) {
+	modelSchema, err := schema.Parse(model, &sortSchemas, db.NamingStrategy)
+	if err != nil {
+		return sortKey{}, err
+	}
+	field := modelSchema.LookUpField(column)
+	if field == nil || field.DBName == "" {
+		return sortKey{}, query_params.ErrInvalidSort
+	}
+
+	valueType := field.FieldType
+	nullable := valueType.Kind() == reflect.Ptr
+	if nullable {
+		valueType = valueType.Elem()
+	}
+	switch valueType.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		if valueType != reflect.TypeOf(time.Time{}) {
+			return sortKey{}, query_params.ErrInvalidSort
+		}
+	}
+
+	return sortKey{
+		sql:      table + "." + field.DBName,
+		desc:     desc,
+		nullable: nullable,
+		typ:      field.FieldType,
+		valueOf: func(ctx context.Context, item reflect.Value) (interface{}, error) {
+			value, _ := field.ValueOf(ctx, item)
+			return value, nil
+		},
+	}, nil
+}
+
+// paginate orders a list query by the keyset and restricts it to a page: the items after params.Cursor if
+// set, or else page params.Page. One item more than the page size is loaded, to tell whether there is a
+// next page (see nextPage).
+func (k *keyset) paginate(query *gorm.DB, params *query_params.QueryParams) (*gorm.DB, error) {
+	query = k.order(query)
+	if params.Cursor != nil {
+		var err error
+		if query, err = k.after(query, params.Cursor); err != nil {
+			return nil, err
+		}
+	} else {
+		query = query.Offset((params.Page - 1) * params.Limit)
+	}
+	return query.Limit(params.Limit + 1), nil
+}
+
+// order orders a query by the keys.
+func (k *keyset) order(query *gorm.DB) *gorm.DB {
+	var sql strings.Builder
+	var vars []interface{}
+	for i, key := range k.keys {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		if key.nullable {
+			sql.WriteString("(" + key.sql + ") IS NULL, ")
+			vars = append(vars, key.vars...)
+		}
+		sql.WriteString(key.sql)
+		vars = append(vars, key.vars...)
+		if key.desc {
+			sql.WriteString(" DESC")
+		}
+	}
+	// All keys are in one expression, as GORM drops an ORDER BY expression when another ORDER BY is added.
+	return query.Order(clause.OrderBy{Expression: clause.Expr{SQL: sql.String(), Vars: vars}})
+}
+
+// after restricts a query to the items after a cursor:
+// (k1 after v1) OR (k1 = v1 AND k2 after v2) OR ...
+func (k *keyset) after(query *gorm.DB, cursor *query_params.Cursor) (*gorm.DB, error) {
+	if cursor.Sort != k.sort || len(cursor.Values) != len(k.keys) {
+		return nil, query_params.ErrInvalidCursor
+	}
+
+	var terms, equal []string
+	var vars, equalVars []interface{}
+	for i, key := range k.keys {
+		value, err := decodeCursorValue(cursor.Values[i], key.typ)
+		if err != nil {
+			return nil, err
+		}
+
+		// Nothing comes after NULL but other NULLs, as NULLs are last.
+		if value != nil {
+			comparison := " > ?"
+			if key.desc {
+				comparison = " < ?"
+			}
+			term := key.sql + comparison
+			termVars := append(append([]interface{}{}, key.vars...), value)
+			if key.nullable {
+				term = "(" + term + " OR " + key.sql + " IS NULL)"
+				termVars = append(termVars, key.vars...)
+			}
+			terms = append(terms, strings.Join(append(append([]string{}, equal...), term), " AND "))
+			vars = append(append(vars, equalVars...), termVars...)
+		}
+
+		if value == nil {
+			equal = append(equal, key.sql+" IS NULL")
+			equalVars = append(equalVars, key.vars...)
+		} else {
+			equal = append(equal, key.sql+" = ?")
+			equalVars = append(append(equalVars, key.vars...), value)
+		}
+	}
+	return query.Where(clause.Expr{SQL: "((" + strings.Join(terms, ") OR (") + "))", Vars: vars}), nil
+}
+
+// decodeCursorValue decodes a sort key value of a cursor into the key's type. NULL is returned as nil.
+func decodeCursorValue(raw json.RawMessage, typ reflect.Type) (interface{}, error) {
+	value := reflect.New(typ)
+	if err := json.Unmarshal(raw, value.Interface()); err != nil {
+		return nil, query_params.ErrInvalidCursor
+	}
+	decoded := value.Elem()
+	if decoded.Kind() == reflect.Ptr {
+		if decoded.IsNil() {
+			return nil, nil
+		}
+		decoded = decoded.Elem()
+	}
+	return decoded.Interface(), nil
+}
+
+// nextPage trims the extra item loaded by paginate from a page, and returns the cursor of the next page, or
+// an empty string on the last page.
+func nextPage[T any](ctx context.Context, k *keyset, items []T, limit int) ([]T, string, error) {
+	if len(items) <= limit {
+		return items, "", nil
+	}
+	items = items[:limit]
+
+	last := reflect.ValueOf(&items[limit-1]).Elem()
+	values := make([]interface{}, 0, len(k.keys))
+	for _, key := range k.keys {
+		value, err := key.valueOf(ctx, last)
+		if err != nil {
+			return nil, "", err
+		}
+		values = append(values, value)
+	}
+	cursor, err := query_params.EncodeCursor(k.sort, values)
+	return items, cursor, err
+}
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+)
+
+// mustKeyset creates the order of the test list for a sort parameter.
+func mustKeyset(t *testing.T, db *gorm.DB, sortBy string) *keyset {
+	t.Helper()
+	keys, err := testListSchema.keyset(db, sortBy, "id", false)
+	if err != nil {
+		t.Fatalf("keyset(%q) error = %v", sortBy, err)
+	}
+	return keys
+}
+
+// testCursor creates a cursor from JSON encoded values.
+func testCursor(sortBy string, values ...string) *query_params.Cursor {
+	cursor := &query_params.Cursor{Sort: sortBy}
+	for _, value := range values {
+		cursor.Values = append(cursor.Values, json.RawMessage(value))
+	}
+	return cursor
+}
+
+func TestKeysetOrderAndAfter(t *testing.T) {
+	db := dryRunDB(t)
+
+	tests := []struct {
+		name      string
+		sort      string
+		cursor    []string
+		wantWhere string
+		wantOrder string
+	}{
+		{
+			"id", "id", []string{`7`},
+			`((test_items.id > 7))`,
+			`test_items.id`,
+		},
+		{
+			"id descending", "id DESC", []string{`7`},
+			`((test_items.id < 7))`,
+			`test_items.id DESC`,
+		},
+		{
+			"not nullable", "name", []string{`"milk"`, `7`},
+			`((test_items.name > 'milk') OR (test_items.name = 'milk' AND test_items.id > 7))`,
+			`test_items.name, test_items.id`,
+		},
+		{
+			"not nullable descending", "name desc", []string{`"milk"`, `7`},
+			`((test_items.name < 'milk') OR (test_items.name = 'milk' AND test_items.id < 7))`,
+			`test_items.name DESC, test_items.id DESC`,
+		},
+		{
+			"nullable", "note", []string{`"a"`, `7`},
+			`(((test_items.note > 'a' OR test_items.note IS NULL)) OR (test_items.note = 'a' AND test_items.id > 7))`,
+			`(test_items.note) IS NULL, test_items.note, test_items.id`,
+		},
+		{
+			"nullable descending", "note DESC", []string{`"a"`, `7`},
+			`(((test_items.note < 'a' OR test_items.note IS NULL)) OR (test_items.note = 'a' AND test_items.id < 7))`,
+			`(test_items.note) IS NULL, test_items.note DESC, test_items.id DESC`,
+		},
+		{
+			// NULLs are last in both directions: only other NULLs come after one.
+			"nullable at NULL", "note", []string{`null`, `7`},
+			`((test_items.note IS NULL AND test_items.id > 7))`,
+			`(test_items.note) IS NULL, test_items.note, test_items.id`,
+		},
+		{
+			"nullable descending at NULL", "note DESC", []string{`null`, `7`},
+			`((test_items.note IS NULL AND test_items.id < 7))`,
+			`(test_items.note) IS NULL, test_items.note DESC, test_items.id DESC`,
+		},
+		{
+			"default order", "", []string{`7`},
+			`((test_items.id > 7))`,
+			`test_items.id`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := mustKeyset(t, db, tt.sort)
+
+			var err error
+			sql := querySQL(db, func(query *gorm.DB) *gorm.DB {
+				query = keys.order(query)
+				after, afterErr := keys.after(query, testCursor(tt.sort, tt.cursor...))
+				if err = afterErr; err != nil {
+					return query
+				}
+				return after
+			})
+			if err != nil {
+				t.Fatalf("after() error = %v", err)
+			}
+
+			want := `SELECT * FROM "test_items" WHERE ` + tt.wantWhere + ` ORDER BY ` + tt.wantOrder
+			if sql != want {
+				t.Errorf("SQL = %s\nwant  %s", sql, want)
+			}
+		})
+	}
+}
+
+func TestKeysetAfterInvalidCursor(t *testing.T) {
+	db := dryRunDB(t)
+	keys := mustKeyset(t, db, "count DESC")
+
+	tests := []struct {
+		name   string
+		cursor *query_params.Cursor
+	}{
+		{"other sort", testCursor("count", `3`, `7`)},
+		{"other sort direction", testCursor("count ASC", `3`, `7`)},
+		{"too few values", testCursor("count DESC", `3`)},
+		{"too many values", testCursor("count DESC", `3`, `7`, `9`)},
+		{"wrong type", testCursor("count DESC", `"3"`, `7`)},
+		{"wrong ID type", testCursor("count DESC", `3`, `"7"`)},
+		{"negative ID", testCursor("count DESC", `3`, `-7`)},
+		{"not JSON", testCursor("count DESC", `3`, `x`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := keys.after(db, tt.cursor); !errors.Is(err, query_params.ErrInvalidCursor) {
+				t.Errorf("after() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorValue(t *testing.T) {
+	when := time.Date(2025, 3, 10, 16, 25, 43, 123456789, time.UTC)
+	text := "milk"
+
+	tests := []struct {
+		name    string
+		raw     string
+		typ     reflect.Type
+		want    interface{}
+		wantErr bool
+	}{
+		{"string", `"milk"`, reflect.TypeOf(""), "milk", false},
+		{"bool", `true`, reflect.TypeOf(false), true, false},
+		{"int", `-42`, reflect.TypeOf(0), -42, false},
+		{"int64", `9007199254740993`, reflect.TypeOf(int64(0)), int64(9007199254740993), false},
+		{"uint", `42`, reflect.TypeOf(uint(0)), uint(42), false},
+		{"float", `4.5`, reflect.TypeOf(0.0), 4.5, false},
+		{"time", `"2025-03-10T16:25:43.123456789Z"`, reflect.TypeOf(time.Time{}), when, false},
+		{"nullable string", `"milk"`, reflect.TypeOf(&text), "milk", false},
+		{"nullable time", `"2025-03-10T16:25:43.123456789Z"`, reflect.TypeOf(&when), when, false},
+		{"NULL", `null`, reflect.TypeOf(&text), nil, false},
+
+		{"string from number", `42`, reflect.TypeOf(""), nil, true},
+		{"int from string", `"42"`, reflect.TypeOf(0), nil, true},
+		{"int from fraction", `4.5`, reflect.TypeOf(0), nil, true},
+		{"uint from negative", `-1`, reflect.TypeOf(uint(0)), nil, true},
+		{"bool from number", `1`, reflect.TypeOf(false), nil, true},
+		{"time from other format", `"10/03/2025"`, reflect.TypeOf(time.Time{}), nil, true},
+		{"not JSON", `milk`, reflect.TypeOf(""), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursorValue(json.RawMessage(tt.raw), tt.typ)
+			if tt.wantErr {
+				if !errors.Is(err, query_params.ErrInvalidCursor) {
+					t.Errorf("decodeCursorValue() = %v, %v, want ErrInvalidCursor", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCursorValue() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCursorValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextPage(t *testing.T) {
+	db := dryRunDB(t)
+	keys := mustKeyset(t, db, "name")
+	items := []testItem{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}
+
+	// The last page has no cursor.
+	for _, limit := range []int{3, 4} {
+		page, cursor, err := nextPage(context.Background(), keys, items, limit)
+		if err != nil {
+			t.Fatalf("nextPage(limit %d) error = %v", limit, err)
+		}
+		if len(page) != len(items) || cursor != "" {
+			t.Errorf("nextPage(limit %d) = %d items, cursor %q, want %d items, no cursor", limit, len(page), cursor, len(items))
+		}
+	}
+
+	// Other pages lose the extra item, and the cursor is after their last item.
+	page, cursor, err := nextPage(context.Background(), keys, items, 2)
+	if err != nil {
+		t.Fatalf("nextPage() error = %v", err)
+	}
+	if len(page) != 2 || page[1].ID != 2 {
+		t.Errorf("nextPage() = %+v, want the first 2 items", page)
+	}
+	decoded, err := query_params.DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	want := testCursor("name", `"b"`, `2`)
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("cursor = %s %s, want %s %s", decoded.Sort, decoded.Values, want.Sort, want.Values)
+	}
+}
+
+// TestCursorRoundTrip checks that the cursor of a page decodes, for the key of every sortable field, back to
+// the values of the page's last item.
+func TestCursorRoundTrip(t *testing.T) {
+	db := dryRunDB(t)
+	note := "milk"
+	closedAt := time.Date(2025, 3, 10, 16, 25, 43, 123456789, time.FixedZone("CST", 8*3600))
+	items := []testItem{
+		{ID: 41},
+		{ID: 42, Name: "牛奶", Count: -3, Score: 4.5, Active: true, Note: &note, ClosedAt: &closedAt, CreatedAt: closedAt.UTC()},
+		{ID: 43},
+	}
+	itemsWithNulls := []testItem{{ID: 41}, {ID: 42}, {ID: 43}}
+
+	for name, field := range testListSchema.fields {
+		if !field.sort {
+			continue
+		}
+		for _, sortBy := range []string{name, name + " DESC"} {
+			for _, items := range [][]testItem{items, itemsWithNulls} {
+				keys := mustKeyset(t, db, sortBy)
+				last := reflect.ValueOf(&items[1]).Elem()
+
+				_, encoded, err := nextPage(context.Background(), keys, items, 2)
+				if err != nil {
+					t.Fatalf("%s: nextPage() error = %v", sortBy, err)
+				}
+				cursor, err := query_params.DecodeCursor(encoded)
+				if err != nil {
+					t.Fatalf("%s: DecodeCursor() error = %v", sortBy, err)
+				}
+				if cursor.Sort != sortBy || len(cursor.Values) != len(keys.keys) {
+					t.Fatalf("%s: cursor = %s %s, want %d values", sortBy, cursor.Sort, cursor.Values, len(keys.keys))
+				}
+
+				for i, key := range keys.keys {
+					got, err := decodeCursorValue(cursor.Values[i], key.typ)
+					if err != nil {
+						t.Fatalf("%s: decodeCursorValue(%s) error = %v", sortBy, cursor.Values[i], err)
+					}
+					want, _ := key.valueOf(context.Background(), last)
+					if value := reflect.ValueOf(want); value.Kind() == reflect.Ptr {
+						want = nil
+						if !value.IsNil() {
+							want = value.Elem().Interface()
+						}
+					}
+					if wantTime, ok := want.(time.Time); ok {
+						if gotTime, ok := got.(time.Time); !ok || !gotTime.Equal(wantTime) {
+							t.Errorf("%s: key %s = %v, want %v", sortBy, key.sql, got, want)
+						}
+						continue
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("%s: key %s = %#v, want %#v", sortBy, key.sql, got, want)
+					}
+				}
+
+				// The cursor is accepted by the list it was created for.
+				if _, err := keys.after(db, cursor); err != nil {
+					t.Errorf("%s: after() error = %v", sortBy, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package repositories
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+List schemas
+
+Clients filter and sort lists by fields, not columns: each list declares in a schema the fields it can be
+filtered and sorted by, with their columns and types. Filters (see query_params.ParseFilter) and sorts on
+other fields are rejected, and filter values are converted to the type of their field, so nothing from the
+request is written into the SQL.
+*/
+
+// fieldType is the type of the values of a list field.
+type fieldType int
+
+const (
+	stringField fieldType = iota
+	intField              // Integers, also accepted as strings of digits
+	boolField
+	timeField // RFC 3339 times, or dates (2006-01-02, UTC)
+)
+
+// typeOperators are the filter operators the fields of each type accept.
+var typeOperators = map[fieldType][]query_params.Operator{
+	stringField: {query_params.OpEq, query_params.OpNe, query_params.OpIn, query_params.OpContains, query_params.OpIsNull},
+	intField:    {query_params.OpEq, query_params.OpNe, query_params.OpIn, query_params.OpGt, query_params.OpLt, query_params.OpBetween, query_params.OpIsNull},
+	boolField:   {query_params.OpEq, query_params.OpNe, query_params.OpIsNull},
+	timeField:   {query_params.OpEq, query_params.OpNe, query_params.OpGt, query_params.OpLt, query_params.OpBetween, query_params.OpIsNull},
+}
+
+// listField is a field a list can be filtered or sorted by.
+type listField struct {
+	column    string // Column in the list's table; empty for fields the repository filters by itself
+	typ       fieldType
+	filter    bool                    // Can be filtered by
+	sort      bool                    // Can be sorted by
+	nullable  bool                    // Can be NULL; only nullable fields accept is_null
+	operators []query_params.Operator // Filter operators, if fewer than those of the type (e.g. enums, which have no LIKE)
+}
+
+// listSchema declares the fields of a list.
+type listSchema struct {
+	model  interface{} // Model of the list's table, for sort keys
+	table  string
+	fields map[string]listField
+}
+
+// filterCondition is a condition of a filter on a field the repository filters by itself, with its values
+// converted to the field's type.
+type filterCondition struct {
+	field    string
+	operator query_params.Operator
+	values   []interface{}
+}
+
+// applyFilter restricts a list query by a filter parameter. Conditions on fields without a column are not
+// applied but returned, for the repository to apply.
+func (s *listSchema) applyFilter(query *gorm.DB, filter map[string]interface{}) (*gorm.DB, []filterCondition, error) {
+	conditions, err := query_params.ParseFilter(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var custom []filterCondition
+	for _, condition := range conditions {
+		field, ok := s.fields[condition.Field]
+		if !ok || !field.filter || !field.accepts(condition.Operator) {
+			return nil, nil, query_params.ErrInvalidFilter
+		}
+		values, err := field.values(condition)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if field.column == "" {
+			custom = append(custom, filterCondition{field: condition.Field, operator: condition.Operator, values: values})
+			continue
+		}
+		query = query.Where(field.condition(s.table+"."+field.column, condition.Operator, values))
+	}
+	return query, custom, nil
+}
+
+// sortBy creates the sort key of a sort parameter ("field", "field ASC" or "field DESC") on a field the list
+// can be sorted by.
+func (s *listSchema) sortBy(db *gorm.DB, sortBy string) (sortKey, error) {
+	name, desc, err := parseSort(sortBy)
+	if err != nil {
+		return sortKey{}, err
+	}
+	field, ok := s.fields[name]
+	if !ok || !field.sort || field.column == "" {
+		return sortKey{}, query_params.ErrInvalidSort
+	}
+	return columnSortKey(db, s.model, s.table, field.column, desc)
+}
+
+// keyset creates the order of a list: by the field of a sort parameter, or by defaultColumn in the given
+// direction if the parameter is empty, then by ID.
+func (s *listSchema) keyset(db *gorm.DB, sortBy, defaultColumn string, defaultDesc bool) (*keyset, error) {
+	var key sortKey
+	var err error
+	if sortBy != "" {
+		key, err = s.sortBy(db, sortBy)
+	} else {
+		key, err = columnSortKey(db, s.model, s.table, defaultColumn, defaultDesc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newKeyset(sortBy, key, s.table+".id"), nil
+}
+
+// accepts reports whether the field can be filtered with an operator.
+func (f listField) accepts(operator query_params.Operator) bool {
+	if operator == query_params.OpIsNull && !f.nullable {
+		return false
+	}
+	operators := f.operators
+	if operators == nil {
+		operators = typeOperators[f.typ]
+	}
+	for _, accepted := range operators {
+		if accepted == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// values converts the value of a condition to the field's type: one value, the values of in, the two of
+// between, or the bool of is_null.
+func (f listField) values(condition query_params.Condition) ([]interface{}, error) {
+	switch condition.Operator {
+	case query_params.OpIsNull:
+		return []interface{}{condition.Value}, nil
+	case query_params.OpContains:
+		return []interface{}{condition.Value}, nil // Checked to be a string by ParseFilter
+	}
+
+	raw, ok := condition.Value.([]interface{})
+	if !ok {
+		raw = []interface{}{condition.Value}
+	}
+	values := make([]interface{}, 0, len(raw))
+	for _, value := range raw {
+		converted, err := f.convert(value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, converted)
+	}
+	return values, nil
+}
+
+// convert converts a JSON value to the field's type.
+func (f listField) convert(value interface{}) (interface{}, error) {
+	switch f.typ {
+	case stringField:
+		if text, ok := value.(string); ok {
+			return text, nil
+		}
+	case intField:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case boolField:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case timeField:
+		if text, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339, text); err == nil {
+				return t, nil
+			}
+			if t, err := time.Parse(time.DateOnly, text); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return nil, query_params.ErrInvalidFilter
+}
+
+// condition creates the SQL condition of an operator on a column.
+func (f listField) condition(column string, operator query_params.Operator, values []interface{}) clause.Expr {
+	switch operator {
+	case query_params.OpNe:
+		if f.nullable {
+			// NULL is not equal to anything, though SQL says unknown.
+			return clause.Expr{SQL: "(" + column + " <> ? OR " + column + " IS NULL)", Vars: values}
+		}
+		return clause.Expr{SQL: column + " <> ?", Vars: values}
+	case query_params.OpIn:
+		return clause.Expr{SQL: column + " IN ?", Vars: []interface{}{values}}
+	case query_params.OpGt:
+		return clause.Expr{SQL: column + " > ?", Vars: values}
+	case query_params.OpLt:
+		return clause.Expr{SQL: column + " < ?", Vars: values}
+	case query_params.OpBetween:
+		return clause.Expr{SQL: column + " BETWEEN ? AND ?", Vars: values}
+	case query_params.OpContains:
+		return clause.Expr{SQL: column + " LIKE ?", Vars: []interface{}{"%" + escapeLike(values[0].(string)) + "%"}}
+	case query_params.OpIsNull:
+		if values[0].(bool) {
+			return clause.Expr{SQL: column + " IS NULL"}
+		}
+		return clause.Expr{SQL: column + " IS NOT NULL"}
+	default:
+		return clause.Expr{SQL: column + " = ?", Vars: values}
+	}
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, with backslash, the default escape character of
+// PostgreSQL and MySQL.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes text to be matched literally in a LIKE pattern.
+func escapeLike(text string) string {
+	return likeEscaper.Replace(text)
+}
@@ -0,0 +1,262 @@
+package repositories
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// testItem is the model of the test list.
+type testItem struct {
+	ID        uint
+	Name      string
+	Status    string
+	Count     int
+	Score     float64
+	Active    bool
+	Note      *string
+	ClosedAt  *time.Time
+	CreatedAt time.Time
+}
+
+// testListSchema has a field of each type, a nullable field, an enum, a field without a column and a field
+// that cannot be filtered by.
+var testListSchema = &listSchema{
+	model: &testItem{},
+	table: "test_items",
+	fields: map[string]listField{
+		"id":         {column: "id", typ: intField, filter: true, sort: true},
+		"name":       {column: "name", typ: stringField, filter: true, sort: true},
+		"status":     {column: "status", typ: stringField, filter: true, operators: []query_params.Operator{query_params.OpEq, query_params.OpIn}},
+		"count":      {column: "count", typ: intField, sort: true},
+		"score":      {column: "score", sort: true}, // Sort only: filters have no float type
+		"note":       {column: "note", typ: stringField, sort: true, nullable: true},
+		"active":     {column: "active", typ: boolField, filter: true, sort: true},
+		"closed_at":  {column: "closed_at", typ: timeField, filter: true, sort: true, nullable: true},
+		"created_at": {column: "created_at", typ: timeField, filter: true, sort: true},
+		"tags":       {typ: intField, filter: true, operators: []query_params.Operator{query_params.OpIn}},
+	},
+}
+
+// dryRunDB returns a PostgreSQL database that only builds SQL, without connecting.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+// querySQL returns the SQL of a query on the test list, with its variables inlined.
+func querySQL(db *gorm.DB, build func(query *gorm.DB) *gorm.DB) string {
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return build(tx.Model(&testItem{})).Find(&[]testItem{})
+	})
+}
+
+func TestListSchemaApplyFilter(t *testing.T) {
+	db := dryRunDB(t)
+
+	tests := []struct {
+		name       string
+		filter     map[string]interface{}
+		wantWhere  string
+		wantCustom []filterCondition
+		wantErr    bool
+	}{
+		{"no filter", nil, "", nil, false},
+		{"eq", map[string]interface{}{"name": "milk"}, `WHERE test_items.name = 'milk'`, nil, false},
+		{"int as string", map[string]interface{}{"id": map[string]interface{}{"gt": "7"}}, `WHERE test_items.id > 7`, nil, false},
+		{"several fields", map[string]interface{}{"active": true, "status": []interface{}{"a", "b"}},
+			`WHERE test_items.active = true AND test_items.status IN ('a','b')`, nil, false},
+		{"is_null on nullable field", map[string]interface{}{"closed_at": nil}, `WHERE test_items.closed_at IS NULL`, nil, false},
+		{"field without column", map[string]interface{}{"tags": []interface{}{1.0, 2.0}}, "",
+			[]filterCondition{{field: "tags", operator: query_params.OpIn, values: []interface{}{int64(1), int64(2)}}}, false},
+
+		{"unknown field", map[string]interface{}{"password": "x"}, "", nil, true},
+		{"field not filterable", map[string]interface{}{"count": 1.0}, "", nil, true},
+		{"operator not of the type", map[string]interface{}{"active": map[string]interface{}{"gt": true}}, "", nil, true},
+		{"operator not of the field", map[string]interface{}{"status": map[string]interface{}{"contains": "a"}}, "", nil, true},
+		{"is_null on non-nullable field", map[string]interface{}{"name": nil}, "", nil, true},
+		{"wrong value type", map[string]interface{}{"active": "yes"}, "", nil, true},
+		{"wrong value type in list", map[string]interface{}{"id": []interface{}{1.0, "x"}}, "", nil, true},
+		{"invalid syntax", map[string]interface{}{"id": map[string]interface{}{"in": []interface{}{}}}, "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var custom []filterCondition
+			var err error
+			sql := querySQL(db, func(query *gorm.DB) *gorm.DB {
+				var filtered *gorm.DB
+				filtered, custom, err = testListSchema.applyFilter(query, tt.filter)
+				if err != nil {
+					return query
+				}
+				return filtered
+			})
+			if tt.wantErr {
+				if !errors.Is(err, query_params.ErrInvalidFilter) {
+					t.Errorf("applyFilter() error = %v, want ErrInvalidFilter", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyFilter() error = %v", err)
+			}
+
+			want := `SELECT * FROM "test_items"`
+			if tt.wantWhere != "" {
+				want += " " + tt.wantWhere
+			}
+			if sql != want {
+				t.Errorf("applyFilter() SQL = %s, want %s", sql, want)
+			}
+			if !reflect.DeepEqual(custom, tt.wantCustom) {
+				t.Errorf("applyFilter() custom = %#v, want %#v", custom, tt.wantCustom)
+			}
+		})
+	}
+}
+
+func TestListFieldAccepts(t *testing.T) {
+	tests := []struct {
+		field    string
+		operator query_params.Operator
+		want     bool
+	}{
+		{"name", query_params.OpContains, true},
+		{"name", query_params.OpIn, true},
+		{"name", query_params.OpGt, false},
+		{"name", query_params.OpIsNull, false}, // Not nullable
+		{"id", query_params.OpBetween, true},
+		{"id", query_params.OpContains, false},
+		{"active", query_params.OpEq, true},
+		{"active", query_params.OpIn, false},
+		{"closed_at", query_params.OpIsNull, true},
+		{"closed_at", query_params.OpBetween, true},
+		{"closed_at", query_params.OpIn, false},
+		{"created_at", query_params.OpIsNull, false},
+		{"status", query_params.OpIn, true}, // Own operators replace those of the type
+		{"status", query_params.OpNe, false},
+		{"status", query_params.OpContains, false},
+		{"id", query_params.Operator("like"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field+" "+string(tt.operator), func(t *testing.T) {
+			if got := testListSchema.fields[tt.field].accepts(tt.operator); got != tt.want {
+				t.Errorf("accepts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFieldConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     fieldType
+		value   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"string", stringField, "milk", "milk", false},
+		{"string from number", stringField, 1.0, nil, true},
+		{"string from bool", stringField, true, nil, true},
+		{"int", intField, 42.0, int64(42), false},
+		{"negative int", intField, -3.0, int64(-3), false},
+		{"int from string", intField, "42", int64(42), false},
+		{"int from fraction", intField, 4.2, nil, true},
+		{"int beyond float precision", intField, float64(1 << 60), nil, true},
+		{"int from text", intField, "4x", nil, true},
+		{"int from bool", intField, true, nil, true},
+		{"bool", boolField, false, false, false},
+		{"bool from string", boolField, "true", nil, true},
+		{"bool from number", boolField, 1.0, nil, true},
+		{"time", timeField, "2025-03-10T16:25:43+08:00", time.Date(2025, 3, 10, 8, 25, 43, 0, time.UTC), false},
+		{"date", timeField, "2025-03-10", time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), false},
+		{"time from other format", timeField, "10/03/2025", nil, true},
+		{"time from number", timeField, 1741594000.0, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listField{typ: tt.typ}.convert(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, query_params.ErrInvalidFilter) {
+					t.Errorf("convert() = %v, %v, want ErrInvalidFilter", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("convert() error = %v", err)
+			}
+			if gotTime, ok := got.(time.Time); ok {
+				if !gotTime.Equal(tt.want.(time.Time)) {
+					t.Errorf("convert() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("convert() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFieldCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		nullable bool
+		operator query_params.Operator
+		values   []interface{}
+		want     clause.Expr
+	}{
+		{"eq", false, query_params.OpEq, []interface{}{"a"}, clause.Expr{SQL: "t.c = ?", Vars: []interface{}{"a"}}},
+		{"ne", false, query_params.OpNe, []interface{}{"a"}, clause.Expr{SQL: "t.c <> ?", Vars: []interface{}{"a"}}},
+		{"ne nullable", true, query_params.OpNe, []interface{}{"a"}, clause.Expr{SQL: "(t.c <> ? OR t.c IS NULL)", Vars: []interface{}{"a"}}},
+		{"in", false, query_params.OpIn, []interface{}{"a", "b"}, clause.Expr{SQL: "t.c IN ?", Vars: []interface{}{[]interface{}{"a", "b"}}}},
+		{"gt", false, query_params.OpGt, []interface{}{int64(1)}, clause.Expr{SQL: "t.c > ?", Vars: []interface{}{int64(1)}}},
+		{"lt", false, query_params.OpLt, []interface{}{int64(1)}, clause.Expr{SQL: "t.c < ?", Vars: []interface{}{int64(1)}}},
+		{"between", false, query_params.OpBetween, []interface{}{int64(1), int64(9)}, clause.Expr{SQL: "t.c BETWEEN ? AND ?", Vars: []interface{}{int64(1), int64(9)}}},
+		{"contains", false, query_params.OpContains, []interface{}{"milk"}, clause.Expr{SQL: "t.c LIKE ?", Vars: []interface{}{"%milk%"}}},
+		{"contains wildcards", false, query_params.OpContains, []interface{}{`50%_\`}, clause.Expr{SQL: "t.c LIKE ?", Vars: []interface{}{`%50\%\_\\%`}}},
+		{"is null", true, query_params.OpIsNull, []interface{}{true}, clause.Expr{SQL: "t.c IS NULL"}},
+		{"is not null", true, query_params.OpIsNull, []interface{}{false}, clause.Expr{SQL: "t.c IS NOT NULL"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := listField{nullable: tt.nullable}.condition("t.c", tt.operator, tt.values)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("condition() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"milk", "milk"},
+		{"100%", `100\%`},
+		{"a_b", `a\_b`},
+		{`C:\dir`, `C:\\dir`},
+		{`%_\`, `\%\_\\`},
+		{`\%`, `\\\%`}, // The backslash is escaped first, so it cannot escape the wildcard
+		{"牛奶", "牛奶"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := escapeLike(tt.text); got != tt.want {
+				t.Errorf("escapeLike(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
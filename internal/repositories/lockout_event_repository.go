@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+)
+
+// lockoutEventListSchema declares the fields lockout events can be filtered and sorted by.
+var lockoutEventListSchema = &listSchema{
+	model: &models.LockoutEvent{},
+	table: "lockout_events",
+	fields: map[string]listField{
+		"id":            {column: "id", typ: intField, sort: true},
+		"scope":         {column: "scope", typ: stringField, filter: true},
+		"user_id":       {column: "user_id", typ: intField, filter: true, nullable: true},
+		"ip_address":    {column: "ip_address", typ: stringField, filter: true},
+		"failure_count": {column: "failure_count", typ: intField, filter: true, sort: true},
+		"locked_until":  {column: "locked_until", typ: timeField, filter: true, sort: true},
+		"created_at":    {column: "created_at", typ: timeField, filter: true, sort: true},
+	},
+}
+
+// LockoutEventRepository defines the interface for lockout event data access operations.
+type LockoutEventRepository interface {
+	CreateLockoutEvent(ctx context.Context, event *models.LockoutEvent) error
+	ListLockoutEvents(ctx context.Context, params *query_params.QueryParams) ([]models.LockoutEvent, int, string, error)
+}
+
+type lockoutEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLockoutEventRepository(db *gorm.DB) LockoutEventRepository {
+	return &lockoutEventRepository{db: db}
+}
+
+// CreateLockoutEvent records a lockout.
+func (r *lockoutEventRepository) CreateLockoutEvent(ctx context.Context, event *models.LockoutEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ListLockoutEvents retrieves lockout events based on query parameters, newest first by default.
+func (r *lockoutEventRepository) ListLockoutEvents(ctx context.Context, params *query_params.QueryParams) ([]models.LockoutEvent, int, string, error) {
+	var events []models.LockoutEvent
+	var totalCount int64
+
+	query := r.db.WithContext(ctx).Model(&models.LockoutEvent{})
+
+	// Handle search, filter, sort.
+	if params.Search != "" {
+		query = query.Where("identifier LIKE ?", "%"+params.Search+"%")
+	}
+
+	query, _, err := lockoutEventListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	keys, err := lockoutEventListSchema.keyset(r.db, params.Sort, "created_at", true) // Default sort by creation time descending.
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	// Get total count of records.
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, "", err
+	}
+
+	// Apply sorting and pagination.
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, 0, "", err
+	}
+	events, nextCursor, err := nextPage(ctx, keys, events, params.Limit)
+	return events, int(totalCount), nextCursor, err
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+)
+
+// loginEventListSchema declares the fields login events can be filtered and sorted by.
+var loginEventListSchema = &listSchema{
+	model: &models.LoginEvent{},
+	table: "login_events",
+	fields: map[string]listField{
+		"id":         {column: "id", typ: intField, sort: true},
+		"method":     {column: "method", typ: stringField, filter: true, sort: true},
+		"success":    {column: "success", typ: boolField, filter: true, sort: true},
+		"mfa":        {column: "mfa", typ: boolField, filter: true, sort: true},
+		"ip_address": {column: "ip_address", typ: stringField, filter: true, sort: true},
+		"new_device": {column: "new_device", typ: boolField, filter: true, sort: true},
+		"created_at": {column: "created_at", typ: timeField, filter: true, sort: true},
+	},
+}
+
+// LoginEventRepository defines the interface for login event data access operations.
+type LoginEventRepository interface {
+	CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error
+	// ListUserLoginEvents lists a user's login events, newest first unless sorted otherwise.
+	ListUserLoginEvents(ctx context.Context, userID uint, params *query_params.QueryParams) ([]models.LoginEvent, int, string, error)
+	// HasSuccessfulLogin reports whether the user has logged in successfully before, from the device with the
+	// given fingerprint, or from any device if the fingerprint is empty.
+	HasSuccessfulLogin(ctx context.Context, userID uint, deviceFingerprint string) (bool, error)
+}
+
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+// CreateLoginEvent records a login attempt.
+func (r *loginEventRepository) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// ListUserLoginEvents retrieves a user's login events based on query parameters.
+func (r *loginEventRepository) ListUserLoginEvents(ctx context.Context, userID uint, params *query_params.QueryParams) ([]models.LoginEvent, int, string, error) {
+	var events []models.LoginEvent
+	var totalCount int64
+
+	query := r.db.WithContext(ctx).Model(&models.LoginEvent{}).Where("user_id = ?", userID)
+
+	// Handle filter, sort.
+	query, _, err := loginEventListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	keys, err := loginEventListSchema.keyset(r.db, params.Sort, "created_at", true) // Default sort by creation time descending.
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	// Get total count of records.
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, 0, "", err
+	}
+
+	// Apply sorting and pagination.
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return nil, 0, "", err
+	}
+	events, nextCursor, err := nextPage(ctx, keys, events, params.Limit)
+	return events, int(totalCount), nextCursor, err
+}
+
+// HasSuccessfulLogin reports whether the user has a successful login, optionally from a specific device.
+func (r *loginEventRepository) HasSuccessfulLogin(ctx context.Context, userID uint, deviceFingerprint string) (bool, error) {
+	query := r.db.WithContext(ctx).Model(&models.LoginEvent{}).Where("user_id = ? AND success = ?", userID, true)
+	if deviceFingerprint != "" {
+		query = query.Where("device_fingerprint = ?", deviceFingerprint)
+	}
+
+	// Only the existence matters, so stop at the first match instead of counting all logins.
+	var ids []uint
+	err := query.Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MFARepository defines the interface for two-factor authentication data access operations.
+type MFARepository interface {
+	GetUserMFA(ctx context.Context, userID uint) (*models.UserMFA, error)
+	SaveUserMFA(ctx context.Context, mfa *models.UserMFA) error
+	UpdateUserMFA(ctx context.Context, userID uint, updates map[string]interface{}) error
+	// UseTOTPStep records a used TOTP time step. It returns false if the step (or a later one) was already used.
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	// DeleteUserMFA removes the TOTP configuration and all recovery codes of a user.
+	DeleteUserMFA(ctx context.Context, userID uint) error
+	// ReplaceRecoveryCodes replaces all recovery codes of a user with new ones.
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	// UseRecoveryCode marks an unused recovery code as used. It returns false if no such code exists.
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID uint) (int64, error)
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+// GetUserMFA retrieves the TOTP configuration of a user.
+func (r *mfaRepository) GetUserMFA(ctx context.Context, userID uint) (*models.UserMFA, error) {
+	var mfa models.UserMFA
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&mfa).Error
+	if err != nil {
+		return nil, err
+	}
+	return &mfa, nil
+}
+
+// SaveUserMFA creates or replaces the TOTP configuration of a user.
+func (r *mfaRepository) SaveUserMFA(ctx context.Context, mfa *models.UserMFA) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"totp_secret", "enabled", "last_used_step", "enabled_at", "updated_at"}),
+	}).Create(mfa).Error
+}
+
+// UpdateUserMFA updates the TOTP configuration of a user.
+func (r *mfaRepository) UpdateUserMFA(ctx context.Context, userID uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.UserMFA{}).Where("user_id = ?", userID).Updates(updates).Error
+}
+
+// UseTOTPStep records a used TOTP time step, rejecting replays.
+func (r *mfaRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteUserMFA removes the TOTP configuration and all recovery codes of a user.
+func (r *mfaRepository) DeleteUserMFA(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserMFA{}).Error
+	})
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of a user with new ones.
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.MFARecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, models.MFARecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused recovery code as used.
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnusedRecoveryCodes counts the recovery codes of a user that have not been used yet.
+func (r *mfaRepository) CountUnusedRecoveryCodes(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OAuthClientRepository defines the interface for data access of third-party OAuth clients and the consents
+// users have given them.
+type OAuthClientRepository interface {
+	CreateOAuthClient(ctx context.Context, client *models.OAuthClient) error
+	GetOAuthClient(ctx context.Context, id uint) (*models.OAuthClient, error)
+	GetOAuthClientByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	ListOAuthClients(ctx context.Context) ([]models.OAuthClient, error)
+	UpdateOAuthClient(ctx context.Context, id uint, updates map[string]interface{}) error
+	// DeleteOAuthClient deletes a client together with the consents given to it.
+	DeleteOAuthClient(ctx context.Context, id uint) error
+
+	GetConsent(ctx context.Context, userID, oauthClientID uint) (*models.OAuthConsent, error)
+	// SaveConsent creates the user's consent for a client or replaces the scopes of the existing one.
+	SaveConsent(ctx context.Context, consent *models.OAuthConsent) error
+	// ListConsents lists the user's consents with their clients, most recently given first.
+	ListConsents(ctx context.Context, userID uint) ([]models.OAuthConsent, error)
+	DeleteConsent(ctx context.Context, userID, oauthClientID uint) error
+}
+
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+// CreateOAuthClient creates a client.
+func (r *oauthClientRepository) CreateOAuthClient(ctx context.Context, client *models.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+// GetOAuthClient retrieves a client by ID.
+func (r *oauthClientRepository) GetOAuthClient(ctx context.Context, id uint) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := r.db.WithContext(ctx).First(&client, id).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// GetOAuthClientByClientID retrieves a client by its public client ID.
+func (r *oauthClientRepository) GetOAuthClientByClientID(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := r.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+// ListOAuthClients lists all clients, oldest first.
+func (r *oauthClientRepository) ListOAuthClients(ctx context.Context) ([]models.OAuthClient, error) {
+	var clients []models.OAuthClient
+	err := r.db.WithContext(ctx).Order("id").Find(&clients).Error
+	return clients, err
+}
+
+// UpdateOAuthClient updates a client.
+func (r *oauthClientRepository) UpdateOAuthClient(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.OAuthClient{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeleteOAuthClient deletes a client and its consents.
+func (r *oauthClientRepository) DeleteOAuthClient(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("oauth_client_id = ?", id).Delete(&models.OAuthConsent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.OAuthClient{}, id).Error
+	})
+}
+
+// GetConsent retrieves the user's consent for a client.
+func (r *oauthClientRepository) GetConsent(ctx context.Context, userID, oauthClientID uint) (*models.OAuthConsent, error) {
+	var consent models.OAuthConsent
+	err := r.db.WithContext(ctx).Where("user_id = ? AND oauth_client_id = ?", userID, oauthClientID).First(&consent).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// SaveConsent creates or updates the user's consent for a client.
+func (r *oauthClientRepository) SaveConsent(ctx context.Context, consent *models.OAuthConsent) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "oauth_client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+	}).Create(consent).Error
+}
+
+// ListConsents lists the user's consents with their clients.
+func (r *oauthClientRepository) ListConsents(ctx context.Context, userID uint) ([]models.OAuthConsent, error) {
+	var consents []models.OAuthConsent
+	err := r.db.WithContext(ctx).Preload("OAuthClient").Where("user_id = ?", userID).Order("updated_at DESC").Find(&consents).Error
+	return consents, err
+}
+
+// DeleteConsent deletes the user's consent for a client.
+func (r *oauthClientRepository) DeleteConsent(ctx context.Context, userID, oauthClientID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND oauth_client_id = ?", userID, oauthClientID).Delete(&models.OAuthConsent{}).Error
+}
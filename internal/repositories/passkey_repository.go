@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+)
+
+// PasskeyRepository defines the interface for passkey (WebAuthn credential) data access operations.
+type PasskeyRepository interface {
+	CreatePasskey(ctx context.Context, passkey *models.Passkey) error
+	GetPasskeyByCredentialID(ctx context.Context, credentialID string) (*models.Passkey, error)
+	ListPasskeys(ctx context.Context, userID uint) ([]models.Passkey, error)
+	UpdatePasskey(ctx context.Context, id uint, updates map[string]interface{}) error
+	// DeletePasskey deletes a passkey owned by the user. It returns gorm.ErrRecordNotFound if there is no such passkey.
+	DeletePasskey(ctx context.Context, userID, id uint) error
+}
+
+type passkeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) PasskeyRepository {
+	return &passkeyRepository{db: db}
+}
+
+// CreatePasskey creates a new passkey.
+func (r *passkeyRepository) CreatePasskey(ctx context.Context, passkey *models.Passkey) error {
+	return r.db.WithContext(ctx).Create(passkey).Error
+}
+
+// GetPasskeyByCredentialID retrieves a passkey by its WebAuthn credential ID.
+func (r *passkeyRepository) GetPasskeyByCredentialID(ctx context.Context, credentialID string) (*models.Passkey, error) {
+	var passkey models.Passkey
+	err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&passkey).Error
+	if err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+// ListPasskeys retrieves all passkeys of a user, newest first.
+func (r *passkeyRepository) ListPasskeys(ctx context.Context, userID uint) ([]models.Passkey, error) {
+	var passkeys []models.Passkey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&passkeys).Error
+	if err != nil {
+		return nil, err
+	}
+	return passkeys, nil
+}
+
+// UpdatePasskey updates a passkey.
+func (r *passkeyRepository) UpdatePasskey(ctx context.Context, id uint, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.Passkey{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// DeletePasskey deletes a passkey owned by the user.
+func (r *passkeyRepository) DeletePasskey(ctx context.Context, userID, id uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.Passkey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package repositories
 
 import (
 	"context" // Added for context
-	"strconv"
 
 	"github.com/go-backend-template/internal/models"
 	"github.com/go-backend-template/pkg/query_params"
@@ -12,7 +11,7 @@ import (
 // ProductRepository defines the interface for product data access operations.
 type ProductRepository interface {
 	// General CRUD queries
-	ListProducts(ctx context.Context, params *query_params.QueryParams) ([]models.Product, int, error)
+	ListProducts(ctx context.Context, params *query_params.QueryParams, searchHits []uint) ([]models.Product, int, string, error)
 	GetProduct(ctx context.Context, id uint) (*models.Product, error)
 	CreateProduct(ctx context.Context, product *models.Product) error
 	UpdateProduct(ctx context.Context, id uint, updates map[string]interface{}) error
@@ -21,6 +20,31 @@ type ProductRepository interface {
 	// Transaction support
 	CreateProductWithRelations(ctx context.Context, product *models.Product, images []models.ProductImage, categoryIDs []uint) error
 	UpdateProductWithRelations(ctx context.Context, id uint, updates map[string]interface{}, images []models.ProductImage, categoryIDs []uint) error
+
+	// Search index support
+	ListProductsForIndex(ctx context.Context, afterID uint, limit int) ([]models.Product, error)
+	ListCategoryProductsForIndex(ctx context.Context, categoryID uint, afterID uint, limit int) ([]models.Product, error)
+
+	// Facets
+	CountProductsByCategory(ctx context.Context, params *query_params.QueryParams, searchHits []uint) (map[uint]int, error)
+	CountProductsByBarcodeType(ctx context.Context, params *query_params.QueryParams, searchHits []uint) (map[string]int, error)
+}
+
+// productListSchema declares the fields product lists can be filtered and sorted by.
+var productListSchema = &listSchema{
+	model: &models.Product{},
+	table: "products",
+	fields: map[string]listField{
+		"id":                    {column: "id", typ: intField, filter: true, sort: true},
+		"name":                  {column: "name", typ: stringField, filter: true, sort: true},
+		"barcode":               {column: "barcode", typ: stringField, filter: true, sort: true},
+		"barcode_type":          {column: "barcode_type", typ: stringField, filter: true, sort: true},
+		"description_status":    {column: "description_status", typ: stringField, filter: true, sort: true, operators: []query_params.Operator{query_params.OpEq, query_params.OpNe, query_params.OpIn}},
+		"description_loaded_at": {column: "description_updated_at", typ: timeField, filter: true, sort: true, nullable: true},
+		"created_at":            {column: "created_at", typ: timeField, filter: true, sort: true},
+		"updated_at":            {column: "updated_at", typ: timeField, filter: true, sort: true},
+		"categories":            {typ: intField, filter: true, operators: []query_params.Operator{query_params.OpEq, query_params.OpIn}}, // See filterByCategories
+	},
 }
 
 type productRepository struct {
@@ -39,89 +63,86 @@ func NewProductRepository(db *gorm.DB, categoryRepo CategoryRepository) ProductR
 5 general CRUD queries
 */
 
-// ListProducts retrieves a list of products based on query parameters.
-func (r *productRepository) ListProducts(ctx context.Context, params *query_params.QueryParams) ([]models.Product, int, error) {
+// ListProducts retrieves a list of products based on query parameters, and the cursor of the next page.
+// If params.Search is set, the list is restricted to searchHits, the products found by the search index.
+func (r *productRepository) ListProducts(ctx context.Context, params *query_params.QueryParams, searchHits []uint) ([]models.Product, int, string, error) {
 	var products []models.Product
 	var totalCount int64
 
-	// Create query.
-	query := r.db.WithContext(ctx).Model(&models.Product{}) // Add WithContext
+	// Create query, with the search and filters.
+	query, err := r.filterProducts(ctx, params, searchHits)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
-	// Handle search.
-	if params.Search != "" {
-		query = query.Where("products.name LIKE ? OR products.barcode LIKE ?",
-			"%"+params.Search+"%", "%"+params.Search+"%")
+	// Handle sorting. Default sort by update time descending.
+	keys, err := productKeyset(r.db, params, searchHits, nil)
+	if err != nil {
+		return nil, 0, "", err
 	}
 
-	// Handle filters.
-	if params.Filter != nil {
-		for key, value := range params.Filter {
-			// Special handling for 'categories' filter.
-			if key == "categories" {
-				if categoryIDs, ok := value.([]interface{}); ok && len(categoryIDs) > 0 {
-					// Create a slice to store numeric IDs.
-					var categoryIDsUint []uint
-
-					// Convert interface{} to uint IDs.
-					for _, id := range categoryIDs {
-						switch v := id.(type) {
-						case float64:
-							// JSON numbers are parsed as float64 by default.
-							categoryIDsUint = append(categoryIDsUint, uint(v))
-						case int:
-							categoryIDsUint = append(categoryIDsUint, uint(v))
-						case uint:
-							categoryIDsUint = append(categoryIDsUint, v)
-						case string:
-							// If it's a string representation of a number, try to convert.
-							if numID, err := strconv.ParseUint(v, 10, 64); err == nil {
-								categoryIDsUint = append(categoryIDsUint, uint(numID))
-							}
-						}
-					}
+	// Get total count of records.
+	err = query.Count(&totalCount).Error
+	if err != nil {
+		return nil, 0, "", err
+	}
 
-					// Expand category ID list to include all children of the specified categories.
-					expandedCategoryIDs, err := r.categoryRepo.ExpandCategoryIDsWithChildren(ctx, categoryIDsUint) // Pass context
-					if err != nil {
-						return nil, 0, err
-					}
+	// Apply sorting and pagination.
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
-					// Filter products using the expanded category ID list.
-					if len(expandedCategoryIDs) > 0 {
-						// Using EXISTS subquery, which is more efficient than JOIN and IN for this case.
-						query = query.Where("EXISTS (SELECT 1 FROM product_categories pc WHERE pc.product_id = products.id AND pc.category_id IN ?)", expandedCategoryIDs)
-					}
-				}
-			} else {
-				// Handle regular filter conditions.
-				query = query.Where("products."+key+" = ?", value)
-			}
-		}
+	// Preload associated data.
+	query = query.Preload("Images").Preload("Categories")
+
+	// Execute query.
+	if err := query.Find(&products).Error; err != nil {
+		return nil, 0, "", err
 	}
+	products, nextCursor, err := nextPage(ctx, keys, products, params.Limit)
+	return products, int(totalCount), nextCursor, err
+}
+
+// filterProducts creates a query for the products matching the search and filters of params. If
+// params.Search is set, the products are restricted to searchHits.
+func (r *productRepository) filterProducts(ctx context.Context, params *query_params.QueryParams, searchHits []uint) (*gorm.DB, error) {
+	query := r.db.WithContext(ctx).Model(&models.Product{}) // Add WithContext
 
-	// Handle sorting.
-	if params.Sort != "" {
-		query = query.Order("products." + params.Sort)
-	} else {
-		query = query.Order("products.updated_at DESC") // Default sort by update time descending.
+	// Handle search, with the hits from the search index.
+	if params.Search != "" {
+		query = restrictToSearchHits(query, searchHits)
 	}
 
-	// Get total count of records.
-	err := query.Count(&totalCount).Error
+	// Handle filters.
+	query, custom, err := productListSchema.applyFilter(query, params.Filter)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
+	return filterByCategories(ctx, query, r.categoryRepo, custom)
+}
 
-	// Apply pagination.
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+// filterByCategories applies the categories filter of a product list (the only field product lists filter
+// by themselves): products in any of the categories or their subcategories.
+func filterByCategories(ctx context.Context, query *gorm.DB, categoryRepo CategoryRepository, conditions []filterCondition) (*gorm.DB, error) {
+	for _, condition := range conditions {
+		categoryIDs := make([]uint, 0, len(condition.values))
+		for _, value := range condition.values {
+			if id := value.(int64); id > 0 {
+				categoryIDs = append(categoryIDs, uint(id))
+			}
+		}
 
-	// Preload associated data.
-	query = query.Preload("Images").Preload("Categories")
+		// Expand category ID list to include all children of the specified categories.
+		expandedCategoryIDs, err := categoryRepo.ExpandCategoryIDsWithChildren(ctx, categoryIDs) // Pass context
+		if err != nil {
+			return nil, err
+		}
 
-	// Execute query.
-	err = query.Find(&products).Error
-	return products, int(totalCount), err
+		// Using EXISTS subquery, which is more efficient than JOIN and IN for this case.
+		query = query.Where("EXISTS (SELECT 1 FROM product_categories pc WHERE pc.product_id = products.id AND pc.category_id IN ?)", expandedCategoryIDs)
+	}
+	return query, nil
 }
 
 // GetProduct retrieves a single product by ID, with preloaded associations.
@@ -280,3 +301,116 @@ func (r *productRepository) UpdateProductWithRelations(ctx context.Context, id u
 		return nil
 	})
 }
+
+/*
+Search index support
+*/
+
+// ListProductsForIndex retrieves up to limit products with IDs greater than afterID, in ID order, with their
+// categories, for (re)building the search index.
+func (r *productRepository) ListProductsForIndex(ctx context.Context, afterID uint, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Preload("Categories").Where("id > ?", afterID).Order("id").Limit(limit).Find(&products).Error
+	return products, err
+}
+
+// ListCategoryProductsForIndex is ListProductsForIndex restricted to the products of a category, for
+// reindexing them after the category has been renamed or deleted. The category itself may be soft-deleted.
+func (r *productRepository) ListCategoryProductsForIndex(ctx context.Context, categoryID uint, afterID uint, limit int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.WithContext(ctx).Preload("Categories").
+		Where("id > ? AND EXISTS (SELECT 1 FROM product_categories pc WHERE pc.product_id = products.id AND pc.category_id = ?)", afterID, categoryID).
+		Order("id").Limit(limit).Find(&products).Error
+	return products, err
+}
+
+/*
+Facets
+*/
+
+// CountProductsByCategory counts the products matching the search and filters of params in each category.
+// Like the categories filter, a category counts the products of its subcategories, and a product in several
+// subcategories of a category counts once. Categories without products are left out.
+func (r *productRepository) CountProductsByCategory(ctx context.Context, params *query_params.QueryParams, searchHits []uint) (map[uint]int, error) {
+	query, err := r.filterProducts(ctx, params, searchHits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the parent of each category, to roll the counts up through the hierarchy.
+	var categories []models.Category
+	if err := r.db.WithContext(ctx).Select("id", "parent_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	// Go through the categories of the matching products, one product at a time.
+	rows, err := r.db.WithContext(ctx).Table("product_categories").
+		Select("product_id, category_id").
+		Where("product_id IN (?)", query.Select("products.id")).
+		Order("product_id").
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uint]int)
+	counted := make(map[uint]bool) // Categories the current product has been counted in
+	currentID := uint(0)
+	for rows.Next() {
+		var productID, categoryID uint
+		if err := rows.Scan(&productID, &categoryID); err != nil {
+			return nil, err
+		}
+		if productID != currentID {
+			clear(counted)
+			currentID = productID
+		}
+
+		// Count the product in its category and the ancestors it has not been counted in yet. Deleted
+		// categories end the walk, as they do when expanding the categories filter.
+		for id := categoryID; !counted[id]; {
+			parentID, ok := parents[id]
+			if !ok {
+				break
+			}
+			counted[id] = true
+			counts[id]++
+			if parentID == nil {
+				break
+			}
+			id = *parentID
+		}
+	}
+	return counts, rows.Err()
+}
+
+// CountProductsByBarcodeType counts the products matching the search and filters of params by barcode type.
+// Products without a barcode type are left out.
+func (r *productRepository) CountProductsByBarcodeType(ctx context.Context, params *query_params.QueryParams, searchHits []uint) (map[string]int, error) {
+	query, err := r.filterProducts(ctx, params, searchHits)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		BarcodeType string
+		Count       int
+	}
+	if err := query.Select("products.barcode_type, COUNT(*) AS count").
+		Where("products.barcode_type <> ''").
+		Group("products.barcode_type").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.BarcodeType] = result.Count
+	}
+	return counts, nil
+}
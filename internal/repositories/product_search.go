@@ -0,0 +1,236 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/search"
+	"github.com/go-backend-template/pkg/query_params"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/*
+Product search
+
+Product lists take the hits of a search from the search index (see the search package) and restrict and
+order their query by them.
+
+With the database search engine, the index is the database itself: the searchable text of a product is kept
+in products.search_text and indexed by
+  - PostgreSQL: a generated tsvector column (search_vector) with a GIN index, queried by prefix so that
+    results show up while the user is typing
+  - MySQL: a FULLTEXT index with the ngram parser, which also works for Chinese text
+Other databases fall back to a case-insensitive LIKE on search_text.
+*/
+
+// postgresSearchConfig is the text search configuration. Product names come in several languages, so words
+// are only lowercased, not stemmed.
+const postgresSearchConfig = "simple"
+
+// productSearchBatchSize is the number of products whose search text is rebuilt at once.
+const productSearchBatchSize = 500
+
+// MigrateProductSearch creates the full-text search index of products and fills in the search text of
+// products created before it existed. It runs after AutoMigrate.
+func MigrateProductSearch(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	migrator := db.Migrator()
+
+	switch db.Dialector.Name() {
+	case "postgres":
+		if !migrator.HasColumn(&models.Product{}, "search_vector") {
+			if err := db.Exec(fmt.Sprintf("ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('%s', coalesce(search_text, ''))) STORED", postgresSearchConfig)).Error; err != nil {
+				return fmt.Errorf("failed to add search_vector column: %w", err)
+			}
+		}
+		if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)").Error; err != nil {
+			return fmt.Errorf("failed to create search index: %w", err)
+		}
+	case "mysql":
+		if !migrator.HasIndex(&models.Product{}, "idx_products_search_text") {
+			if err := db.Exec("ALTER TABLE products ADD FULLTEXT INDEX idx_products_search_text (search_text) WITH PARSER ngram").Error; err != nil {
+				return fmt.Errorf("failed to create search index: %w", err)
+			}
+		}
+	}
+
+	// Fill in the search text of existing products.
+	lastID := uint(0)
+	for {
+		var ids []uint
+		if err := db.Unscoped().Model(&models.Product{}).
+			Where("id > ? AND (search_text IS NULL OR search_text = '')", lastID).
+			Order("id").
+			Limit(productSearchBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := refreshProductSearchText(db, ids); err != nil {
+			return err
+		}
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// productSearchIndex is the database search engine: a search.SearchIndex over products.search_text.
+type productSearchIndex struct {
+	db *gorm.DB
+}
+
+// NewProductSearchIndex creates the search index of the database search engine.
+func NewProductSearchIndex(db *gorm.DB) search.SearchIndex {
+	return &productSearchIndex{db: db}
+}
+
+// Index writes the search text of products.
+func (r *productSearchIndex) Index(ctx context.Context, docs ...search.Document) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, doc := range docs {
+			// UpdateColumn leaves updated_at alone: the product itself has not changed.
+			if err := tx.Unscoped().Model(&models.Product{}).Where("id = ?", doc.ID).
+				UpdateColumn("search_text", doc.Text()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete does nothing: the search text is deleted with the product, and soft-deleted products are never
+// returned.
+func (r *productSearchIndex) Delete(ctx context.Context, ids ...uint) error {
+	return nil
+}
+
+// Clear does nothing: rebuilding the index overwrites the search text of every product.
+func (r *productSearchIndex) Clear(ctx context.Context) error {
+	return nil
+}
+
+// Query runs a full-text search on the products, ordered by relevance, then by update time.
+func (r *productSearchIndex) Query(ctx context.Context, query search.Query) ([]uint, error) {
+	db := r.db.WithContext(ctx).Model(&models.Product{})
+
+	var rank clause.Expr
+	switch db.Dialector.Name() {
+	case "postgres":
+		tsQuery := toPrefixTSQuery(query.Text)
+		if tsQuery == "" {
+			return []uint{}, nil
+		}
+		db = db.Where(fmt.Sprintf("products.search_vector @@ to_tsquery('%s', ?)", postgresSearchConfig), tsQuery)
+		rank = clause.Expr{SQL: fmt.Sprintf("ts_rank(products.search_vector, to_tsquery('%s', ?)) DESC, products.updated_at DESC", postgresSearchConfig), Vars: []interface{}{tsQuery}}
+	case "mysql":
+		db = db.Where("MATCH (products.search_text) AGAINST (? IN NATURAL LANGUAGE MODE)", query.Text)
+		rank = clause.Expr{SQL: "MATCH (products.search_text) AGAINST (? IN NATURAL LANGUAGE MODE) DESC, products.updated_at DESC", Vars: []interface{}{query.Text}}
+	default:
+		db = db.Where("LOWER(products.search_text) LIKE ?", "%"+strings.ToLower(query.Text)+"%")
+		rank = clause.Expr{SQL: "products.updated_at DESC"}
+	}
+	// Rank and update time are in one expression, as GORM drops an ORDER BY expression when another
+	// ORDER BY is added.
+	db = db.Order(clause.OrderBy{Expression: rank})
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var ids []uint
+	err := db.Pluck("products.id", &ids).Error
+	return ids, err
+}
+
+// toPrefixTSQuery converts a search into a tsquery matching products that contain all of its words, each by
+// prefix. Only letters and digits are kept, so the search cannot inject tsquery operators.
+func toPrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// restrictToSearchHits restricts a product query to the hits of a search.
+func restrictToSearchHits(query *gorm.DB, hits []uint) *gorm.DB {
+	return query.Where("products.id IN ?", hits)
+}
+
+// relevanceSortKey orders products by their rank in the hits of a search.
+func relevanceSortKey(hits []uint) sortKey {
+	var sql strings.Builder
+	vars := make([]interface{}, 0, len(hits)*2)
+	ranks := make(map[uint]int, len(hits))
+	sql.WriteString("CASE products.id")
+	for rank, id := range hits {
+		sql.WriteString(" WHEN ? THEN ?")
+		vars = append(vars, id, rank)
+		ranks[id] = rank
+	}
+	sql.WriteString(" END")
+
+	return sortKey{
+		sql:  sql.String(),
+		vars: vars,
+		typ:  reflect.TypeOf(0),
+		valueOf: func(ctx context.Context, item reflect.Value) (interface{}, error) {
+			return ranks[uint(item.FieldByName("ID").Uint())], nil
+		},
+	}
+}
+
+// productKeyset creates the order of a product list: by relevance for searches sorted by relevance, or else by
+// a field of productListSchema, by update time descending by default. Lists with another default order pass
+// it as defaultKey.
+func productKeyset(db *gorm.DB, params *query_params.QueryParams, searchHits []uint, defaultKey *sortKey) (*keyset, error) {
+	sortBy := params.Sort
+	if isRelevanceSort(sortBy) {
+		if params.Search != "" && len(searchHits) > 0 {
+			return newKeyset(params.Sort, relevanceSortKey(searchHits), "products.id"), nil
+		}
+		sortBy = "" // Relevance without a search is the default order.
+	}
+	if sortBy == "" && defaultKey != nil {
+		return newKeyset(params.Sort, *defaultKey, "products.id"), nil
+	}
+
+	keys, err := productListSchema.keyset(db, sortBy, "updated_at", true)
+	if err != nil {
+		return nil, err
+	}
+	keys.sort = params.Sort // Cursors are bound to the sort parameter as given
+	return keys, nil
+}
+
+// isRelevanceSort reports whether the sort parameter asks for results by relevance ("relevance" or
+// "relevance:desc"). Relevance only applies to searches.
+func isRelevanceSort(sortBy string) bool {
+	return strings.EqualFold(strings.SplitN(sortBy, " ", 2)[0], "relevance")
+}
+
+// refreshProductSearchText rebuilds the search text of the given products from their current data.
+func refreshProductSearchText(tx *gorm.DB, productIDs []uint) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	var products []models.Product
+	if err := tx.Unscoped().Preload("Categories").Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+		return err
+	}
+	for i := range products {
+		if err := tx.Unscoped().Model(&models.Product{}).Where("id = ?", products[i].ID).
+			UpdateColumn("search_text", search.ProductDocument(&products[i]).Text()).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository defines the interface for role, permission and role assignment data access operations.
+type RoleRepository interface {
+	// Permissions
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	GetPermissionsByNames(ctx context.Context, names []string) ([]models.Permission, error)
+	// CreateMissingPermissions creates the permissions that do not exist yet.
+	CreateMissingPermissions(ctx context.Context, permissions []models.Permission) error
+
+	// Roles
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	GetRole(ctx context.Context, id uint) (*models.Role, error)
+	GetRoleByName(ctx context.Context, name string) (*models.Role, error)
+	CreateRole(ctx context.Context, role *models.Role) error
+	// UpdateRole updates a role. If permissions is not nil, the role's permissions are replaced.
+	UpdateRole(ctx context.Context, role *models.Role, updates map[string]interface{}, permissions []models.Permission) error
+	// DeleteRole deletes a role together with its permissions and assignments.
+	DeleteRole(ctx context.Context, id uint) error
+
+	// Role assignments
+	ListUserRoles(ctx context.Context, userID uint) ([]models.Role, error)
+	AssignRole(ctx context.Context, userRole *models.UserRole) error
+	RemoveRole(ctx context.Context, userID, roleID uint) error
+	CountRoleUsers(ctx context.Context, roleID uint) (int64, error)
+	// AssignRoleToUsersWithLegacyRole assigns a role to the users whose legacy users.role column has the given value.
+	AssignRoleToUsersWithLegacyRole(ctx context.Context, roleID uint, legacyRole string) (int64, error)
+	// GetUserPermissionNames returns the names of all permissions granted to the user by their roles.
+	GetUserPermissionNames(ctx context.Context, userID uint) ([]string, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// ListPermissions lists all permissions ordered by name.
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.WithContext(ctx).Order("name").Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// GetPermissionsByNames retrieves the permissions with the given names.
+func (r *roleRepository) GetPermissionsByNames(ctx context.Context, names []string) ([]models.Permission, error) {
+	permissions := []models.Permission{}
+	if len(names) == 0 {
+		return permissions, nil
+	}
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// CreateMissingPermissions creates the permissions that do not exist yet; descriptions of existing ones are updated.
+func (r *roleRepository) CreateMissingPermissions(ctx context.Context, permissions []models.Permission) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description"}),
+	}).Create(&permissions).Error
+}
+
+// ListRoles lists all roles with their permissions.
+func (r *roleRepository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetRole retrieves a role with its permissions by ID.
+func (r *roleRepository) GetRole(ctx context.Context, id uint) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", id).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetRoleByName retrieves a role with its permissions by name.
+func (r *roleRepository) GetRoleByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// CreateRole creates a role together with its permissions.
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	return r.db.WithContext(ctx).Create(role).Error
+}
+
+// UpdateRole updates a role and optionally replaces its permissions.
+func (r *roleRepository) UpdateRole(ctx context.Context, role *models.Role, updates map[string]interface{}, permissions []models.Permission) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(role).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if permissions != nil {
+			if err := tx.Model(role).Association("Permissions").Replace(permissions); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRole deletes a role together with its permissions and assignments.
+func (r *roleRepository) DeleteRole(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Role{ID: id}).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Role{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ListUserRoles lists the roles assigned to the user.
+func (r *roleRepository) ListUserRoles(ctx context.Context, userID uint) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).
+		Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AssignRole assigns a role to a user. Assigning a role the user already has is a no-op.
+func (r *roleRepository) AssignRole(ctx context.Context, userRole *models.UserRole) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error
+}
+
+// RemoveRole removes a role from a user.
+func (r *roleRepository) RemoveRole(ctx context.Context, userID, roleID uint) error {
+	result := r.db.WithContext(ctx).Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountRoleUsers counts the users that have the role.
+func (r *roleRepository) CountRoleUsers(ctx context.Context, roleID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.UserRole{}).Where("role_id = ?", roleID).Count(&count).Error
+	return count, err
+}
+
+// AssignRoleToUsersWithLegacyRole assigns a role to the users whose legacy users.role column has the given value.
+func (r *roleRepository) AssignRoleToUsersWithLegacyRole(ctx context.Context, roleID uint, legacyRole string) (int64, error) {
+	var userIDs []uint
+	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("role = ?", legacyRole).Pluck("id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	userRoles := make([]models.UserRole, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userRoles = append(userRoles, models.UserRole{UserID: userID, RoleID: roleID, CreatedAt: now})
+	}
+	result := r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoles)
+	return result.RowsAffected, result.Error
+}
+
+// GetUserPermissionNames returns the names of all permissions granted to the user by their roles.
+func (r *roleRepository) GetUserPermissionNames(ctx context.Context, userID uint) ([]string, error) {
+	var names []string
+	err := r.db.WithContext(ctx).
+		Model(&models.Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.name", &names).Error
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
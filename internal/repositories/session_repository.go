@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+)
+
+// SessionRepository defines the interface for session data access operations.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	ListActiveSessions(ctx context.Context, userID uint) ([]models.Session, error)
+	UpdateSession(ctx context.Context, id string, updates map[string]interface{}) error
+	// RevokeSessions revokes the user's active sessions and returns the IDs of the revoked sessions.
+	// If exceptID is not empty, that session is kept.
+	RevokeSessions(ctx context.Context, userID uint, exceptID string) ([]string, error)
+	// RevokeClientSessions revokes the active sessions of an OAuth client, only those of one user if userID is not 0,
+	// and returns the IDs of the revoked sessions.
+	RevokeClientSessions(ctx context.Context, oauthClientID, userID uint) ([]string, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// CreateSession creates a new session.
+func (r *sessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+// GetSession retrieves a session by ID.
+func (r *sessionRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveSessions retrieves the user's sessions that are neither revoked nor expired, most recently active first.
+func (r *sessionRepository) ListActiveSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// UpdateSession updates a session.
+func (r *sessionRepository) UpdateSession(ctx context.Context, id string, updates map[string]interface{}) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// RevokeSessions revokes the user's active sessions, optionally keeping one.
+func (r *sessionRepository) RevokeSessions(ctx context.Context, userID uint, exceptID string) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptID != "" {
+			query = query.Where("id <> ?", exceptID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Session{}).Where("id IN ?", ids).Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// RevokeClientSessions revokes the active sessions of an OAuth client, optionally only those of one user.
+func (r *sessionRepository) RevokeClientSessions(ctx context.Context, oauthClientID, userID uint) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Session{}).Where("oauth_client_id = ? AND revoked_at IS NULL", oauthClientID)
+		if userID != 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Session{}).Where("id IN ?", ids).Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
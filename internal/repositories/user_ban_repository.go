@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-backend-template/internal/models"
+	"gorm.io/gorm"
+)
+
+// UserBanRepository defines the interface for user ban data access operations.
+// The ban status on the user (is_banned, banned_until) is updated together with the ban records.
+type UserBanRepository interface {
+	// CreateBan bans a user, replacing the user's active ban if there is one.
+	CreateBan(ctx context.Context, ban *models.UserBan) error
+	// LiftBan lifts the user's active ban. liftedBy is the admin who lifted it.
+	LiftBan(ctx context.Context, userID uint, liftedBy uint, now time.Time) error
+	// LiftExpiredBan lifts the user's ban if it expired before now. It returns false if the user is no longer
+	// banned, or has been banned again in the meantime.
+	LiftExpiredBan(ctx context.Context, userID uint, now time.Time) (bool, error)
+	// GetActiveBan returns the user's active ban, or gorm.ErrRecordNotFound if the user was banned without
+	// a ban record (before ban records existed).
+	GetActiveBan(ctx context.Context, userID uint) (*models.UserBan, error)
+	// ListBans lists the user's bans, newest first.
+	ListBans(ctx context.Context, userID uint) ([]models.UserBan, error)
+}
+
+type userBanRepository struct {
+	db *gorm.DB
+}
+
+func NewUserBanRepository(db *gorm.DB) UserBanRepository {
+	return &userBanRepository{db: db}
+}
+
+// CreateBan records a ban and marks the user as banned until the ban expires.
+func (r *userBanRepository) CreateBan(ctx context.Context, ban *models.UserBan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBan{}).
+			Where("user_id = ? AND lifted_at IS NULL", ban.UserID).
+			Updates(map[string]interface{}{"lifted_at": ban.BannedAt, "lifted_by": ban.BannedBy, "lift_type": models.BanLiftReplaced}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(ban).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", ban.UserID).
+			Updates(map[string]interface{}{"is_banned": true, "banned_until": ban.ExpiresAt}).Error
+	})
+}
+
+// LiftBan lifts the user's active ban and clears the user's ban status.
+func (r *userBanRepository) LiftBan(ctx context.Context, userID uint, liftedBy uint, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserBan{}).
+			Where("user_id = ? AND lifted_at IS NULL", userID).
+			Updates(map[string]interface{}{"lifted_at": now, "lifted_by": liftedBy, "lift_type": models.BanLiftUnbanned}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"is_banned": false, "banned_until": nil}).Error
+	})
+}
+
+// LiftExpiredBan lifts an expired ban. The ban is recorded as lifted at its expiry time.
+func (r *userBanRepository) LiftExpiredBan(ctx context.Context, userID uint, now time.Time) (bool, error) {
+	lifted := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only lift the ban if it is still the expired one, so that a concurrent new ban is kept.
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND is_banned = ? AND banned_until IS NOT NULL AND banned_until <= ?", userID, true, now).
+			Updates(map[string]interface{}{"is_banned": false, "banned_until": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		lifted = true
+
+		return tx.Model(&models.UserBan{}).
+			Where("user_id = ? AND lifted_at IS NULL AND expires_at IS NOT NULL AND expires_at <= ?", userID, now).
+			Updates(map[string]interface{}{"lifted_at": gorm.Expr("expires_at"), "lift_type": models.BanLiftExpired}).Error
+	})
+	return lifted, err
+}
+
+// GetActiveBan returns the user's most recent ban that has not been lifted.
+func (r *userBanRepository) GetActiveBan(ctx context.Context, userID uint) (*models.UserBan, error) {
+	var ban models.UserBan
+	err := r.db.WithContext(ctx).Where("user_id = ? AND lifted_at IS NULL", userID).Order("banned_at DESC").First(&ban).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
+
+// ListBans lists the user's bans, newest first.
+func (r *userBanRepository) ListBans(ctx context.Context, userID uint) ([]models.UserBan, error) {
+	var bans []models.UserBan
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("banned_at DESC").Order("id DESC").Find(&bans).Error
+	return bans, err
+}
@@ -2,7 +2,8 @@ package repositories
 
 import (
 	"context" // Added for context
-	"strconv"
+	"reflect"
+	"time"
 
 	"github.com/go-backend-template/internal/models"
 	"github.com/go-backend-template/pkg/query_params"
@@ -22,7 +23,7 @@ type UserInteractionRepository interface {
 	IsFavorited(ctx context.Context, userID, productID uint) (bool, error)
 
 	// General list method for interacted products
-	ListUserInteractedProducts(ctx context.Context, userID uint, params *query_params.QueryParams, interactionType string) ([]models.Product, int, error)
+	ListUserInteractedProducts(ctx context.Context, userID uint, params *query_params.QueryParams, interactionType string, searchHits []uint) ([]models.Product, int, string, error)
 
 	// Statistics
 	GetProductLikeCount(ctx context.Context, productID uint) (int, error)
@@ -101,9 +102,9 @@ func (r *userInteractionRepository) IsFavorited(ctx context.Context, userID, pro
 	return count > 0, err
 }
 
-// ListUserInteractedProducts retrieves products liked or favorited by a user.
-// interactionType can be "like" or "favorite".
-func (r *userInteractionRepository) ListUserInteractedProducts(ctx context.Context, userID uint, params *query_params.QueryParams, interactionType string) ([]models.Product, int, error) {
+// ListUserInteractedProducts retrieves products liked or favorited by a user, and the cursor of the next page.
+// interactionType can be "like" or "favorite". If params.Search is set, the list is restricted to searchHits.
+func (r *userInteractionRepository) ListUserInteractedProducts(ctx context.Context, userID uint, params *query_params.QueryParams, interactionType string, searchHits []uint) ([]models.Product, int, string, error) {
 	var products []models.Product
 	var total int64
 	var tableName, orderField string
@@ -117,7 +118,7 @@ func (r *userInteractionRepository) ListUserInteractedProducts(ctx context.Conte
 		tableName = "user_product_favorites"
 		orderField = "user_product_favorites.created_at"
 	default:
-		return nil, 0, nil // Or return an error for invalid interactionType
+		return nil, 0, "", nil // Or return an error for invalid interactionType
 	}
 
 	// Create query.
@@ -125,81 +126,59 @@ func (r *userInteractionRepository) ListUserInteractedProducts(ctx context.Conte
 		Joins("JOIN "+tableName+" ON products.id = "+tableName+".product_id").
 		Where(tableName+".user_id = ? AND products.deleted_at IS NULL", userID)
 
-	// Handle search.
+	// Handle search, with the hits from the search index.
 	if params.Search != "" {
-		query = query.Where("products.name LIKE ? OR products.barcode LIKE ?",
-			"%"+params.Search+"%", "%"+params.Search+"%")
+		query = restrictToSearchHits(query, searchHits)
 	}
 
 	// Handle filters.
-	if params.Filter != nil {
-		for key, value := range params.Filter {
-			// Special handling for 'categories' filter.
-			if key == "categories" {
-				if categoryIDs, ok := value.([]interface{}); ok && len(categoryIDs) > 0 {
-					// Create a slice to store numeric IDs.
-					var categoryIDsUint []uint
-
-					// Convert interface{} to uint IDs.
-					for _, id := range categoryIDs {
-						switch v := id.(type) {
-						case float64:
-							// JSON numbers are parsed as float64 by default.
-							categoryIDsUint = append(categoryIDsUint, uint(v))
-						case int:
-							categoryIDsUint = append(categoryIDsUint, uint(v))
-						case uint:
-							categoryIDsUint = append(categoryIDsUint, v)
-						case string:
-							// If it's a string representation of a number, try to convert.
-							if numID, err := strconv.ParseUint(v, 10, 64); err == nil {
-								categoryIDsUint = append(categoryIDsUint, uint(numID))
-							}
-						}
-					}
-
-					// Expand category ID list to include all children of the specified categories.
-					expandedCategoryIDs, err := r.categoryRepo.ExpandCategoryIDsWithChildren(ctx, categoryIDsUint) // Pass context
-					if err != nil {
-						return nil, 0, err
-					}
-
-					// Filter products using the expanded category ID list.
-					if len(expandedCategoryIDs) > 0 {
-						// Using EXISTS subquery, which is more efficient than JOIN and IN for this case.
-						query = query.Where("EXISTS (SELECT 1 FROM product_categories pc WHERE pc.product_id = products.id AND pc.category_id IN ?)", expandedCategoryIDs)
-					}
-				}
-			} else {
-				// Handle regular filter conditions.
-				query = query.Where("products."+key+" = ?", value)
-			}
-		}
+	query, custom, err := productListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if query, err = filterByCategories(ctx, query, r.categoryRepo, custom); err != nil {
+		return nil, 0, "", err
 	}
 
-	// Handle sorting.
-	if params.Sort != "" {
-		query = query.Order("products." + params.Sort)
-	} else {
-		query = query.Order(orderField + " DESC") // Default sort by interaction time descending.
+	// Handle sorting. Default sort by interaction time descending.
+	keys, err := productKeyset(r.db, params, searchHits, &sortKey{
+		sql:  orderField,
+		desc: true,
+		typ:  reflect.TypeOf(time.Time{}),
+		valueOf: func(ctx context.Context, item reflect.Value) (interface{}, error) {
+			// The interaction time is not loaded with the product.
+			var createdAt time.Time
+			err := r.db.WithContext(ctx).Table(tableName).
+				Where("user_id = ? AND product_id = ?", userID, item.FieldByName("ID").Uint()).
+				Select("created_at").
+				Row().Scan(&createdAt)
+			return createdAt, err
+		},
+	})
+	if err != nil {
+		return nil, 0, "", err
 	}
 
 	// Get total count of records.
-	err := query.Count(&total).Error
+	err = query.Count(&total).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
-	// Apply pagination.
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+	// Apply sorting and pagination.
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
 	// Preload associated data.
 	query = query.Preload("Images").Preload("Categories")
 
-	err = query.Find(&products).Error
-
-	return products, int(total), err
+	if err := query.Find(&products).Error; err != nil {
+		return nil, 0, "", err
+	}
+	products, nextCursor, err := nextPage(ctx, keys, products, params.Limit)
+	return products, int(total), nextCursor, err
 }
 
 // GetProductLikeCount retrieves the like count for a product.
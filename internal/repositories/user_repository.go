@@ -10,7 +10,7 @@ import (
 // UserRepository defines the interface for user data access operations.
 type UserRepository interface {
 	// General CRUD queries
-	ListUsers(ctx context.Context, params *query_params.QueryParams, includeSoftDeleted ...bool) ([]models.User, int, error)
+	ListUsers(ctx context.Context, params *query_params.QueryParams, includeSoftDeleted ...bool) ([]models.User, int, string, error)
 	GetUser(ctx context.Context, id uint, includeSoftDeleted ...bool) (*models.User, error)
 	CreateUser(ctx context.Context, user *models.User) error
 	UpdateUser(ctx context.Context, id uint, updates map[string]interface{}, includeSoftDeleted ...bool) error
@@ -24,6 +24,32 @@ type UserRepository interface {
 	GetUserProvider(ctx context.Context, userID uint, provider string) (*models.UserProvider, error)
 }
 
+// userListSchema declares the fields user lists can be filtered and sorted by. Secrets such as the password
+// hash are left out, so that they cannot be probed with filters.
+var userListSchema = &listSchema{
+	model: &models.User{},
+	table: "users",
+	fields: map[string]listField{
+		"id":                    {column: "id", typ: intField, filter: true, sort: true},
+		"email":                 {column: "email", typ: stringField, filter: true, sort: true, nullable: true},
+		"is_email_verified":     {column: "is_email_verified", typ: boolField, filter: true, sort: true},
+		"phone":                 {column: "phone", typ: stringField, filter: true, sort: true, nullable: true},
+		"is_phone_verified":     {column: "is_phone_verified", typ: boolField, filter: true, sort: true},
+		"name":                  {column: "name", typ: stringField, filter: true, sort: true},
+		"gender":                {column: "gender", typ: stringField, filter: true, sort: true, operators: []query_params.Operator{query_params.OpEq, query_params.OpNe, query_params.OpIn}},
+		"birth_date":            {column: "birth_date", typ: timeField, filter: true, sort: true, nullable: true},
+		"locale":                {column: "locale", typ: stringField, filter: true, sort: true},
+		"role":                  {column: "role", typ: stringField, filter: true, sort: true},
+		"is_banned":             {column: "is_banned", typ: boolField, filter: true, sort: true},
+		"banned_until":          {column: "banned_until", typ: timeField, filter: true, sort: true, nullable: true},
+		"last_login":            {column: "last_login", typ: timeField, filter: true, sort: true, nullable: true},
+		"deletion_scheduled_at": {column: "deletion_scheduled_at", typ: timeField, filter: true, sort: true, nullable: true},
+		"created_at":            {column: "created_at", typ: timeField, filter: true, sort: true},
+		"updated_at":            {column: "updated_at", typ: timeField, filter: true, sort: true},
+		"deleted_at":            {column: "deleted_at", typ: timeField, filter: true, nullable: true}, // For lists including soft-deleted users
+	},
+}
+
 type userRepository struct {
 	db *gorm.DB
 }
@@ -37,7 +63,7 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 */
 
 // ListUsers retrieves a list of users based on query parameters.
-func (r *userRepository) ListUsers(ctx context.Context, params *query_params.QueryParams, includeSoftDeleted ...bool) ([]models.User, int, error) {
+func (r *userRepository) ListUsers(ctx context.Context, params *query_params.QueryParams, includeSoftDeleted ...bool) ([]models.User, int, string, error) {
 	var users []models.User
 	var totalCount int64
 
@@ -53,32 +79,37 @@ func (r *userRepository) ListUsers(ctx context.Context, params *query_params.Que
 		query = query.Where("nickname LIKE ?", "%"+params.Search+"%")
 	}
 
-	if params.Filter != nil {
-		for key, value := range params.Filter {
-			query = query.Where(key+" = ?", value)
-		}
+	query, _, err := userListSchema.applyFilter(query, params.Filter)
+	if err != nil {
+		return nil, 0, "", err
 	}
 
-	if params.Sort != "" {
-		query = query.Order(params.Sort)
+	keys, err := userListSchema.keyset(r.db, params.Sort, "id", false) // Default sort by ID ascending.
+	if err != nil {
+		return nil, 0, "", err
 	}
 
 	// Get total count of records.
-	err := query.Count(&totalCount).Error
+	err = query.Count(&totalCount).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 
-	// Apply pagination (note: no need to validate pagination params here as it's done in ParseQueryParams Middleware).
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+	// Apply sorting and pagination (note: no need to validate pagination params here as it's done in ParseQueryParams Middleware).
+	query, err = keys.paginate(query, params)
+	if err != nil {
+		return nil, 0, "", err
+	}
 
 	// Preload associated data.
 	query = query.Preload("UserProviders") // Preload user's associated third-party login providers.
 
 	// Execute query.
-	err = query.Find(&users).Error
-	return users, int(totalCount), err
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, "", err
+	}
+	users, nextCursor, err := nextPage(ctx, keys, users, params.Limit)
+	return users, int(totalCount), nextCursor, err
 }
 
 // GetUser retrieves a single user by ID, optionally including soft-deleted records.
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-backend-template/internal/di"
 	"github.com/go-backend-template/internal/middlewares"
+	"github.com/go-backend-template/internal/services"
 )
 
 func InitRoutes(r *gin.Engine, container *di.Container) {
@@ -16,6 +17,26 @@ func InitRoutes(r *gin.Engine, container *di.Container) {
 	r.Use(middlewares.ErrorHandler())
 	r.Use(middlewares.QueryParamParser())
 
+	// Public keys for verifying issued JWTs (used by downstream services).
+	r.GET("/.well-known/jwks.json", container.JWKSHandler.GetJWKS)
+
+	// OAuth2 authorization server endpoints called by third-party apps (RFC 6749, RFC 7009)
+	// and by internal services (RFC 7662).
+	oauthRoutes := r.Group("/oauth")
+	{
+		oauthRoutes.POST("/token", container.OAuthServerHandler.Token)           // Redeem an authorization code or a refresh token
+		oauthRoutes.POST("/revoke", container.OAuthServerHandler.Revoke)         // Revoke an access or refresh token
+		oauthRoutes.POST("/introspect", container.OAuthServerHandler.Introspect) // Check an access token (internal services)
+	}
+
+	// OpenID Connect style userinfo endpoint, also available to API keys and OAuth tokens with the profile scope.
+	userInfoAuth := []gin.HandlerFunc{
+		middlewares.AllowAPIKey(services.APIKeyScopeProfile),
+		middlewares.RequiredAuthenticate(container.JWTKeys, container.UserService, container.SessionService, container.APIKeyService, container.BanService),
+	}
+	r.GET("/userinfo", append(userInfoAuth, container.OAuthServerHandler.UserInfo)...)
+	r.POST("/userinfo", append(userInfoAuth, container.OAuthServerHandler.UserInfo)...)
+
 	// Initialize public API route group.
 	api := r.Group("/api/v1")
 	initRoutes(api, container)
@@ -28,28 +49,92 @@ func InitRoutes(r *gin.Engine, container *di.Container) {
 // Public API routes
 func initRoutes(api *gin.RouterGroup, container *di.Container) {
 	// Middlewares
-	requiredAuthMiddleware := middlewares.RequiredAuthenticate(container.Config, container.UserService) // Must be logged in
-	optionalAuthMiddleware := middlewares.OptionalAuthenticate(container.Config, container.UserService) // Optional login
+	requiredAuthMiddleware := middlewares.RequiredAuthenticate(container.JWTKeys, container.UserService, container.SessionService, container.APIKeyService, container.BanService) // Must be logged in
+	optionalAuthMiddleware := middlewares.OptionalAuthenticate(container.JWTKeys, container.UserService, container.SessionService, container.APIKeyService, container.BanService) // Optional login
+
+	// API keys are only accepted on routes that allow their scope
+	profileAPIKeyScope := middlewares.AllowAPIKey(services.APIKeyScopeProfile)
+
+	// Credential and account security changes are not allowed with an admin impersonation token
+	denyImpersonation := middlewares.DenyImpersonation()
 
 	rateLimiter := middlewares.NewRateLimiter(container.Redis) // Rate limiter
 	emailVerificationRateLimit := rateLimiter.EmailVerificationRateLimit()
 	passwordResetRateLimit := rateLimiter.PasswordResetRateLimit()
+	phoneCodeRateLimit := rateLimiter.PhoneCodeRateLimit()
+	magicLinkRateLimit := rateLimiter.MagicLinkRateLimit()
+	emailChangeRateLimit := rateLimiter.EmailChangeRateLimit()
+	phoneChangeRateLimit := rateLimiter.PhoneChangeRateLimit()
+	mfaManagementRateLimit := rateLimiter.MFAManagementRateLimit()
 
 	// Auth related routes
 	authRoutes := api.Group("/auth")
 	{
-		authRoutes.GET("/profile", requiredAuthMiddleware, container.AuthHandler.GetProfile)        // Get user profile
-		authRoutes.PATCH("/profile", requiredAuthMiddleware, container.AuthHandler.UpdateProfile)   // Update user profile
-		authRoutes.PATCH("/password", requiredAuthMiddleware, container.AuthHandler.UpdatePassword) // Update password
+		authRoutes.GET("/profile", profileAPIKeyScope, requiredAuthMiddleware, container.AuthHandler.GetProfile)       // Get user profile
+		authRoutes.PATCH("/profile", requiredAuthMiddleware, denyImpersonation, container.AuthHandler.UpdateProfile)   // Update user profile (read-only for API keys and OAuth clients)
+		authRoutes.PATCH("/password", requiredAuthMiddleware, denyImpersonation, container.AuthHandler.UpdatePassword) // Update password
+
+		// Account deletion and data export
+		authRoutes.DELETE("/account", requiredAuthMiddleware, denyImpersonation, container.AccountHandler.DeleteAccount)         // Schedule account deletion (cancelled by logging in again)
+		authRoutes.GET("/account/export", requiredAuthMiddleware, denyImpersonation, container.AccountHandler.ExportAccountData) // Download an archive of the user's data
 
 		authRoutes.POST("/register", container.AuthHandler.RegisterWithPassword) // Register with password
 		authRoutes.POST("/login", container.AuthHandler.LoginWithPassword)       // Login with password
 		authRoutes.POST("/refresh", container.AuthHandler.RefreshToken)          // Refresh access token
 
+		// Session (device) management
+		authRoutes.POST("/logout", requiredAuthMiddleware, container.SessionHandler.Logout)                                   // Log out the current session
+		authRoutes.GET("/sessions", requiredAuthMiddleware, container.SessionHandler.ListSessions)                            // List active sessions
+		authRoutes.DELETE("/sessions", requiredAuthMiddleware, denyImpersonation, container.SessionHandler.RevokeAllSessions) // Log out everywhere (?keep_current=true keeps this session)
+		authRoutes.DELETE("/sessions/:id", requiredAuthMiddleware, denyImpersonation, container.SessionHandler.RevokeSession) // Log out a specific session
+		authRoutes.GET("/login-history", requiredAuthMiddleware, container.LoginHistoryHandler.ListLoginHistory)              // List login attempts (?filter={"success":false})
+
+		// API keys (personal access tokens) for scripts and integrations
+		authRoutes.GET("/api-keys", requiredAuthMiddleware, container.APIKeyHandler.ListAPIKeys)                            // List API keys
+		authRoutes.POST("/api-keys", requiredAuthMiddleware, denyImpersonation, container.APIKeyHandler.CreateAPIKey)       // Create an API key (shown only once)
+		authRoutes.DELETE("/api-keys/:id", requiredAuthMiddleware, denyImpersonation, container.APIKeyHandler.RevokeAPIKey) // Revoke an API key
+
+		// Third-party apps authorized through the OAuth2 authorization server
+		authRoutes.GET("/oauth-apps", requiredAuthMiddleware, container.OAuthServerHandler.ListAuthorizedApps)                                   // List authorized apps
+		authRoutes.DELETE("/oauth-apps/:client_id", requiredAuthMiddleware, denyImpersonation, container.OAuthServerHandler.RevokeAuthorizedApp) // Revoke an app's access
+
+		// Two-factor authentication (TOTP)
+		authRoutes.POST("/mfa/login", container.AuthHandler.CompleteMFALogin)                                                                                   // Complete login with a TOTP or recovery code
+		authRoutes.GET("/mfa", requiredAuthMiddleware, container.MFAHandler.GetStatus)                                                                          // Get two-factor authentication status
+		authRoutes.POST("/mfa/totp/enroll", requiredAuthMiddleware, denyImpersonation, container.MFAHandler.EnrollTOTP)                                         // Start TOTP enrollment
+		authRoutes.POST("/mfa/totp/verify", requiredAuthMiddleware, denyImpersonation, container.MFAHandler.ConfirmTOTP)                                        // Confirm TOTP enrollment
+		authRoutes.DELETE("/mfa/totp", requiredAuthMiddleware, denyImpersonation, mfaManagementRateLimit, container.MFAHandler.DisableTOTP)                     // Disable TOTP
+		authRoutes.POST("/mfa/recovery-codes", requiredAuthMiddleware, denyImpersonation, mfaManagementRateLimit, container.MFAHandler.RegenerateRecoveryCodes) // Regenerate recovery codes
+
+		// Passkeys (WebAuthn)
+		authRoutes.POST("/passkeys/register/begin", requiredAuthMiddleware, denyImpersonation, container.PasskeyHandler.BeginRegistration)   // Get passkey creation options
+		authRoutes.POST("/passkeys/register/finish", requiredAuthMiddleware, denyImpersonation, container.PasskeyHandler.FinishRegistration) // Register a passkey
+		authRoutes.POST("/passkeys/login/begin", container.PasskeyHandler.BeginLogin)                                                        // Get passkey request options
+		authRoutes.POST("/passkeys/login/finish", container.PasskeyHandler.FinishLogin)                                                      // Login with a passkey
+		authRoutes.GET("/passkeys", requiredAuthMiddleware, container.PasskeyHandler.ListPasskeys)                                           // List passkeys
+		authRoutes.DELETE("/passkeys/:id", requiredAuthMiddleware, denyImpersonation, container.PasskeyHandler.DeletePasskey)                // Delete a passkey
+
 		// Email verification related (with rate limiting)
 		authRoutes.POST("/email/send-verification", emailVerificationRateLimit, container.AuthHandler.SendEmailVerification) // Send email verification code
 		authRoutes.POST("/email/verify", container.AuthHandler.VerifyEmail)                                                  // Verify email
 
+		// Email change related (with rate limiting)
+		authRoutes.POST("/email/change", requiredAuthMiddleware, denyImpersonation, emailChangeRateLimit, container.AuthHandler.RequestEmailChange) // Send a code to the new address and a notice to the current one
+		authRoutes.POST("/email/change/confirm", requiredAuthMiddleware, denyImpersonation, container.AuthHandler.ConfirmEmailChange)               // Confirm the new address with the code
+
+		// Magic link (email login) related (with rate limiting)
+		authRoutes.POST("/email/magic-link", magicLinkRateLimit, container.AuthHandler.SendMagicLink) // Send a one-time login link
+		authRoutes.POST("/email/magic-link/login", container.AuthHandler.LoginWithMagicLink)          // Login with the token from the link
+
+		// Phone verification and login related (with rate limiting)
+		authRoutes.POST("/phone/send-code", phoneCodeRateLimit, container.AuthHandler.SendPhoneCode) // Send SMS verification/login code
+		authRoutes.POST("/phone/verify", container.AuthHandler.VerifyPhone)                          // Verify phone number
+		authRoutes.POST("/phone/login", container.AuthHandler.LoginWithPhoneCode)                    // Login with SMS code
+
+		// Phone change related
+		authRoutes.POST("/phone/change", requiredAuthMiddleware, denyImpersonation, phoneChangeRateLimit, container.AuthHandler.RequestPhoneChange) // Send a code to the new number
+		authRoutes.POST("/phone/change/confirm", requiredAuthMiddleware, denyImpersonation, container.AuthHandler.ConfirmPhoneChange)               // Confirm the new number with the code
+
 		// Password reset related (with rate limiting)
 		authRoutes.POST("/password/reset-request", passwordResetRateLimit, container.AuthHandler.SendPasswordReset) // Send password reset email
 		authRoutes.POST("/password/reset", container.AuthHandler.ResetPassword)                                     // Reset password
@@ -58,18 +143,15 @@ func initRoutes(api *gin.RouterGroup, container *di.Container) {
 		authRoutes.POST("/wxmini/register", container.AuthHandler.RegisterFromWechatMiniProgram) // WeChat Mini Program registration
 		authRoutes.POST("/wxmini/login", container.AuthHandler.LoginFromWechatMiniProgram)       // WeChat Mini Program login
 
-		// OAuth2
-		authRoutes.POST("/wechat/token", container.AuthHandler.ExchangeWechatOAuth)                            // WeChat login (Authorization Code Flow)
-		authRoutes.POST("/wechat/bind", requiredAuthMiddleware, container.AuthHandler.BindWechatAccount)       // Bind WeChat account
-		authRoutes.DELETE("/wechat/unbind", requiredAuthMiddleware, container.AuthHandler.UnbindWechatAccount) // Unbind WeChat account
-
-		authRoutes.POST("/google/token", container.AuthHandler.ExchangeGoogleOAuth)                            // Google login (Authorization Code Flow with PKCE)
-		authRoutes.POST("/google/bind", requiredAuthMiddleware, container.AuthHandler.BindGoogleAccount)       // Bind Google account
-		authRoutes.DELETE("/google/unbind", requiredAuthMiddleware, container.AuthHandler.UnbindGoogleAccount) // Unbind Google account
+		// OAuth2 / OpenID Connect providers configured under oauth.providers (e.g. google, apple, wechat)
+		authRoutes.POST("/:provider/token", container.AuthHandler.ExchangeOAuth)                                                    // Login with a provider (authorization code or ID token)
+		authRoutes.POST("/:provider/bind", requiredAuthMiddleware, denyImpersonation, container.AuthHandler.BindOAuthAccount)       // Bind a provider account
+		authRoutes.DELETE("/:provider/unbind", requiredAuthMiddleware, denyImpersonation, container.AuthHandler.UnbindOAuthAccount) // Unbind a provider account
 	}
 
 	// Product related routes
 	productRoutes := api.Group("/products")
+	productRoutes.Use(middlewares.AllowAPIKey(services.APIKeyScopeProducts)) // API keys with the products scope
 	{
 		// List products - supports ?is_liked=true and ?is_favorited=true to filter liked/favorited products
 		productRoutes.GET("", optionalAuthMiddleware, container.ProductHandler.ListProducts)
@@ -81,6 +163,13 @@ func initRoutes(api *gin.RouterGroup, container *di.Container) {
 		productRoutes.PUT("/:id/favorite", requiredAuthMiddleware, container.UserInteractionHandler.ToggleFavorite) // Favorite/unfavorite product
 	}
 
+	// OAuth2 consent screen API, called by the frontend's authorization page
+	oauthRoutes := api.Group("/oauth")
+	{
+		oauthRoutes.GET("/authorize", requiredAuthMiddleware, denyImpersonation, container.OAuthServerHandler.GetAuthorization) // Validate an authorization request and get the consent screen
+		oauthRoutes.POST("/authorize", requiredAuthMiddleware, denyImpersonation, container.OAuthServerHandler.Authorize)       // Approve or deny, returns the redirect URI
+	}
+
 	// Category related routes
 	categoryRoutes := api.Group("/categories")
 	{
@@ -90,29 +179,71 @@ func initRoutes(api *gin.RouterGroup, container *di.Container) {
 }
 
 // Admin API routes
+// Every route requires a permission granted by the admin's roles (see services.RoleService).
 func initAdminRoutes(admin *gin.RouterGroup, container *di.Container) {
 	// Middlewares
-	admin.Use(middlewares.AdminAuthMiddleware(container.Config, container.UserService)) // All admin routes require admin privileges
+	admin.Use(
+		middlewares.AllowAPIKey(services.APIKeyScopeAdmin), // API keys with the admin scope
+		middlewares.AdminAuthMiddleware(container.Config, container.JWTKeys, container.UserService, container.SessionService, container.APIKeyService, container.RoleService, container.BanService), // All admin routes require an admin role
+	)
+	requirePermission := middlewares.RequirePermission // Per-route permission check
 
 	// User management routes
 	userRoutes := admin.Group("/users")
 	{
-		userRoutes.GET("", container.UserHandlerForAdmin.ListUsers)
-		userRoutes.GET("/:id", container.UserHandlerForAdmin.GetUser)
-		userRoutes.POST("", container.UserHandlerForAdmin.CreateUser)
-		userRoutes.PATCH("/:id", container.UserHandlerForAdmin.UpdateUser)
-		userRoutes.DELETE("/:id", container.UserHandlerForAdmin.DeleteUser)
-		userRoutes.PATCH("/:id/restore", container.UserHandlerForAdmin.RestoreUser) // Restore soft-deleted user
-		userRoutes.PATCH("/:id/ban", container.UserHandlerForAdmin.BanUser)
+		userRoutes.GET("", requirePermission(services.PermissionUsersRead), container.UserHandlerForAdmin.ListUsers)
+		userRoutes.GET("/:id", requirePermission(services.PermissionUsersRead), container.UserHandlerForAdmin.GetUser)
+		userRoutes.POST("", requirePermission(services.PermissionUsersCreate), container.UserHandlerForAdmin.CreateUser)
+		userRoutes.PATCH("/:id", requirePermission(services.PermissionUsersUpdate), container.UserHandlerForAdmin.UpdateUser)
+		userRoutes.DELETE("/:id", requirePermission(services.PermissionUsersDelete), container.UserHandlerForAdmin.DeleteUser)
+		userRoutes.PATCH("/:id/restore", requirePermission(services.PermissionUsersDelete), container.UserHandlerForAdmin.RestoreUser) // Restore soft-deleted user
+		userRoutes.PATCH("/:id/ban", requirePermission(services.PermissionUsersBan), container.UserHandlerForAdmin.BanUser)
+		userRoutes.GET("/:id/bans", requirePermission(services.PermissionUsersRead), container.UserHandlerForAdmin.ListUserBans)                         // A user's ban history
+		userRoutes.GET("/:id/api-keys", requirePermission(services.PermissionUsersRead), container.APIKeyHandlerForAdmin.ListUserAPIKeys)                // List a user's API keys
+		userRoutes.GET("/:id/login-history", requirePermission(services.PermissionSecurityRead), container.SecurityHandlerForAdmin.ListUserLoginHistory) // A user's login attempts
+		userRoutes.POST("/:id/impersonate", requirePermission(services.PermissionUsersImpersonate), container.ImpersonationHandlerForAdmin.Impersonate)  // Get a short-lived access token acting as the user
+
+		// Role assignments
+		userRoutes.GET("/:id/roles", requirePermission(services.PermissionRolesRead), container.RoleHandlerForAdmin.ListUserRoles)
+		userRoutes.POST("/:id/roles", requirePermission(services.PermissionRolesManage), container.RoleHandlerForAdmin.AssignRole)
+		userRoutes.DELETE("/:id/roles/:role_id", requirePermission(services.PermissionRolesManage), container.RoleHandlerForAdmin.RemoveRole)
 	}
 
 	// Product management routes
 	productRoutes := admin.Group("/products")
 	{
-		productRoutes.GET("", container.ProductHandlerForAdmin.ListProducts)
-		productRoutes.GET("/:id", container.ProductHandlerForAdmin.GetProduct)
-		productRoutes.POST("", container.ProductHandlerForAdmin.CreateProduct)
-		productRoutes.PATCH("/:id", container.ProductHandlerForAdmin.UpdateProduct)
-		productRoutes.DELETE("/:id", container.ProductHandlerForAdmin.DeleteProduct)
+		productRoutes.GET("", requirePermission(services.PermissionProductsRead), container.ProductHandlerForAdmin.ListProducts)
+		productRoutes.GET("/:id", requirePermission(services.PermissionProductsRead), container.ProductHandlerForAdmin.GetProduct)
+		productRoutes.POST("", requirePermission(services.PermissionProductsCreate), container.ProductHandlerForAdmin.CreateProduct)
+		productRoutes.PATCH("/:id", requirePermission(services.PermissionProductsUpdate), container.ProductHandlerForAdmin.UpdateProduct)
+		productRoutes.DELETE("/:id", requirePermission(services.PermissionProductsDelete), container.ProductHandlerForAdmin.DeleteProduct)
+	}
+
+	// Security routes
+	securityRoutes := admin.Group("/security")
+	{
+		securityRoutes.GET("/lockouts", requirePermission(services.PermissionSecurityRead), container.SecurityHandlerForAdmin.ListLockoutEvents)                  // Login lockout events
+		securityRoutes.GET("/impersonations", requirePermission(services.PermissionSecurityRead), container.ImpersonationHandlerForAdmin.ListImpersonationEvents) // Impersonation audit trail
+		securityRoutes.DELETE("/api-keys/:id", requirePermission(services.PermissionAPIKeysRevoke), container.APIKeyHandlerForAdmin.RevokeAPIKey)                 // Revoke any API key
+	}
+
+	// Role management routes
+	roleRoutes := admin.Group("/roles")
+	{
+		roleRoutes.GET("", requirePermission(services.PermissionRolesRead), container.RoleHandlerForAdmin.ListRoles)
+		roleRoutes.POST("", requirePermission(services.PermissionRolesManage), container.RoleHandlerForAdmin.CreateRole)
+		roleRoutes.PATCH("/:id", requirePermission(services.PermissionRolesManage), container.RoleHandlerForAdmin.UpdateRole)
+		roleRoutes.DELETE("/:id", requirePermission(services.PermissionRolesManage), container.RoleHandlerForAdmin.DeleteRole)
+	}
+	admin.GET("/permissions", requirePermission(services.PermissionRolesRead), container.RoleHandlerForAdmin.ListPermissions) // Permissions that can be granted to roles
+
+	// OAuth client (third-party app) registration routes
+	oauthClientRoutes := admin.Group("/oauth-clients")
+	{
+		oauthClientRoutes.GET("", requirePermission(services.PermissionOAuthClientsRead), container.OAuthClientHandlerForAdmin.ListOAuthClients)
+		oauthClientRoutes.POST("", requirePermission(services.PermissionOAuthClientsManage), container.OAuthClientHandlerForAdmin.CreateOAuthClient)
+		oauthClientRoutes.PATCH("/:id", requirePermission(services.PermissionOAuthClientsManage), container.OAuthClientHandlerForAdmin.UpdateOAuthClient)
+		oauthClientRoutes.POST("/:id/secret", requirePermission(services.PermissionOAuthClientsManage), container.OAuthClientHandlerForAdmin.RotateOAuthClientSecret) // Rotate the client secret (shown only once)
+		oauthClientRoutes.DELETE("/:id", requirePermission(services.PermissionOAuthClientsManage), container.OAuthClientHandlerForAdmin.DeleteOAuthClient)            // Also revokes all of the client's grants
 	}
 }
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// meilisearchIndex is a SearchIndex stored in an external Meilisearch server, for deployments with several
+// server instances or large catalogs. Writes are applied by Meilisearch asynchronously, usually within
+// milliseconds.
+type meilisearchIndex struct {
+	baseURL    string
+	apiKey     string
+	index      string
+	httpClient *http.Client
+}
+
+// NewMeilisearchIndex creates a SearchIndex stored in the given Meilisearch index.
+func NewMeilisearchIndex(baseURL, apiKey, index string) SearchIndex {
+	return &meilisearchIndex{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		index:      index,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Index adds or replaces documents.
+func (m *meilisearchIndex) Index(ctx context.Context, docs ...Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/documents?primaryKey=id", docs, nil)
+}
+
+// Delete removes documents.
+func (m *meilisearchIndex) Delete(ctx context.Context, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/documents/delete-batch", ids, nil)
+}
+
+// Clear removes all documents, and sets the searchable attributes so that title matches rank higher.
+func (m *meilisearchIndex) Clear(ctx context.Context) error {
+	if err := m.do(ctx, http.MethodDelete, "/documents", nil, nil); err != nil {
+		return err
+	}
+	return m.do(ctx, http.MethodPut, "/settings/searchable-attributes", []string{"title", "body"}, nil)
+}
+
+// Query searches the index. Meilisearch matches the last word of the query by prefix and tolerates typos.
+func (m *meilisearchIndex) Query(ctx context.Context, query Query) ([]uint, error) {
+	request := map[string]interface{}{
+		"q":                    query.Text,
+		"attributesToRetrieve": []string{"id"},
+		"matchingStrategy":     "all",
+	}
+	if query.Limit > 0 {
+		request["limit"] = query.Limit
+	}
+
+	var result struct {
+		Hits []struct {
+			ID uint `json:"id"`
+		} `json:"hits"`
+	}
+	if err := m.do(ctx, http.MethodPost, "/search", request, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids, nil
+}
+
+// do sends a request to the Meilisearch index and decodes the response into result, if not nil.
+func (m *meilisearchIndex) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+"/indexes/"+url.PathEscape(m.index)+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("meilisearch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("meilisearch returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
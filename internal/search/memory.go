@@ -0,0 +1,404 @@
+package search
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// MemoryIndex is the embedded search index. It is kept in memory and saved to a snapshot file, so that the
+// server does not have to rebuild it from the database on every start.
+//
+// Each process has its own copy of the index: it suits deployments with a single server instance. The
+// reindex command replaces the snapshot file, and running servers pick it up on their next Sync.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	path     string                          // Snapshot file, empty to keep the index in memory only
+	docs     map[uint]Document               // Indexed documents by ID
+	postings map[string]map[uint]*termCounts // Term -> documents containing it
+	terms    []string                        // Sorted terms for prefix lookups, nil when outdated
+	pending  map[uint]*Document              // Changes since the snapshot was saved, nil for deletions
+	snapshot snapshotInfo                    // The snapshot file as last loaded or saved
+}
+
+// termCounts counts the occurrences of a term in a document.
+type termCounts struct {
+	title int
+	body  int
+}
+
+// snapshotInfo identifies a version of the snapshot file.
+type snapshotInfo struct {
+	modTime time.Time
+	size    int64
+}
+
+// titleWeight is how much more an occurrence in the title counts than one in the body.
+const titleWeight = 3
+
+// prefixWeight is how much a word counts when it only matches the beginning of a term.
+const prefixWeight = 0.5
+
+// NewMemoryIndex creates an embedded index saved to the given snapshot file, and loads the snapshot if it
+// exists. An empty path keeps the index in memory only.
+func NewMemoryIndex(path string) (*MemoryIndex, error) {
+	idx := &MemoryIndex{path: path}
+	idx.reset()
+	if path == "" {
+		return idx, nil
+	}
+	if err := idx.load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Len returns the number of indexed documents.
+func (idx *MemoryIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Index adds documents to the index.
+func (idx *MemoryIndex) Index(ctx context.Context, docs ...Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, doc := range docs {
+		idx.put(doc)
+		idx.pending[doc.ID] = &doc
+	}
+	return nil
+}
+
+// Delete removes documents from the index.
+func (idx *MemoryIndex) Delete(ctx context.Context, ids ...uint) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, id := range ids {
+		idx.remove(id)
+		idx.pending[id] = nil
+	}
+	return nil
+}
+
+// Clear removes all documents.
+func (idx *MemoryIndex) Clear(ctx context.Context) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for id := range idx.docs {
+		idx.pending[id] = nil
+	}
+	pending := idx.pending
+	idx.reset()
+	idx.pending = pending
+	return nil
+}
+
+// Query returns the documents containing all words of the query, as whole terms or term prefixes, ranked by
+// a TF-IDF score in which title matches count more. Ties are broken by the newest (highest) ID.
+func (idx *MemoryIndex) Query(ctx context.Context, query Query) ([]uint, error) {
+	words := tokenize(query.Text)
+	if len(words) == 0 {
+		return []uint{}, nil
+	}
+
+	// Sort the terms for prefix lookups if the index has new terms.
+	for {
+		idx.mu.RLock()
+		if idx.terms != nil {
+			break
+		}
+		idx.mu.RUnlock()
+
+		idx.mu.Lock()
+		if idx.terms == nil {
+			terms := make([]string, 0, len(idx.postings))
+			for term := range idx.postings {
+				terms = append(terms, term)
+			}
+			sort.Strings(terms)
+			idx.terms = terms
+		}
+		idx.mu.Unlock()
+	}
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.docs))
+	var scores map[uint]float64
+	for _, word := range words {
+		// Score the documents matching the word, by the best matching term of each document.
+		wordScores := make(map[uint]float64)
+		for i := sort.SearchStrings(idx.terms, word); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], word); i++ {
+			term := idx.terms[i]
+			postings := idx.postings[term]
+			idf := math.Log(1 + total/float64(len(postings)))
+			weight := 1.0
+			if term != word {
+				weight = prefixWeight
+			}
+			for id, counts := range postings {
+				tf := float64(counts.title*titleWeight + counts.body)
+				score := weight * idf * tf / (tf + 1.2)
+				if score > wordScores[id] {
+					wordScores[id] = score
+				}
+			}
+		}
+
+		// Keep the documents matching all words.
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for id := range scores {
+			if score, ok := wordScores[id]; ok {
+				scores[id] += score
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	ids := make([]uint, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] > ids[j]
+	})
+	if query.Limit > 0 && len(ids) > query.Limit {
+		ids = ids[:query.Limit]
+	}
+	return ids, nil
+}
+
+// Sync saves the index to the snapshot file if it has changed. If another process (the reindex command) has
+// replaced the snapshot in the meantime, the snapshot is loaded instead, keeping the changes made since.
+func (idx *MemoryIndex) Sync() error {
+	if idx.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(idx.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil && (info.ModTime() != idx.currentSnapshot().modTime || info.Size() != idx.currentSnapshot().size) {
+		if err := idx.load(); err != nil {
+			return err
+		}
+	}
+	return idx.save()
+}
+
+// currentSnapshot returns the snapshot file as last loaded or saved.
+func (idx *MemoryIndex) currentSnapshot() snapshotInfo {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.snapshot
+}
+
+// load replaces the index with the snapshot file, then applies the changes not saved yet.
+func (idx *MemoryIndex) load() error {
+	file, err := os.Open(idx.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	var docs []Document
+	if err := gob.NewDecoder(file).Decode(&docs); err != nil {
+		return fmt.Errorf("failed to read search index snapshot %s: %w", idx.path, err)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	pending := idx.pending
+	idx.reset()
+	for _, doc := range docs {
+		idx.put(doc)
+	}
+	for id, doc := range pending {
+		if doc == nil {
+			idx.remove(id)
+		} else {
+			idx.put(*doc)
+		}
+	}
+	idx.pending = pending
+	idx.snapshot = snapshotInfo{modTime: info.ModTime(), size: info.Size()}
+	return nil
+}
+
+// save writes the index to the snapshot file if there are changes. The file is replaced atomically.
+func (idx *MemoryIndex) save() error {
+	idx.mu.Lock()
+	if len(idx.pending) == 0 {
+		idx.mu.Unlock()
+		return nil
+	}
+	saved := idx.pending
+	idx.pending = make(map[uint]*Document)
+	docs := make([]Document, 0, len(idx.docs))
+	for _, doc := range idx.docs {
+		docs = append(docs, doc)
+	}
+	idx.mu.Unlock()
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+
+	info, err := writeSnapshot(idx.path, docs)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err != nil {
+		// Keep the changes for the next save, unless they have been superseded in the meantime.
+		for id, doc := range saved {
+			if _, ok := idx.pending[id]; !ok {
+				idx.pending[id] = doc
+			}
+		}
+		return err
+	}
+	idx.snapshot = snapshotInfo{modTime: info.ModTime(), size: info.Size()}
+	return nil
+}
+
+// writeSnapshot writes documents to a snapshot file through a temporary file, so that readers never see a
+// partially written snapshot.
+func writeSnapshot(path string, docs []Document) (fs.FileInfo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(docs); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write search index snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+// reset empties the index. The caller must hold the write lock.
+func (idx *MemoryIndex) reset() {
+	idx.docs = make(map[uint]Document)
+	idx.postings = make(map[string]map[uint]*termCounts)
+	idx.terms = nil
+	idx.pending = make(map[uint]*Document)
+}
+
+// put adds or replaces a document. The caller must hold the write lock.
+func (idx *MemoryIndex) put(doc Document) {
+	idx.remove(doc.ID)
+	idx.docs[doc.ID] = doc
+
+	counts := make(map[string]*termCounts)
+	for _, term := range tokenize(doc.Title) {
+		if counts[term] == nil {
+			counts[term] = &termCounts{}
+		}
+		counts[term].title++
+	}
+	for _, term := range tokenize(doc.Body) {
+		if counts[term] == nil {
+			counts[term] = &termCounts{}
+		}
+		counts[term].body++
+	}
+	for term, c := range counts {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[uint]*termCounts)
+			idx.terms = nil
+		}
+		idx.postings[term][doc.ID] = c
+	}
+}
+
+// remove removes a document. The caller must hold the write lock.
+func (idx *MemoryIndex) remove(id uint) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	delete(idx.docs, id)
+	for _, term := range tokenize(doc.Text()) {
+		postings := idx.postings[term]
+		if postings == nil {
+			continue
+		}
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(idx.postings, term)
+			idx.terms = nil
+		}
+	}
+}
+
+// tokenize splits text into lowercase terms. Words are runs of letters and digits; Chinese, Japanese and
+// Korean text, which has no spaces between words, is split into overlapping pairs of characters.
+func tokenize(text string) []string {
+	var terms []string
+	var word, cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch len(cjk) {
+		case 0:
+		case 1:
+			terms = append(terms, string(cjk))
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-backend-template/internal/models"
+)
+
+// ProductDocument converts a product, with its categories loaded, to the document indexed for it: the name
+// as the title, and the barcode, the string values of the description and the category names as the body.
+func ProductDocument(product *models.Product) Document {
+	parts := []string{product.Barcode}
+	parts = appendJSONText(parts, map[string]interface{}(product.Description))
+	for _, category := range product.Categories {
+		parts = append(parts, category.Name, category.NameZH)
+	}
+
+	var body strings.Builder
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if body.Len() > 0 {
+			body.WriteByte('\n')
+		}
+		body.WriteString(part)
+	}
+
+	return Document{
+		ID:    product.ID,
+		Title: strings.TrimSpace(product.Name),
+		Body:  body.String(),
+	}
+}
+
+// appendJSONText appends the string values of a decoded JSON value, in a stable order.
+func appendJSONText(parts []string, value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return append(parts, v)
+	case []interface{}:
+		for _, item := range v {
+			parts = appendJSONText(parts, item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			parts = appendJSONText(parts, v[key])
+		}
+	}
+	return parts
+}
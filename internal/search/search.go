@@ -0,0 +1,50 @@
+// Package search defines the search index that product searches go through, with an embedded in-process
+// implementation and an optional external engine.
+//
+// The database stays the source of truth: the index only maps a search to the IDs of matching products,
+// best first, and the product lists load, filter and paginate the products from the database.
+package search
+
+import (
+	"context"
+)
+
+// Search engines
+const (
+	EngineMemory      = "memory"      // Embedded in-process index, saved to a snapshot file (default)
+	EngineDatabase    = "database"    // Full-text index of the database (PostgreSQL tsvector, MySQL FULLTEXT)
+	EngineMeilisearch = "meilisearch" // External Meilisearch server
+)
+
+// Document is an indexed item.
+type Document struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"` // Ranked higher than the body, e.g. the product name
+	Body  string `json:"body"`  // The remaining searchable text
+}
+
+// Text returns the whole searchable text of the document.
+func (d Document) Text() string {
+	if d.Body == "" {
+		return d.Title
+	}
+	return d.Title + "\n" + d.Body
+}
+
+// Query is a search.
+type Query struct {
+	Text  string
+	Limit int // Maximum number of hits
+}
+
+// SearchIndex is a full-text index of documents.
+type SearchIndex interface {
+	// Index adds documents to the index, replacing those with the same IDs.
+	Index(ctx context.Context, docs ...Document) error
+	// Delete removes documents from the index. Unknown IDs are ignored.
+	Delete(ctx context.Context, ids ...uint) error
+	// Query returns the IDs of the documents matching all words of the query, most relevant first.
+	Query(ctx context.Context, query Query) ([]uint, error)
+	// Clear removes all documents, before the index is rebuilt.
+	Clear(ctx context.Context) error
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// accountPurgeBatchSize is the number of accounts loaded per batch when purging.
+const accountPurgeBatchSize = 100
+
+// AccountService defines the interface for self-service account management: deleting one's own account
+// and exporting one's data.
+//
+// Deleting an account only schedules the deletion: the user is logged out everywhere and the account is
+// kept for a grace period, during which logging in again cancels the deletion. Once the grace period has
+// ended, PurgeDueAccounts permanently deletes the account and its data.
+type AccountService interface {
+	// ScheduleDeletion schedules the deletion of the user's account and logs the user out of all sessions.
+	ScheduleDeletion(ctx context.Context, userID uint, req *dto.DeleteAccountRequest) (time.Time, error)
+	// ExportData returns a zip archive of the user's profile, bound providers, likes and favorites.
+	ExportData(ctx context.Context, userID uint) ([]byte, error)
+	// PurgeDueAccounts permanently deletes the accounts whose grace period has ended and returns their number.
+	PurgeDueAccounts(ctx context.Context) (int, error)
+}
+
+// accountService is the implementation of AccountService.
+type accountService struct {
+	userRepo        repositories.UserRepository
+	accountRepo     repositories.AccountRepository
+	sessionService  SessionService
+	passwordService PasswordService
+	gracePeriod     time.Duration
+}
+
+// NewAccountService creates a new instance of AccountService.
+func NewAccountService(config *config.Config, userRepo repositories.UserRepository, accountRepo repositories.AccountRepository, sessionService SessionService, passwordService PasswordService) AccountService {
+	s := &accountService{
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		sessionService:  sessionService,
+		passwordService: passwordService,
+		gracePeriod:     30 * 24 * time.Hour,
+	}
+	if config.AccountDeletion.GracePeriodDays > 0 {
+		s.gracePeriod = time.Duration(config.AccountDeletion.GracePeriodDays) * 24 * time.Hour
+	}
+	return s
+}
+
+// ScheduleDeletion schedules the deletion of the user's account.
+func (s *accountService) ScheduleDeletion(ctx context.Context, userID uint, req *dto.DeleteAccountRequest) (time.Time, error) {
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return time.Time{}, errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user for account deletion", "userId", userID, "error", err) // Use slog.ErrorContext
+		return time.Time{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Accounts with a password must confirm the deletion with it.
+	if user.Password != nil && *user.Password != "" {
+		if match, _ := s.passwordService.VerifyPassword(ctx, *user.Password, req.Password); !match { // Pass context
+			logger.Warn(ctx, "Password verification failed for account deletion", "userId", user.ID) // Use slog.WarnContext
+			return time.Time{}, errors.ErrInvalidPassword
+		}
+	}
+
+	scheduledAt := time.Now().Add(s.gracePeriod)
+	if err := s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"deletion_scheduled_at": scheduledAt}); err != nil { // Pass context
+		logger.Error(ctx, "Failed to schedule account deletion", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return time.Time{}, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	// Log out everywhere, so that only a new login (which cancels the deletion) gives access to the account.
+	if err := s.sessionService.RevokeAllSessions(ctx, user.ID, ""); err != nil { // Pass context
+		return time.Time{}, err
+	}
+
+	logger.Info(ctx, "Account deletion scheduled", "userId", user.ID, "deletionScheduledAt", scheduledAt) // Use slog.InfoContext
+	return scheduledAt, nil
+}
+
+// ExportData returns a zip archive of the user's data, with one JSON file per kind of data.
+func (s *accountService) ExportData(ctx context.Context, userID uint) ([]byte, error) {
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user for data export", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	likes, err := s.accountRepo.ListLikes(ctx, user.ID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list likes for data export", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list likes: %w", err)
+	}
+	favorites, err := s.accountRepo.ListFavorites(ctx, user.ID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list favorites for data export", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+
+	likeDTOs := make([]dto.AccountExportInteractionDTO, 0, len(likes))
+	for _, like := range likes {
+		likeDTOs = append(likeDTOs, dto.AccountExportInteractionDTO{ProductID: like.ProductID, CreatedAt: like.CreatedAt})
+	}
+	favoriteDTOs := make([]dto.AccountExportInteractionDTO, 0, len(favorites))
+	for _, favorite := range favorites {
+		favoriteDTOs = append(favoriteDTOs, dto.AccountExportInteractionDTO{ProductID: favorite.ProductID, CreatedAt: favorite.CreatedAt})
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", dto.ToUserProfileDTO(user)},
+		{"providers.json", dto.ToAccountExportProviderDTOs(user.UserProviders)},
+		{"likes.json", likeDTOs},
+		{"favorites.json", favoriteDTOs},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s in export archive: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, fmt.Errorf("failed to write %s to export archive: %w", file.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close export archive: %w", err)
+	}
+
+	logger.Info(ctx, "Account data exported", "userId", user.ID) // Use slog.InfoContext
+	return buf.Bytes(), nil
+}
+
+// PurgeDueAccounts permanently deletes the accounts whose grace period has ended.
+// An account that fails to be purged is logged and retried on the next run.
+func (s *accountService) PurgeDueAccounts(ctx context.Context) (int, error) {
+	now := time.Now()
+	purged := 0
+	failed := make(map[uint]bool)
+
+	for {
+		userIDs, err := s.accountRepo.ListUserIDsDueForDeletion(ctx, now, accountPurgeBatchSize+len(failed)) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to list accounts due for deletion", "error", err) // Use slog.ErrorContext
+			return purged, fmt.Errorf("failed to list accounts due for deletion: %w", err)
+		}
+
+		progressed := false
+		for _, userID := range userIDs {
+			if failed[userID] {
+				continue
+			}
+			progressed = true
+			if err := s.accountRepo.PurgeUser(ctx, userID, now); err != nil { // Pass context
+				if err == gorm.ErrRecordNotFound {
+					continue // The user logged in again and cancelled the deletion.
+				}
+				logger.Error(ctx, "Failed to purge account", "userId", userID, "error", err) // Use slog.ErrorContext
+				failed[userID] = true
+				continue
+			}
+			purged++
+			logger.Info(ctx, "Account purged", "userId", userID) // Use slog.InfoContext
+		}
+
+		if !progressed {
+			break
+		}
+	}
+
+	return purged, nil
+}
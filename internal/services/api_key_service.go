@@ -0,0 +1,272 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// API key scopes. Each scope grants access to one group of routes; routes without a scope reject API keys.
+const (
+	APIKeyScopeProfile  = "profile"  // Read access to the user's profile (GET /auth/profile, /userinfo)
+	APIKeyScopeProducts = "products" // Products and product interactions (/products)
+	APIKeyScopeAdmin    = "admin"    // The admin API, only for keys of users with admin permissions
+)
+
+// API key format: ak_<prefix>_<secret>. The prefix is stored in plain text to look the key up, and is long
+// enough that random prefixes do not collide on the unique index. Keys created before it was lengthened
+// have a shorter prefix and are still accepted.
+const (
+	apiKeyMarker            = "ak_"
+	apiKeyPrefixBytes       = 8  // 16 hex characters
+	legacyAPIKeyPrefixBytes = 4  // 8 hex characters
+	apiKeySecretBytes       = 24 // 48 hex characters
+	apiKeyTouchInterval     = time.Minute
+)
+
+// APIKeyService defines the interface for API keys (personal access tokens) used by scripts and integrations.
+// A key acts as its owner, limited to the route groups of its scopes.
+type APIKeyService interface {
+	// CreateAPIKey creates a key for the user and returns it together with the full key, which is not stored.
+	// mfa reports whether the current login passed two-factor authentication.
+	CreateAPIKey(ctx context.Context, user *models.User, mfa bool, req *dto.CreateAPIKeyRequest) (*models.APIKey, string, error)
+	// ListAPIKeys lists the user's keys that have not been revoked.
+	ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error)
+	// RevokeAPIKey revokes one of the user's keys.
+	RevokeAPIKey(ctx context.Context, userID, keyID uint) error
+	// RevokeAPIKeyForAdmin revokes any key (admin).
+	RevokeAPIKeyForAdmin(ctx context.Context, keyID uint) error
+	// Authenticate checks a key presented in a request and records its use.
+	Authenticate(ctx context.Context, key string, ipAddress string) (*models.APIKey, error)
+}
+
+// apiKeyService is the implementation of APIKeyService.
+type apiKeyService struct {
+	config        *config.Config
+	apiKeyRepo    repositories.APIKeyRepository
+	roleService   RoleService
+	maxPerUser    int
+	defaultExpiry int // Days
+	maxExpiry     int // Days
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService.
+func NewAPIKeyService(config *config.Config, apiKeyRepo repositories.APIKeyRepository, roleService RoleService) APIKeyService {
+	cfg := config.APIKeys
+	s := &apiKeyService{
+		config:        config,
+		apiKeyRepo:    apiKeyRepo,
+		roleService:   roleService,
+		maxPerUser:    20,
+		defaultExpiry: 90,
+		maxExpiry:     365,
+	}
+	if cfg.MaxPerUser > 0 {
+		s.maxPerUser = cfg.MaxPerUser
+	}
+	if cfg.MaxExpireDays > 0 {
+		s.maxExpiry = cfg.MaxExpireDays
+	}
+	if cfg.DefaultExpireDays > 0 {
+		s.defaultExpiry = cfg.DefaultExpireDays
+	}
+	if s.defaultExpiry > s.maxExpiry {
+		s.defaultExpiry = s.maxExpiry
+	}
+	return s
+}
+
+// CreateAPIKey creates a key for the user.
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, user *models.User, mfa bool, req *dto.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	// Admin keys can only be created by admins, from a login that satisfies the admin API's requirements.
+	// The key is limited to the permissions its owner has at the time of each request.
+	for _, scope := range req.Scopes {
+		if scope != APIKeyScopeAdmin {
+			continue
+		}
+		permissions, err := s.roleService.GetUserPermissions(ctx, user.ID) // Pass context
+		if err != nil {
+			return nil, "", err
+		}
+		if len(permissions) == 0 {
+			logger.Warn(ctx, "Permission denied for admin API key", "userId", user.ID) // Use slog.WarnContext
+			return nil, "", errors.ErrPermissionDenied
+		}
+		if s.config.MFA.RequireForAdmins && !mfa {
+			return nil, "", errors.ErrMFARequired
+		}
+	}
+
+	expireDays := req.ExpiresInDays
+	if expireDays == 0 {
+		expireDays = s.defaultExpiry
+	}
+	if expireDays > s.maxExpiry {
+		return nil, "", errors.ErrInvalidAPIKeyExpiry.WithData(map[string]interface{}{"max_expire_days": s.maxExpiry})
+	}
+
+	count, err := s.apiKeyRepo.CountActiveAPIKeys(ctx, user.ID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to count API keys", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, "", fmt.Errorf("failed to count API keys: %w", err)
+	}
+	if count >= int64(s.maxPerUser) {
+		return nil, "", errors.ErrAPIKeyLimitExceeded
+	}
+
+	prefix, key, err := generateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	apiKey := &models.APIKey{
+		UserID:    user.ID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(key),
+		Scopes:    strings.Join(req.Scopes, ","),
+		ExpiresAt: time.Now().AddDate(0, 0, expireDays),
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(ctx, apiKey); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create API key", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	logger.Info(ctx, "API key created", "userId", user.ID, "apiKeyId", apiKey.ID, "prefix", prefix, "scopes", apiKey.Scopes) // Use slog.InfoContext
+	return apiKey, key, nil
+}
+
+// ListAPIKeys lists the user's keys that have not been revoked.
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	apiKeys, err := s.apiKeyRepo.ListAPIKeys(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list API keys", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// RevokeAPIKey revokes one of the user's keys.
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, userID, keyID uint) error {
+	apiKey, err := s.getAPIKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	if apiKey.UserID != userID {
+		return errors.ErrAPIKeyNotFound
+	}
+	return s.revoke(ctx, apiKey)
+}
+
+// RevokeAPIKeyForAdmin revokes any key.
+func (s *apiKeyService) RevokeAPIKeyForAdmin(ctx context.Context, keyID uint) error {
+	apiKey, err := s.getAPIKey(ctx, keyID)
+	if err != nil {
+		return err
+	}
+	return s.revoke(ctx, apiKey)
+}
+
+// Authenticate checks a key presented in a request and records its use.
+func (s *apiKeyService) Authenticate(ctx context.Context, key string, ipAddress string) (*models.APIKey, error) {
+	prefix, ok := parseAPIKeyPrefix(key)
+	if !ok {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.GetAPIKeyByPrefix(ctx, prefix) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvalidAPIKey
+		}
+		logger.Error(ctx, "Failed to get API key", "prefix", prefix, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(hashAPIKey(key))) != 1 || !apiKey.IsActive() {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	// Record use, at most once per interval to avoid a write on every request.
+	if apiKey.LastUsedAt == nil || time.Since(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		now := time.Now()
+		updates := map[string]interface{}{"last_used_at": now}
+		if ipAddress != "" {
+			updates["last_used_ip"] = ipAddress
+		}
+		if err := s.apiKeyRepo.UpdateAPIKey(ctx, apiKey.ID, updates); err != nil { // Pass context
+			logger.Warn(ctx, "Failed to update API key last used time", "apiKeyId", apiKey.ID, "error", err) // Use slog.WarnContext
+		}
+	}
+
+	return apiKey, nil
+}
+
+// getAPIKey retrieves a key by ID.
+func (s *apiKeyService) getAPIKey(ctx context.Context, keyID uint) (*models.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetAPIKey(ctx, keyID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrAPIKeyNotFound
+		}
+		logger.Error(ctx, "Failed to get API key", "apiKeyId", keyID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return apiKey, nil
+}
+
+// revoke marks a key as revoked. Revoking a revoked key is a no-op.
+func (s *apiKeyService) revoke(ctx context.Context, apiKey *models.APIKey) error {
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+	if err := s.apiKeyRepo.UpdateAPIKey(ctx, apiKey.ID, map[string]interface{}{"revoked_at": time.Now()}); err != nil { // Pass context
+		logger.Error(ctx, "Failed to revoke API key", "apiKeyId", apiKey.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	logger.Info(ctx, "API key revoked", "userId", apiKey.UserID, "apiKeyId", apiKey.ID, "prefix", apiKey.Prefix) // Use slog.InfoContext
+	return nil
+}
+
+// generateAPIKey generates a new key and returns its prefix and the full key.
+func generateAPIKey() (string, string, error) {
+	buf := make([]byte, apiKeyPrefixBytes+apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	prefix := hex.EncodeToString(buf[:apiKeyPrefixBytes])
+	secret := hex.EncodeToString(buf[apiKeyPrefixBytes:])
+	return prefix, apiKeyMarker + prefix + "_" + secret, nil
+}
+
+// parseAPIKeyPrefix extracts the prefix from a key in the format ak_<prefix>_<secret>.
+func parseAPIKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(key, apiKeyMarker), "_")
+	if !strings.HasPrefix(key, apiKeyMarker) || len(parts) != 2 || parts[1] == "" {
+		return "", false
+	}
+	if len(parts[0]) != apiKeyPrefixBytes*2 && len(parts[0]) != legacyAPIKeyPrefixBytes*2 {
+		return "", false
+	}
+	return parts[0], true
+}
+
+// hashAPIKey hashes a key so that raw keys are never stored.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// BanService defines the interface for banning users.
+//
+// A ban carries a reason, a message for the user, the acting admin and an optional expiry. Bans are kept as
+// the user's ban history after they are lifted. Expired bans are lifted lazily, the next time the user logs
+// in, refreshes a token or makes an authenticated request (see CheckBan).
+type BanService interface {
+	// BanUser bans a user, replacing the user's current ban if there is one.
+	BanUser(ctx context.Context, admin *models.User, userID uint, req *dto.BanUserRequest) (*models.UserBan, error)
+	// UnbanUser lifts the user's ban. It does nothing if the user is not banned.
+	UnbanUser(ctx context.Context, admin *models.User, userID uint) error
+	// ListBans lists the user's ban history, newest first (admin).
+	ListBans(ctx context.Context, userID uint) ([]models.UserBan, error)
+	// CheckBan returns ErrUserBanned with the ban details if the user is banned. A ban that has expired is
+	// lifted instead, and the user is updated accordingly.
+	CheckBan(ctx context.Context, user *models.User) error
+}
+
+// banService is the implementation of BanService.
+type banService struct {
+	userRepo    repositories.UserRepository
+	userBanRepo repositories.UserBanRepository
+}
+
+// NewBanService creates a new instance of BanService.
+func NewBanService(userRepo repositories.UserRepository, userBanRepo repositories.UserBanRepository) BanService {
+	return &banService{
+		userRepo:    userRepo,
+		userBanRepo: userBanRepo,
+	}
+}
+
+// BanUser bans a user until the requested expiry, or permanently.
+func (s *banService) BanUser(ctx context.Context, admin *models.User, userID uint, req *dto.BanUserRequest) (*models.UserBan, error) {
+	if userID == admin.ID {
+		return nil, errors.ErrCannotBanSelf
+	}
+
+	now := time.Now()
+	expiresAt := req.ExpiresAt
+	if req.DurationHours > 0 {
+		expiry := now.Add(time.Duration(req.DurationHours) * time.Hour)
+		expiresAt = &expiry
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, errors.ErrInvalidBanExpiry
+	}
+
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	ban := &models.UserBan{
+		UserID:    userID,
+		Reason:    req.Reason,
+		Message:   req.Message,
+		BannedBy:  &admin.ID,
+		BannedAt:  now,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.userBanRepo.CreateBan(ctx, ban); err != nil { // Pass context
+		logger.Error(ctx, "Failed to ban user", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to ban user: %w", err)
+	}
+
+	logger.Info(ctx, "User banned", "userId", userID, "adminId", admin.ID, "reason", req.Reason, "expiresAt", expiresAt) // Use slog.InfoContext
+	return ban, nil
+}
+
+// UnbanUser lifts the user's ban.
+func (s *banService) UnbanUser(ctx context.Context, admin *models.User, userID uint) error {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.userBanRepo.LiftBan(ctx, userID, admin.ID, time.Now()); err != nil { // Pass context
+		logger.Error(ctx, "Failed to unban user", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to unban user: %w", err)
+	}
+
+	logger.Info(ctx, "User unbanned", "userId", userID, "adminId", admin.ID) // Use slog.InfoContext
+	return nil
+}
+
+// ListBans lists the user's ban history.
+func (s *banService) ListBans(ctx context.Context, userID uint) ([]models.UserBan, error) {
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	bans, err := s.userBanRepo.ListBans(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list bans", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+
+	// Return an empty array if there is no data.
+	if len(bans) == 0 {
+		bans = []models.UserBan{}
+	}
+	return bans, nil
+}
+
+// CheckBan returns ErrUserBanned with the details of the active ban, lifting the ban if it has expired.
+func (s *banService) CheckBan(ctx context.Context, user *models.User) error {
+	if !user.IsBanned {
+		return nil
+	}
+
+	// Lift an expired temporary ban.
+	now := time.Now()
+	if user.BannedUntil != nil && !now.Before(*user.BannedUntil) {
+		lifted, err := s.userBanRepo.LiftExpiredBan(ctx, user.ID, now) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to lift expired ban", "userId", user.ID, "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to lift expired ban: %w", err)
+		}
+		if lifted {
+			logger.Info(ctx, "Expired ban lifted", "userId", user.ID) // Use slog.InfoContext
+			user.IsBanned = false
+			user.BannedUntil = nil
+			return nil
+		}
+
+		// The ban was changed in the meantime, check the current one.
+		current, err := s.userRepo.GetUser(ctx, user.ID) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to reload banned user", "userId", user.ID, "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+		user.IsBanned = current.IsBanned
+		user.BannedUntil = current.BannedUntil
+		if !user.IsBanned {
+			return nil
+		}
+	}
+
+	// Return the details of the ban. Users banned before ban records existed have none.
+	ban, err := s.userBanRepo.GetActiveBan(ctx, user.ID) // Pass context
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to get active ban", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get active ban: %w", err)
+	}
+	return errors.ErrUserBanned.WithData(dto.ToBanDetailsDTO(user, ban))
+}
+
+// ensureUserExists returns ErrUserNotFound if the user does not exist.
+func (s *banService) ensureUserExists(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.GetUser(ctx, userID); err != nil { // Pass context
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return nil
+}
@@ -20,27 +20,32 @@ type CategoryService interface {
 
 	// Management methods - can be implemented as needed later
 	// CreateCategory(ctx context.Context, category *models.Category) (uint, error)
-	// UpdateCategory(ctx context.Context, id uint, updates map[string]interface{}) error
-	// DeleteCategory(ctx context.Context, id uint) error
+	UpdateCategory(ctx context.Context, id uint, updates map[string]interface{}) error
+	DeleteCategory(ctx context.Context, id uint) error
 }
 
 // categoryService is the implementation of CategoryService.
 type categoryService struct {
-	categoryRepo repositories.CategoryRepository
+	categoryRepo         repositories.CategoryRepository
+	productSearchService ProductSearchService
 }
 
 // NewCategoryService creates a new instance of CategoryService.
-func NewCategoryService(categoryRepo repositories.CategoryRepository) CategoryService {
+func NewCategoryService(categoryRepo repositories.CategoryRepository, productSearchService ProductSearchService) CategoryService {
 	return &categoryService{
-		categoryRepo: categoryRepo,
+		categoryRepo:         categoryRepo,
+		productSearchService: productSearchService,
 	}
 }
 
 // ListCategories retrieves a list of categories.
 func (s *categoryService) ListCategories(ctx context.Context, params *query_params.QueryParams) ([]models.Category, *response.Pagination, error) {
 	// Call the repository layer to get the list of categories.
-	categories, total, err := s.categoryRepo.ListCategories(ctx, params) // Pass context
+	categories, total, nextCursor, err := s.categoryRepo.ListCategories(ctx, params) // Pass context
 	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
 		logger.Error(ctx, "Failed to list categories", "error", err) // Use slog.ErrorContext
 		return nil, nil, fmt.Errorf("failed to list categories: %w", err)
 	}
@@ -56,6 +61,7 @@ func (s *categoryService) ListCategories(ctx context.Context, params *query_para
 		PageSize:    params.Limit,
 		CurrentPage: params.Page,
 		TotalPages:  (int(total) + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
 	}
 
 	return categories, pagination, nil
@@ -89,3 +95,30 @@ func (s *categoryService) GetCategory(ctx context.Context, id uint) (*models.Cat
 
 	return category, nil
 }
+
+// UpdateCategory updates a category. Category names are part of the search text of its products, so they are
+// reindexed when a name changes.
+func (s *categoryService) UpdateCategory(ctx context.Context, id uint, updates map[string]interface{}) error {
+	if err := s.categoryRepo.UpdateCategory(ctx, id, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update category", "categoryId", id, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to update category: %w", err)
+	}
+
+	_, nameChanged := updates["name"]
+	_, nameZHChanged := updates["name_zh"]
+	if nameChanged || nameZHChanged {
+		s.productSearchService.IndexCategoryProducts(ctx, id) // Pass context
+	}
+	return nil
+}
+
+// DeleteCategory deletes a category (soft delete) and reindexes its products without it.
+func (s *categoryService) DeleteCategory(ctx context.Context, id uint) error {
+	if err := s.categoryRepo.DeleteCategory(ctx, id); err != nil { // Pass context
+		logger.Error(ctx, "Failed to delete category", "categoryId", id, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	s.productSearchService.IndexCategoryProducts(ctx, id) // Pass context
+	return nil
+}
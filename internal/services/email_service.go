@@ -20,6 +20,14 @@ type EmailService interface {
 	SendEmailVerification(ctx context.Context, to, name, verificationCode, locale string) error
 	// SendPasswordReset sends a password reset email.
 	SendPasswordReset(ctx context.Context, to, name, resetToken, locale string) error
+	// SendMagicLink sends a one-time login link.
+	SendMagicLink(ctx context.Context, to, name, link, locale string) error
+	// SendEmailChangeVerification sends the code confirming an email change to the new address.
+	SendEmailChangeVerification(ctx context.Context, to, name, verificationCode, locale string) error
+	// SendEmailChangeNotice notifies the current address that a change to another address was requested.
+	SendEmailChangeNotice(ctx context.Context, to, name, newEmail, locale string) error
+	// SendNewDeviceLoginAlert notifies the user of a login from a device that has not been used before.
+	SendNewDeviceLoginAlert(ctx context.Context, to, name, device, ipAddress string, loginTime time.Time, locale string) error
 }
 
 // emailService is the implementation of the EmailService.
@@ -360,6 +368,676 @@ Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.
 	},
 }
 
+// Magic link (email login) templates
+var magicLinkTemplates = map[string]EmailTemplate{
+	"zh": {
+		Subject: "登录链接", // Login Link
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>登录链接</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #28a745; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .button { display: inline-block; background: #28a745; color: white; padding: 12px 30px; text-decoration: none; border-radius: 4px; font-weight: bold; }
+        .link { word-break: break-all; color: #666; font-size: 13px; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>登录链接</h2>
+            <p>尊敬的 {{ .Name }}，</p>
+            <p>请点击下面的按钮登录您的账号：</p>
+            <p style="text-align: center; margin: 30px 0;"><a class="button" href="{{ .Link }}">立即登录</a></p>
+            <p class="link">{{ .Link }}</p>
+            <p>链接有效期为 <strong>15 分钟</strong>，且只能使用一次。</p>
+            <p>如果您没有请求登录，请忽略此邮件，您的账号仍然安全。</p>
+        </div>
+        <div class="footer">
+            <p>这是一封自动发送的邮件，请勿回复。</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+登录链接 - {{ .AppName }}
+
+尊敬的 {{ .Name }}，
+
+请打开下面的链接登录您的账号：
+
+{{ .Link }}
+
+链接有效期为 15 分钟，且只能使用一次。
+
+如果您没有请求登录，请忽略此邮件，您的账号仍然安全。
+
+这是一封自动发送的邮件，请勿回复。
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"en": {
+		Subject: "Sign-in Link",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Sign-in Link</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #28a745; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .button { display: inline-block; background: #28a745; color: white; padding: 12px 30px; text-decoration: none; border-radius: 4px; font-weight: bold; }
+        .link { word-break: break-all; color: #666; font-size: 13px; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Sign-in Link</h2>
+            <p>Dear {{ .Name }},</p>
+            <p>Click the button below to sign in to your account:</p>
+            <p style="text-align: center; margin: 30px 0;"><a class="button" href="{{ .Link }}">Sign in</a></p>
+            <p class="link">{{ .Link }}</p>
+            <p>The link is valid for <strong>15 minutes</strong> and can only be used once.</p>
+            <p>If you did not request to sign in, please ignore this email. Your account is still secure.</p>
+        </div>
+        <div class="footer">
+            <p>This is an automated email, please do not reply.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Sign-in Link - {{ .AppName }}
+
+Dear {{ .Name }},
+
+Open the link below to sign in to your account:
+
+{{ .Link }}
+
+The link is valid for 15 minutes and can only be used once.
+
+If you did not request to sign in, please ignore this email. Your account is still secure.
+
+This is an automated email, please do not reply.
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"de": {
+		Subject: "Anmeldelink",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Anmeldelink</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #28a745; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .button { display: inline-block; background: #28a745; color: white; padding: 12px 30px; text-decoration: none; border-radius: 4px; font-weight: bold; }
+        .link { word-break: break-all; color: #666; font-size: 13px; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Anmeldelink</h2>
+            <p>Liebe/r {{ .Name }},</p>
+            <p>Klicken Sie auf die Schaltfläche unten, um sich bei Ihrem Konto anzumelden:</p>
+            <p style="text-align: center; margin: 30px 0;"><a class="button" href="{{ .Link }}">Jetzt anmelden</a></p>
+            <p class="link">{{ .Link }}</p>
+            <p>Der Link ist <strong>15 Minuten</strong> gültig und kann nur einmal verwendet werden.</p>
+            <p>Falls Sie keine Anmeldung angefordert haben, ignorieren Sie bitte diese E-Mail. Ihr Konto ist weiterhin sicher.</p>
+        </div>
+        <div class="footer">
+            <p>Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Anmeldelink - {{ .AppName }}
+
+Liebe/r {{ .Name }},
+
+Öffnen Sie den folgenden Link, um sich bei Ihrem Konto anzumelden:
+
+{{ .Link }}
+
+Der Link ist 15 Minuten gültig und kann nur einmal verwendet werden.
+
+Falls Sie keine Anmeldung angefordert haben, ignorieren Sie bitte diese E-Mail. Ihr Konto ist weiterhin sicher.
+
+Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.
+© {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.`,
+	},
+}
+
+// Email change verification templates, sent to the new address
+var emailChangeTemplates = map[string]EmailTemplate{
+	"zh": {
+		Subject: "确认新邮箱", // Confirm New Email
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>确认新邮箱</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #007bff; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .code { background: #e7f3ff; padding: 15px; text-align: center; font-size: 24px; font-weight: bold; letter-spacing: 3px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>确认新邮箱</h2>
+            <p>尊敬的 {{ .Name }}，</p>
+            <p>您申请将账号邮箱更换为此邮箱。请使用下面的验证码确认更换：</p>
+            <div class="code">{{ .Code }}</div>
+            <p>验证码有效期为 <strong>10 分钟</strong>，请尽快使用。</p>
+            <p>如果您没有申请更换邮箱，请忽略此邮件。</p>
+        </div>
+        <div class="footer">
+            <p>这是一封自动发送的邮件，请勿回复。</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+确认新邮箱 - {{ .AppName }}
+
+尊敬的 {{ .Name }}，
+
+您申请将账号邮箱更换为此邮箱。请使用下面的验证码确认更换：
+
+验证码：{{ .Code }}
+
+验证码有效期为 10 分钟，请尽快使用。
+
+如果您没有申请更换邮箱，请忽略此邮件。
+
+这是一封自动发送的邮件，请勿回复。
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"en": {
+		Subject: "Confirm Your New Email",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Confirm Your New Email</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #007bff; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .code { background: #e7f3ff; padding: 15px; text-align: center; font-size: 24px; font-weight: bold; letter-spacing: 3px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Confirm Your New Email</h2>
+            <p>Dear {{ .Name }},</p>
+            <p>You requested to change the email address of your account to this address. Please use the verification code below to confirm the change:</p>
+            <div class="code">{{ .Code }}</div>
+            <p>The verification code is valid for <strong>10 minutes</strong>. Please use it promptly.</p>
+            <p>If you did not request this change, please ignore this email.</p>
+        </div>
+        <div class="footer">
+            <p>This is an automated email, please do not reply.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Confirm Your New Email - {{ .AppName }}
+
+Dear {{ .Name }},
+
+You requested to change the email address of your account to this address. Please use the verification code below to confirm the change:
+
+Verification Code: {{ .Code }}
+
+The verification code is valid for 10 minutes. Please use it promptly.
+
+If you did not request this change, please ignore this email.
+
+This is an automated email, please do not reply.
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"de": {
+		Subject: "Neue E-Mail-Adresse bestätigen",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Neue E-Mail-Adresse bestätigen</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #007bff; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .code { background: #e7f3ff; padding: 15px; text-align: center; font-size: 24px; font-weight: bold; letter-spacing: 3px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Neue E-Mail-Adresse bestätigen</h2>
+            <p>Liebe/r {{ .Name }},</p>
+            <p>Sie haben beantragt, die E-Mail-Adresse Ihres Kontos auf diese Adresse zu ändern. Bitte verwenden Sie den folgenden Bestätigungscode, um die Änderung zu bestätigen:</p>
+            <div class="code">{{ .Code }}</div>
+            <p>Der Bestätigungscode ist <strong>10 Minuten</strong> gültig. Bitte verwenden Sie ihn umgehend.</p>
+            <p>Falls Sie diese Änderung nicht beantragt haben, ignorieren Sie bitte diese E-Mail.</p>
+        </div>
+        <div class="footer">
+            <p>Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Neue E-Mail-Adresse bestätigen - {{ .AppName }}
+
+Liebe/r {{ .Name }},
+
+Sie haben beantragt, die E-Mail-Adresse Ihres Kontos auf diese Adresse zu ändern. Bitte verwenden Sie den folgenden Bestätigungscode, um die Änderung zu bestätigen:
+
+Bestätigungscode: {{ .Code }}
+
+Der Bestätigungscode ist 10 Minuten gültig. Bitte verwenden Sie ihn umgehend.
+
+Falls Sie diese Änderung nicht beantragt haben, ignorieren Sie bitte diese E-Mail.
+
+Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.
+© {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.`,
+	},
+}
+
+// Email change notice templates, sent to the current address
+var emailChangeNoticeTemplates = map[string]EmailTemplate{
+	"zh": {
+		Subject: "邮箱更换申请", // Email Change Requested
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>邮箱更换申请</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #dc3545; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .email { background: #f8d7da; padding: 15px; text-align: center; font-size: 18px; font-weight: bold; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>邮箱更换申请</h2>
+            <p>尊敬的 {{ .Name }}，</p>
+            <p>您的账号申请将邮箱更换为：</p>
+            <div class="email">{{ .NewEmail }}</div>
+            <p>新邮箱确认后，此邮箱将不再用于登录和接收账号通知。</p>
+            <p>如果这不是您本人的操作，请立即修改密码并退出所有设备的登录。</p>
+        </div>
+        <div class="footer">
+            <p>这是一封自动发送的邮件，请勿回复。</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+邮箱更换申请 - {{ .AppName }}
+
+尊敬的 {{ .Name }}，
+
+您的账号申请将邮箱更换为：{{ .NewEmail }}
+
+新邮箱确认后，此邮箱将不再用于登录和接收账号通知。
+
+如果这不是您本人的操作，请立即修改密码并退出所有设备的登录。
+
+这是一封自动发送的邮件，请勿回复。
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"en": {
+		Subject: "Email Change Requested",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Email Change Requested</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #dc3545; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .email { background: #f8d7da; padding: 15px; text-align: center; font-size: 18px; font-weight: bold; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Email Change Requested</h2>
+            <p>Dear {{ .Name }},</p>
+            <p>A request was made to change the email address of your account to:</p>
+            <div class="email">{{ .NewEmail }}</div>
+            <p>Once the new address is confirmed, this address will no longer be used to sign in or to receive account notifications.</p>
+            <p>If this was not you, please change your password and sign out of all devices immediately.</p>
+        </div>
+        <div class="footer">
+            <p>This is an automated email, please do not reply.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Email Change Requested - {{ .AppName }}
+
+Dear {{ .Name }},
+
+A request was made to change the email address of your account to: {{ .NewEmail }}
+
+Once the new address is confirmed, this address will no longer be used to sign in or to receive account notifications.
+
+If this was not you, please change your password and sign out of all devices immediately.
+
+This is an automated email, please do not reply.
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"de": {
+		Subject: "Änderung der E-Mail-Adresse beantragt",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Änderung der E-Mail-Adresse beantragt</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #dc3545; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .email { background: #f8d7da; padding: 15px; text-align: center; font-size: 18px; font-weight: bold; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Änderung der E-Mail-Adresse beantragt</h2>
+            <p>Liebe/r {{ .Name }},</p>
+            <p>Es wurde beantragt, die E-Mail-Adresse Ihres Kontos zu ändern auf:</p>
+            <div class="email">{{ .NewEmail }}</div>
+            <p>Sobald die neue Adresse bestätigt ist, wird diese Adresse nicht mehr für die Anmeldung und für Kontobenachrichtigungen verwendet.</p>
+            <p>Falls Sie das nicht waren, ändern Sie bitte sofort Ihr Passwort und melden Sie sich auf allen Geräten ab.</p>
+        </div>
+        <div class="footer">
+            <p>Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Änderung der E-Mail-Adresse beantragt - {{ .AppName }}
+
+Liebe/r {{ .Name }},
+
+Es wurde beantragt, die E-Mail-Adresse Ihres Kontos zu ändern auf: {{ .NewEmail }}
+
+Sobald die neue Adresse bestätigt ist, wird diese Adresse nicht mehr für die Anmeldung und für Kontobenachrichtigungen verwendet.
+
+Falls Sie das nicht waren, ändern Sie bitte sofort Ihr Passwort und melden Sie sich auf allen Geräten ab.
+
+Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.
+© {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.`,
+	},
+}
+
+// New device login alert templates
+var newDeviceLoginTemplates = map[string]EmailTemplate{
+	"zh": {
+		Subject: "新设备登录提醒", // New Device Login
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>新设备登录提醒</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #dc3545; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .details { background: #f8d7da; padding: 15px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>新设备登录提醒</h2>
+            <p>尊敬的 {{ .Name }}，</p>
+            <p>您的账号刚刚在一台新设备上登录：</p>
+            <div class="details">
+                <p>时间：{{ .Time }}</p>
+                <p>设备：{{ .Device }}</p>
+                <p>IP地址：{{ .IPAddress }}</p>
+            </div>
+            <p>如果这是您本人的操作，请忽略此邮件。</p>
+            <p>如果这不是您本人的操作，请立即修改密码并退出所有设备的登录。</p>
+        </div>
+        <div class="footer">
+            <p>这是一封自动发送的邮件，请勿回复。</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+新设备登录提醒 - {{ .AppName }}
+
+尊敬的 {{ .Name }}，
+
+您的账号刚刚在一台新设备上登录：
+
+时间：{{ .Time }}
+设备：{{ .Device }}
+IP地址：{{ .IPAddress }}
+
+如果这是您本人的操作，请忽略此邮件。
+
+如果这不是您本人的操作，请立即修改密码并退出所有设备的登录。
+
+这是一封自动发送的邮件，请勿回复。
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"en": {
+		Subject: "New Sign-in to Your Account",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>New Sign-in to Your Account</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #dc3545; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .details { background: #f8d7da; padding: 15px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>New Sign-in to Your Account</h2>
+            <p>Dear {{ .Name }},</p>
+            <p>Your account was just signed in to from a device you have not used before:</p>
+            <div class="details">
+                <p>Time: {{ .Time }}</p>
+                <p>Device: {{ .Device }}</p>
+                <p>IP address: {{ .IPAddress }}</p>
+            </div>
+            <p>If this was you, you can ignore this email.</p>
+            <p>If this was not you, please change your password and sign out of all devices immediately.</p>
+        </div>
+        <div class="footer">
+            <p>This is an automated email, please do not reply.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. All rights reserved.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+New Sign-in to Your Account - {{ .AppName }}
+
+Dear {{ .Name }},
+
+Your account was just signed in to from a device you have not used before:
+
+Time: {{ .Time }}
+Device: {{ .Device }}
+IP address: {{ .IPAddress }}
+
+If this was you, you can ignore this email.
+
+If this was not you, please change your password and sign out of all devices immediately.
+
+This is an automated email, please do not reply.
+© {{ .Year }} {{ .AppName }}. All rights reserved.`,
+	},
+	"de": {
+		Subject: "Neue Anmeldung bei Ihrem Konto",
+		HTMLContent: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>Neue Anmeldung bei Ihrem Konto</title>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background: #dc3545; color: white; padding: 20px; text-align: center; }
+        .content { padding: 30px; background: #f9f9f9; }
+        .details { background: #f8d7da; padding: 15px; margin: 20px 0; }
+        .footer { padding: 20px; text-align: center; color: #666; font-size: 14px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{ .AppName }}</h1>
+        </div>
+        <div class="content">
+            <h2>Neue Anmeldung bei Ihrem Konto</h2>
+            <p>Liebe/r {{ .Name }},</p>
+            <p>Ihr Konto wurde soeben auf einem neuen Gerät angemeldet:</p>
+            <div class="details">
+                <p>Zeit: {{ .Time }}</p>
+                <p>Gerät: {{ .Device }}</p>
+                <p>IP-Adresse: {{ .IPAddress }}</p>
+            </div>
+            <p>Wenn Sie das waren, können Sie diese E-Mail ignorieren.</p>
+            <p>Falls Sie das nicht waren, ändern Sie bitte sofort Ihr Passwort und melden Sie sich auf allen Geräten ab.</p>
+        </div>
+        <div class="footer">
+            <p>Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.</p>
+            <p>&copy; {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+		TextContent: `
+Neue Anmeldung bei Ihrem Konto - {{ .AppName }}
+
+Liebe/r {{ .Name }},
+
+Ihr Konto wurde soeben auf einem neuen Gerät angemeldet:
+
+Zeit: {{ .Time }}
+Gerät: {{ .Device }}
+IP-Adresse: {{ .IPAddress }}
+
+Wenn Sie das waren, können Sie diese E-Mail ignorieren.
+
+Falls Sie das nicht waren, ändern Sie bitte sofort Ihr Passwort und melden Sie sich auf allen Geräten ab.
+
+Dies ist eine automatisch generierte E-Mail, bitte antworten Sie nicht darauf.
+© {{ .Year }} {{ .AppName }}. Alle Rechte vorbehalten.`,
+	},
+}
+
 // NewEmailService creates a new instance of the email service.
 func NewEmailService(config *config.Config) EmailService {
 	return &emailService{
@@ -368,7 +1046,7 @@ func NewEmailService(config *config.Config) EmailService {
 }
 
 // getSupportedLanguage gets the supported language from a locale, following IETF BCP 47 standard.
-func (s *emailService) getSupportedLanguage(locale string) string {
+func getSupportedLanguage(locale string) string {
 	// Map of supported languages
 	supportedLanguages := map[string]string{
 		// Chinese related locales
@@ -410,7 +1088,7 @@ func (s *emailService) getSupportedLanguage(locale string) string {
 // SendEmailVerification sends an email verification email.
 func (s *emailService) SendEmailVerification(ctx context.Context, to, name, verificationCode, locale string) error {
 	// Validate and get the supported language.
-	lang := s.getSupportedLanguage(locale)
+	lang := getSupportedLanguage(locale)
 
 	// Get the template for the corresponding language.
 	template := emailVerificationTemplates[lang]
@@ -445,7 +1123,7 @@ func (s *emailService) SendEmailVerification(ctx context.Context, to, name, veri
 // SendPasswordReset sends a password reset email.
 func (s *emailService) SendPasswordReset(ctx context.Context, to, name, resetToken, locale string) error {
 	// Validate and get the supported language.
-	lang := s.getSupportedLanguage(locale)
+	lang := getSupportedLanguage(locale)
 
 	// Get the template for the corresponding language.
 	template := passwordResetTemplates[lang]
@@ -477,6 +1155,150 @@ func (s *emailService) SendPasswordReset(ctx context.Context, to, name, resetTok
 	return s.sendEmail(ctx, to, subject, textContent, htmlContent) // Pass context
 }
 
+// SendMagicLink sends a one-time login link.
+func (s *emailService) SendMagicLink(ctx context.Context, to, name, link, locale string) error {
+	// Validate and get the supported language.
+	lang := getSupportedLanguage(locale)
+
+	// Get the template for the corresponding language.
+	template := magicLinkTemplates[lang]
+
+	subject := template.Subject + " - " + s.config.Email.FromName
+
+	data := struct {
+		Name    string
+		Link    string
+		AppName string
+		Year    int
+	}{
+		Name:    name,
+		Link:    link,
+		AppName: s.config.Email.FromName,
+		Year:    time.Now().Year(),
+	}
+
+	htmlContent, err := s.renderTemplate(template.HTMLContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render HTML email template: %w", err)
+	}
+
+	textContent, err := s.renderTemplate(template.TextContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render text email template: %w", err)
+	}
+
+	return s.sendEmail(ctx, to, subject, textContent, htmlContent) // Pass context
+}
+
+// SendEmailChangeVerification sends the code confirming an email change to the new address.
+func (s *emailService) SendEmailChangeVerification(ctx context.Context, to, name, verificationCode, locale string) error {
+	// Validate and get the supported language.
+	lang := getSupportedLanguage(locale)
+
+	// Get the template for the corresponding language.
+	template := emailChangeTemplates[lang]
+
+	subject := template.Subject + " - " + s.config.Email.FromName
+
+	data := struct {
+		Name    string
+		Code    string
+		AppName string
+		Year    int
+	}{
+		Name:    name,
+		Code:    verificationCode,
+		AppName: s.config.Email.FromName,
+		Year:    time.Now().Year(),
+	}
+
+	htmlContent, err := s.renderTemplate(template.HTMLContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render HTML email template: %w", err)
+	}
+
+	textContent, err := s.renderTemplate(template.TextContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render text email template: %w", err)
+	}
+
+	return s.sendEmail(ctx, to, subject, textContent, htmlContent) // Pass context
+}
+
+// SendEmailChangeNotice notifies the current address that a change to newEmail was requested.
+func (s *emailService) SendEmailChangeNotice(ctx context.Context, to, name, newEmail, locale string) error {
+	// Validate and get the supported language.
+	lang := getSupportedLanguage(locale)
+
+	// Get the template for the corresponding language.
+	template := emailChangeNoticeTemplates[lang]
+
+	subject := template.Subject + " - " + s.config.Email.FromName
+
+	data := struct {
+		Name     string
+		NewEmail string
+		AppName  string
+		Year     int
+	}{
+		Name:     name,
+		NewEmail: newEmail,
+		AppName:  s.config.Email.FromName,
+		Year:     time.Now().Year(),
+	}
+
+	htmlContent, err := s.renderTemplate(template.HTMLContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render HTML email template: %w", err)
+	}
+
+	textContent, err := s.renderTemplate(template.TextContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render text email template: %w", err)
+	}
+
+	return s.sendEmail(ctx, to, subject, textContent, htmlContent) // Pass context
+}
+
+// SendNewDeviceLoginAlert notifies the user of a login from a new device. The time is shown in UTC.
+func (s *emailService) SendNewDeviceLoginAlert(ctx context.Context, to, name, device, ipAddress string, loginTime time.Time, locale string) error {
+	// Validate and get the supported language.
+	lang := getSupportedLanguage(locale)
+
+	// Get the template for the corresponding language.
+	template := newDeviceLoginTemplates[lang]
+
+	subject := template.Subject + " - " + s.config.Email.FromName
+
+	data := struct {
+		Name      string
+		Device    string
+		IPAddress string
+		Time      string
+		AppName   string
+		Year      int
+	}{
+		Name:      name,
+		Device:    device,
+		IPAddress: ipAddress,
+		Time:      loginTime.UTC().Format("2006-01-02 15:04 UTC"),
+		AppName:   s.config.Email.FromName,
+		Year:      time.Now().Year(),
+	}
+
+	htmlContent, err := s.renderTemplate(template.HTMLContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render HTML email template: %w", err)
+	}
+
+	textContent, err := s.renderTemplate(template.TextContent, data)
+	if err != nil {
+		return fmt.Errorf("failed to render text email template: %w", err)
+	}
+
+	return s.sendEmail(ctx, to, subject, textContent, htmlContent) // Pass context
+}
+
 // sendEmail selects the email sending method based on configuration.
 func (s *emailService) sendEmail(ctx context.Context, to, subject, textContent, htmlContent string) error {
 	switch s.config.Email.Provider {
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/jwt"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/query_params"
+	"github.com/go-backend-template/pkg/response"
+	"gorm.io/gorm"
+)
+
+// ImpersonationService defines the interface for admins acting as a user, e.g. to reproduce what a user sees.
+//
+// Impersonation issues a short-lived access token for the user that carries the admin's ID in the
+// impersonator_id claim. The token has no login session and no refresh token, it cannot be used on the admin
+// API or for sensitive account operations, and every token is recorded in the audit trail.
+type ImpersonationService interface {
+	// Impersonate issues an impersonation token for the user and records an impersonation event.
+	Impersonate(ctx context.Context, impersonator *models.User, userID uint, req *dto.ImpersonateRequest, client *dto.ClientInfo) (*dto.ImpersonationTokenDTO, error)
+	// ListImpersonationEvents lists recorded impersonations (admin).
+	ListImpersonationEvents(ctx context.Context, params *query_params.QueryParams) ([]models.ImpersonationEvent, *response.Pagination, error)
+}
+
+// impersonationService is the implementation of ImpersonationService.
+type impersonationService struct {
+	jwtKeys                *jwt.KeySet
+	userRepo               repositories.UserRepository
+	impersonationEventRepo repositories.ImpersonationEventRepository
+	roleService            RoleService
+}
+
+// NewImpersonationService creates a new instance of ImpersonationService.
+func NewImpersonationService(jwtKeys *jwt.KeySet, userRepo repositories.UserRepository, impersonationEventRepo repositories.ImpersonationEventRepository, roleService RoleService) ImpersonationService {
+	return &impersonationService{
+		jwtKeys:                jwtKeys,
+		userRepo:               userRepo,
+		impersonationEventRepo: impersonationEventRepo,
+		roleService:            roleService,
+	}
+}
+
+// Impersonate issues an impersonation token for the user.
+func (s *impersonationService) Impersonate(ctx context.Context, impersonator *models.User, userID uint, req *dto.ImpersonateRequest, client *dto.ClientInfo) (*dto.ImpersonationTokenDTO, error) {
+	if userID == impersonator.ID {
+		return nil, errors.ErrCannotImpersonate
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user to impersonate", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Admins cannot be impersonated, otherwise impersonation could be used to borrow another admin's permissions.
+	permissions, err := s.roleService.GetUserPermissions(ctx, user.ID) // Pass context
+	if err != nil {
+		return nil, err
+	}
+	if len(permissions) > 0 {
+		logger.Warn(ctx, "Attempt to impersonate an admin", "impersonatorId", impersonator.ID, "userId", user.ID) // Use slog.WarnContext
+		return nil, errors.ErrCannotImpersonate
+	}
+
+	tokenID := jwt.NewTokenID()
+	accessToken, err := jwt.GenerateImpersonationToken(user.ID, user.Role, impersonator.ID, tokenID, s.jwtKeys)
+	if err != nil {
+		logger.Error(ctx, "Failed to generate impersonation token", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to generate impersonation token: %w", err)
+	}
+	expiresAt := time.Now().Add(jwt.ImpersonationTokenDuration)
+
+	// The token is only handed out once the audit record is stored.
+	event := &models.ImpersonationEvent{
+		ImpersonatorID: impersonator.ID,
+		UserID:         user.ID,
+		Reason:         req.Reason,
+		TokenID:        tokenID,
+		IPAddress:      client.IPAddress,
+		UserAgent:      truncate(client.UserAgent, 500),
+		ExpiresAt:      expiresAt,
+	}
+	if err := s.impersonationEventRepo.CreateImpersonationEvent(ctx, event); err != nil { // Pass context
+		logger.Error(ctx, "Failed to record impersonation event", "impersonatorId", impersonator.ID, "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to record impersonation event: %w", err)
+	}
+
+	logger.Info(ctx, "Admin started impersonating user", // Use slog.InfoContext
+		"impersonatorId", impersonator.ID,
+		"userId", user.ID,
+		"tokenId", tokenID,
+		"reason", req.Reason,
+	)
+
+	return &dto.ImpersonationTokenDTO{
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+		UserID:      user.ID,
+	}, nil
+}
+
+// ListImpersonationEvents lists recorded impersonations.
+func (s *impersonationService) ListImpersonationEvents(ctx context.Context, params *query_params.QueryParams) ([]models.ImpersonationEvent, *response.Pagination, error) {
+	events, total, nextCursor, err := s.impersonationEventRepo.ListImpersonationEvents(ctx, params) // Pass context
+	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
+		logger.Error(ctx, "Failed to list impersonation events", "error", err) // Use slog.ErrorContext
+		return nil, nil, fmt.Errorf("failed to list impersonation events: %w", err)
+	}
+
+	// Return an empty array if there is no data.
+	if len(events) == 0 {
+		events = []models.ImpersonationEvent{}
+	}
+
+	// Construct pagination information.
+	pagination := &response.Pagination{
+		TotalCount:  total,
+		PageSize:    params.Limit,
+		CurrentPage: params.Page,
+		TotalPages:  (total + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
+	}
+
+	return events, pagination, nil
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	stderrors "errors"
+
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/pkg/query_params"
+)
+
+// listParamsError converts the error of a list query caused by invalid list parameters (filter, sort or cursor) to
+// the matching API error. It returns nil for other errors.
+func listParamsError(err error) error {
+	switch {
+	case stderrors.Is(err, query_params.ErrInvalidCursor):
+		return errors.ErrInvalidCursor
+	case stderrors.Is(err, query_params.ErrInvalidSort):
+		return errors.ErrInvalidSort
+	case stderrors.Is(err, query_params.ErrInvalidFilter):
+		return errors.ErrInvalidFilter
+	}
+	return nil
+}
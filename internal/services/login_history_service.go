@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/query_params"
+	"github.com/go-backend-template/pkg/response"
+)
+
+// LoginHistoryService defines the interface for the login history of users.
+//
+// Every login attempt is recorded with its method, IP address and user agent. When a user logs in successfully
+// from a device that has not been used before, an alert is sent to the user's email address. Users who have no
+// login history yet (e.g. right after registering) are not alerted.
+type LoginHistoryService interface {
+	// RecordLoginSuccess records a successful login that created the given session, alerting the user if the
+	// device is new. mfa records whether the login was completed with a second factor.
+	RecordLoginSuccess(ctx context.Context, user *models.User, method string, mfa bool, sessionID string, client *dto.ClientInfo)
+	// RecordLoginFailure records a failed login attempt. userID is nil if the account does not exist; identifier
+	// is the email address or phone number that was tried, if any. The reason is the error returned to the client.
+	RecordLoginFailure(ctx context.Context, userID *uint, identifier, method string, reason error, client *dto.ClientInfo)
+	// ListLoginEvents lists a user's login history, newest first unless sorted otherwise.
+	ListLoginEvents(ctx context.Context, userID uint, params *query_params.QueryParams) ([]models.LoginEvent, *response.Pagination, error)
+}
+
+// loginHistoryService is the implementation of LoginHistoryService.
+type loginHistoryService struct {
+	loginEventRepo repositories.LoginEventRepository
+	emailService   EmailService
+}
+
+// NewLoginHistoryService creates a new instance of LoginHistoryService.
+func NewLoginHistoryService(loginEventRepo repositories.LoginEventRepository, emailService EmailService) LoginHistoryService {
+	return &loginHistoryService{
+		loginEventRepo: loginEventRepo,
+		emailService:   emailService,
+	}
+}
+
+// RecordLoginSuccess records a successful login. Failures are only logged, so that they never block a login.
+func (s *loginHistoryService) RecordLoginSuccess(ctx context.Context, user *models.User, method string, mfa bool, sessionID string, client *dto.ClientInfo) {
+	event := newLoginEvent(&user.ID, method, client)
+	event.Success = true
+	event.MFA = mfa
+	event.SessionID = sessionID
+
+	// A device is new if the user has logged in before, but never from this device.
+	seen, err := s.loginEventRepo.HasSuccessfulLogin(ctx, user.ID, event.DeviceFingerprint) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to check known devices", "userId", user.ID, "error", err) // Use slog.ErrorContext
+	} else if !seen {
+		hasHistory, err := s.loginEventRepo.HasSuccessfulLogin(ctx, user.ID, "") // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to check login history", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		}
+		event.NewDevice = err == nil && hasHistory
+	}
+
+	if err := s.loginEventRepo.CreateLoginEvent(ctx, event); err != nil { // Pass context
+		logger.Error(ctx, "Failed to record login event", "userId", user.ID, "method", method, "error", err) // Use slog.ErrorContext
+	}
+
+	if event.NewDevice {
+		logger.Info(ctx, "Login from a new device", "userId", user.ID, "method", method, "ip", event.IPAddress) // Use slog.InfoContext
+		s.sendNewDeviceAlert(ctx, user, event)
+	}
+}
+
+// RecordLoginFailure records a failed login attempt.
+func (s *loginHistoryService) RecordLoginFailure(ctx context.Context, userID *uint, identifier, method string, reason error, client *dto.ClientInfo) {
+	event := newLoginEvent(userID, method, client)
+	event.Identifier = identifier
+	event.FailureReason = "unknown"
+	if appError, ok := reason.(*errors.AppError); ok {
+		event.FailureReason = appError.Code
+	}
+
+	if err := s.loginEventRepo.CreateLoginEvent(ctx, event); err != nil { // Pass context
+		logger.Error(ctx, "Failed to record failed login event", "userId", userID, "method", method, "error", err) // Use slog.ErrorContext
+	}
+}
+
+// ListLoginEvents lists a user's login history.
+func (s *loginHistoryService) ListLoginEvents(ctx context.Context, userID uint, params *query_params.QueryParams) ([]models.LoginEvent, *response.Pagination, error) {
+	events, total, nextCursor, err := s.loginEventRepo.ListUserLoginEvents(ctx, userID, params) // Pass context
+	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
+		logger.Error(ctx, "Failed to list login events", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, nil, fmt.Errorf("failed to list login events: %w", err)
+	}
+
+	// Return an empty array if there is no data.
+	if len(events) == 0 {
+		events = []models.LoginEvent{}
+	}
+
+	// Construct pagination information.
+	pagination := &response.Pagination{
+		TotalCount:  total,
+		PageSize:    params.Limit,
+		CurrentPage: params.Page,
+		TotalPages:  (total + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
+	}
+
+	return events, pagination, nil
+}
+
+// sendNewDeviceAlert emails the user about a login from a new device. The email is sent in the background,
+// so that a slow mail server does not delay the login.
+func (s *loginHistoryService) sendNewDeviceAlert(ctx context.Context, user *models.User, event *models.LoginEvent) {
+	if user.Email == nil || *user.Email == "" {
+		return
+	}
+
+	device := event.DeviceName
+	if device == "" {
+		device = event.UserAgent
+	}
+	userID, to, name, locale := user.ID, *user.Email, user.Name, user.Locale
+	ipAddress, loginTime := event.IPAddress, event.CreatedAt
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := s.emailService.SendNewDeviceLoginAlert(ctx, to, name, device, ipAddress, loginTime, locale); err != nil { // Pass context
+			logger.Error(ctx, "Failed to send new device login alert", "userId", userID, "error", err) // Use slog.ErrorContext
+		}
+	}()
+}
+
+// newLoginEvent creates a login event with the details of the client.
+func newLoginEvent(userID *uint, method string, client *dto.ClientInfo) *models.LoginEvent {
+	event := &models.LoginEvent{UserID: userID, Method: method, CreatedAt: time.Now()}
+	if client != nil {
+		event.IPAddress = client.IPAddress
+		event.UserAgent = truncate(client.UserAgent, 500)
+		event.DeviceName = truncate(client.DeviceName, 100)
+	}
+	event.DeviceFingerprint = deviceFingerprint(client)
+	return event
+}
+
+// deviceFingerprint identifies the device of a login: the X-Device-ID the app sends if there is one, otherwise
+// the user agent together with the device name.
+func deviceFingerprint(client *dto.ClientInfo) string {
+	source := "ua:"
+	if client != nil {
+		if client.DeviceID != "" {
+			source = "id:" + client.DeviceID
+		} else {
+			source += client.UserAgent + "\n" + client.DeviceName
+		}
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
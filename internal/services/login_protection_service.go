@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/query_params"
+	"github.com/go-backend-template/pkg/response"
+	"github.com/redis/go-redis/v9"
+)
+
+// Lockout scopes
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+)
+
+// maxLoginDelay caps the progressive delay between failed login attempts.
+const maxLoginDelay = time.Minute
+
+// LoginProtectionService defines the interface for brute-force protection of password logins.
+//
+// Failed attempts are counted per account and per IP address in Redis. After a few failures on an account
+// each further attempt has to wait progressively longer (1s, 2s, 4s, ...); once a limit is reached the
+// account or IP is locked out temporarily and a lockout event is recorded for admins.
+type LoginProtectionService interface {
+	// CheckLogin returns an error if the account or IP is currently locked out or must wait before retrying.
+	CheckLogin(ctx context.Context, account, ip string) error
+	// RecordLoginFailure counts a failed login. userID is nil if the account does not exist.
+	RecordLoginFailure(ctx context.Context, account, ip string, userID *uint)
+	// RecordLoginSuccess resets the failure counter of the account.
+	RecordLoginSuccess(ctx context.Context, account string)
+	// ListLockoutEvents lists recorded lockouts (admin).
+	ListLockoutEvents(ctx context.Context, params *query_params.QueryParams) ([]models.LockoutEvent, *response.Pagination, error)
+}
+
+// loginProtectionService is the Redis implementation of LoginProtectionService.
+type loginProtectionService struct {
+	redis              *redis.Client
+	lockoutEventRepo   repositories.LockoutEventRepository
+	maxAccountFailures int
+	maxIPFailures      int
+	delayAfterFailures int
+	failureWindow      time.Duration
+	lockoutDuration    time.Duration
+}
+
+// NewLoginProtectionService creates a new instance of LoginProtectionService.
+func NewLoginProtectionService(config *config.Config, redisClient *redis.Client, lockoutEventRepo repositories.LockoutEventRepository) LoginProtectionService {
+	cfg := config.LoginProtection
+	s := &loginProtectionService{
+		redis:              redisClient,
+		lockoutEventRepo:   lockoutEventRepo,
+		maxAccountFailures: 5,
+		maxIPFailures:      20,
+		delayAfterFailures: 3,
+		failureWindow:      15 * time.Minute,
+		lockoutDuration:    15 * time.Minute,
+	}
+	if cfg.MaxAccountFailures > 0 {
+		s.maxAccountFailures = cfg.MaxAccountFailures
+	}
+	if cfg.MaxIPFailures > 0 {
+		s.maxIPFailures = cfg.MaxIPFailures
+	}
+	if cfg.DelayAfterFailures > 0 {
+		s.delayAfterFailures = cfg.DelayAfterFailures
+	}
+	if cfg.FailureWindowMinutes > 0 {
+		s.failureWindow = time.Duration(cfg.FailureWindowMinutes) * time.Minute
+	}
+	if cfg.LockoutMinutes > 0 {
+		s.lockoutDuration = time.Duration(cfg.LockoutMinutes) * time.Minute
+	}
+	return s
+}
+
+// CheckLogin returns an error if the account or IP is currently locked out or must wait before retrying.
+// If Redis is unavailable the login is allowed.
+func (s *loginProtectionService) CheckLogin(ctx context.Context, account, ip string) error {
+	// Check lockouts.
+	for _, key := range []string{lockoutKey(LockoutScopeAccount, account), lockoutKey(LockoutScopeIP, ip)} {
+		ttl, err := s.redis.TTL(ctx, key).Result()
+		if err != nil {
+			logger.Error(ctx, "Failed to check login lockout in Redis", "key", key, "error", err) // Use slog.ErrorContext
+			return nil
+		}
+		if ttl > 0 {
+			return errors.ErrAccountLocked.WithData(retryAfter(ttl))
+		}
+	}
+
+	// Check the progressive delay of the account.
+	ttl, err := s.redis.PTTL(ctx, loginDelayKey(account)).Result()
+	if err != nil {
+		logger.Error(ctx, "Failed to check login delay in Redis", "account", account, "error", err) // Use slog.ErrorContext
+		return nil
+	}
+	if ttl > 0 {
+		return errors.ErrLoginThrottled.WithData(retryAfter(ttl))
+	}
+
+	return nil
+}
+
+// RecordLoginFailure counts a failed login for the account and the IP address.
+func (s *loginProtectionService) RecordLoginFailure(ctx context.Context, account, ip string, userID *uint) {
+	// Account failures: progressive delay, then lockout.
+	failures, err := s.incrementFailures(ctx, LockoutScopeAccount, account)
+	if err != nil {
+		logger.Error(ctx, "Failed to count login failure in Redis", "account", account, "error", err) // Use slog.ErrorContext
+	} else if failures >= s.maxAccountFailures {
+		s.lockout(ctx, LockoutScopeAccount, account, ip, userID, failures)
+	} else if failures >= s.delayAfterFailures {
+		delay := time.Second << (failures - s.delayAfterFailures)
+		if delay > maxLoginDelay {
+			delay = maxLoginDelay
+		}
+		if err := s.redis.Set(ctx, loginDelayKey(account), 1, delay).Err(); err != nil {
+			logger.Error(ctx, "Failed to set login delay in Redis", "account", account, "error", err) // Use slog.ErrorContext
+		}
+	}
+
+	// IP failures: lockout only, since many users may share an IP address.
+	if ip == "" {
+		return
+	}
+	failures, err = s.incrementFailures(ctx, LockoutScopeIP, ip)
+	if err != nil {
+		logger.Error(ctx, "Failed to count login failure in Redis", "ip", ip, "error", err) // Use slog.ErrorContext
+	} else if failures >= s.maxIPFailures {
+		s.lockout(ctx, LockoutScopeIP, ip, ip, nil, failures)
+	}
+}
+
+// RecordLoginSuccess resets the failure counter of the account.
+func (s *loginProtectionService) RecordLoginSuccess(ctx context.Context, account string) {
+	if err := s.redis.Del(ctx, loginFailuresKey(LockoutScopeAccount, account), loginDelayKey(account)).Err(); err != nil {
+		logger.Error(ctx, "Failed to reset login failures in Redis", "account", account, "error", err) // Use slog.ErrorContext
+	}
+}
+
+// ListLockoutEvents lists recorded lockouts.
+func (s *loginProtectionService) ListLockoutEvents(ctx context.Context, params *query_params.QueryParams) ([]models.LockoutEvent, *response.Pagination, error) {
+	events, total, nextCursor, err := s.lockoutEventRepo.ListLockoutEvents(ctx, params) // Pass context
+	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
+		logger.Error(ctx, "Failed to list lockout events", "error", err) // Use slog.ErrorContext
+		return nil, nil, fmt.Errorf("failed to list lockout events: %w", err)
+	}
+
+	// Return an empty array if there is no data.
+	if len(events) == 0 {
+		events = []models.LockoutEvent{}
+	}
+
+	// Construct pagination information.
+	pagination := &response.Pagination{
+		TotalCount:  total,
+		PageSize:    params.Limit,
+		CurrentPage: params.Page,
+		TotalPages:  (total + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
+	}
+
+	return events, pagination, nil
+}
+
+// incrementFailures increments a failure counter, starting the counting window on the first failure.
+func (s *loginProtectionService) incrementFailures(ctx context.Context, scope, identifier string) (int, error) {
+	key := loginFailuresKey(scope, identifier)
+	failures, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if failures == 1 {
+		if err := s.redis.Expire(ctx, key, s.failureWindow).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return int(failures), nil
+}
+
+// lockout locks the account or IP, resets its failure counter and records a lockout event.
+func (s *loginProtectionService) lockout(ctx context.Context, scope, identifier, ip string, userID *uint, failures int) {
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, lockoutKey(scope, identifier), 1, s.lockoutDuration)
+	pipe.Del(ctx, loginFailuresKey(scope, identifier))
+	if scope == LockoutScopeAccount {
+		pipe.Del(ctx, loginDelayKey(identifier))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error(ctx, "Failed to store login lockout in Redis", "scope", scope, "identifier", identifier, "error", err) // Use slog.ErrorContext
+		return
+	}
+
+	logger.Warn(ctx, "Login locked out after too many failed attempts", // Use slog.WarnContext
+		"scope", scope,
+		"identifier", identifier,
+		"ip", ip,
+		"failures", failures,
+		"lockoutDuration", s.lockoutDuration.String(),
+	)
+
+	event := &models.LockoutEvent{
+		Scope:        scope,
+		Identifier:   identifier,
+		UserID:       userID,
+		IPAddress:    ip,
+		FailureCount: failures,
+		LockedUntil:  time.Now().Add(s.lockoutDuration),
+	}
+	if err := s.lockoutEventRepo.CreateLockoutEvent(ctx, event); err != nil { // Pass context
+		logger.Error(ctx, "Failed to record lockout event", "scope", scope, "identifier", identifier, "error", err) // Use slog.ErrorContext
+	}
+}
+
+// loginFailuresKey returns the Redis key of a failure counter.
+func loginFailuresKey(scope, identifier string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, identifier)
+}
+
+// lockoutKey returns the Redis key marking a lockout.
+func lockoutKey(scope, identifier string) string {
+	return fmt.Sprintf("login_lockout:%s:%s", scope, identifier)
+}
+
+// loginDelayKey returns the Redis key enforcing the delay before the next attempt on an account.
+func loginDelayKey(account string) string {
+	return fmt.Sprintf("login_delay:%s", account)
+}
+
+// retryAfter builds the error details telling the client when to retry.
+func retryAfter(ttl time.Duration) map[string]interface{} {
+	seconds := int((ttl + time.Second - 1) / time.Second)
+	return map[string]interface{}{"retry_after": seconds}
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/totp"
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes generated at a time.
+const recoveryCodeCount = 10
+
+// MFAService defines the interface for TOTP two-factor authentication.
+type MFAService interface {
+	// GetStatus returns the two-factor authentication status of a user.
+	GetStatus(ctx context.Context, userID uint) (*dto.MFAStatusDTO, error)
+	// IsEnabled reports whether a user has two-factor authentication enabled.
+	IsEnabled(ctx context.Context, userID uint) (bool, error)
+	// EnrollTOTP starts enrollment by generating a new TOTP secret.
+	EnrollTOTP(ctx context.Context, user *models.User) (*dto.TOTPEnrollmentDTO, error)
+	// ConfirmTOTP completes enrollment with a code from the authenticator app and returns recovery codes.
+	ConfirmTOTP(ctx context.Context, userID uint, code string) ([]string, error)
+	// DisableTOTP disables two-factor authentication after verifying a code.
+	DisableTOTP(ctx context.Context, userID uint, code string, client *dto.ClientInfo) error
+	// RegenerateRecoveryCodes replaces the recovery codes after verifying a code.
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string, client *dto.ClientInfo) ([]string, error)
+	// VerifyCode verifies a TOTP code or consumes a recovery code.
+	VerifyCode(ctx context.Context, userID uint, code string) error
+}
+
+// mfaService is the implementation of MFAService.
+type mfaService struct {
+	config                 *config.Config
+	mfaRepo                repositories.MFARepository
+	loginProtectionService LoginProtectionService
+}
+
+// NewMFAService creates a new instance of MFAService.
+func NewMFAService(config *config.Config, mfaRepo repositories.MFARepository, loginProtectionService LoginProtectionService) MFAService {
+	return &mfaService{
+		config:                 config,
+		mfaRepo:                mfaRepo,
+		loginProtectionService: loginProtectionService,
+	}
+}
+
+// GetStatus returns the two-factor authentication status of a user.
+func (s *mfaService) GetStatus(ctx context.Context, userID uint) (*dto.MFAStatusDTO, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &dto.MFAStatusDTO{}, nil
+		}
+		logger.Error(ctx, "Failed to get MFA settings", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+
+	status := &dto.MFAStatusDTO{
+		Enabled:   mfa.Enabled,
+		EnabledAt: mfa.EnabledAt,
+	}
+	if mfa.Enabled {
+		remaining, err := s.mfaRepo.CountUnusedRecoveryCodes(ctx, userID) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to count recovery codes", "userId", userID, "error", err) // Use slog.ErrorContext
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+
+	return status, nil
+}
+
+// IsEnabled reports whether a user has two-factor authentication enabled.
+func (s *mfaService) IsEnabled(ctx context.Context, userID uint) (bool, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		logger.Error(ctx, "Failed to get MFA settings", "userId", userID, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	return mfa.Enabled, nil
+}
+
+// EnrollTOTP starts enrollment by generating a new TOTP secret.
+// Calling it again before confirming replaces the pending secret.
+func (s *mfaService) EnrollTOTP(ctx context.Context, user *models.User) (*dto.TOTPEnrollmentDTO, error) {
+	enabled, err := s.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errors.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		logger.Error(ctx, "Failed to generate TOTP secret", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	mfa := &models.UserMFA{
+		UserID:     user.ID,
+		TOTPSecret: secret,
+	}
+	if err := s.mfaRepo.SaveUserMFA(ctx, mfa); err != nil { // Pass context
+		logger.Error(ctx, "Failed to save TOTP secret", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	// Label the account in the authenticator app with the email (or phone) if available.
+	accountName := user.Name
+	if user.Email != nil && *user.Email != "" {
+		accountName = *user.Email
+	} else if user.Phone != nil && *user.Phone != "" {
+		accountName = *user.Phone
+	}
+
+	logger.Info(ctx, "TOTP enrollment started", "userId", user.ID) // Use slog.InfoContext
+	return &dto.TOTPEnrollmentDTO{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer(), accountName, secret),
+	}, nil
+}
+
+// ConfirmTOTP completes enrollment with a code from the authenticator app and returns recovery codes.
+func (s *mfaService) ConfirmTOTP(ctx context.Context, userID uint, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrMFANotEnrolled
+		}
+		logger.Error(ctx, "Failed to get MFA settings", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if mfa.Enabled {
+		return nil, errors.ErrMFAAlreadyEnabled
+	}
+
+	// Verify the code against the pending secret.
+	step, ok := totp.Validate(code, mfa.TOTPSecret, time.Now())
+	if !ok {
+		logger.Warn(ctx, "Invalid TOTP code during enrollment", "userId", userID) // Use slog.WarnContext
+		return nil, errors.ErrInvalidMFACode
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"enabled":        true,
+		"enabled_at":     now,
+		"last_used_step": step,
+	}
+	if err := s.mfaRepo.UpdateUserMFA(ctx, userID, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to enable TOTP", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "TOTP two-factor authentication enabled", "userId", userID) // Use slog.InfoContext
+	return recoveryCodes, nil
+}
+
+// DisableTOTP disables two-factor authentication after verifying a code.
+func (s *mfaService) DisableTOTP(ctx context.Context, userID uint, code string, client *dto.ClientInfo) error {
+	if err := s.verifyProtectedCode(ctx, userID, code, client); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteUserMFA(ctx, userID); err != nil { // Pass context
+		logger.Error(ctx, "Failed to disable TOTP", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+
+	logger.Info(ctx, "TOTP two-factor authentication disabled", "userId", userID) // Use slog.InfoContext
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after verifying a code.
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string, client *dto.ClientInfo) ([]string, error) {
+	if err := s.verifyProtectedCode(ctx, userID, code, client); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "MFA recovery codes regenerated", "userId", userID) // Use slog.InfoContext
+	return recoveryCodes, nil
+}
+
+// verifyProtectedCode verifies a code like VerifyCode, counting wrong codes on the same account as the second
+// login step, so the code cannot be guessed through the management endpoints instead.
+func (s *mfaService) verifyProtectedCode(ctx context.Context, userID uint, code string, client *dto.ClientInfo) error {
+	account := mfaLoginAccount(userID)
+	ip := clientIP(client)
+	if err := s.loginProtectionService.CheckLogin(ctx, account, ip); err != nil {
+		logger.Warn(ctx, "MFA code rejected by brute-force protection", "userId", userID, "ip", ip) // Use slog.WarnContext
+		return err
+	}
+	if err := s.VerifyCode(ctx, userID, code); err != nil {
+		if stderrors.Is(err, errors.ErrInvalidMFACode) {
+			s.loginProtectionService.RecordLoginFailure(ctx, account, ip, &userID)
+		}
+		return err
+	}
+	s.loginProtectionService.RecordLoginSuccess(ctx, account)
+	return nil
+}
+
+// mfaLoginAccount returns the account under which wrong second factor codes of a user are counted.
+func mfaLoginAccount(userID uint) string {
+	return fmt.Sprintf("mfa:%d", userID)
+}
+
+// VerifyCode verifies a TOTP code or consumes a recovery code.
+func (s *mfaService) VerifyCode(ctx context.Context, userID uint, code string) error {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrMFANotEnabled
+		}
+		logger.Error(ctx, "Failed to get MFA settings", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get MFA settings: %w", err)
+	}
+	if !mfa.Enabled {
+		return errors.ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	// 6-digit codes are TOTP codes.
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(code, mfa.TOTPSecret, time.Now())
+		if !ok {
+			logger.Warn(ctx, "Invalid TOTP code", "userId", userID) // Use slog.WarnContext
+			return errors.ErrInvalidMFACode
+		}
+		// Each code can only be used once.
+		used, err := s.mfaRepo.UseTOTPStep(ctx, userID, step) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to record TOTP usage", "userId", userID, "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to record TOTP usage: %w", err)
+		}
+		if !used {
+			logger.Warn(ctx, "TOTP code replay rejected", "userId", userID) // Use slog.WarnContext
+			return errors.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	// Anything else is treated as a recovery code.
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code)) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to use recovery code", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if !used {
+		logger.Warn(ctx, "Invalid MFA recovery code", "userId", userID) // Use slog.WarnContext
+		return errors.ErrInvalidMFACode
+	}
+
+	logger.Info(ctx, "MFA recovery code used", "userId", userID) // Use slog.InfoContext
+	return nil
+}
+
+// replaceRecoveryCodes generates and stores a new set of recovery codes, returning them in plain text.
+func (s *mfaService) replaceRecoveryCodes(ctx context.Context, userID uint) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			logger.Error(ctx, "Failed to generate recovery code", "userId", userID, "error", err) // Use slog.ErrorContext
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil { // Pass context
+		logger.Error(ctx, "Failed to store recovery codes", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// issuer returns the issuer name shown in authenticator apps.
+func (s *mfaService) issuer() string {
+	if s.config.MFA.Issuer != "" {
+		return s.config.MFA.Issuer
+	}
+	return "go-backend-template"
+}
+
+// generateRecoveryCode generates a recovery code in the format XXXXX-XXXXX.
+func generateRecoveryCode() (string, error) {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // Without easily confused characters
+	code := make([]byte, 10)
+	for i := range code {
+		num, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = charset[num.Int64()]
+	}
+	return string(code[:5]) + "-" + string(code[5:]), nil
+}
+
+// hashRecoveryCode normalizes and hashes a recovery code.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
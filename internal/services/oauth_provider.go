@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+)
+
+// OAuth provider types
+const (
+	OAuthProviderTypeOIDC   = "oidc"
+	OAuthProviderTypeWechat = "wechat"
+)
+
+// reservedProviderNames are login methods that are not OAuth providers. Configuring a provider with one
+// of these names would mix up the provider recorded in sessions and user_providers.
+var reservedProviderNames = map[string]bool{
+	"password":            true,
+	"passkey":             true,
+	"phone":               true,
+	"magic_link":          true,
+	"email":               true,
+	"mfa":                 true,
+	"wxmini":              true,
+	"wechat_mini_program": true,
+}
+
+// providerNamePattern restricts provider names to values that are safe in routes and as database keys.
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// OAuthIdentity is the user identity returned by an OAuth provider.
+type OAuthIdentity struct {
+	ProviderUID    string // Stable user identifier at the provider (sub claim, WeChat openid)
+	Email          string // Lowercased, may be empty
+	EmailVerified  bool
+	IsPrivateEmail bool   // Relay address that forwards to the user's real inbox (e.g. Apple "Hide My Email")
+	Name           string // May be empty
+	AvatarURL      string // May be empty
+	Locale         string // May be empty
+	UnionID        string // WeChat unionid, shared by all apps under the same WeChat Open Platform account
+}
+
+// OAuthProvider defines the interface of an external identity provider used for login and account binding.
+type OAuthProvider interface {
+	// Name returns the provider name used in routes and recorded in user_providers and sessions.
+	Name() string
+	// Authenticate exchanges the authorization code or verifies the identity token in the request and
+	// returns the identity of the user.
+	Authenticate(ctx context.Context, req *dto.OAuthTokenRequest) (*OAuthIdentity, error)
+}
+
+// OAuthProviderRegistry holds the OAuth providers enabled by configuration.
+type OAuthProviderRegistry interface {
+	// Get returns the provider with the given name.
+	Get(name string) (OAuthProvider, bool)
+}
+
+type oauthProviderRegistry struct {
+	providers map[string]OAuthProvider
+}
+
+// NewOAuthProviderRegistry creates the providers configured under oauth.providers.
+// Providers without a type are treated as generic OpenID Connect providers.
+func NewOAuthProviderRegistry(config *config.Config) (OAuthProviderRegistry, error) {
+	registry := &oauthProviderRegistry{providers: make(map[string]OAuthProvider)}
+
+	for name, providerCfg := range config.OAuth.Providers {
+		if !providerNamePattern.MatchString(name) || reservedProviderNames[name] {
+			return nil, fmt.Errorf("invalid OAuth provider name: %s", name)
+		}
+		if len(providerCfg.Clients) == 0 {
+			return nil, fmt.Errorf("OAuth provider %s has no clients", name)
+		}
+
+		var provider OAuthProvider
+		switch providerCfg.Type {
+		case "", OAuthProviderTypeOIDC:
+			if providerCfg.Issuer == "" {
+				return nil, fmt.Errorf("OAuth provider %s has no issuer", name)
+			}
+			provider = newOIDCProvider(name, providerCfg)
+		case OAuthProviderTypeWechat:
+			provider = newWechatOAuthProvider(name, providerCfg)
+		default:
+			return nil, fmt.Errorf("unsupported type %q for OAuth provider %s", providerCfg.Type, name)
+		}
+		registry.providers[name] = provider
+	}
+
+	return registry, nil
+}
+
+// Get returns the provider with the given name.
+func (r *oauthProviderRegistry) Get(name string) (OAuthProvider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
@@ -0,0 +1,836 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/jwt"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/pkce"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// OAuth2 grant types supported by the token endpoint.
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantRefreshToken      = "refresh_token"
+)
+
+const (
+	oauthClientSecretMarker    = "ocs_"
+	oauthClientIDBytes         = 16 // 32 hex characters
+	oauthClientSecretBytes     = 32 // 64 hex characters
+	oauthAuthorizationCodeTTL  = 10 * time.Minute
+	oauthAuthorizationCodeSize = 32
+)
+
+// oauthScopes are the scopes third-party clients can be granted, with their description on the consent screen.
+// They are the route scopes of API keys; the admin scope is never granted to third-party clients.
+var oauthScopes = []dto.OAuthScopeDTO{
+	{Name: APIKeyScopeProfile, Description: "View your profile"},
+	{Name: APIKeyScopeProducts, Description: "Browse products, and view and manage your likes and favorites"},
+}
+
+// OAuthServerService defines the interface for acting as an OAuth2 authorization server for third-party apps.
+//
+// Admins register clients; users grant them access on a consent screen with the authorization code flow,
+// which always requires PKCE (S256). Each grant is a login session of the user named after the app, so it
+// shows up in the user's session list and is revoked like any other session, e.g. when the user logs out
+// everywhere or deletes the account.
+// Access tokens carry the client_id and scope claims and are limited to the route groups of their scopes.
+type OAuthServerService interface {
+	// ListClients lists the registered clients (admin).
+	ListClients(ctx context.Context) ([]models.OAuthClient, error)
+	// CreateClient registers a client and returns it together with its secret, which is not stored
+	// (empty for public clients).
+	CreateClient(ctx context.Context, admin *models.User, req *dto.CreateOAuthClientRequest) (*models.OAuthClient, string, error)
+	// UpdateClient updates a client.
+	UpdateClient(ctx context.Context, id uint, req *dto.UpdateOAuthClientRequest) (*models.OAuthClient, error)
+	// RotateClientSecret replaces the secret of a confidential client and returns the new one.
+	RotateClientSecret(ctx context.Context, id uint) (*models.OAuthClient, string, error)
+	// DeleteClient deletes a client, the consents given to it and all of its grants.
+	DeleteClient(ctx context.Context, id uint) error
+
+	// GetAuthorization validates an authorization request and returns the content of the consent screen.
+	GetAuthorization(ctx context.Context, user *models.User, req *dto.OAuthAuthorizeRequest) (*dto.OAuthAuthorizationDTO, error)
+	// Authorize records the user's decision on an authorization request and returns the URI to redirect the
+	// user to: with an authorization code if the user approved, with error=access_denied otherwise.
+	Authorize(ctx context.Context, user *models.User, req *dto.OAuthAuthorizeDecisionRequest) (*dto.OAuthRedirectDTO, error)
+	// ListAuthorizedApps lists the apps the user has authorized, with their clients loaded.
+	ListAuthorizedApps(ctx context.Context, userID uint) ([]models.OAuthConsent, error)
+	// RevokeAuthorizedApp withdraws the user's consent for an app and revokes the app's tokens for the user.
+	RevokeAuthorizedApp(ctx context.Context, userID uint, clientID string) error
+
+	// Token handles a token request (authorization_code or refresh_token grant) of an authenticated client.
+	Token(ctx context.Context, req *dto.OAuthTokenGrantRequest, client *dto.ClientInfo) (*dto.OAuthTokenResponse, error)
+	// Revoke handles a revocation request (RFC 7009): it revokes the grant of the client the token belongs to.
+	// Unknown, invalid and already revoked tokens are not an error.
+	Revoke(ctx context.Context, req *dto.OAuthRevokeRequest) error
+	// Introspect handles an introspection request (RFC 7662) of an internal service configured under
+	// oauth_server.introspection_clients. An access token is active if it is validly signed and unexpired,
+	// its session has not been revoked, and its user exists and is not banned.
+	Introspect(ctx context.Context, req *dto.TokenIntrospectionRequest) (*dto.TokenIntrospectionResponse, error)
+}
+
+// oauthServerService is the implementation of OAuthServerService.
+type oauthServerService struct {
+	config              *config.Config
+	jwtKeys             *jwt.KeySet
+	redis               *redis.Client
+	oauthClientRepo     repositories.OAuthClientRepository
+	userRepo            repositories.UserRepository
+	sessionService      SessionService
+	refreshTokenService RefreshTokenService
+	banService          BanService
+	roleService         RoleService
+	accessTokenDuration time.Duration
+}
+
+// oauthAuthorizationCode is the record of an authorization code stored in Redis until it is redeemed.
+type oauthAuthorizationCode struct {
+	OAuthClientID uint   `json:"oauth_client_id"`
+	UserID        uint   `json:"user_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	Scope         string `json:"scope"`
+	CodeChallenge string `json:"code_challenge"`
+}
+
+// NewOAuthServerService creates a new instance of OAuthServerService.
+func NewOAuthServerService(config *config.Config, jwtKeys *jwt.KeySet, redisClient *redis.Client, oauthClientRepo repositories.OAuthClientRepository, userRepo repositories.UserRepository, sessionService SessionService, refreshTokenService RefreshTokenService, banService BanService, roleService RoleService) OAuthServerService {
+	s := &oauthServerService{
+		config:              config,
+		jwtKeys:             jwtKeys,
+		redis:               redisClient,
+		oauthClientRepo:     oauthClientRepo,
+		userRepo:            userRepo,
+		sessionService:      sessionService,
+		refreshTokenService: refreshTokenService,
+		banService:          banService,
+		roleService:         roleService,
+		accessTokenDuration: time.Hour,
+	}
+	if config.OAuthServer.AccessTokenMinutes > 0 {
+		s.accessTokenDuration = time.Duration(config.OAuthServer.AccessTokenMinutes) * time.Minute
+	}
+	return s
+}
+
+/*
+Client registration (admin)
+*/
+
+// ListClients lists the registered clients.
+func (s *oauthServerService) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	clients, err := s.oauthClientRepo.ListOAuthClients(ctx) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list OAuth clients", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list OAuth clients: %w", err)
+	}
+	return clients, nil
+}
+
+// CreateClient registers a client.
+func (s *oauthServerService) CreateClient(ctx context.Context, admin *models.User, req *dto.CreateOAuthClientRequest) (*models.OAuthClient, string, error) {
+	if err := validateRedirectURIs(req.RedirectURIs); err != nil {
+		return nil, "", err
+	}
+
+	clientID, err := randomHex(oauthClientIDBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate client ID: %w", err)
+	}
+
+	client := &models.OAuthClient{
+		ClientID:     clientID,
+		Confidential: req.Confidential,
+		Name:         req.Name,
+		LogoURL:      req.LogoURL,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		Scopes:       strings.Join(req.Scopes, " "),
+		CreatedBy:    admin.ID,
+	}
+
+	var secret string
+	if client.Confidential {
+		if secret, err = generateClientSecret(); err != nil {
+			return nil, "", fmt.Errorf("failed to generate client secret: %w", err)
+		}
+		client.SecretHash = hashClientSecret(secret)
+	}
+
+	if err := s.oauthClientRepo.CreateOAuthClient(ctx, client); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create OAuth client", "name", req.Name, "error", err) // Use slog.ErrorContext
+		return nil, "", fmt.Errorf("failed to create OAuth client: %w", err)
+	}
+
+	logger.Info(ctx, "OAuth client registered", "oauthClientId", client.ID, "clientId", client.ClientID, "confidential", client.Confidential, "adminId", admin.ID) // Use slog.InfoContext
+	return client, secret, nil
+}
+
+// UpdateClient updates a client. Grants keep working; their scopes are narrowed to the client's on refresh.
+func (s *oauthServerService) UpdateClient(ctx context.Context, id uint, req *dto.UpdateOAuthClientRequest) (*models.OAuthClient, error) {
+	if _, err := s.getClient(ctx, id); err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.LogoURL != nil {
+		updates["logo_url"] = *req.LogoURL
+	}
+	if req.RedirectURIs != nil {
+		if err := validateRedirectURIs(req.RedirectURIs); err != nil {
+			return nil, err
+		}
+		updates["redirect_uris"] = strings.Join(req.RedirectURIs, " ")
+	}
+	if req.Scopes != nil {
+		updates["scopes"] = strings.Join(req.Scopes, " ")
+	}
+	if len(updates) == 0 {
+		return nil, errors.ErrNoValidUpdates
+	}
+
+	if err := s.oauthClientRepo.UpdateOAuthClient(ctx, id, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update OAuth client", "oauthClientId", id, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to update OAuth client: %w", err)
+	}
+
+	logger.Info(ctx, "OAuth client updated", "oauthClientId", id) // Use slog.InfoContext
+	return s.getClient(ctx, id)
+}
+
+// RotateClientSecret replaces the secret of a confidential client. The old secret stops working immediately.
+func (s *oauthServerService) RotateClientSecret(ctx context.Context, id uint) (*models.OAuthClient, string, error) {
+	client, err := s.getClient(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !client.Confidential {
+		return nil, "", errors.ErrOAuthClientPublic
+	}
+
+	secret, err := generateClientSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate client secret: %w", err)
+	}
+	if err := s.oauthClientRepo.UpdateOAuthClient(ctx, id, map[string]interface{}{"secret_hash": hashClientSecret(secret)}); err != nil { // Pass context
+		logger.Error(ctx, "Failed to rotate OAuth client secret", "oauthClientId", id, "error", err) // Use slog.ErrorContext
+		return nil, "", fmt.Errorf("failed to rotate client secret: %w", err)
+	}
+
+	logger.Info(ctx, "OAuth client secret rotated", "oauthClientId", id) // Use slog.InfoContext
+	return client, secret, nil
+}
+
+// DeleteClient deletes a client after revoking all of its grants.
+func (s *oauthServerService) DeleteClient(ctx context.Context, id uint) error {
+	if _, err := s.getClient(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeClientSessions(ctx, id, 0); err != nil { // Pass context
+		return err
+	}
+	if err := s.oauthClientRepo.DeleteOAuthClient(ctx, id); err != nil { // Pass context
+		logger.Error(ctx, "Failed to delete OAuth client", "oauthClientId", id, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to delete OAuth client: %w", err)
+	}
+
+	logger.Info(ctx, "OAuth client deleted", "oauthClientId", id) // Use slog.InfoContext
+	return nil
+}
+
+// getClient retrieves a client by ID.
+func (s *oauthServerService) getClient(ctx context.Context, id uint) (*models.OAuthClient, error) {
+	client, err := s.oauthClientRepo.GetOAuthClient(ctx, id) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrOAuthClientNotFound
+		}
+		logger.Error(ctx, "Failed to get OAuth client", "oauthClientId", id, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+	return client, nil
+}
+
+/*
+Authorization and consent
+*/
+
+// GetAuthorization validates an authorization request and returns the content of the consent screen.
+func (s *oauthServerService) GetAuthorization(ctx context.Context, user *models.User, req *dto.OAuthAuthorizeRequest) (*dto.OAuthAuthorizationDTO, error) {
+	client, scopes, err := s.validateAuthorizeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The frontend can skip the consent screen if the user has already consented to every requested scope.
+	consented := false
+	consent, err := s.oauthClientRepo.GetConsent(ctx, user.ID, client.ID) // Pass context
+	if err == nil {
+		consented = containsAll(consent.ScopeList(), scopes)
+	} else if err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to get OAuth consent", "userId", user.ID, "oauthClientId", client.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	scopeDTOs := make([]dto.OAuthScopeDTO, 0, len(scopes))
+	for _, scope := range oauthScopes {
+		if slices.Contains(scopes, scope.Name) {
+			scopeDTOs = append(scopeDTOs, scope)
+		}
+	}
+
+	return &dto.OAuthAuthorizationDTO{
+		App:         dto.OAuthAppDTO{ClientID: client.ClientID, Name: client.Name, LogoURL: client.LogoURL},
+		Scopes:      scopeDTOs,
+		RedirectURI: req.RedirectURI,
+		Consented:   consented,
+	}, nil
+}
+
+// Authorize records the user's decision on an authorization request.
+func (s *oauthServerService) Authorize(ctx context.Context, user *models.User, req *dto.OAuthAuthorizeDecisionRequest) (*dto.OAuthRedirectDTO, error) {
+	client, scopes, err := s.validateAuthorizeRequest(ctx, &req.OAuthAuthorizeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Approve {
+		logger.Info(ctx, "OAuth authorization denied by user", "userId", user.ID, "clientId", client.ClientID) // Use slog.InfoContext
+		return &dto.OAuthRedirectDTO{RedirectURI: buildRedirectURI(req.RedirectURI, map[string]string{"error": "access_denied", "state": req.State})}, nil
+	}
+
+	// Remember the consent, adding the requested scopes to those consented to before.
+	consentScopes := scopes
+	if consent, err := s.oauthClientRepo.GetConsent(ctx, user.ID, client.ID); err == nil { // Pass context
+		consentScopes = mergeScopes(consent.ScopeList(), scopes)
+	} else if err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to get OAuth consent", "userId", user.ID, "oauthClientId", client.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+	consent := &models.OAuthConsent{UserID: user.ID, OAuthClientID: client.ID, Scopes: strings.Join(consentScopes, " ")}
+	if err := s.oauthClientRepo.SaveConsent(ctx, consent); err != nil { // Pass context
+		logger.Error(ctx, "Failed to save OAuth consent", "userId", user.ID, "oauthClientId", client.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to save consent: %w", err)
+	}
+
+	// Issue a one-time authorization code bound to the client, the redirect URI and the PKCE challenge.
+	buf := make([]byte, oauthAuthorizationCodeSize)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+	code := base64.RawURLEncoding.EncodeToString(buf)
+	record, err := json.Marshal(oauthAuthorizationCode{
+		OAuthClientID: client.ID,
+		UserID:        user.ID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		CodeChallenge: req.CodeChallenge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode authorization code: %w", err)
+	}
+	if err := s.redis.Set(ctx, oauthAuthorizationCodeKey(code), record, oauthAuthorizationCodeTTL).Err(); err != nil {
+		logger.Error(ctx, "Failed to store OAuth authorization code in Redis", "userId", user.ID, "oauthClientId", client.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to store authorization code: %w", err)
+	}
+
+	logger.Info(ctx, "OAuth authorization granted", "userId", user.ID, "clientId", client.ClientID, "scopes", scopes) // Use slog.InfoContext
+	return &dto.OAuthRedirectDTO{RedirectURI: buildRedirectURI(req.RedirectURI, map[string]string{"code": code, "state": req.State})}, nil
+}
+
+// validateAuthorizeRequest checks an authorization request and returns the client and the requested scopes.
+func (s *oauthServerService) validateAuthorizeRequest(ctx context.Context, req *dto.OAuthAuthorizeRequest) (*models.OAuthClient, []string, error) {
+	client, err := s.oauthClientRepo.GetOAuthClientByClientID(ctx, req.ClientID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.ErrOAuthClientNotFound
+		}
+		logger.Error(ctx, "Failed to get OAuth client", "clientId", req.ClientID, "error", err) // Use slog.ErrorContext
+		return nil, nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+	if !client.HasRedirectURI(req.RedirectURI) {
+		logger.Warn(ctx, "OAuth authorization request with unregistered redirect URI", "clientId", client.ClientID, "redirectUri", req.RedirectURI) // Use slog.WarnContext
+		return nil, nil, errors.ErrOAuthInvalidRedirect
+	}
+	if req.ResponseType != "code" {
+		return nil, nil, errors.ErrOAuthUnsupportedResponseType
+	}
+	if req.CodeChallengeMethod != pkce.MethodS256 || !pkce.ValidChallenge(req.CodeChallenge) {
+		return nil, nil, errors.ErrOAuthPKCERequired
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.ScopeList()
+	}
+	if !containsAll(client.ScopeList(), scopes) {
+		return nil, nil, errors.ErrOAuthInvalidScope
+	}
+
+	return client, mergeScopes(nil, scopes), nil
+}
+
+// ListAuthorizedApps lists the apps the user has authorized.
+func (s *oauthServerService) ListAuthorizedApps(ctx context.Context, userID uint) ([]models.OAuthConsent, error) {
+	consents, err := s.oauthClientRepo.ListConsents(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list OAuth consents", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list authorized apps: %w", err)
+	}
+	return consents, nil
+}
+
+// RevokeAuthorizedApp withdraws the user's consent for an app and revokes the app's tokens for the user.
+func (s *oauthServerService) RevokeAuthorizedApp(ctx context.Context, userID uint, clientID string) error {
+	client, err := s.oauthClientRepo.GetOAuthClientByClientID(ctx, clientID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrOAuthAppNotAuthorized
+		}
+		logger.Error(ctx, "Failed to get OAuth client", "clientId", clientID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+	if _, err := s.oauthClientRepo.GetConsent(ctx, userID, client.ID); err != nil { // Pass context
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrOAuthAppNotAuthorized
+		}
+		logger.Error(ctx, "Failed to get OAuth consent", "userId", userID, "oauthClientId", client.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get consent: %w", err)
+	}
+
+	if err := s.oauthClientRepo.DeleteConsent(ctx, userID, client.ID); err != nil { // Pass context
+		logger.Error(ctx, "Failed to delete OAuth consent", "userId", userID, "oauthClientId", client.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to revoke authorized app: %w", err)
+	}
+	if err := s.sessionService.RevokeClientSessions(ctx, client.ID, userID); err != nil { // Pass context
+		return err
+	}
+
+	logger.Info(ctx, "Authorized OAuth app revoked by user", "userId", userID, "clientId", client.ClientID) // Use slog.InfoContext
+	return nil
+}
+
+/*
+Token and revocation endpoints
+*/
+
+// Token handles a token request.
+func (s *oauthServerService) Token(ctx context.Context, req *dto.OAuthTokenGrantRequest, client *dto.ClientInfo) (*dto.OAuthTokenResponse, error) {
+	oauthClient, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case OAuthGrantAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, oauthClient, req, client)
+	case OAuthGrantRefreshToken:
+		return s.refreshClientToken(ctx, oauthClient, req)
+	case "":
+		return nil, errors.ErrOAuthInvalidRequest
+	default:
+		return nil, errors.ErrOAuthUnsupportedGrantType
+	}
+}
+
+// exchangeAuthorizationCode redeems an authorization code and starts a grant session.
+func (s *oauthServerService) exchangeAuthorizationCode(ctx context.Context, oauthClient *models.OAuthClient, req *dto.OAuthTokenGrantRequest, client *dto.ClientInfo) (*dto.OAuthTokenResponse, error) {
+	if req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		return nil, errors.ErrOAuthInvalidRequest
+	}
+
+	// Consume the code (one-time use).
+	data, err := s.redis.GetDel(ctx, oauthAuthorizationCodeKey(req.Code)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			logger.Warn(ctx, "OAuth authorization code not found or expired", "clientId", oauthClient.ClientID) // Use slog.WarnContext
+			return nil, errors.ErrOAuthInvalidGrant
+		}
+		logger.Error(ctx, "Failed to get OAuth authorization code from Redis", "clientId", oauthClient.ClientID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+	var code oauthAuthorizationCode
+	if err := json.Unmarshal([]byte(data), &code); err != nil {
+		return nil, fmt.Errorf("failed to decode authorization code: %w", err)
+	}
+
+	if code.OAuthClientID != oauthClient.ID || code.RedirectURI != req.RedirectURI {
+		logger.Warn(ctx, "OAuth authorization code redeemed by another client or with another redirect URI", "clientId", oauthClient.ClientID) // Use slog.WarnContext
+		return nil, errors.ErrOAuthInvalidGrant
+	}
+	if !pkce.Verify(req.CodeVerifier, code.CodeChallenge) {
+		logger.Warn(ctx, "OAuth PKCE verification failed", "clientId", oauthClient.ClientID, "userId", code.UserID) // Use slog.WarnContext
+		return nil, errors.ErrOAuthInvalidGrant
+	}
+
+	user, err := s.getGrantUser(ctx, code.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessionService.CreateClientSession(ctx, user.ID, oauthClient, client, jwt.RefreshTokenDuration(s.config.JWT.ExpireHours)) // Pass context
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info(ctx, "OAuth authorization code redeemed", "userId", user.ID, "clientId", oauthClient.ClientID, "sessionId", session.ID) // Use slog.InfoContext
+	return s.issueClientTokens(ctx, oauthClient, user, session.ID, code.Scope)
+}
+
+// refreshClientToken rotates a refresh token issued to the client.
+func (s *oauthServerService) refreshClientToken(ctx context.Context, oauthClient *models.OAuthClient, req *dto.OAuthTokenGrantRequest) (*dto.OAuthTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, errors.ErrOAuthInvalidRequest
+	}
+
+	details, err := jwt.ValidateToken(req.RefreshToken, s.jwtKeys)
+	if err != nil || details.TokenType != jwt.RefreshToken || details.TokenID == "" || details.ClientID != oauthClient.ClientID {
+		logger.Debug(ctx, "OAuth refresh token validation failed", "clientId", oauthClient.ClientID, "error", err) // Use slog.DebugContext
+		return nil, errors.ErrOAuthInvalidGrant
+	}
+
+	// A narrower scope may be requested. Scopes removed from the client since are dropped.
+	scopes := mergeScopes(nil, strings.Fields(details.Scope))
+	if requested := strings.Fields(req.Scope); len(requested) > 0 {
+		if !containsAll(scopes, requested) {
+			return nil, errors.ErrOAuthInvalidScope
+		}
+		scopes = mergeScopes(nil, requested)
+	}
+	scopes = slices.DeleteFunc(scopes, func(scope string) bool { return !slices.Contains(oauthClient.ScopeList(), scope) })
+	if len(scopes) == 0 {
+		return nil, errors.ErrOAuthInvalidScope
+	}
+
+	// Consume the refresh token (rotation and reuse detection). The token family ID is the grant session ID.
+	sessionID, err := s.refreshTokenService.RotateRefreshToken(ctx, details.UserID, details.TokenID, req.RefreshToken)
+	if err != nil {
+		if _, ok := err.(*errors.AppError); ok {
+			return nil, errors.ErrOAuthInvalidGrant
+		}
+		return nil, err
+	}
+
+	user, err := s.getGrantUser(ctx, details.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.ExtendSession(ctx, sessionID, jwt.RefreshTokenDuration(s.config.JWT.ExpireHours)); err != nil { // Pass context
+		if _, ok := err.(*errors.AppError); ok {
+			return nil, errors.ErrOAuthInvalidGrant
+		}
+		return nil, err
+	}
+
+	logger.Info(ctx, "OAuth access token refreshed", "userId", user.ID, "clientId", oauthClient.ClientID) // Use slog.InfoContext
+	return s.issueClientTokens(ctx, oauthClient, user, sessionID, strings.Join(scopes, " "))
+}
+
+// getGrantUser loads the user of a grant, who must still be allowed to use the account.
+func (s *oauthServerService) getGrantUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrOAuthInvalidGrant
+		}
+		logger.Error(ctx, "Failed to get user for OAuth grant", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		if _, ok := err.(*errors.AppError); !ok {
+			return nil, err
+		}
+		logger.Warn(ctx, "OAuth grant rejected for banned user", "userId", user.ID) // Use slog.WarnContext
+		return nil, errors.ErrOAuthInvalidGrant
+	}
+	if user.DeletionScheduledAt != nil {
+		logger.Warn(ctx, "OAuth grant rejected for user pending deletion", "userId", user.ID) // Use slog.WarnContext
+		return nil, errors.ErrOAuthInvalidGrant
+	}
+	return user, nil
+}
+
+// issueClientTokens generates a scoped access/refresh token pair for a grant session and records the refresh
+// token in the server-side store.
+func (s *oauthServerService) issueClientTokens(ctx context.Context, oauthClient *models.OAuthClient, user *models.User, sessionID, scope string) (*dto.OAuthTokenResponse, error) {
+	subject := jwt.Subject{UserID: user.ID, Role: user.Role, SessionID: sessionID, ClientID: oauthClient.ClientID, Scope: scope}
+
+	accessToken, err := jwt.GenerateOAuthAccessToken(subject, s.jwtKeys, s.accessTokenDuration)
+	if err != nil {
+		logger.Error(ctx, "Failed to generate OAuth access token", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	tokenID := jwt.NewTokenID()
+	refreshToken, err := jwt.GenerateRefreshToken(subject, tokenID, s.jwtKeys, s.config.JWT.ExpireHours)
+	if err != nil {
+		logger.Error(ctx, "Failed to generate OAuth refresh token", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	ttl := jwt.RefreshTokenDuration(s.config.JWT.ExpireHours)
+	if err := s.refreshTokenService.StoreRefreshToken(ctx, user.ID, sessionID, tokenID, refreshToken, ttl); err != nil {
+		return nil, err
+	}
+
+	return &dto.OAuthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTokenDuration.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
+	}, nil
+}
+
+// Revoke handles a revocation request. Access and refresh tokens of a grant share its session, so revoking
+// either revokes the whole grant.
+func (s *oauthServerService) Revoke(ctx context.Context, req *dto.OAuthRevokeRequest) error {
+	oauthClient, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return errors.ErrOAuthInvalidRequest
+	}
+
+	details, err := jwt.ValidateToken(req.Token, s.jwtKeys)
+	if err != nil || details.ClientID != oauthClient.ClientID || details.SessionID == "" {
+		logger.Debug(ctx, "OAuth revocation request for an invalid token or a token of another client", "clientId", oauthClient.ClientID) // Use slog.DebugContext
+		return nil
+	}
+
+	if err := s.sessionService.RevokeSession(ctx, details.UserID, details.SessionID); err != nil { // Pass context
+		if _, ok := err.(*errors.AppError); ok {
+			return nil
+		}
+		return err
+	}
+
+	logger.Info(ctx, "OAuth grant revoked by client", "userId", details.UserID, "clientId", oauthClient.ClientID, "sessionId", details.SessionID) // Use slog.InfoContext
+	return nil
+}
+
+// Introspect handles an introspection request. Refresh tokens and other token types are reported as inactive,
+// as they cannot be used to access resources.
+func (s *oauthServerService) Introspect(ctx context.Context, req *dto.TokenIntrospectionRequest) (*dto.TokenIntrospectionResponse, error) {
+	if !s.authenticateIntrospectionClient(req.ClientID, req.ClientSecret) {
+		logger.Warn(ctx, "Introspection client authentication failed", "clientId", req.ClientID) // Use slog.WarnContext
+		return nil, errors.ErrOAuthInvalidClient
+	}
+	if req.Token == "" {
+		return nil, errors.ErrOAuthInvalidRequest
+	}
+
+	inactive := &dto.TokenIntrospectionResponse{Active: false}
+
+	details, err := jwt.ValidateToken(req.Token, s.jwtKeys)
+	if err != nil || details.TokenType != jwt.AccessToken {
+		logger.Debug(ctx, "Introspected token is invalid or not an access token", "clientId", req.ClientID, "error", err) // Use slog.DebugContext
+		return inactive, nil
+	}
+
+	// Check the login session (tokens issued before sessions existed carry no session ID).
+	if details.SessionID != "" {
+		if err := s.sessionService.ValidateSession(ctx, details.SessionID, ""); err != nil { // Pass context
+			if _, ok := err.(*errors.AppError); ok {
+				return inactive, nil
+			}
+			return nil, err
+		}
+	}
+
+	// The user must still exist and not be banned.
+	user, err := s.userRepo.GetUser(ctx, details.UserID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return inactive, nil
+		}
+		logger.Error(ctx, "Failed to get user for token introspection", "userId", details.UserID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		if _, ok := err.(*errors.AppError); ok {
+			return inactive, nil
+		}
+		return nil, err
+	}
+
+	// Roles and permissions are loaded rather than taken from the token, so that changes to them take effect
+	// immediately. The role claim of the token is the legacy users.role column and grants nothing.
+	roles, err := s.roleService.ListUserRoles(ctx, user.ID) // Pass context
+	if err != nil {
+		return nil, err
+	}
+	roleNames := make([]string, 0, len(roles))
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+	permissions, err := s.roleService.GetUserPermissions(ctx, user.ID) // Pass context
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenIntrospectionResponse{
+		Active:         true,
+		TokenType:      "Bearer",
+		Subject:        strconv.FormatUint(uint64(user.ID), 10),
+		UserID:         user.ID,
+		Roles:          roleNames,
+		Permissions:    permissions,
+		SessionID:      details.SessionID,
+		ExpiresAt:      details.ExpiresAt.Unix(),
+		TokenID:        details.TokenID,
+		MFA:            details.MFA,
+		ClientID:       details.ClientID,
+		Scope:          details.Scope,
+		ImpersonatorID: details.ImpersonatorID,
+	}, nil
+}
+
+// authenticateIntrospectionClient checks the credentials of an internal service calling the introspection
+// endpoint. Service IDs are matched case-insensitively, as configuration keys are lowercased when loaded.
+func (s *oauthServerService) authenticateIntrospectionClient(clientID, secret string) bool {
+	expected, ok := s.config.OAuthServer.IntrospectionClients[strings.ToLower(clientID)]
+	return ok && expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(secret)) == 1
+}
+
+// authenticateClient authenticates the client of a token or revocation request.
+// Confidential clients must present their secret; public clients only identify themselves.
+func (s *oauthServerService) authenticateClient(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, errors.ErrOAuthInvalidClient
+	}
+
+	client, err := s.oauthClientRepo.GetOAuthClientByClientID(ctx, clientID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			logger.Warn(ctx, "Unknown OAuth client", "clientId", clientID) // Use slog.WarnContext
+			return nil, errors.ErrOAuthInvalidClient
+		}
+		logger.Error(ctx, "Failed to get OAuth client", "clientId", clientID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+
+	if client.Confidential && subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(hashClientSecret(secret))) != 1 {
+		logger.Warn(ctx, "OAuth client authentication failed", "clientId", clientID) // Use slog.WarnContext
+		return nil, errors.ErrOAuthInvalidClient
+	}
+
+	return client, nil
+}
+
+// validateRedirectURIs checks that redirect URIs are absolute, have no fragment and use https, a loopback
+// http address (native apps, RFC 8252 section 7.3) or a private-use scheme in reverse domain notation
+// (RFC 8252 section 7.1), which rules out schemes such as javascript: and data:.
+func validateRedirectURIs(uris []string) error {
+	for _, raw := range uris {
+		u, err := url.Parse(raw)
+		if err != nil || strings.Contains(raw, "#") || strings.ContainsAny(raw, " \t\r\n") {
+			return errors.ErrInvalidRedirectURI.WithData(map[string]interface{}{"redirect_uri": raw})
+		}
+
+		valid := false
+		switch u.Scheme {
+		case "https":
+			valid = u.Host != ""
+		case "http":
+			host := u.Hostname()
+			valid = host == "localhost" || host == "127.0.0.1" || host == "::1"
+		default:
+			valid = strings.Contains(u.Scheme, ".")
+		}
+		if !valid {
+			return errors.ErrInvalidRedirectURI.WithData(map[string]interface{}{"redirect_uri": raw})
+		}
+	}
+	return nil
+}
+
+// buildRedirectURI adds the non-empty parameters to the query of a registered redirect URI.
+func buildRedirectURI(redirectURI string, params map[string]string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := u.Query()
+	for name, value := range params {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// containsAll reports whether every scope is in granted.
+func containsAll(granted, scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeScopes returns the union of two scope lists, without duplicates and in the order of the scope list.
+func mergeScopes(a, b []string) []string {
+	merged := make([]string, 0, len(oauthScopes))
+	for _, scope := range oauthScopes {
+		if slices.Contains(a, scope.Name) || slices.Contains(b, scope.Name) {
+			merged = append(merged, scope.Name)
+		}
+	}
+	return merged
+}
+
+// generateClientSecret generates a new client secret in the format ocs_<secret>.
+func generateClientSecret() (string, error) {
+	secret, err := randomHex(oauthClientSecretBytes)
+	if err != nil {
+		return "", err
+	}
+	return oauthClientSecretMarker + secret, nil
+}
+
+// randomHex returns n random bytes as a hex string.
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashClientSecret hashes a client secret so that raw secrets are never stored.
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// oauthAuthorizationCodeKey returns the Redis key of an authorization code, which is stored hashed.
+func oauthAuthorizationCodeKey(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return fmt.Sprintf("oauth_code:%s", hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/pkg/jwt"
+	"github.com/go-backend-template/pkg/logger"
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscoveryDocument contains the fields of the OpenID Provider metadata used for login.
+// [Reference] https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type oidcDiscoveryDocument struct {
+	Issuer           string `json:"issuer"`
+	TokenEndpoint    string `json:"token_endpoint"`
+	UserinfoEndpoint string `json:"userinfo_endpoint"`
+	JWKSURI          string `json:"jwks_uri"`
+}
+
+// oidcProvider is a generic OpenID Connect provider (Google, Apple, Keycloak, Azure AD, Okta, ...).
+//
+// Two flows are supported:
+//   - Authorization code (with PKCE): the code is exchanged at the token endpoint and the returned ID token
+//     is verified. Requires client_type and a configured redirect URI.
+//   - ID token: a token obtained by a native SDK (e.g. Sign in with Apple, Google Sign-In for iOS) is verified
+//     directly. Its audience must be the client ID of client_type, or of any configured client if omitted.
+//
+// Endpoints are resolved from {issuer}/.well-known/openid-configuration on first use.
+type oidcProvider struct {
+	name       string
+	config     config.OAuthProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscoveryDocument
+	jwks      *jwt.JWKSClient
+}
+
+// newOIDCProvider creates a generic OpenID Connect provider.
+func newOIDCProvider(name string, cfg config.OAuthProviderConfig) *oidcProvider {
+	p := &oidcProvider{
+		name:       name,
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	if cfg.JWKSURL != "" {
+		p.jwks = jwt.NewJWKSClient(cfg.JWKSURL, jwt.DefaultJWKSCacheDuration)
+	}
+	return p
+}
+
+// Name returns the provider name.
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+// Authenticate exchanges the authorization code or verifies the ID token and returns the user identity.
+func (p *oidcProvider) Authenticate(ctx context.Context, req *dto.OAuthTokenRequest) (*OAuthIdentity, error) {
+	var (
+		idToken     string
+		accessToken string
+		audiences   []string
+		userinfoURL string
+		jwks        *jwt.JWKSClient
+	)
+
+	if req.Code != "" {
+		// 1a. Authorization code flow.
+		client, ok := p.config.Clients[req.ClientType]
+		if !ok {
+			return nil, errors.ErrInvalidClientType
+		}
+		if !slices.Contains(client.RedirectURLs, req.RedirectURI) {
+			logger.Warn(ctx, "Invalid redirect URI", "provider", p.name, "redirectURI", req.RedirectURI, "clientType", req.ClientType) // Use slog.WarnContext
+			return nil, errors.ErrInvalidRedirectURL
+		}
+
+		doc, keys, err := p.resolve(ctx, true) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to resolve OIDC provider metadata", "provider", p.name, "error", err) // Use slog.ErrorContext
+			return nil, fmt.Errorf("failed to resolve OIDC provider metadata: %w", err)
+		}
+
+		token, err := p.exchangeCode(ctx, doc.TokenEndpoint, client, req) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to exchange OAuth code for token", "provider", p.name, "error", err) // Use slog.ErrorContext
+			return nil, errors.ErrOAuthTokenExchange
+		}
+		idToken, _ = token.Extra("id_token").(string)
+		if idToken == "" {
+			logger.Error(ctx, "OAuth token response has no ID token", "provider", p.name) // Use slog.ErrorContext
+			return nil, errors.ErrOAuthTokenExchange
+		}
+
+		accessToken = token.AccessToken
+		audiences = []string{client.ClientID}
+		userinfoURL = doc.UserinfoEndpoint
+		jwks = keys
+	} else {
+		// 1b. ID token flow.
+		audiences = p.audiences(req.ClientType)
+		if len(audiences) == 0 {
+			return nil, errors.ErrInvalidClientType
+		}
+
+		_, keys, err := p.resolve(ctx, false) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to resolve OIDC provider metadata", "provider", p.name, "error", err) // Use slog.ErrorContext
+			return nil, fmt.Errorf("failed to resolve OIDC provider metadata: %w", err)
+		}
+		idToken = req.IdentityToken
+		jwks = keys
+	}
+
+	// 2. Verify signature, issuer, audience and expiry of the ID token.
+	claims, err := jwks.ValidateIDToken(ctx, idToken, p.config.Issuer, audiences)
+	if err != nil {
+		logger.Warn(ctx, "ID token validation failed", "provider", p.name, "error", err) // Use slog.WarnContext
+		return nil, errors.ErrInvalidIdentityToken
+	}
+	if claims.Subject == "" {
+		logger.Warn(ctx, "ID token has no subject", "provider", p.name) // Use slog.WarnContext
+		return nil, errors.ErrInvalidIdentityToken
+	}
+
+	// Prevent replay of tokens requested by another sign-in attempt.
+	if req.Nonce != "" && !matchNonce(claims.Nonce, req.Nonce) {
+		logger.Warn(ctx, "ID token nonce mismatch", "provider", p.name, "sub", claims.Subject) // Use slog.WarnContext
+		return nil, errors.ErrInvalidIdentityToken
+	}
+
+	// 3. Some providers only return the profile from the userinfo endpoint.
+	if claims.Email == "" && accessToken != "" && userinfoURL != "" {
+		if err := p.fillFromUserInfo(ctx, userinfoURL, accessToken, claims); err != nil {
+			logger.Warn(ctx, "Failed to fetch OIDC userinfo", "provider", p.name, "error", err) // Use slog.WarnContext
+		}
+	}
+
+	identity := &OAuthIdentity{
+		ProviderUID:    claims.Subject,
+		Email:          strings.ToLower(claims.Email),
+		EmailVerified:  bool(claims.EmailVerified),
+		IsPrivateEmail: bool(claims.IsPrivateEmail),
+		Name:           claims.Name,
+		AvatarURL:      claims.Picture,
+		Locale:         claims.Locale,
+	}
+	if identity.Name == "" {
+		identity.Name = joinName(claims.GivenName, claims.FamilyName)
+	}
+	// Apple only returns the user's name to the client on the first authorization, so the client forwards it.
+	if identity.Name == "" {
+		identity.Name = joinName(derefString(req.GivenName), derefString(req.FamilyName))
+	}
+
+	return identity, nil
+}
+
+// audiences returns the accepted ID token audiences for a client type, or of all clients if it is empty.
+func (p *oidcProvider) audiences(clientType string) []string {
+	if clientType != "" {
+		client, ok := p.config.Clients[clientType]
+		if !ok {
+			return nil
+		}
+		return []string{client.ClientID}
+	}
+
+	audiences := make([]string, 0, len(p.config.Clients))
+	for _, client := range p.config.Clients {
+		audiences = append(audiences, client.ClientID)
+	}
+	return audiences
+}
+
+// resolve returns the discovery document and the JWKS client, fetching the document on first use.
+// If jwks_url is configured and the token endpoint is not needed, discovery is skipped.
+func (p *oidcProvider) resolve(ctx context.Context, needDiscovery bool) (*oidcDiscoveryDocument, *jwt.JWKSClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery == nil && (needDiscovery || p.jwks == nil) {
+		doc, err := p.fetchDiscovery(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		p.discovery = doc
+		if p.jwks == nil {
+			p.jwks = jwt.NewJWKSClient(doc.JWKSURI, jwt.DefaultJWKSCacheDuration)
+		}
+	}
+	return p.discovery, p.jwks, nil
+}
+
+// fetchDiscovery downloads the OpenID Provider metadata of the issuer.
+func (p *oidcProvider) fetchDiscovery(ctx context.Context) (*oidcDiscoveryDocument, error) {
+	discoveryURL := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch discovery document: status %d", resp.StatusCode)
+	}
+
+	var doc oidcDiscoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+	if doc.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match configured issuer %q", doc.Issuer, p.config.Issuer)
+	}
+	if doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document is missing token_endpoint or jwks_uri")
+	}
+	return &doc, nil
+}
+
+// exchangeCode exchanges an authorization code for tokens, sending the PKCE code verifier if provided.
+func (p *oidcProvider) exchangeCode(ctx context.Context, tokenURL string, client config.OAuthClientConfig, req *dto.OAuthTokenRequest) (*oauth2.Token, error) {
+	oauthConfig := &oauth2.Config{
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+		RedirectURL:  req.RedirectURI,
+		Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if req.CodeVerifier != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("code_verifier", req.CodeVerifier))
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	return oauthConfig.Exchange(ctx, req.Code, opts...)
+}
+
+// fillFromUserInfo completes missing profile claims from the userinfo endpoint.
+func (p *oidcProvider) fillFromUserInfo(ctx context.Context, userinfoURL, accessToken string, claims *jwt.IDTokenClaims) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userinfoURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch userinfo: status %d", resp.StatusCode)
+	}
+
+	var info jwt.IDTokenClaims
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return fmt.Errorf("failed to decode userinfo: %w", err)
+	}
+	// The userinfo response must describe the same user as the ID token.
+	if info.Subject != claims.Subject {
+		return fmt.Errorf("userinfo subject does not match ID token subject")
+	}
+
+	claims.Email = info.Email
+	claims.EmailVerified = info.EmailVerified
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+	if claims.Picture == "" {
+		claims.Picture = info.Picture
+	}
+	if claims.Locale == "" {
+		claims.Locale = info.Locale
+	}
+	return nil
+}
+
+// matchNonce compares the nonce claim with the raw nonce, accepting both the raw value and its SHA-256 hex
+// digest (iOS clients usually pass the hashed nonce to Apple).
+func matchNonce(claim, nonce string) bool {
+	if claim == nonce {
+		return true
+	}
+	hash := sha256.Sum256([]byte(nonce))
+	return claim == hex.EncodeToString(hash[:])
+}
+
+// joinName joins the non-empty parts of a name with spaces.
+func joinName(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// derefString returns the value of a string pointer, or an empty string if it is nil.
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
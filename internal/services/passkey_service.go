@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/webauthn"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// PasskeyService defines the interface for passkey (WebAuthn) registration and login.
+type PasskeyService interface {
+	// BeginRegistration starts a registration ceremony and returns the options for navigator.credentials.create().
+	BeginRegistration(ctx context.Context, user *models.User) (*webauthn.CredentialCreationOptions, error)
+	// FinishRegistration verifies the registration response and stores the new passkey.
+	FinishRegistration(ctx context.Context, userID uint, req *dto.FinishPasskeyRegistrationRequest) (*models.Passkey, error)
+	// BeginLogin starts an authentication ceremony and returns the options for navigator.credentials.get().
+	BeginLogin(ctx context.Context) (*webauthn.CredentialRequestOptions, error)
+	// FinishLogin verifies the authentication response and returns the user it belongs to.
+	FinishLogin(ctx context.Context, credential *webauthn.AssertionResponse) (uint, bool, error) // Returns (userID, userVerified, error)
+	ListPasskeys(ctx context.Context, userID uint) ([]models.Passkey, error)
+	DeletePasskey(ctx context.Context, userID, passkeyID uint) error
+}
+
+// passkeyService is the implementation of PasskeyService.
+type passkeyService struct {
+	rp          *webauthn.RelyingParty
+	redis       *redis.Client
+	passkeyRepo repositories.PasskeyRepository
+}
+
+// NewPasskeyService creates a new instance of PasskeyService.
+func NewPasskeyService(config *config.Config, redisClient *redis.Client, passkeyRepo repositories.PasskeyRepository) PasskeyService {
+	return &passkeyService{
+		rp:          webauthn.NewRelyingParty(config.WebAuthn.RPID, config.WebAuthn.RPName, config.WebAuthn.Origins),
+		redis:       redisClient,
+		passkeyRepo: passkeyRepo,
+	}
+}
+
+// BeginRegistration starts a registration ceremony for the user.
+func (s *passkeyService) BeginRegistration(ctx context.Context, user *models.User) (*webauthn.CredentialCreationOptions, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate passkey challenge: %w", err)
+	}
+
+	// Store the challenge in Redis until the ceremony times out (one pending registration per user).
+	key := fmt.Sprintf("passkey_registration:%d", user.ID)
+	if err := s.redis.Set(ctx, key, challenge, webauthn.DefaultTimeout).Err(); err != nil {
+		logger.Error(ctx, "Failed to store passkey challenge in Redis", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to store passkey challenge: %w", err)
+	}
+
+	// Exclude the user's existing passkeys so the same authenticator is not registered twice.
+	passkeys, err := s.passkeyRepo.ListPasskeys(ctx, user.ID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list passkeys", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		exclude = append(exclude, webauthn.CredentialDescriptor{Type: "public-key", ID: passkey.CredentialID})
+	}
+
+	// Label the account in the authenticator with the email (or phone) if available.
+	accountName := user.Name
+	if user.Email != nil && *user.Email != "" {
+		accountName = *user.Email
+	} else if user.Phone != nil && *user.Phone != "" {
+		accountName = *user.Phone
+	}
+	displayName := user.Name
+	if displayName == "" {
+		displayName = accountName
+	}
+
+	userEntity := webauthn.UserEntity{
+		ID:          userHandle(user.ID),
+		Name:        accountName,
+		DisplayName: displayName,
+	}
+
+	logger.Info(ctx, "Passkey registration started", "userId", user.ID) // Use slog.InfoContext
+	return s.rp.CreationOptions(challenge, userEntity, exclude), nil
+}
+
+// FinishRegistration verifies the registration response and stores the new passkey.
+func (s *passkeyService) FinishRegistration(ctx context.Context, userID uint, req *dto.FinishPasskeyRegistrationRequest) (*models.Passkey, error) {
+	// Consume the challenge (one-time use).
+	key := fmt.Sprintf("passkey_registration:%d", userID)
+	challenge, err := s.redis.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			logger.Warn(ctx, "Passkey registration challenge not found or expired", "userId", userID) // Use slog.WarnContext
+			return nil, errors.ErrPasskeyVerificationFailed
+		}
+		logger.Error(ctx, "Failed to get passkey challenge from Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get passkey challenge: %w", err)
+	}
+
+	credential, err := s.rp.VerifyRegistration(&req.Credential, challenge)
+	if err != nil {
+		logger.Warn(ctx, "Passkey registration verification failed", "userId", userID, "error", err) // Use slog.WarnContext
+		return nil, errors.ErrPasskeyVerificationFailed
+	}
+
+	// Check if the credential is already registered.
+	credentialID := webauthn.EncodeBase64URL(credential.ID)
+	if _, err := s.passkeyRepo.GetPasskeyByCredentialID(ctx, credentialID); err == nil { // Pass context
+		return nil, errors.ErrPasskeyAlreadyRegistered
+	} else if err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to check existing passkey", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Passkey"
+	}
+
+	passkey := &models.Passkey{
+		UserID:         userID,
+		CredentialID:   credentialID,
+		PublicKey:      credential.PublicKey,
+		SignCount:      credential.SignCount,
+		AAGUID:         formatAAGUID(credential.AAGUID),
+		Transports:     strings.Join(credential.Transports, ","),
+		BackupEligible: credential.BackupEligible,
+		Name:           name,
+	}
+	if err := s.passkeyRepo.CreatePasskey(ctx, passkey); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create passkey", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to create passkey: %w", err)
+	}
+
+	logger.Info(ctx, "Passkey registered", "userId", userID, "passkeyId", passkey.ID) // Use slog.InfoContext
+	return passkey, nil
+}
+
+// BeginLogin starts an authentication ceremony with a discoverable credential.
+func (s *passkeyService) BeginLogin(ctx context.Context) (*webauthn.CredentialRequestOptions, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate passkey challenge: %w", err)
+	}
+
+	// The user is not known yet, so the challenge itself is the key.
+	key := fmt.Sprintf("passkey_login:%s", challenge)
+	if err := s.redis.Set(ctx, key, 1, webauthn.DefaultTimeout).Err(); err != nil {
+		logger.Error(ctx, "Failed to store passkey challenge in Redis", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to store passkey challenge: %w", err)
+	}
+
+	return s.rp.RequestOptions(challenge), nil
+}
+
+// FinishLogin verifies the authentication response and returns the user it belongs to.
+func (s *passkeyService) FinishLogin(ctx context.Context, credential *webauthn.AssertionResponse) (uint, bool, error) {
+	// Consume the challenge the client signed (one-time use).
+	challenge, err := credential.Challenge()
+	if err != nil {
+		return 0, false, errors.ErrPasskeyVerificationFailed
+	}
+	key := fmt.Sprintf("passkey_login:%s", challenge)
+	deleted, err := s.redis.Del(ctx, key).Result()
+	if err != nil {
+		logger.Error(ctx, "Failed to consume passkey challenge in Redis", "error", err) // Use slog.ErrorContext
+		return 0, false, fmt.Errorf("failed to consume passkey challenge: %w", err)
+	}
+	if deleted == 0 {
+		logger.Warn(ctx, "Passkey login challenge not found or expired") // Use slog.WarnContext
+		return 0, false, errors.ErrPasskeyVerificationFailed
+	}
+
+	// Look up the passkey.
+	credentialID, err := credential.CredentialID()
+	if err != nil {
+		return 0, false, errors.ErrPasskeyVerificationFailed
+	}
+	passkey, err := s.passkeyRepo.GetPasskeyByCredentialID(ctx, credentialID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			logger.Warn(ctx, "Unknown passkey used for login", "credentialId", credentialID) // Use slog.WarnContext
+			return 0, false, errors.ErrPasskeyVerificationFailed
+		}
+		logger.Error(ctx, "Failed to get passkey", "credentialId", credentialID, "error", err) // Use slog.ErrorContext
+		return 0, false, fmt.Errorf("database error: %w", err)
+	}
+
+	// The user handle, if returned, must belong to the passkey owner.
+	if credential.Response.UserHandle != "" && credential.Response.UserHandle != userHandle(passkey.UserID) {
+		logger.Warn(ctx, "Passkey user handle mismatch", "userId", passkey.UserID) // Use slog.WarnContext
+		return 0, false, errors.ErrPasskeyVerificationFailed
+	}
+
+	result, err := s.rp.VerifyAssertion(credential, challenge, passkey.PublicKey, passkey.SignCount)
+	if err != nil {
+		logger.Warn(ctx, "Passkey login verification failed", "userId", passkey.UserID, "passkeyId", passkey.ID, "error", err) // Use slog.WarnContext
+		return 0, false, errors.ErrPasskeyVerificationFailed
+	}
+
+	// Record usage.
+	updates := map[string]interface{}{
+		"sign_count":   result.SignCount,
+		"last_used_at": time.Now(),
+	}
+	if err := s.passkeyRepo.UpdatePasskey(ctx, passkey.ID, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update passkey usage", "passkeyId", passkey.ID, "error", err) // Use slog.ErrorContext
+		return 0, false, fmt.Errorf("failed to update passkey: %w", err)
+	}
+
+	return passkey.UserID, result.UserVerified, nil
+}
+
+// ListPasskeys lists the user's passkeys.
+func (s *passkeyService) ListPasskeys(ctx context.Context, userID uint) ([]models.Passkey, error) {
+	passkeys, err := s.passkeyRepo.ListPasskeys(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list passkeys", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	return passkeys, nil
+}
+
+// DeletePasskey deletes one of the user's passkeys.
+func (s *passkeyService) DeletePasskey(ctx context.Context, userID, passkeyID uint) error {
+	if err := s.passkeyRepo.DeletePasskey(ctx, userID, passkeyID); err != nil { // Pass context
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrPasskeyNotFound
+		}
+		logger.Error(ctx, "Failed to delete passkey", "userId", userID, "passkeyId", passkeyID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	logger.Info(ctx, "Passkey deleted", "userId", userID, "passkeyId", passkeyID) // Use slog.InfoContext
+	return nil
+}
+
+// userHandle returns the WebAuthn user handle (base64url) for a user ID.
+func userHandle(userID uint) string {
+	return webauthn.EncodeBase64URL([]byte(strconv.FormatUint(uint64(userID), 10)))
+}
+
+// formatAAGUID formats an authenticator AAGUID as a UUID string.
+func formatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 {
+		return ""
+	}
+	h := hex.EncodeToString(aaguid)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
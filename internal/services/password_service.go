@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/go-backend-template/pkg/password"
+)
+
+// Password policy violations, returned in the data of errors.ErrPasswordTooWeak.
+const (
+	PasswordViolationMinLength = "min_length"
+	PasswordViolationMaxLength = "max_length"
+	PasswordViolationUppercase = "uppercase"
+	PasswordViolationLowercase = "lowercase"
+	PasswordViolationDigit     = "digit"
+	PasswordViolationSymbol    = "symbol"
+	PasswordViolationBreached  = "breached"
+)
+
+// PasswordService defines the interface for the password policy and password hashing.
+//
+// New passwords are hashed with the configured algorithm (argon2id by default). Hashes created with another
+// algorithm or other parameters, such as the bcrypt hashes of existing users, keep working and are
+// replaced with a hash using the current configuration on the next successful password login.
+type PasswordService interface {
+	// CheckPolicy returns errors.ErrPasswordTooWeak, listing the violated rules, if a new password does not
+	// meet the policy.
+	CheckPolicy(ctx context.Context, plain string) error
+	// HashPassword hashes a new password. It does not check the policy.
+	HashPassword(ctx context.Context, plain string) (string, error)
+	// VerifyPassword checks a password against a stored hash. needsRehash reports whether the hash should be
+	// replaced after a successful verification.
+	VerifyPassword(ctx context.Context, hash, plain string) (match bool, needsRehash bool)
+}
+
+// passwordService is the implementation of PasswordService.
+type passwordService struct {
+	hasher           *password.Hasher
+	minLength        int
+	maxLength        int
+	requireUppercase bool
+	requireLowercase bool
+	requireDigit     bool
+	requireSymbol    bool
+	breached         map[string]struct{}
+}
+
+// NewPasswordService creates a new instance of PasswordService and loads the breached password list.
+func NewPasswordService(config *config.Config) (PasswordService, error) {
+	cfg := config.Password
+	s := &passwordService{
+		minLength:        8,
+		maxLength:        128,
+		requireUppercase: cfg.RequireUppercase,
+		requireLowercase: cfg.RequireLowercase,
+		requireDigit:     cfg.RequireDigit,
+		requireSymbol:    cfg.RequireSymbol,
+	}
+	if cfg.MinLength > 0 {
+		s.minLength = cfg.MinLength
+	}
+	if cfg.MaxLength > 0 {
+		s.maxLength = cfg.MaxLength
+	}
+	if s.minLength > s.maxLength {
+		return nil, fmt.Errorf("password min_length %d exceeds max_length %d", s.minLength, s.maxLength)
+	}
+
+	params := password.DefaultParams()
+	if cfg.Hash.Algorithm != "" {
+		params.Algorithm = cfg.Hash.Algorithm
+	}
+	if cfg.Hash.Memory > 0 {
+		params.Memory = cfg.Hash.Memory
+	}
+	if cfg.Hash.Iterations > 0 {
+		params.Iterations = cfg.Hash.Iterations
+	}
+	if cfg.Hash.Parallelism > 0 {
+		params.Parallelism = cfg.Hash.Parallelism
+	}
+	if cfg.Hash.BcryptCost > 0 {
+		params.BcryptCost = cfg.Hash.BcryptCost
+	}
+	hasher, err := password.NewHasher(params)
+	if err != nil {
+		return nil, err
+	}
+	s.hasher = hasher
+
+	if cfg.BreachedListFile != "" {
+		breached, err := loadBreachedPasswords(cfg.BreachedListFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load breached password list: %w", err)
+		}
+		s.breached = breached
+	}
+
+	return s, nil
+}
+
+// loadBreachedPasswords reads a breached password list with one password per line.
+func loadBreachedPasswords(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			breached[line] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return breached, nil
+}
+
+// CheckPolicy checks a new password against the policy.
+func (s *passwordService) CheckPolicy(ctx context.Context, plain string) error {
+	var violations []string
+
+	length := utf8.RuneCountInString(plain)
+	if length < s.minLength {
+		violations = append(violations, PasswordViolationMinLength)
+	}
+	if length > s.maxLength {
+		violations = append(violations, PasswordViolationMaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range plain {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if s.requireUppercase && !hasUpper {
+		violations = append(violations, PasswordViolationUppercase)
+	}
+	if s.requireLowercase && !hasLower {
+		violations = append(violations, PasswordViolationLowercase)
+	}
+	if s.requireDigit && !hasDigit {
+		violations = append(violations, PasswordViolationDigit)
+	}
+	if s.requireSymbol && !hasSymbol {
+		violations = append(violations, PasswordViolationSymbol)
+	}
+
+	if _, ok := s.breached[plain]; ok {
+		violations = append(violations, PasswordViolationBreached)
+	}
+
+	if len(violations) > 0 {
+		logger.Debug(ctx, "Password rejected by password policy", "violations", violations) // Use slog.DebugContext
+		return errors.ErrPasswordTooWeak.WithData(map[string]interface{}{
+			"violations": violations,
+			"min_length": s.minLength,
+			"max_length": s.maxLength,
+		})
+	}
+	return nil
+}
+
+// HashPassword hashes a new password with the current hash configuration.
+func (s *passwordService) HashPassword(ctx context.Context, plain string) (string, error) {
+	hash, err := s.hasher.Hash(plain)
+	if err != nil {
+		logger.Error(ctx, "Failed to hash password", "error", err) // Use slog.ErrorContext
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hash, nil
+}
+
+// VerifyPassword checks a password against a stored hash.
+func (s *passwordService) VerifyPassword(ctx context.Context, hash, plain string) (bool, bool) {
+	match, needsRehash, err := s.hasher.Verify(plain, hash)
+	if err != nil {
+		logger.Error(ctx, "Failed to verify password hash", "error", err) // Use slog.ErrorContext
+		return false, false
+	}
+	return match, needsRehash
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/internal/search"
+	"github.com/go-backend-template/pkg/logger"
+)
+
+// ProductSearchService runs product searches on the search index and keeps the index in sync with the database.
+type ProductSearchService interface {
+	// Search returns the IDs of the products matching a search, most relevant first, and whether more products
+	// matched than the hits returned.
+	Search(ctx context.Context, text string) ([]uint, bool, error)
+	// IndexProduct indexes a product after it has been created or updated.
+	IndexProduct(ctx context.Context, id uint)
+	// IndexCategoryProducts reindexes the products of a category after the category has been renamed or deleted.
+	IndexCategoryProducts(ctx context.Context, categoryID uint)
+	// RemoveProduct removes a product from the index after it has been deleted.
+	RemoveProduct(ctx context.Context, id uint)
+	// Reindex rebuilds the index from the database and returns the number of indexed products.
+	Reindex(ctx context.Context) (int, error)
+}
+
+// productSearchService is the implementation of ProductSearchService.
+type productSearchService struct {
+	searchIndex search.SearchIndex
+	productRepo repositories.ProductRepository
+	maxHits     int
+}
+
+// reindexBatchSize is the number of products loaded and indexed at once by Reindex and IndexCategoryProducts.
+const reindexBatchSize = 500
+
+// NewProductSearchService creates a new instance of ProductSearchService.
+func NewProductSearchService(config *config.Config, searchIndex search.SearchIndex, productRepo repositories.ProductRepository) ProductSearchService {
+	s := &productSearchService{
+		searchIndex: searchIndex,
+		productRepo: productRepo,
+		maxHits:     1000,
+	}
+	if config.Search.MaxHits > 0 {
+		s.maxHits = config.Search.MaxHits
+	}
+	return s
+}
+
+// Search returns the IDs of the products matching a search, at most maxHits of them. The lists restrict their
+// queries to the hits, so a truncated search must be reported: one more hit is queried to detect it.
+func (s *productSearchService) Search(ctx context.Context, text string) ([]uint, bool, error) {
+	hits, err := s.searchIndex.Query(ctx, search.Query{Text: text, Limit: s.maxHits + 1})
+	if err != nil {
+		logger.Error(ctx, "Failed to query search index", "search", text, "error", err) // Use slog.ErrorContext
+		return nil, false, fmt.Errorf("failed to search products: %w", err)
+	}
+	if len(hits) > s.maxHits {
+		logger.Debug(ctx, "Search truncated", "search", text, "maxHits", s.maxHits) // Use slog.DebugContext
+		return hits[:s.maxHits], true, nil
+	}
+	return hits, false, nil
+}
+
+// IndexProduct indexes a product from its current data. The write to the database has already succeeded, so
+// failures are only logged; the next reindex fixes the index.
+func (s *productSearchService) IndexProduct(ctx context.Context, id uint) {
+	product, err := s.productRepo.GetProduct(ctx, id) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to load product for search index", "productId", id, "error", err) // Use slog.ErrorContext
+		return
+	}
+	if err := s.searchIndex.Index(ctx, search.ProductDocument(product)); err != nil {
+		logger.Error(ctx, "Failed to index product", "productId", id, "error", err) // Use slog.ErrorContext
+	}
+}
+
+// IndexCategoryProducts indexes the products of a category from their current data, in batches. Category names
+// are part of the indexed text of products. Failures are only logged, as in IndexProduct.
+func (s *productSearchService) IndexCategoryProducts(ctx context.Context, categoryID uint) {
+	lastID := uint(0)
+	for {
+		products, err := s.productRepo.ListCategoryProductsForIndex(ctx, categoryID, lastID, reindexBatchSize) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to load category products for search index", "categoryId", categoryID, "error", err) // Use slog.ErrorContext
+			return
+		}
+		if len(products) == 0 {
+			return
+		}
+
+		docs := make([]search.Document, 0, len(products))
+		for i := range products {
+			docs = append(docs, search.ProductDocument(&products[i]))
+		}
+		if err := s.searchIndex.Index(ctx, docs...); err != nil {
+			logger.Error(ctx, "Failed to index category products", "categoryId", categoryID, "error", err) // Use slog.ErrorContext
+			return
+		}
+		lastID = products[len(products)-1].ID
+	}
+}
+
+// RemoveProduct removes a product from the index. Failures are only logged, as in IndexProduct.
+func (s *productSearchService) RemoveProduct(ctx context.Context, id uint) {
+	if err := s.searchIndex.Delete(ctx, id); err != nil {
+		logger.Error(ctx, "Failed to remove product from search index", "productId", id, "error", err) // Use slog.ErrorContext
+	}
+}
+
+// Reindex clears the index and indexes all products, in batches. The embedded index is saved to its
+// snapshot file at the end, so that running servers pick it up.
+func (s *productSearchService) Reindex(ctx context.Context) (int, error) {
+	if err := s.searchIndex.Clear(ctx); err != nil {
+		return 0, fmt.Errorf("failed to clear search index: %w", err)
+	}
+
+	indexed := 0
+	lastID := uint(0)
+	for {
+		products, err := s.productRepo.ListProductsForIndex(ctx, lastID, reindexBatchSize) // Pass context
+		if err != nil {
+			return indexed, fmt.Errorf("failed to load products: %w", err)
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		docs := make([]search.Document, 0, len(products))
+		for i := range products {
+			docs = append(docs, search.ProductDocument(&products[i]))
+		}
+		if err := s.searchIndex.Index(ctx, docs...); err != nil {
+			return indexed, fmt.Errorf("failed to index products: %w", err)
+		}
+		indexed += len(products)
+		lastID = products[len(products)-1].ID
+	}
+
+	if memoryIndex, ok := s.searchIndex.(*search.MemoryIndex); ok {
+		if err := memoryIndex.Sync(); err != nil {
+			return indexed, fmt.Errorf("failed to save search index: %w", err)
+		}
+	}
+	return indexed, nil
+}
@@ -3,7 +3,9 @@ package services
 import (
 	"context" // Added for context
 	"fmt"
+	"sort"
 
+	"github.com/go-backend-template/internal/dto"
 	"github.com/go-backend-template/internal/errors"
 	"github.com/go-backend-template/internal/models"
 	"github.com/go-backend-template/internal/repositories"
@@ -23,27 +25,47 @@ type ProductService interface {
 	CreateProduct(ctx context.Context, product *models.Product, images []models.ProductImage, categoryIDs []uint) (uint, error)
 	UpdateProduct(ctx context.Context, id uint, updates map[string]interface{}, images []models.ProductImage, categoryIDs []uint) error
 	DeleteProduct(ctx context.Context, id uint) error
+
+	// Facets
+	GetProductFacets(ctx context.Context, params *query_params.QueryParams, facets []string) (dto.ProductFacetsDTO, error)
 }
 
+// Product facets
+const (
+	ProductFacetCategories  = "categories"
+	ProductFacetBarcodeType = "barcode_type"
+)
+
 // productService is the implementation of ProductService.
 type productService struct {
-	productRepo  repositories.ProductRepository
-	categoryRepo repositories.CategoryRepository
+	productRepo   repositories.ProductRepository
+	categoryRepo  repositories.CategoryRepository
+	searchService ProductSearchService
 }
 
 // NewProductService creates a new instance of ProductService.
-func NewProductService(productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository) ProductService {
+func NewProductService(productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository, searchService ProductSearchService) ProductService {
 	return &productService{
-		productRepo:  productRepo,
-		categoryRepo: categoryRepo,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		searchService: searchService,
 	}
 }
 
 // ListProducts retrieves a list of products.
 func (s *productService) ListProducts(ctx context.Context, params *query_params.QueryParams) ([]models.Product, *response.Pagination, error) {
+	// Find the products matching the search in the search index.
+	searchHits, truncated, err := s.findSearchHits(ctx, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Call the repository layer to get the list of products.
-	productList, total, err := s.productRepo.ListProducts(ctx, params) // Pass context
+	productList, total, nextCursor, err := s.productRepo.ListProducts(ctx, params, searchHits) // Pass context
 	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
 		logger.Error(ctx, "Failed to list products", "error", err) // Use slog.ErrorContext
 		return nil, nil, fmt.Errorf("failed to list products: %w", err)
 	}
@@ -59,6 +81,8 @@ func (s *productService) ListProducts(ctx context.Context, params *query_params.
 		PageSize:    params.Limit,
 		CurrentPage: params.Page,
 		TotalPages:  (int(total) + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
+		Truncated:   truncated,
 	}
 
 	return productList, pagination, nil
@@ -107,6 +131,9 @@ func (s *productService) CreateProduct(ctx context.Context, product *models.Prod
 		return 0, fmt.Errorf("failed to create product: %w", err)
 	}
 
+	// Add the product to the search index.
+	s.searchService.IndexProduct(ctx, product.ID)
+
 	return product.ID, nil
 }
 
@@ -144,6 +171,9 @@ func (s *productService) UpdateProduct(ctx context.Context, id uint, updates map
 		return fmt.Errorf("failed to update product: %w", err)
 	}
 
+	// Update the product in the search index.
+	s.searchService.IndexProduct(ctx, id)
+
 	return nil
 }
 
@@ -164,5 +194,103 @@ func (s *productService) DeleteProduct(ctx context.Context, id uint) error {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
+	// Remove the product from the search index.
+	s.searchService.RemoveProduct(ctx, id)
+
 	return nil
 }
+
+// GetProductFacets counts the products matching the search and filters of a product list by each of the
+// requested facets. Values are ordered by count, most products first.
+func (s *productService) GetProductFacets(ctx context.Context, params *query_params.QueryParams, facets []string) (dto.ProductFacetsDTO, error) {
+	for _, facet := range facets {
+		if facet != ProductFacetCategories && facet != ProductFacetBarcodeType {
+			return nil, errors.ErrInvalidFacet
+		}
+	}
+
+	// Find the products matching the search in the search index. A truncated search is reported by the list.
+	searchHits, _, err := s.findSearchHits(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(dto.ProductFacetsDTO, len(facets))
+	for _, facet := range facets {
+		if _, ok := result[facet]; ok {
+			continue
+		}
+		switch facet {
+		case ProductFacetCategories:
+			values, err := s.getCategoryFacet(ctx, params, searchHits)
+			if err != nil {
+				return nil, err
+			}
+			result[facet] = values
+		case ProductFacetBarcodeType:
+			counts, err := s.productRepo.CountProductsByBarcodeType(ctx, params, searchHits) // Pass context
+			if err != nil {
+				logger.Error(ctx, "Failed to count products by barcode type", "error", err) // Use slog.ErrorContext
+				return nil, fmt.Errorf("failed to count products by barcode type: %w", err)
+			}
+			values := make([]dto.FacetValueDTO, 0, len(counts))
+			for barcodeType, count := range counts {
+				values = append(values, dto.FacetValueDTO{Value: barcodeType, Count: count})
+			}
+			sort.Slice(values, func(i, j int) bool {
+				if values[i].Count != values[j].Count {
+					return values[i].Count > values[j].Count
+				}
+				return values[i].Value.(string) < values[j].Value.(string)
+			})
+			result[facet] = values
+		}
+	}
+	return result, nil
+}
+
+// getCategoryFacet counts the matching products in each category, with the categories' names.
+func (s *productService) getCategoryFacet(ctx context.Context, params *query_params.QueryParams, searchHits []uint) ([]dto.FacetValueDTO, error) {
+	counts, err := s.productRepo.CountProductsByCategory(ctx, params, searchHits) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to count products by category", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to count products by category: %w", err)
+	}
+
+	categoryIDs := make([]uint, 0, len(counts))
+	for id := range counts {
+		categoryIDs = append(categoryIDs, id)
+	}
+	categories, err := s.categoryRepo.GetCategoriesByIDs(ctx, categoryIDs) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to get categories for facets", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	values := make([]dto.FacetValueDTO, 0, len(categories))
+	for _, category := range categories {
+		values = append(values, dto.FacetValueDTO{
+			Value:    category.ID,
+			Name:     category.Name,
+			NameZH:   category.NameZH,
+			ParentID: category.ParentID,
+			Count:    counts[category.ID],
+		})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value.(uint) < values[j].Value.(uint)
+	})
+	return values, nil
+}
+
+// findSearchHits returns the products matching the search of a product list, or nil if it has no search,
+// and whether the search was truncated.
+func (s *productService) findSearchHits(ctx context.Context, params *query_params.QueryParams) ([]uint, bool, error) {
+	if params.Search == "" {
+		return nil, false, nil
+	}
+	return s.searchService.Search(ctx, params.Search) // Pass context
+}
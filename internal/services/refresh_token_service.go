@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// RefreshTokenService defines the interface for the server-side refresh token store.
+//
+// Refresh tokens are grouped into families: every token obtained by rotating a refresh token
+// belongs to the same family as the token it replaced. Each token can be used exactly once;
+// presenting an already rotated token is treated as theft and revokes the whole family.
+type RefreshTokenService interface {
+	// StoreRefreshToken records a newly issued refresh token (hashed) as part of a token family.
+	StoreRefreshToken(ctx context.Context, userID uint, familyID, tokenID, token string, ttl time.Duration) error
+	// RotateRefreshToken consumes a refresh token and returns the family it belongs to.
+	RotateRefreshToken(ctx context.Context, userID uint, tokenID, token string) (string, error)
+	// RevokeFamily revokes every refresh token in a token family.
+	RevokeFamily(ctx context.Context, familyID string) error
+}
+
+// refreshTokenService is the Redis implementation of the RefreshTokenService.
+type refreshTokenService struct {
+	redis *redis.Client
+}
+
+// NewRefreshTokenService creates a new instance of the refresh token service.
+func NewRefreshTokenService(redisClient *redis.Client) RefreshTokenService {
+	return &refreshTokenService{
+		redis: redisClient,
+	}
+}
+
+// StoreRefreshToken records a newly issued refresh token (hashed) as part of a token family.
+func (s *refreshTokenService) StoreRefreshToken(ctx context.Context, userID uint, familyID, tokenID, token string, ttl time.Duration) error {
+	tokenKey := refreshTokenKey(tokenID)
+	familyKey := refreshTokenFamilyKey(familyID)
+
+	pipe := s.redis.TxPipeline()
+	pipe.HSet(ctx, tokenKey, map[string]interface{}{
+		"user_id":    userID,
+		"family_id":  familyID,
+		"token_hash": hashRefreshToken(token),
+	})
+	pipe.Expire(ctx, tokenKey, ttl)
+	// The family stays active as long as its most recent token is valid.
+	pipe.Set(ctx, familyKey, userID, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error(ctx, "Failed to store refresh token in Redis", "userId", userID, "familyId", familyID, "error", err)
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken consumes a refresh token and returns the family it belongs to.
+func (s *refreshTokenService) RotateRefreshToken(ctx context.Context, userID uint, tokenID, token string) (string, error) {
+	tokenKey := refreshTokenKey(tokenID)
+
+	// 1. Load the stored token record.
+	record, err := s.redis.HGetAll(ctx, tokenKey).Result()
+	if err != nil {
+		logger.Error(ctx, "Failed to get refresh token from Redis", "userId", userID, "error", err)
+		return "", fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if len(record) == 0 {
+		logger.Warn(ctx, "Refresh token not found or expired", "userId", userID)
+		return "", errors.ErrInvalidToken
+	}
+
+	// 2. Make sure the presented token matches the stored one.
+	familyID := record["family_id"]
+	storedUserID, _ := strconv.ParseUint(record["user_id"], 10, 64)
+	if uint(storedUserID) != userID || subtle.ConstantTimeCompare([]byte(record["token_hash"]), []byte(hashRefreshToken(token))) != 1 {
+		logger.Warn(ctx, "Refresh token does not match stored record", "userId", userID, "familyId", familyID)
+		return "", errors.ErrInvalidToken
+	}
+
+	// 3. Make sure the family has not been revoked.
+	exists, err := s.redis.Exists(ctx, refreshTokenFamilyKey(familyID)).Result()
+	if err != nil {
+		logger.Error(ctx, "Failed to check refresh token family", "userId", userID, "familyId", familyID, "error", err)
+		return "", fmt.Errorf("failed to check refresh token family: %w", err)
+	}
+	if exists == 0 {
+		logger.Warn(ctx, "Refresh token family has been revoked", "userId", userID, "familyId", familyID)
+		return "", errors.ErrInvalidToken
+	}
+
+	// 4. Mark the token as rotated. HSETNX is atomic, so only the first use succeeds.
+	rotated, err := s.redis.HSetNX(ctx, tokenKey, "rotated_at", time.Now().Unix()).Result()
+	if err != nil {
+		logger.Error(ctx, "Failed to mark refresh token as rotated", "userId", userID, "familyId", familyID, "error", err)
+		return "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// A rotated token was presented again: assume it was stolen and revoke the whole family.
+		logger.Warn(ctx, "Refresh token reuse detected, revoking token family", "userId", userID, "familyId", familyID)
+		if err := s.RevokeFamily(ctx, familyID); err != nil {
+			return "", err
+		}
+		return "", errors.ErrRefreshTokenReused
+	}
+
+	return familyID, nil
+}
+
+// RevokeFamily revokes every refresh token in a token family.
+func (s *refreshTokenService) RevokeFamily(ctx context.Context, familyID string) error {
+	if err := s.redis.Del(ctx, refreshTokenFamilyKey(familyID)).Err(); err != nil {
+		logger.Error(ctx, "Failed to revoke refresh token family", "familyId", familyID, "error", err)
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	logger.Info(ctx, "Refresh token family revoked", "familyId", familyID)
+	return nil
+}
+
+// refreshTokenKey returns the Redis key of a refresh token record.
+func refreshTokenKey(tokenID string) string {
+	return fmt.Sprintf("refresh_token:%s", tokenID)
+}
+
+// refreshTokenFamilyKey returns the Redis key marking a refresh token family as active.
+func refreshTokenFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_token_family:%s", familyID)
+}
+
+// hashRefreshToken hashes a refresh token so that raw tokens are never stored.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Permissions. Every admin route requires one of them (see middlewares.RequirePermission).
+const (
+	PermissionUsersRead          = "users:read"
+	PermissionUsersCreate        = "users:create"
+	PermissionUsersUpdate        = "users:update"
+	PermissionUsersDelete        = "users:delete"
+	PermissionUsersBan           = "users:ban"
+	PermissionUsersImpersonate   = "users:impersonate"
+	PermissionProductsRead       = "products:read"
+	PermissionProductsCreate     = "products:create"
+	PermissionProductsUpdate     = "products:update"
+	PermissionProductsDelete     = "products:delete"
+	PermissionSecurityRead       = "security:read"
+	PermissionAPIKeysRevoke      = "api_keys:revoke"
+	PermissionRolesRead          = "roles:read"
+	PermissionRolesManage        = "roles:manage"
+	PermissionOAuthClientsRead   = "oauth_clients:read"
+	PermissionOAuthClientsManage = "oauth_clients:manage"
+)
+
+// SystemRoleAdmin is the built-in role that has every permission.
+// On migration it is assigned to the users whose legacy users.role column is "admin".
+const SystemRoleAdmin = "admin"
+
+// permissionCatalog lists every permission with its description. It is synced to the database on migration.
+var permissionCatalog = []models.Permission{
+	{Name: PermissionUsersRead, Description: "List and view users and their API keys"},
+	{Name: PermissionUsersCreate, Description: "Create users"},
+	{Name: PermissionUsersUpdate, Description: "Update users"},
+	{Name: PermissionUsersDelete, Description: "Delete and restore users"},
+	{Name: PermissionUsersBan, Description: "Ban and unban users"},
+	{Name: PermissionUsersImpersonate, Description: "Act as a user with a short-lived access token"},
+	{Name: PermissionProductsRead, Description: "List and view products"},
+	{Name: PermissionProductsCreate, Description: "Create products"},
+	{Name: PermissionProductsUpdate, Description: "Update products"},
+	{Name: PermissionProductsDelete, Description: "Delete products"},
+	{Name: PermissionSecurityRead, Description: "View login lockouts, impersonation events and login history"},
+	{Name: PermissionAPIKeysRevoke, Description: "Revoke any user's API keys"},
+	{Name: PermissionRolesRead, Description: "View roles, permissions and role assignments"},
+	{Name: PermissionRolesManage, Description: "Create, update and delete roles and assign them to users"},
+	{Name: PermissionOAuthClientsRead, Description: "View registered OAuth client applications"},
+	{Name: PermissionOAuthClientsManage, Description: "Register, update and delete OAuth client applications and rotate their secrets"},
+}
+
+// roleNamePattern restricts role names to lowercase identifiers.
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// RoleService defines the interface for role based access control of the admin API.
+// Users are assigned roles; a role grants a set of permissions; each admin route requires a permission.
+// Users without any role cannot access the admin API.
+type RoleService interface {
+	// SyncPermissions creates the permissions of the catalog, ensures the admin role has all of them,
+	// and assigns it to users whose legacy role is admin. It is run on migration.
+	SyncPermissions(ctx context.Context) error
+	// GetUserPermissions returns the names of the permissions granted to the user.
+	GetUserPermissions(ctx context.Context, userID uint) ([]string, error)
+	// ListPermissions lists all permissions.
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+
+	// ListRoles lists all roles.
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	// CreateRole creates a role. grantorPermissions are the permissions of the admin making the request,
+	// who cannot grant permissions they do not have.
+	CreateRole(ctx context.Context, req *dto.CreateRoleRequest, grantorPermissions []string) (*models.Role, error)
+	// UpdateRole updates a role's description and permissions.
+	UpdateRole(ctx context.Context, id uint, req *dto.UpdateRoleRequest, grantorPermissions []string) (*models.Role, error)
+	// DeleteRole deletes a role and removes it from all users.
+	DeleteRole(ctx context.Context, id uint, grantorPermissions []string) error
+
+	// ListUserRoles lists the roles assigned to a user.
+	ListUserRoles(ctx context.Context, userID uint) ([]models.Role, error)
+	// AssignRole assigns a role to a user.
+	AssignRole(ctx context.Context, userID, roleID, grantorID uint, grantorPermissions []string) error
+	// RemoveRole removes a role from a user.
+	RemoveRole(ctx context.Context, userID, roleID uint, grantorPermissions []string) error
+}
+
+// roleService is the implementation of RoleService.
+type roleService struct {
+	roleRepo repositories.RoleRepository
+	userRepo repositories.UserRepository
+}
+
+// NewRoleService creates a new instance of RoleService.
+func NewRoleService(roleRepo repositories.RoleRepository, userRepo repositories.UserRepository) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+		userRepo: userRepo,
+	}
+}
+
+// SyncPermissions syncs the permission catalog and the admin role.
+func (s *roleService) SyncPermissions(ctx context.Context) error {
+	if err := s.roleRepo.CreateMissingPermissions(ctx, slices.Clone(permissionCatalog)); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create permissions", "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to create permissions: %w", err)
+	}
+	permissions, err := s.roleRepo.ListPermissions(ctx) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list permissions", "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to list permissions: %w", err)
+	}
+
+	// Create the admin role, or grant it permissions added since the last migration.
+	role, err := s.roleRepo.GetRoleByName(ctx, SystemRoleAdmin) // Pass context
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to get admin role", "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get admin role: %w", err)
+	}
+	if err == gorm.ErrRecordNotFound {
+		role = &models.Role{
+			Name:        SystemRoleAdmin,
+			Description: "Full access to the admin API",
+			IsSystem:    true,
+			Permissions: permissions,
+		}
+		if err := s.roleRepo.CreateRole(ctx, role); err != nil { // Pass context
+			logger.Error(ctx, "Failed to create admin role", "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to create admin role: %w", err)
+		}
+	} else if err := s.roleRepo.UpdateRole(ctx, role, map[string]interface{}{"is_system": true}, permissions); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update admin role", "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to update admin role: %w", err)
+	}
+
+	// Users that were admins before roles existed keep their access.
+	assigned, err := s.roleRepo.AssignRoleToUsersWithLegacyRole(ctx, role.ID, "admin") // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to assign admin role to legacy admins", "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to assign admin role: %w", err)
+	}
+
+	logger.Info(ctx, "Permissions synced", "permissions", len(permissions), "legacyAdminsAssigned", assigned) // Use slog.InfoContext
+	return nil
+}
+
+// GetUserPermissions returns the names of the permissions granted to the user.
+func (s *roleService) GetUserPermissions(ctx context.Context, userID uint) ([]string, error) {
+	permissions, err := s.roleRepo.GetUserPermissionNames(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to get user permissions", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// ListPermissions lists all permissions.
+func (s *roleService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	permissions, err := s.roleRepo.ListPermissions(ctx) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list permissions", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// ListRoles lists all roles.
+func (s *roleService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	roles, err := s.roleRepo.ListRoles(ctx) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list roles", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// CreateRole creates a role.
+func (s *roleService) CreateRole(ctx context.Context, req *dto.CreateRoleRequest, grantorPermissions []string) (*models.Role, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, errors.ErrInvalidRoleName
+	}
+
+	// Check if the name is taken.
+	_, err := s.roleRepo.GetRoleByName(ctx, req.Name) // Pass context
+	if err == nil {
+		return nil, errors.ErrRoleAlreadyExists
+	} else if err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to check role name", "name", req.Name, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to check role name: %w", err)
+	}
+
+	permissions, err := s.resolvePermissions(ctx, req.Permissions, grantorPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.CreateRole(ctx, role); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create role", "name", req.Name, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	logger.Info(ctx, "Role created", "roleId", role.ID, "name", role.Name, "permissions", req.Permissions) // Use slog.InfoContext
+	return role, nil
+}
+
+// UpdateRole updates a role's description and permissions.
+func (s *roleService) UpdateRole(ctx context.Context, id uint, req *dto.UpdateRoleRequest, grantorPermissions []string) (*models.Role, error) {
+	role, err := s.getRole(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role.IsSystem {
+		return nil, errors.ErrSystemRoleImmutable
+	}
+	if err := checkGrantor(role, grantorPermissions); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	var permissions []models.Permission
+	if req.Permissions != nil {
+		if permissions, err = s.resolvePermissions(ctx, req.Permissions, grantorPermissions); err != nil {
+			return nil, err
+		}
+	}
+	if len(updates) == 0 && req.Permissions == nil {
+		return nil, errors.ErrNoValidUpdates
+	}
+
+	if err := s.roleRepo.UpdateRole(ctx, role, updates, permissions); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update role", "roleId", id, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	logger.Info(ctx, "Role updated", "roleId", id, "name", role.Name, "permissions", req.Permissions) // Use slog.InfoContext
+	return s.getRole(ctx, id)
+}
+
+// DeleteRole deletes a role and removes it from all users.
+func (s *roleService) DeleteRole(ctx context.Context, id uint, grantorPermissions []string) error {
+	role, err := s.getRole(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return errors.ErrSystemRoleImmutable
+	}
+	if err := checkGrantor(role, grantorPermissions); err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.DeleteRole(ctx, id); err != nil { // Pass context
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrRoleNotFound
+		}
+		logger.Error(ctx, "Failed to delete role", "roleId", id, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	logger.Info(ctx, "Role deleted", "roleId", id, "name", role.Name) // Use slog.InfoContext
+	return nil
+}
+
+// ListUserRoles lists the roles assigned to a user.
+func (s *roleService) ListUserRoles(ctx context.Context, userID uint) ([]models.Role, error) {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.ListUserRoles(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list user roles", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// AssignRole assigns a role to a user.
+func (s *roleService) AssignRole(ctx context.Context, userID, roleID, grantorID uint, grantorPermissions []string) error {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return err
+	}
+	role, err := s.getRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkGrantor(role, grantorPermissions); err != nil {
+		logger.Warn(ctx, "Permission escalation denied", "grantorId", grantorID, "roleId", roleID) // Use slog.WarnContext
+		return err
+	}
+
+	if err := s.roleRepo.AssignRole(ctx, &models.UserRole{UserID: userID, RoleID: roleID, AssignedBy: &grantorID}); err != nil { // Pass context
+		logger.Error(ctx, "Failed to assign role", "userId", userID, "roleId", roleID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	logger.Info(ctx, "Role assigned", "userId", userID, "roleId", roleID, "role", role.Name, "grantorId", grantorID) // Use slog.InfoContext
+	return nil
+}
+
+// RemoveRole removes a role from a user.
+func (s *roleService) RemoveRole(ctx context.Context, userID, roleID uint, grantorPermissions []string) error {
+	role, err := s.getRole(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if err := checkGrantor(role, grantorPermissions); err != nil {
+		return err
+	}
+
+	// Keep at least one admin, otherwise nobody could manage roles anymore.
+	if role.IsSystem {
+		count, err := s.roleRepo.CountRoleUsers(ctx, roleID) // Pass context
+		if err != nil {
+			logger.Error(ctx, "Failed to count role users", "roleId", roleID, "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to count role users: %w", err)
+		}
+		if count <= 1 {
+			return errors.ErrLastSystemAdmin
+		}
+	}
+
+	if err := s.roleRepo.RemoveRole(ctx, userID, roleID); err != nil { // Pass context
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrRoleNotAssigned
+		}
+		logger.Error(ctx, "Failed to remove role", "userId", userID, "roleId", roleID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	logger.Info(ctx, "Role removed", "userId", userID, "roleId", roleID, "role", role.Name) // Use slog.InfoContext
+	return nil
+}
+
+// checkGrantor ensures that a role is only changed, assigned or removed by someone who has all of its
+// permissions, so that roles cannot be used to gain or take away more access than one has.
+func checkGrantor(role *models.Role, grantorPermissions []string) error {
+	for _, permission := range role.Permissions {
+		if !slices.Contains(grantorPermissions, permission.Name) {
+			return errors.ErrPermissionEscalation.WithData(map[string]interface{}{"permission": permission.Name})
+		}
+	}
+	return nil
+}
+
+// getRole retrieves a role by ID.
+func (s *roleService) getRole(ctx context.Context, id uint) (*models.Role, error) {
+	role, err := s.roleRepo.GetRole(ctx, id) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrRoleNotFound
+		}
+		logger.Error(ctx, "Failed to get role", "roleId", id, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+// checkUserExists returns ErrUserNotFound if the user does not exist.
+func (s *roleService) checkUserExists(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.GetUser(ctx, userID); err != nil { // Pass context
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return nil
+}
+
+// resolvePermissions looks up the named permissions, which must exist and be held by the grantor.
+func (s *roleService) resolvePermissions(ctx context.Context, names []string, grantorPermissions []string) ([]models.Permission, error) {
+	for _, name := range names {
+		if !slices.Contains(grantorPermissions, name) {
+			if !slices.ContainsFunc(permissionCatalog, func(p models.Permission) bool { return p.Name == name }) {
+				return nil, errors.ErrUnknownPermission.WithData(map[string]interface{}{"permission": name})
+			}
+			return nil, errors.ErrPermissionEscalation.WithData(map[string]interface{}{"permission": name})
+		}
+	}
+
+	permissions, err := s.roleRepo.GetPermissionsByNames(ctx, names) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to get permissions", "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+	if len(permissions) != len(names) {
+		return nil, errors.ErrUnknownPermission
+	}
+	return permissions, nil
+}
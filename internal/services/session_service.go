@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/internal/models"
+	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/pkg/jwt"
+	"github.com/go-backend-template/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// SessionProviderOAuth is the provider of the sessions of grants to third-party OAuth clients.
+const SessionProviderOAuth = "oauth"
+
+// sessionTouchInterval limits how often last_seen_at is written for an active session.
+const sessionTouchInterval = 5 * time.Minute
+
+// SessionService defines the interface for device session management.
+// A session is created on every login; its ID is also the refresh token family ID,
+// so revoking a session invalidates its refresh tokens as well as its access tokens.
+type SessionService interface {
+	// CreateSession creates a new session for a login.
+	CreateSession(ctx context.Context, userID uint, provider string, client *dto.ClientInfo, ttl time.Duration) (*models.Session, error)
+	// CreateClientSession creates the session of a grant to a third-party OAuth client.
+	CreateClientSession(ctx context.Context, userID uint, oauthClient *models.OAuthClient, client *dto.ClientInfo, ttl time.Duration) (*models.Session, error)
+	// ValidateSession checks that a session is still active and records activity on it.
+	ValidateSession(ctx context.Context, sessionID string, ipAddress string) error
+	// ExtendSession extends an active session when its refresh token is rotated.
+	ExtendSession(ctx context.Context, sessionID string, ttl time.Duration) error
+	// ListSessions lists the user's active sessions.
+	ListSessions(ctx context.Context, userID uint) ([]models.Session, error)
+	// RevokeSession revokes one of the user's sessions.
+	RevokeSession(ctx context.Context, userID uint, sessionID string) error
+	// RevokeAllSessions revokes all of the user's sessions, optionally keeping one.
+	RevokeAllSessions(ctx context.Context, userID uint, exceptSessionID string) error
+	// RevokeClientSessions revokes the sessions of an OAuth client, only those of one user if userID is not 0.
+	RevokeClientSessions(ctx context.Context, oauthClientID, userID uint) error
+}
+
+// sessionService is the implementation of SessionService.
+type sessionService struct {
+	sessionRepo         repositories.SessionRepository
+	refreshTokenService RefreshTokenService
+}
+
+// NewSessionService creates a new instance of SessionService.
+func NewSessionService(sessionRepo repositories.SessionRepository, refreshTokenService RefreshTokenService) SessionService {
+	return &sessionService{
+		sessionRepo:         sessionRepo,
+		refreshTokenService: refreshTokenService,
+	}
+}
+
+// CreateSession creates a new session for a login.
+func (s *sessionService) CreateSession(ctx context.Context, userID uint, provider string, client *dto.ClientInfo, ttl time.Duration) (*models.Session, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:         jwt.NewTokenID(),
+		UserID:     userID,
+		Provider:   provider,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if client != nil {
+		session.DeviceName = truncate(client.DeviceName, 100)
+		session.UserAgent = truncate(client.UserAgent, 500)
+		session.IPAddress = client.IPAddress
+	}
+
+	if err := s.sessionRepo.CreateSession(ctx, session); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create session", "userId", userID, "provider", provider, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	logger.Info(ctx, "Session created", "userId", userID, "sessionId", session.ID, "provider", provider) // Use slog.InfoContext
+	return session, nil
+}
+
+// CreateClientSession creates the session of a grant to a third-party OAuth client.
+// The session is named after the client, so that the user can find and revoke it in the session list.
+func (s *sessionService) CreateClientSession(ctx context.Context, userID uint, oauthClient *models.OAuthClient, client *dto.ClientInfo, ttl time.Duration) (*models.Session, error) {
+	now := time.Now()
+	session := &models.Session{
+		ID:            jwt.NewTokenID(),
+		UserID:        userID,
+		Provider:      SessionProviderOAuth,
+		DeviceName:    truncate(oauthClient.Name, 100),
+		OAuthClientID: &oauthClient.ID,
+		LastSeenAt:    now,
+		ExpiresAt:     now.Add(ttl),
+	}
+	if client != nil {
+		session.UserAgent = truncate(client.UserAgent, 500)
+		session.IPAddress = client.IPAddress
+	}
+
+	if err := s.sessionRepo.CreateSession(ctx, session); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create OAuth client session", "userId", userID, "oauthClientId", oauthClient.ID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	logger.Info(ctx, "OAuth client session created", "userId", userID, "sessionId", session.ID, "clientId", oauthClient.ClientID) // Use slog.InfoContext
+	return session, nil
+}
+
+// ValidateSession checks that a session is still active and records activity on it.
+func (s *sessionService) ValidateSession(ctx context.Context, sessionID string, ipAddress string) error {
+	session, err := s.sessionRepo.GetSession(ctx, sessionID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrSessionRevoked
+		}
+		logger.Error(ctx, "Failed to get session", "sessionId", sessionID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if !session.IsActive() {
+		return errors.ErrSessionRevoked
+	}
+
+	// Record activity, at most once per interval to avoid a write on every request.
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		updates := map[string]interface{}{"last_seen_at": time.Now()}
+		if ipAddress != "" {
+			updates["ip_address"] = ipAddress
+		}
+		if err := s.sessionRepo.UpdateSession(ctx, sessionID, updates); err != nil { // Pass context
+			logger.Warn(ctx, "Failed to update session last seen time", "sessionId", sessionID, "error", err) // Use slog.WarnContext
+		}
+	}
+
+	return nil
+}
+
+// ExtendSession extends an active session when its refresh token is rotated.
+func (s *sessionService) ExtendSession(ctx context.Context, sessionID string, ttl time.Duration) error {
+	session, err := s.sessionRepo.GetSession(ctx, sessionID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrSessionRevoked
+		}
+		logger.Error(ctx, "Failed to get session", "sessionId", sessionID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if !session.IsActive() {
+		return errors.ErrSessionRevoked
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"last_seen_at": now,
+		"expires_at":   now.Add(ttl),
+	}
+	if err := s.sessionRepo.UpdateSession(ctx, sessionID, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to extend session", "sessionId", sessionID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to extend session: %w", err)
+	}
+
+	return nil
+}
+
+// ListSessions lists the user's active sessions.
+func (s *sessionService) ListSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.ListActiveSessions(ctx, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to list sessions", "userId", userID, "error", err) // Use slog.ErrorContext
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions.
+func (s *sessionService) RevokeSession(ctx context.Context, userID uint, sessionID string) error {
+	// Check that the session exists and belongs to the user.
+	session, err := s.sessionRepo.GetSession(ctx, sessionID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrSessionNotFound
+		}
+		logger.Error(ctx, "Failed to get session", "sessionId", sessionID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	if session.UserID != userID {
+		return errors.ErrSessionNotFound
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.sessionRepo.UpdateSession(ctx, sessionID, map[string]interface{}{"revoked_at": time.Now()}); err != nil { // Pass context
+		logger.Error(ctx, "Failed to revoke session", "userId", userID, "sessionId", sessionID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	// Revoke the session's refresh tokens.
+	if err := s.refreshTokenService.RevokeFamily(ctx, sessionID); err != nil {
+		return err
+	}
+
+	logger.Info(ctx, "Session revoked", "userId", userID, "sessionId", sessionID) // Use slog.InfoContext
+	return nil
+}
+
+// RevokeAllSessions revokes all of the user's sessions, optionally keeping one.
+func (s *sessionService) RevokeAllSessions(ctx context.Context, userID uint, exceptSessionID string) error {
+	revokedIDs, err := s.sessionRepo.RevokeSessions(ctx, userID, exceptSessionID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to revoke sessions", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	// Revoke the refresh tokens of every revoked session.
+	for _, sessionID := range revokedIDs {
+		if err := s.refreshTokenService.RevokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	logger.Info(ctx, "All sessions revoked", "userId", userID, "count", len(revokedIDs), "keptSessionId", exceptSessionID) // Use slog.InfoContext
+	return nil
+}
+
+// RevokeClientSessions revokes the sessions of an OAuth client, optionally only those of one user.
+func (s *sessionService) RevokeClientSessions(ctx context.Context, oauthClientID, userID uint) error {
+	revokedIDs, err := s.sessionRepo.RevokeClientSessions(ctx, oauthClientID, userID) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to revoke OAuth client sessions", "oauthClientId", oauthClientID, "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	// Revoke the refresh tokens of every revoked session.
+	for _, sessionID := range revokedIDs {
+		if err := s.refreshTokenService.RevokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	logger.Info(ctx, "OAuth client sessions revoked", "oauthClientId", oauthClientID, "userId", userID, "count", len(revokedIDs)) // Use slog.InfoContext
+	return nil
+}
+
+// truncate shortens a string to at most max characters.
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) > max {
+		return string(runes[:max])
+	}
+	return value
+}
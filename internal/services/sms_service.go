@@ -0,0 +1,314 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// SmsService defines the interface for the SMS service.
+type SmsService interface {
+	// SendVerificationCode sends a verification code to a phone number in E.164 format.
+	SendVerificationCode(ctx context.Context, to, code, locale string) error
+	// Enabled reports whether SMS can be sent. Phone endpoints fail with errors.ErrSMSDisabled otherwise.
+	Enabled() bool
+}
+
+// smsService is the implementation of the SmsService.
+//
+// The provider is selected by configuration: "log" and "file" only record the message and are meant for
+// development, "twilio" and "aliyun" deliver it through the provider's HTTP API, and "disabled" (or no
+// provider) sends nothing.
+type smsService struct {
+	config     *config.Config
+	httpClient *http.Client
+	fileMu     sync.Mutex // Serializes writes to the file sink
+}
+
+// SMS verification code templates
+var smsVerificationTemplates = map[string]string{
+	"zh": "【{{ .AppName }}】您的验证码是 {{ .Code }}，10 分钟内有效。请勿将验证码告诉他人。",
+	"en": "[{{ .AppName }}] Your verification code is {{ .Code }}. It is valid for 10 minutes. Do not share it with anyone.",
+	"de": "[{{ .AppName }}] Ihr Verifizierungscode lautet {{ .Code }}. Er ist 10 Minuten gültig. Geben Sie ihn nicht weiter.",
+}
+
+// Provider API endpoints
+const (
+	twilioAPIURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+	aliyunAPIURL = "https://dysmsapi.aliyuncs.com/"
+)
+
+// NewSmsService creates a new instance of the SMS service. Without a provider SMS is disabled, the sinks
+// that only record messages (which contain the codes) can only be used with the dev config, and the
+// delivering providers need their credentials.
+func NewSmsService(config *config.Config, env string) (SmsService, error) {
+	switch config.SMS.Provider {
+	case "", "disabled":
+	case "log", "file":
+		if env != "dev" {
+			return nil, fmt.Errorf("sms.provider %q records verification codes and is only allowed with the dev config", config.SMS.Provider)
+		}
+	case "twilio":
+		cfg := config.SMS.Twilio
+		if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.FromNumber == "" {
+			return nil, fmt.Errorf("sms.twilio.account_sid, auth_token and from_number are required with the twilio provider")
+		}
+	case "aliyun":
+		cfg := config.SMS.Aliyun
+		if cfg.AccessKeyID == "" || cfg.AccessKeySecret == "" || cfg.SignName == "" || cfg.TemplateCode == "" {
+			return nil, fmt.Errorf("sms.aliyun.access_key_id, access_key_secret, sign_name and template_code are required with the aliyun provider")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SMS provider: %s", config.SMS.Provider)
+	}
+
+	return &smsService{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Enabled reports whether an SMS provider is configured.
+func (s *smsService) Enabled() bool {
+	return s.config.SMS.Provider != "" && s.config.SMS.Provider != "disabled"
+}
+
+// SendVerificationCode sends a verification code to a phone number.
+func (s *smsService) SendVerificationCode(ctx context.Context, to, code, locale string) error {
+	if !s.Enabled() {
+		return errors.ErrSMSDisabled
+	}
+
+	// Validate and get the supported language.
+	lang := getSupportedLanguage(locale)
+
+	data := struct {
+		Code    string
+		AppName string
+	}{
+		Code:    code,
+		AppName: s.config.SMS.AppName,
+	}
+
+	message, err := s.renderTemplate(smsVerificationTemplates[lang], data)
+	if err != nil {
+		return fmt.Errorf("failed to render SMS template: %w", err)
+	}
+
+	return s.sendSMS(ctx, to, code, message) // Pass context
+}
+
+// sendSMS selects the SMS sending method based on configuration.
+// Template based providers (aliyun) only receive the code; the others receive the rendered message.
+func (s *smsService) sendSMS(ctx context.Context, to, code, message string) error {
+	switch s.config.SMS.Provider {
+	case "log":
+		return s.sendToLog(ctx, to, message) // Pass context
+	case "file":
+		return s.sendToFile(ctx, to, message) // Pass context
+	case "twilio":
+		return s.sendWithTwilio(ctx, to, message) // Pass context
+	case "aliyun":
+		return s.sendWithAliyun(ctx, to, code) // Pass context
+	default:
+		return fmt.Errorf("unsupported SMS provider: %s", s.config.SMS.Provider)
+	}
+}
+
+// sendToLog writes the SMS to the application log instead of sending it (development only).
+func (s *smsService) sendToLog(ctx context.Context, to, message string) error {
+	logger.Info(ctx, "SMS (log sink)", "to", to, "message", message) // Use slog.InfoContext
+	return nil
+}
+
+// sendToFile appends the SMS to a local file instead of sending it (development and tests).
+func (s *smsService) sendToFile(ctx context.Context, to, message string) error {
+	path := s.config.SMS.FilePath
+	if path == "" {
+		path = "sms.log"
+	}
+
+	s.fileMu.Lock()
+	defer s.fileMu.Unlock()
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create SMS file directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		logger.Error(ctx, "Failed to open SMS file sink", "path", path, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to open SMS file: %w", err)
+	}
+	defer f.Close()
+
+	line := fmt.Sprintf("%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, message)
+	if _, err := f.WriteString(line); err != nil {
+		logger.Error(ctx, "Failed to write SMS file sink", "path", path, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to write SMS file: %w", err)
+	}
+
+	logger.Info(ctx, "SMS written to file sink", "to", to, "path", path) // Use slog.InfoContext
+	return nil
+}
+
+// sendWithTwilio sends an SMS using the Twilio Messages API.
+func (s *smsService) sendWithTwilio(ctx context.Context, to, message string) error {
+	cfg := s.config.SMS.Twilio
+
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", cfg.FromNumber)
+	form.Set("Body", message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(twilioAPIURL, cfg.AccountSID), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create Twilio request: %w", err)
+	}
+	req.SetBasicAuth(cfg.AccountSID, cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		logger.Error(ctx, "Failed to send SMS via Twilio", "error", err, "to", to)
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var result struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		logger.Error(ctx, "Twilio returned error", "status", resp.StatusCode, "code", result.Code, "message", result.Message, "to", to)
+		return fmt.Errorf("twilio error: status %d", resp.StatusCode)
+	}
+
+	logger.Info(ctx, "SMS sent successfully via Twilio", "to", to, "status", resp.StatusCode)
+	return nil
+}
+
+// sendWithAliyun sends an SMS using the Aliyun SMS API (SendSms, signature version 1.0).
+func (s *smsService) sendWithAliyun(ctx context.Context, to, code string) error {
+	cfg := s.config.SMS.Aliyun
+
+	templateParam, err := json.Marshal(map[string]string{"code": code})
+	if err != nil {
+		return fmt.Errorf("failed to encode Aliyun template parameters: %w", err)
+	}
+
+	params := map[string]string{
+		"AccessKeyId":      cfg.AccessKeyID,
+		"Action":           "SendSms",
+		"Format":           "JSON",
+		"PhoneNumbers":     strings.TrimPrefix(to, "+"),
+		"RegionId":         "cn-hangzhou",
+		"SignName":         cfg.SignName,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   uuid.NewString(),
+		"SignatureVersion": "1.0",
+		"TemplateCode":     cfg.TemplateCode,
+		"TemplateParam":    string(templateParam),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+		"Version":          "2017-05-25",
+	}
+	query := aliyunCanonicalQuery(params)
+	signature := aliyunSignature(http.MethodGet, query, cfg.AccessKeySecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, aliyunAPIURL+"?Signature="+aliyunPercentEncode(signature)+"&"+query, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Aliyun request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		logger.Error(ctx, "Failed to send SMS via Aliyun", "error", err, "to", to)
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code      string `json:"Code"`
+		Message   string `json:"Message"`
+		RequestID string `json:"RequestId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		logger.Error(ctx, "Failed to decode Aliyun response", "status", resp.StatusCode, "error", err, "to", to)
+		return fmt.Errorf("aliyun error: status %d", resp.StatusCode)
+	}
+	if result.Code != "OK" {
+		logger.Error(ctx, "Aliyun returned error", "status", resp.StatusCode, "code", result.Code, "message", result.Message, "requestId", result.RequestID, "to", to)
+		return fmt.Errorf("aliyun error: %s", result.Code)
+	}
+
+	logger.Info(ctx, "SMS sent successfully via Aliyun", "to", to, "requestId", result.RequestID)
+	return nil
+}
+
+// aliyunCanonicalQuery builds the sorted, percent-encoded query string that is signed.
+func aliyunCanonicalQuery(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, aliyunPercentEncode(k)+"="+aliyunPercentEncode(params[k]))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// aliyunSignature signs a canonical query string with HMAC-SHA1.
+func aliyunSignature(method, canonicalQuery, secret string) string {
+	stringToSign := method + "&" + aliyunPercentEncode("/") + "&" + aliyunPercentEncode(canonicalQuery)
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunPercentEncode encodes a value as required by the Aliyun RPC signature (RFC 3986).
+func aliyunPercentEncode(value string) string {
+	encoded := url.QueryEscape(value)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	encoded = strings.ReplaceAll(encoded, "%7E", "~")
+	return encoded
+}
+
+// renderTemplate renders an SMS template.
+func (s *smsService) renderTemplate(templateContent string, data interface{}) (string, error) {
+	tmpl, err := template.New("sms").Parse(templateContent)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -40,16 +40,19 @@ type UserInteractionService interface {
 type userInteractionService struct {
 	interactionRepo repositories.UserInteractionRepository
 	productRepo     repositories.ProductRepository
+	searchService   ProductSearchService
 }
 
 // NewUserInteractionService creates a new instance of UserInteractionService.
 func NewUserInteractionService(
 	interactionRepo repositories.UserInteractionRepository,
 	productRepo repositories.ProductRepository,
+	searchService ProductSearchService,
 ) UserInteractionService {
 	return &userInteractionService{
 		interactionRepo: interactionRepo,
 		productRepo:     productRepo,
+		searchService:   searchService,
 	}
 }
 
@@ -121,9 +124,23 @@ func (s *userInteractionService) IsLiked(ctx context.Context, userID, productID
 
 // ListUserLikedProducts retrieves products liked by a user.
 func (s *userInteractionService) ListUserLikedProducts(ctx context.Context, userID uint, params *query_params.QueryParams) ([]models.Product, *response.Pagination, error) {
+	// Find the products matching the search in the search index.
+	var searchHits []uint
+	var truncated bool
+	if params.Search != "" {
+		hits, more, err := s.searchService.Search(ctx, params.Search) // Pass context
+		if err != nil {
+			return nil, nil, err
+		}
+		searchHits, truncated = hits, more
+	}
+
 	// Get the list of liked products.
-	products, total, err := s.interactionRepo.ListUserInteractedProducts(ctx, userID, params, "like") // Pass context
+	products, total, nextCursor, err := s.interactionRepo.ListUserInteractedProducts(ctx, userID, params, "like", searchHits) // Pass context
 	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
 		logger.Error(ctx, "Failed to list user likes", "userId", userID, "error", err) // Use slog.ErrorContext
 		return nil, nil, fmt.Errorf("failed to list user likes: %w", err)
 	}
@@ -134,6 +151,8 @@ func (s *userInteractionService) ListUserLikedProducts(ctx context.Context, user
 		PageSize:    params.Limit,
 		CurrentPage: params.Page,
 		TotalPages:  (total + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
+		Truncated:   truncated,
 	}
 
 	// Return an empty array if there is no data, instead of nil.
@@ -208,9 +227,23 @@ func (s *userInteractionService) IsFavorited(ctx context.Context, userID, produc
 
 // ListUserFavoritedProducts retrieves products favorited by a user.
 func (s *userInteractionService) ListUserFavoritedProducts(ctx context.Context, userID uint, params *query_params.QueryParams) ([]models.Product, *response.Pagination, error) {
+	// Find the products matching the search in the search index.
+	var searchHits []uint
+	var truncated bool
+	if params.Search != "" {
+		hits, more, err := s.searchService.Search(ctx, params.Search) // Pass context
+		if err != nil {
+			return nil, nil, err
+		}
+		searchHits, truncated = hits, more
+	}
+
 	// Get the list of favorited products.
-	products, total, err := s.interactionRepo.ListUserInteractedProducts(ctx, userID, params, "favorite") // Pass context
+	products, total, nextCursor, err := s.interactionRepo.ListUserInteractedProducts(ctx, userID, params, "favorite", searchHits) // Pass context
 	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
 		logger.Error(ctx, "Failed to list user favorites", "userId", userID, "error", err) // Use slog.ErrorContext
 		return nil, nil, fmt.Errorf("failed to list user favorites: %w", err)
 	}
@@ -221,6 +254,8 @@ func (s *userInteractionService) ListUserFavoritedProducts(ctx context.Context,
 		PageSize:    params.Limit,
 		CurrentPage: params.Page,
 		TotalPages:  (total + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
+		Truncated:   truncated,
 	}
 
 	// Return an empty array if there is no data, instead of nil.
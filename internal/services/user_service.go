@@ -2,9 +2,10 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	stderrors "errors"
 	"fmt"
-	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-backend-template/config"
@@ -12,11 +13,12 @@ import (
 	"github.com/go-backend-template/internal/errors"
 	"github.com/go-backend-template/internal/models"
 	"github.com/go-backend-template/internal/repositories"
+	"github.com/go-backend-template/internal/utils"
 	"github.com/go-backend-template/pkg/jwt"
 	"github.com/go-backend-template/pkg/logger"
 	"github.com/go-backend-template/pkg/query_params"
 	"github.com/go-backend-template/pkg/response"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/go-backend-template/pkg/webauthn"
 	"gorm.io/gorm"
 )
 
@@ -28,56 +30,90 @@ type UserService interface {
 	CreateUser(ctx context.Context, req *dto.RegisterWithPasswordRequest) (uint, error)
 	UpdateUser(ctx context.Context, id uint, req *dto.UpdateProfileRequest) error
 	DeleteUser(ctx context.Context, id uint) error
-	RestoreUser(ctx context.Context, id uint) error          // Restore soft-deleted user
-	BanUser(ctx context.Context, id uint, banned bool) error // Ban or unban user
+	RestoreUser(ctx context.Context, id uint) error // Restore soft-deleted user
 
 	/* Auth logic */
 	UpdatePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error
 
 	/* Traditional registration/login related */
 	RegisterWithPassword(ctx context.Context, req *dto.RegisterWithPasswordRequest) (uint, error)
-	LoginWithPassword(ctx context.Context, emailOrPhone, password string) (string, string, error) // Returns (accessToken, refreshToken, error)
-	RefreshAccessToken(ctx context.Context, refreshToken string) (string, string, error)          // Returns (newAccessToken, newRefreshToken, error)
+	LoginWithPassword(ctx context.Context, emailOrPhone, password string, client *dto.ClientInfo) (string, string, string, error)         // Returns (accessToken, refreshToken, mfaToken, error); only mfaToken is set when MFA is required
+	CompleteMFALogin(ctx context.Context, mfaToken, code string, client *dto.ClientInfo) (string, string, error)                          // Returns (accessToken, refreshToken, error)
+	LoginWithPasskey(ctx context.Context, credential *webauthn.AssertionResponse, client *dto.ClientInfo) (string, string, string, error) // Returns (accessToken, refreshToken, mfaToken, error)
+	LoginWithPhoneCode(ctx context.Context, phone, code string, client *dto.ClientInfo) (string, string, string, error)                   // Returns (accessToken, refreshToken, mfaToken, error); only mfaToken is set when MFA is required
+	RefreshAccessToken(ctx context.Context, refreshToken string) (string, string, error)                                                  // Returns (newAccessToken, newRefreshToken, error)
 
 	/* Email verification related */
 	SendEmailVerification(ctx context.Context, email string) error
 	VerifyEmail(ctx context.Context, email, code string) error
 
+	/* Email change related */
+	RequestEmailChange(ctx context.Context, userID uint, req *dto.EmailChangeRequest) error // Sends a code to the new address and a notice to the current one
+	ConfirmEmailChange(ctx context.Context, userID uint, req *dto.ConfirmEmailChangeRequest) error
+
+	/* Magic link (email login) related */
+	SendMagicLink(ctx context.Context, email string) error
+	LoginWithMagicLink(ctx context.Context, token string, client *dto.ClientInfo) (string, string, string, error) // Returns (accessToken, refreshToken, mfaToken, error); only mfaToken is set when MFA is required
+
+	/* Phone verification related */
+	SendPhoneCode(ctx context.Context, phone string) error // Sends a code usable for verification, and for login once the number is verified
+	VerifyPhone(ctx context.Context, phone, code string) error
+
+	/* Phone change related */
+	RequestPhoneChange(ctx context.Context, userID uint, req *dto.PhoneChangeRequest) error // Sends a code to the new number
+	ConfirmPhoneChange(ctx context.Context, userID uint, req *dto.ConfirmPhoneChangeRequest) error
+
 	/* Password reset related */
 	SendPasswordReset(ctx context.Context, email string) error
 	ResetPassword(ctx context.Context, email, resetToken, newPassword string) error
 
 	/* WeChat Mini Program related */
 	RegisterFromWechatMiniProgram(ctx context.Context, req *dto.RegisterFromWechatMiniProgramRequest, unionID *string, openID *string) (uint, error)
-	LoginFromWechatMiniProgram(ctx context.Context, unionID *string, openID *string) (string, string, error) // Returns (accessToken, refreshToken, error)
-
-	/* WeChat OAuth2.0 (App/Web) related */
-	ExchangeWechatOAuth(ctx context.Context, req *dto.WechatOAuthRequest) (string, string, bool, error) // Returns (accessToken, refreshToken, isNewUser, error)
-	BindWechatAccount(ctx context.Context, userID uint, req *dto.BindWechatAccountRequest, authenticatedUser *models.User) error
-	UnbindWechatAccount(ctx context.Context, userID uint, authenticatedUser *models.User) error
-	/* Google OAuth2.0 (App/Web) related */
-	ExchangeGoogleOAuth(ctx context.Context, req *dto.GoogleOAuthRequest) (string, string, bool, error) // Returns (accessToken, refreshToken, isNewUser, error)
-	BindGoogleAccount(ctx context.Context, userID uint, req *dto.BindGoogleAccountRequest, authenticatedUser *models.User) error
-	UnbindGoogleAccount(ctx context.Context, userID uint, authenticatedUser *models.User) error
+	LoginFromWechatMiniProgram(ctx context.Context, unionID *string, openID *string, client *dto.ClientInfo) (string, string, string, error) // Returns (accessToken, refreshToken, mfaToken, error)
+
+	/* OAuth2 / OpenID Connect (App/Web) related */
+	ExchangeOAuth(ctx context.Context, provider string, req *dto.OAuthTokenRequest, client *dto.ClientInfo) (string, string, string, bool, error) // Returns (accessToken, refreshToken, mfaToken, isNewUser, error)
+	BindOAuthAccount(ctx context.Context, userID uint, provider string, req *dto.BindOAuthAccountRequest, authenticatedUser *models.User) error
+	UnbindOAuthAccount(ctx context.Context, userID uint, provider string, authenticatedUser *models.User) error
 }
 
 // userService is the implementation of UserService.
 type userService struct {
-	config              *config.Config
-	userRepo            repositories.UserRepository
-	googleOAuthService  GoogleOAuthService
-	emailService        EmailService
-	verificationService VerificationService
+	config                 *config.Config
+	jwtKeys                *jwt.KeySet
+	userRepo               repositories.UserRepository
+	oauthProviders         OAuthProviderRegistry
+	emailService           EmailService
+	smsService             SmsService
+	verificationService    VerificationService
+	refreshTokenService    RefreshTokenService
+	sessionService         SessionService
+	mfaService             MFAService
+	passkeyService         PasskeyService
+	loginProtectionService LoginProtectionService
+	loginHistoryService    LoginHistoryService
+	banService             BanService
+	passwordService        PasswordService
 }
 
 // NewUserService creates a new instance of UserService.
-func NewUserService(config *config.Config, userRepo repositories.UserRepository, googleOAuthService GoogleOAuthService, emailService EmailService, verificationService VerificationService) UserService {
+func NewUserService(config *config.Config, jwtKeys *jwt.KeySet, userRepo repositories.UserRepository, oauthProviders OAuthProviderRegistry, emailService EmailService, smsService SmsService, verificationService VerificationService, refreshTokenService RefreshTokenService, sessionService SessionService, mfaService MFAService, passkeyService PasskeyService, loginProtectionService LoginProtectionService, loginHistoryService LoginHistoryService, banService BanService, passwordService PasswordService) UserService {
 	return &userService{
-		config:              config,
-		userRepo:            userRepo,
-		googleOAuthService:  googleOAuthService,
-		emailService:        emailService,
-		verificationService: verificationService,
+		config:                 config,
+		jwtKeys:                jwtKeys,
+		userRepo:               userRepo,
+		oauthProviders:         oauthProviders,
+		emailService:           emailService,
+		smsService:             smsService,
+		verificationService:    verificationService,
+		refreshTokenService:    refreshTokenService,
+		sessionService:         sessionService,
+		mfaService:             mfaService,
+		passkeyService:         passkeyService,
+		loginProtectionService: loginProtectionService,
+		loginHistoryService:    loginHistoryService,
+		banService:             banService,
+		passwordService:        passwordService,
 	}
 }
 
@@ -88,8 +124,11 @@ Admin-facing business logic
 // ListUsers retrieves a list of users.
 func (s *userService) ListUsers(ctx context.Context, params *query_params.QueryParams, includeSoftDeleted ...bool) ([]models.User, *response.Pagination, error) {
 	// Call the repository layer to get the list of users.
-	userList, total, err := s.userRepo.ListUsers(ctx, params, includeSoftDeleted...) // Pass context
+	userList, total, nextCursor, err := s.userRepo.ListUsers(ctx, params, includeSoftDeleted...) // Pass context
 	if err != nil {
+		if paramsErr := listParamsError(err); paramsErr != nil {
+			return nil, nil, paramsErr
+		}
 		logger.Error(ctx, "Failed to list users", "error", err) // Use slog.ErrorContext
 		return nil, nil, fmt.Errorf("failed to list users: %w", err)
 	}
@@ -105,6 +144,7 @@ func (s *userService) ListUsers(ctx context.Context, params *query_params.QueryP
 		PageSize:    params.Limit,
 		CurrentPage: params.Page,
 		TotalPages:  (int(total) + params.Limit - 1) / params.Limit,
+		NextCursor:  nextCursor,
 	}
 
 	return userList, pagination, nil
@@ -144,10 +184,12 @@ func (s *userService) CreateUser(ctx context.Context, req *dto.RegisterWithPassw
 		}
 	}
 
-	// Hash the password.
-	hashedPassword, err := hashPassword(req.Password)
+	// Check the password policy and hash the password.
+	if err := s.passwordService.CheckPolicy(ctx, req.Password); err != nil { // Pass context
+		return 0, err
+	}
+	hashedPassword, err := s.passwordService.HashPassword(ctx, req.Password) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to hash password", "error", err) // Use slog.ErrorContext
 		return 0, fmt.Errorf("error processing password: %w", err)
 	}
 	// Convert DTO to User model.
@@ -185,17 +227,6 @@ func (s *userService) CreateUser(ctx context.Context, req *dto.RegisterWithPassw
 	return user.ID, nil
 }
 
-// hashPassword hashes a password using bcrypt.
-func hashPassword(password string) (string, error) {
-	// Generate a hash with the recommended cost (10-12).
-	// bcrypt automatically adds a random salt and includes it in the hash result.
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), 12)
-	if err != nil {
-		return "", err
-	}
-	return string(hash), nil
-}
-
 // UpdateUser updates a user.
 func (s *userService) UpdateUser(ctx context.Context, id uint, req *dto.UpdateProfileRequest) error {
 	// Check if the user exists.
@@ -222,6 +253,21 @@ func (s *userService) UpdateUser(ctx context.Context, id uint, req *dto.UpdatePr
 		updates["is_email_verified"] = false
 	}
 
+	// If Phone is updated, check if it already exists and set it to unverified.
+	if req.Phone != nil && *req.Phone != "" && (user.Phone == nil || *user.Phone != *req.Phone) {
+		existingUser, err := s.userRepo.GetUserByField(ctx, "phone", *req.Phone) // Pass context
+		if err != nil && err != gorm.ErrRecordNotFound {
+			logger.Error(ctx, "Failed to check existing phone", "phone", *req.Phone, "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to check existing phone: %w", err)
+		}
+		if existingUser != nil && existingUser.ID != 0 && existingUser.ID != id {
+			logger.Warn(ctx, "Phone already exists", "phone", *req.Phone) // Use slog.WarnContext
+			return errors.ErrPhoneAlreadyExists
+		}
+		// If Phone is updated, set it to unverified.
+		updates["is_phone_verified"] = false
+	}
+
 	// Call the repository layer to perform the update.
 	if err := s.userRepo.UpdateUser(ctx, id, updates); err != nil { // Pass context
 		logger.Error(ctx, "Failed to update user", "userId", id, "error", err) // Use slog.ErrorContext
@@ -273,27 +319,6 @@ func (s *userService) RestoreUser(ctx context.Context, id uint) error {
 	return nil
 }
 
-// BanUser bans or unbans a user.
-func (s *userService) BanUser(ctx context.Context, id uint, isBanned bool) error {
-	// Check if the user exists.
-	_, err := s.GetUser(ctx, id) // Pass context
-	if err != nil {
-		return err
-	}
-
-	// Construct a map of fields to update.
-	updates := map[string]interface{}{
-		"is_banned": isBanned,
-	}
-
-	// Call the repository layer to update the user's ban status.
-	if err := s.userRepo.UpdateUser(ctx, id, updates); err != nil { // Pass context
-		logger.Error(ctx, "Failed to update ban status", "userId", id, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to update ban status: %w", err)
-	}
-	return nil
-}
-
 /*
 Auth logic
 */
@@ -316,17 +341,18 @@ func (s *userService) UpdatePassword(ctx context.Context, userID uint, currentPa
 		return errors.ErrInvalidPassword
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(currentPassword))
-	if err != nil {
+	if match, _ := s.passwordService.VerifyPassword(ctx, *user.Password, currentPassword); !match { // Pass context
 		logger.Warn(ctx, "Current password verification failed", "userId", user.ID) // Use slog.WarnContext
 		return errors.ErrInvalidPassword
 	}
 
-	// Hash the new password.
-	hashedPassword, err := hashPassword(newPassword)
+	// Check the password policy and hash the new password.
+	if err := s.passwordService.CheckPolicy(ctx, newPassword); err != nil { // Pass context
+		return err
+	}
+	hashedPassword, err := s.passwordService.HashPassword(ctx, newPassword) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to hash new password", "userId", user.ID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to hash password: %w", err)
+		return err
 	}
 
 	// Update the user's password.
@@ -355,10 +381,19 @@ func (s *userService) RegisterWithPassword(ctx context.Context, req *dto.Registe
 }
 
 // LoginWithPassword validates user password and generates JWT tokens.
-func (s *userService) LoginWithPassword(ctx context.Context, emailOrPhone, password string) (string, string, error) {
+func (s *userService) LoginWithPassword(ctx context.Context, emailOrPhone, password string, client *dto.ClientInfo) (string, string, string, error) {
 	var user *models.User
 	var err error
 
+	// Reject the attempt if the account or IP is locked out after too many failures.
+	account := strings.ToLower(strings.TrimSpace(emailOrPhone))
+	ip := clientIP(client)
+	if err := s.loginProtectionService.CheckLogin(ctx, account, ip); err != nil {
+		logger.Warn(ctx, "Login attempt rejected by brute-force protection", "account", account, "ip", ip) // Use slog.WarnContext
+		s.loginHistoryService.RecordLoginFailure(ctx, nil, account, "password", err, client)
+		return "", "", "", err
+	}
+
 	// Try finding the user by email first.
 	user, err = s.userRepo.GetUserByField(ctx, "email", emailOrPhone) // Pass context
 	if err != nil && err == gorm.ErrRecordNotFound {
@@ -366,65 +401,293 @@ func (s *userService) LoginWithPassword(ctx context.Context, emailOrPhone, passw
 		user, err = s.userRepo.GetUserByField(ctx, "phone", emailOrPhone) // Pass context
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
-				return "", "", errors.ErrUserNotFound
+				s.loginProtectionService.RecordLoginFailure(ctx, account, ip, nil)
+				s.loginHistoryService.RecordLoginFailure(ctx, nil, account, "password", errors.ErrUserNotFound, client)
+				return "", "", "", errors.ErrUserNotFound
 			}
 			logger.Error(ctx, "Failed to find user by phone", "emailOrPhone", emailOrPhone, "error", err) // Use slog.ErrorContext
-			return "", "", fmt.Errorf("database error: %w", err)
+			return "", "", "", fmt.Errorf("database error: %w", err)
 		}
 	} else if err != nil {
 		logger.Error(ctx, "Failed to find user by email", "emailOrPhone", emailOrPhone, "error", err) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("database error: %w", err)
-	}
-
-	// Check if the user is banned.
-	if user.IsBanned {
-		return "", "", errors.ErrUserBanned
+		return "", "", "", fmt.Errorf("database error: %w", err)
 	}
 
 	// Check if the user has a password set.
 	if user.Password == nil || *user.Password == "" {
 		logger.Warn(ctx, "User has no password set", "userId", user.ID) // Use slog.WarnContext
-		return "", "", errors.ErrInvalidPassword
+		s.loginProtectionService.RecordLoginFailure(ctx, account, ip, &user.ID)
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, account, "password", errors.ErrInvalidPassword, client)
+		return "", "", "", errors.ErrInvalidPassword
 	}
 
 	// Validate the password.
-	err = bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(password))
-	if err != nil {
+	match, needsRehash := s.passwordService.VerifyPassword(ctx, *user.Password, password) // Pass context
+	if !match {
 		logger.Warn(ctx, "Password verification failed", "userId", user.ID) // Use slog.WarnContext
-		return "", "", errors.ErrInvalidPassword
+		s.loginProtectionService.RecordLoginFailure(ctx, account, ip, &user.ID)
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, account, "password", errors.ErrInvalidPassword, client)
+		return "", "", "", errors.ErrInvalidPassword
 	}
+	s.loginProtectionService.RecordLoginSuccess(ctx, account)
 
-	// Generate JWT access token.
-	accessToken, err := jwt.GenerateAccessToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
+	// Check if the user is banned. This is only revealed with the correct password, as the error includes the ban details.
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, account, "password", err, client)
+		return "", "", "", err
+	}
+
+	// Upgrade hashes created with an older algorithm or parameters (e.g. bcrypt) now that the password is known.
+	if needsRehash {
+		s.rehashPassword(ctx, user.ID, password)
+	}
+
+	return s.completeFirstFactorLogin(ctx, user, "password", client)
+}
+
+// rehashPassword replaces the user's password hash with one using the current hash configuration.
+// Failures are only logged: the old hash keeps working and is upgraded on a later login.
+func (s *userService) rehashPassword(ctx context.Context, userID uint, password string) {
+	hashedPassword, err := s.passwordService.HashPassword(ctx, password) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to generate access token", "error", err, "userId", user.ID) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+		return
 	}
+	if err := s.userRepo.UpdateUser(ctx, userID, map[string]interface{}{"password": hashedPassword}); err != nil { // Pass context
+		logger.Error(ctx, "Failed to store upgraded password hash", "userId", userID, "error", err) // Use slog.ErrorContext
+		return
+	}
+	logger.Info(ctx, "Password hash upgraded", "userId", userID) // Use slog.InfoContext
+}
 
-	// Generate JWT refresh token.
-	refreshToken, err := jwt.GenerateRefreshToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
+// completeFirstFactorLogin finishes a login whose first factor (password, phone code, magic link, passkey
+// without user verification, OAuth provider or WeChat) has been verified. If two-factor authentication is
+// enabled, a short-lived, single-use MFA pending token is returned instead of a session.
+func (s *userService) completeFirstFactorLogin(ctx context.Context, user *models.User, provider string, client *dto.ClientInfo) (string, string, string, error) {
+	mfaEnabled, err := s.mfaService.IsEnabled(ctx, user.ID) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to generate refresh token", "error", err, "userId", user.ID) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+		return "", "", "", err
+	}
+	if mfaEnabled {
+		tokenID := jwt.NewTokenID()
+		if err := s.verificationService.StoreMFAPendingTokenID(ctx, user.ID, tokenID, jwt.MFAPendingTokenDuration); err != nil { // Pass context
+			return "", "", "", err
+		}
+		mfaToken, err := jwt.GenerateMFAPendingToken(user.ID, user.Role, provider, tokenID, s.jwtKeys)
+		if err != nil {
+			logger.Error(ctx, "Failed to generate MFA pending token", "error", err, "userId", user.ID) // Use slog.ErrorContext
+			return "", "", "", fmt.Errorf("failed to generate MFA pending token: %w", err)
+		}
+		logger.Info(ctx, "First factor verified, waiting for second factor", "userId", user.ID, "provider", provider) // Use slog.InfoContext
+		return "", "", mfaToken, nil
+	}
+
+	// Generate JWT access token and refresh token in a new login session.
+	accessToken, refreshToken, err := s.startSession(ctx, user, provider, false, client)
+	if err != nil {
+		return "", "", "", err
 	}
 
 	// Update last login time.
 	now := time.Now()
 	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
 
+	return accessToken, refreshToken, "", nil
+}
+
+// CompleteMFALogin completes a login waiting for a second factor by verifying a TOTP code or recovery code.
+// The MFA pending token is consumed by a successful second factor, so it yields a single session.
+func (s *userService) CompleteMFALogin(ctx context.Context, mfaToken, code string, client *dto.ClientInfo) (string, string, error) {
+	// 1. Validate the MFA pending token.
+	mfaTokenDetails, err := jwt.ValidateToken(mfaToken, s.jwtKeys)
+	if err != nil || mfaTokenDetails.TokenType != jwt.MFAPendingToken {
+		logger.Debug(ctx, "MFA pending token validation failed", "error", err) // Use slog.DebugContext
+		return "", "", errors.ErrInvalidToken
+	}
+
+	// 2. Validate if the user exists and is not banned.
+	user, err := s.userRepo.GetUser(ctx, mfaTokenDetails.UserID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", "", errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user during MFA login", "userId", mfaTokenDetails.UserID, "error", err) // Use slog.ErrorContext
+		return "", "", fmt.Errorf("database error: %w", err)
+	}
+	// Tokens issued before the provider claim existed always came from a password login.
+	provider := mfaTokenDetails.Provider
+	if provider == "" {
+		provider = "password"
+	}
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", provider, err, client)
+		return "", "", err
+	}
+
+	// 3. Verify the second factor, counting failures like password attempts.
+	account := mfaLoginAccount(user.ID)
+	ip := clientIP(client)
+	if err := s.loginProtectionService.CheckLogin(ctx, account, ip); err != nil {
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", provider, err, client)
+		return "", "", err
+	}
+	if err := s.mfaService.VerifyCode(ctx, user.ID, code); err != nil { // Pass context
+		if stderrors.Is(err, errors.ErrInvalidMFACode) {
+			s.loginProtectionService.RecordLoginFailure(ctx, account, ip, &user.ID)
+			s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", provider, err, client)
+		}
+		return "", "", err
+	}
+	s.loginProtectionService.RecordLoginSuccess(ctx, account)
+
+	// Consume the MFA pending token, so that it cannot start another session.
+	consumed, err := s.verificationService.ConsumeMFAPendingTokenID(ctx, user.ID, mfaTokenDetails.TokenID) // Pass context
+	if err != nil {
+		return "", "", err
+	}
+	if !consumed {
+		return "", "", errors.ErrInvalidToken
+	}
+
+	// 4. Generate JWT access token and refresh token in a new login session.
+	accessToken, refreshToken, err := s.startSession(ctx, user, provider, true, client)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 5. Update last login time.
+	now := time.Now()
+	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
+
+	logger.Info(ctx, "User logged in with two-factor authentication", "userId", user.ID) // Use slog.InfoContext
 	return accessToken, refreshToken, nil
 }
 
+// LoginWithPasskey verifies a passkey assertion and generates JWT tokens. An assertion without user
+// verification is only one factor, so users with two-factor authentication get an MFA pending token instead.
+func (s *userService) LoginWithPasskey(ctx context.Context, credential *webauthn.AssertionResponse, client *dto.ClientInfo) (string, string, string, error) {
+	// 1. Verify the passkey assertion.
+	userID, userVerified, err := s.passkeyService.FinishLogin(ctx, credential) // Pass context
+	if err != nil {
+		if _, ok := err.(*errors.AppError); ok {
+			s.loginHistoryService.RecordLoginFailure(ctx, nil, "", "passkey", err, client)
+		}
+		return "", "", "", err
+	}
+
+	// 2. Validate if the user exists and is not banned.
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", "", "", errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user during passkey login", "userId", userID, "error", err) // Use slog.ErrorContext
+		return "", "", "", fmt.Errorf("database error: %w", err)
+	}
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", "passkey", err, client)
+		return "", "", "", err
+	}
+
+	// 3. Without user verification the passkey is only a first factor.
+	if !userVerified {
+		accessToken, refreshToken, mfaToken, err := s.completeFirstFactorLogin(ctx, user, "passkey", client)
+		if err != nil {
+			return "", "", "", err
+		}
+		if mfaToken == "" {
+			logger.Info(ctx, "User logged in with passkey", "userId", user.ID) // Use slog.InfoContext
+		}
+		return accessToken, refreshToken, mfaToken, nil
+	}
+
+	// 4. Generate JWT access token and refresh token in a new login session.
+	// A passkey with user verification (biometrics or PIN) already combines two factors.
+	accessToken, refreshToken, err := s.startSession(ctx, user, "passkey", true, client)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// 5. Update last login time.
+	now := time.Now()
+	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
+
+	logger.Info(ctx, "User logged in with passkey", "userId", user.ID) // Use slog.InfoContext
+	return accessToken, refreshToken, "", nil
+}
+
+// LoginWithPhoneCode verifies a code sent by SMS and generates JWT tokens.
+// Only verified phone numbers can log in: an unverified number has not been shown to belong to the account.
+func (s *userService) LoginWithPhoneCode(ctx context.Context, phone, code string, client *dto.ClientInfo) (string, string, string, error) {
+	// 1. Reject the attempt if the phone number or IP is locked out after too many failures.
+	ip := clientIP(client)
+	if err := s.loginProtectionService.CheckLogin(ctx, phone, ip); err != nil {
+		logger.Warn(ctx, "Phone login attempt rejected by brute-force protection", "phone", phone, "ip", ip) // Use slog.WarnContext
+		s.loginHistoryService.RecordLoginFailure(ctx, nil, phone, "phone", err, client)
+		return "", "", "", err
+	}
+
+	// 2. Validate if the user exists and is not banned.
+	user, err := s.userRepo.GetUserByField(ctx, "phone", phone) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			s.loginProtectionService.RecordLoginFailure(ctx, phone, ip, nil)
+			s.loginHistoryService.RecordLoginFailure(ctx, nil, phone, "phone", errors.ErrUserNotFound, client)
+			return "", "", "", errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to find user by phone", "phone", phone, "error", err) // Use slog.ErrorContext
+		return "", "", "", fmt.Errorf("database error: %w", err)
+	}
+	if !user.IsPhoneVerified {
+		logger.Warn(ctx, "Phone login attempt with an unverified phone number", "userId", user.ID) // Use slog.WarnContext
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, phone, "phone", errors.ErrPhoneNotVerified, client)
+		return "", "", "", errors.ErrPhoneNotVerified
+	}
+
+	// 3. Validate the code.
+	isValid, err := s.verificationService.VerifyPhoneVerificationCode(ctx, phone, code) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to verify phone login code", "phone", phone, "error", err) // Use slog.ErrorContext
+		return "", "", "", fmt.Errorf("failed to verify code: %w", err)
+	}
+	if !isValid {
+		s.loginProtectionService.RecordLoginFailure(ctx, phone, ip, &user.ID)
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, phone, "phone", errors.ErrInvalidVerificationCode, client)
+		return "", "", "", errors.ErrInvalidVerificationCode
+	}
+	s.loginProtectionService.RecordLoginSuccess(ctx, phone)
+
+	// Check if the user is banned, now that the code proves the request comes from the user.
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, phone, "phone", err, client)
+		return "", "", "", err
+	}
+
+	logger.Info(ctx, "Phone login code verified", "userId", user.ID) // Use slog.InfoContext
+	return s.completeFirstFactorLogin(ctx, user, "phone", client)
+}
+
 // RefreshAccessToken uses a refresh token to get a new access token.
 func (s *userService) RefreshAccessToken(ctx context.Context, refreshToken string) (string, string, error) {
 	// 1. Validate the refresh token.
-	refreshTokenDetails, err := jwt.ValidateToken(refreshToken, s.config.JWT.Secret)
-	if err != nil || refreshTokenDetails.TokenType != jwt.RefreshToken {
+	refreshTokenDetails, err := jwt.ValidateToken(refreshToken, s.jwtKeys)
+	if err != nil || refreshTokenDetails.TokenType != jwt.RefreshToken || refreshTokenDetails.TokenID == "" {
 		logger.Debug(ctx, "Refresh token validation failed", "error", err) // Use slog.DebugContext
 		return "", "", errors.ErrInvalidToken
 	}
+	// Refresh tokens of third-party OAuth clients can only be used at the OAuth token endpoint.
+	if refreshTokenDetails.ClientID != "" {
+		logger.Warn(ctx, "OAuth client refresh token used on the first-party refresh endpoint", "userId", refreshTokenDetails.UserID, "clientId", refreshTokenDetails.ClientID) // Use slog.WarnContext
+		return "", "", errors.ErrInvalidToken
+	}
 
-	// 2. Validate if the user exists and is not banned.
+	// 2. Consume the refresh token in the server-side store (rotation and reuse detection).
+	// The token family ID is the login session ID.
+	sessionID, err := s.refreshTokenService.RotateRefreshToken(ctx, refreshTokenDetails.UserID, refreshTokenDetails.TokenID, refreshToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 3. Validate if the user exists and is not banned.
 	user, err := s.userRepo.GetUser(ctx, refreshTokenDetails.UserID) // Pass context
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -435,27 +698,26 @@ func (s *userService) RefreshAccessToken(ctx context.Context, refreshToken strin
 		return "", "", fmt.Errorf("database error: %w", err)
 	}
 
-	// 3. Check if the user is banned.
-	if user.IsBanned {
+	// 4. Check if the user is banned.
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
 		logger.Warn(ctx, "Refresh token rejected for banned user", "userId", user.ID) // Use slog.WarnContext
-		return "", "", errors.ErrUserBanned
+		return "", "", err
 	}
 
-	// 4. Generate a new access token.
-	newAccessToken, err := jwt.GenerateAccessToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate new access token", "userId", user.ID, "error", err) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	// 5. Extend the login session, making sure it has not been logged out.
+	if err := s.sessionService.ExtendSession(ctx, sessionID, jwt.RefreshTokenDuration(s.config.JWT.ExpireHours)); err != nil {
+		return "", "", err
 	}
 
-	// 5. Generate a new refresh token (optional, for refresh token rotation).
-	newRefreshToken, err := jwt.GenerateRefreshToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
+	// 6. Generate a new access token and a new refresh token in the same session.
+	// The session keeps the MFA status it was created with.
+	subject := jwt.Subject{UserID: user.ID, Role: user.Role, SessionID: sessionID, MFA: refreshTokenDetails.MFA}
+	newAccessToken, newRefreshToken, err := s.issueTokens(ctx, subject)
 	if err != nil {
-		logger.Error(ctx, "Failed to generate new refresh token", "userId", user.ID, "error", err) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+		return "", "", err
 	}
 
-	// 6. Update last login time.
+	// 7. Update last login time.
 	now := time.Now()
 	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
 
@@ -463,6 +725,68 @@ func (s *userService) RefreshAccessToken(ctx context.Context, refreshToken strin
 	return newAccessToken, newRefreshToken, nil
 }
 
+// startSession creates a new login session for a user, issues its access/refresh token pair and records the
+// login in the login history. mfa records whether the login was completed with a second factor.
+func (s *userService) startSession(ctx context.Context, user *models.User, provider string, mfa bool, client *dto.ClientInfo) (string, string, error) {
+	// Logging in again cancels a scheduled account deletion.
+	if user.DeletionScheduledAt != nil {
+		if err := s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"deletion_scheduled_at": nil}); err != nil { // Pass context
+			logger.Error(ctx, "Failed to cancel account deletion", "userId", user.ID, "error", err) // Use slog.ErrorContext
+			return "", "", fmt.Errorf("failed to cancel account deletion: %w", err)
+		}
+		user.DeletionScheduledAt = nil
+		logger.Info(ctx, "Account deletion cancelled by login", "userId", user.ID, "provider", provider) // Use slog.InfoContext
+	}
+
+	session, err := s.sessionService.CreateSession(ctx, user.ID, provider, client, jwt.RefreshTokenDuration(s.config.JWT.ExpireHours))
+	if err != nil {
+		return "", "", err
+	}
+	accessToken, refreshToken, err := s.issueTokens(ctx, jwt.Subject{UserID: user.ID, Role: user.Role, SessionID: session.ID, MFA: mfa})
+	if err != nil {
+		return "", "", err
+	}
+
+	// Record the login in the login history, alerting the user if the device is new.
+	s.loginHistoryService.RecordLoginSuccess(ctx, user, provider, mfa, session.ID, client)
+	return accessToken, refreshToken, nil
+}
+
+// clientIP returns the IP address of the client, if known.
+func clientIP(client *dto.ClientInfo) string {
+	if client == nil {
+		return ""
+	}
+	return client.IPAddress
+}
+
+// issueTokens generates a new access/refresh token pair for a login session and records the refresh token
+// in the server-side store. The session ID is used as the refresh token family ID.
+func (s *userService) issueTokens(ctx context.Context, subject jwt.Subject) (string, string, error) {
+	// Generate JWT access token.
+	accessToken, err := jwt.GenerateAccessToken(subject, s.jwtKeys, s.config.JWT.ExpireHours)
+	if err != nil {
+		logger.Error(ctx, "Failed to generate access token", "error", err, "userId", subject.UserID) // Use slog.ErrorContext
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	// Generate JWT refresh token with a unique jti.
+	tokenID := jwt.NewTokenID()
+	refreshToken, err := jwt.GenerateRefreshToken(subject, tokenID, s.jwtKeys, s.config.JWT.ExpireHours)
+	if err != nil {
+		logger.Error(ctx, "Failed to generate refresh token", "error", err, "userId", subject.UserID) // Use slog.ErrorContext
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	// Store the refresh token so it can be rotated and revoked.
+	ttl := jwt.RefreshTokenDuration(s.config.JWT.ExpireHours)
+	if err := s.refreshTokenService.StoreRefreshToken(ctx, subject.UserID, subject.SessionID, tokenID, refreshToken, ttl); err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
 /*
 Email verification related
 */
@@ -545,103 +869,529 @@ func (s *userService) VerifyEmail(ctx context.Context, email, code string) error
 }
 
 /*
-Password reset related
+Email change related
 */
 
-// SendPasswordReset sends a password reset email.
-func (s *userService) SendPasswordReset(ctx context.Context, email string) error {
-	// Check if the user exists.
-	user, err := s.userRepo.GetUserByField(ctx, "email", email) // Pass context
+// RequestEmailChange starts a change of the user's email address. A code is sent to the new address and a
+// notice to the current one; the address is only changed once the code is confirmed.
+func (s *userService) RequestEmailChange(ctx context.Context, userID uint, req *dto.EmailChangeRequest) error {
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrUserNotFound
 		}
-		logger.Error(ctx, "Failed to find user by email", "email", email, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("database error: %w", err)
+		logger.Error(ctx, "Failed to get user for email change", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Generate reset token.
-	token, err := s.verificationService.GeneratePasswordResetToken(ctx, email) // Pass context
-	if err != nil {
-		logger.Error(ctx, "Failed to generate password reset token", "email", email, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to generate reset token: %w", err)
+	if user.Email != nil && *user.Email == req.NewEmail {
+		return errors.ErrEmailUnchanged
 	}
 
-	// Send reset email.
-	err = s.emailService.SendPasswordReset(ctx, email, user.Name, token, user.Locale) // Pass context
+	// Accounts with a password must confirm the change with it.
+	if user.Password != nil && *user.Password != "" {
+		if match, _ := s.passwordService.VerifyPassword(ctx, *user.Password, req.Password); !match { // Pass context
+			logger.Warn(ctx, "Password verification failed for email change", "userId", user.ID) // Use slog.WarnContext
+			return errors.ErrInvalidPassword
+		}
+	}
+
+	if err := s.checkEmailAvailable(ctx, user.ID, req.NewEmail); err != nil {
+		return err
+	}
+
+	// Notify the current address first, so a change cannot be confirmed without its owner being told.
+	if user.Email != nil && *user.Email != "" {
+		if err := s.emailService.SendEmailChangeNotice(ctx, *user.Email, user.Name, req.NewEmail, user.Locale); err != nil { // Pass context
+			logger.Error(ctx, "Failed to send email change notice", "userId", user.ID, "error", err) // Use slog.ErrorContext
+			return fmt.Errorf("failed to send email change notice: %w", err)
+		}
+	}
+
+	code, err := s.verificationService.GenerateEmailChangeCode(ctx, user.ID, req.NewEmail) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to send password reset email", "email", email, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to send reset email: %w", err)
+		logger.Error(ctx, "Failed to generate email change code", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to generate verification code: %w", err)
 	}
 
-	logger.Info(ctx, "Password reset email sent successfully", "email", email) // Use slog.InfoContext
+	if err := s.emailService.SendEmailChangeVerification(ctx, req.NewEmail, user.Name, code, user.Locale); err != nil { // Pass context
+		logger.Error(ctx, "Failed to send email change verification", "userId", user.ID, "newEmail", req.NewEmail, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	logger.Info(ctx, "Email change requested", "userId", user.ID, "newEmail", req.NewEmail) // Use slog.InfoContext
 	return nil
 }
 
-// ResetPassword resets a user's password.
-func (s *userService) ResetPassword(ctx context.Context, email, resetToken, newPassword string) error {
-	// Check if the user exists.
-	user, err := s.userRepo.GetUserByField(ctx, "email", email) // Pass context
+// ConfirmEmailChange swaps in the new email address once the code sent to it is confirmed.
+// The new address is verified by the confirmation.
+func (s *userService) ConfirmEmailChange(ctx context.Context, userID uint, req *dto.ConfirmEmailChangeRequest) error {
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrUserNotFound
 		}
-		logger.Error(ctx, "Failed to find user by email", "email", email, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("database error: %w", err)
+		logger.Error(ctx, "Failed to get user for email change", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Validate the reset token.
-	isValid, err := s.verificationService.VerifyPasswordResetToken(ctx, email, resetToken) // Pass context
+	isValid, err := s.verificationService.VerifyEmailChangeCode(ctx, user.ID, req.NewEmail, req.Code) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to verify password reset token", "email", email, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to verify reset token: %w", err)
+		logger.Error(ctx, "Failed to verify email change code", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to verify code: %w", err)
 	}
-
 	if !isValid {
 		return errors.ErrInvalidVerificationCode
 	}
 
-	// Hash the new password.
-	hashedPassword, err := hashPassword(newPassword)
-	if err != nil {
-		logger.Error(ctx, "Failed to hash new password", "email", email, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to hash password: %w", err)
+	// The address may have been taken since the change was requested.
+	if err := s.checkEmailAvailable(ctx, user.ID, req.NewEmail); err != nil {
+		return err
 	}
 
-	// Update the user's password.
 	updates := map[string]interface{}{
-		"password": hashedPassword,
+		"email":             req.NewEmail,
+		"is_email_verified": true,
 	}
-
 	if err := s.userRepo.UpdateUser(ctx, user.ID, updates); err != nil { // Pass context
-		logger.Error(ctx, "Failed to update user password", "userId", user.ID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to update password: %w", err)
+		// A concurrent change to the same address fails on idx_email.
+		if availableErr := s.checkEmailAvailable(ctx, user.ID, req.NewEmail); availableErr != nil {
+			return availableErr
+		}
+		logger.Error(ctx, "Failed to change email", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to change email: %w", err)
 	}
 
-	logger.Info(ctx, "Password reset successfully", "email", email, "userId", user.ID) // Use slog.InfoContext
+	logger.Info(ctx, "Email changed successfully", "userId", user.ID, "newEmail", req.NewEmail) // Use slog.InfoContext
+	return nil
+}
+
+// checkEmailAvailable returns errors.ErrEmailAlreadyExists if the email address belongs to another user.
+// Soft-deleted users are included, as they still hold their address in idx_email.
+func (s *userService) checkEmailAvailable(ctx context.Context, userID uint, email string) error {
+	existingUser, err := s.userRepo.GetUserByField(ctx, "email", email, true) // Pass context
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to check existing email", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to check existing email: %w", err)
+	}
+	if existingUser != nil && existingUser.ID != 0 && existingUser.ID != userID {
+		logger.Warn(ctx, "Email already exists", "email", email) // Use slog.WarnContext
+		return errors.ErrEmailAlreadyExists
+	}
 	return nil
 }
 
 /*
-WeChat Mini Program related
+Magic link (email login) related
 */
 
-// RegisterUserFromWechatMiniProgram registers a user from WeChat Mini Program.
-func (s *userService) RegisterFromWechatMiniProgram(ctx context.Context, req *dto.RegisterFromWechatMiniProgramRequest, unionID *string, openID *string) (uint, error) {
-	// Check sensitive content in production environment.
-	// if openID != nil && *openID != "" {
-	// 	// Check if user nickname contains sensitive content.
-	// 	if err := utils.CheckSensitiveContent(req.Name, *openID, utils.SecuritySceneProfile); err != nil {
-	// 		return 0, err
-	// 	}
-	// }
-
-	// Validate if openID is provided.
-	if openID == nil || *openID == "" {
-		return 0, errors.ErrOpenIDNotProvided
+// SendMagicLink sends a one-time login link to a registered email address.
+// Sending a new link invalidates the previous one.
+func (s *userService) SendMagicLink(ctx context.Context, email string) error {
+	// Check if the user exists.
+	user, err := s.userRepo.GetUserByField(ctx, "email", email) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to find user by email", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("database error: %w", err)
 	}
 
-	// Validate: if the user has already registered via WeChat Mini Program.
+	// Check if the user is banned. The ban details are only returned after logging in.
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		if stderrors.Is(err, errors.ErrUserBanned) {
+			return errors.ErrUserBanned
+		}
+		return err
+	}
+
+	// Store the one-time link ID and sign it into the link token.
+	linkID, err := s.verificationService.GenerateMagicLinkID(ctx, email, jwt.MagicLinkTokenDuration) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to generate magic link ID", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to generate magic link: %w", err)
+	}
+	token, err := jwt.GenerateMagicLinkToken(user.ID, user.Role, linkID, s.jwtKeys)
+	if err != nil {
+		logger.Error(ctx, "Failed to generate magic link token", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to generate magic link: %w", err)
+	}
+
+	// Build the link to the frontend page, which redeems the token.
+	link, err := s.buildMagicLink(token)
+	if err != nil {
+		logger.Error(ctx, "Failed to build magic link", "error", err) // Use slog.ErrorContext
+		return err
+	}
+
+	// Send the login email.
+	err = s.emailService.SendMagicLink(ctx, email, user.Name, link, user.Locale) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to send magic link email", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to send magic link email: %w", err)
+	}
+
+	logger.Info(ctx, "Magic link sent successfully", "email", email) // Use slog.InfoContext
+	return nil
+}
+
+// buildMagicLink appends the link token to the configured frontend URL.
+func (s *userService) buildMagicLink(token string) (string, error) {
+	if s.config.Email.MagicLinkURL == "" {
+		return "", fmt.Errorf("magic link URL is not configured")
+	}
+	link, err := url.Parse(s.config.Email.MagicLinkURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid magic link URL: %w", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// LoginWithMagicLink redeems a one-time login link and generates JWT tokens.
+// Redeeming the link proves ownership of the email address, so it is also marked as verified.
+func (s *userService) LoginWithMagicLink(ctx context.Context, token string, client *dto.ClientInfo) (string, string, string, error) {
+	// 1. Validate the signed link token.
+	tokenDetails, err := jwt.ValidateToken(token, s.jwtKeys)
+	if err != nil || tokenDetails.TokenType != jwt.MagicLinkToken || tokenDetails.TokenID == "" {
+		logger.Debug(ctx, "Magic link token validation failed", "error", err) // Use slog.DebugContext
+		return "", "", "", errors.ErrInvalidToken
+	}
+
+	// 2. Validate if the user exists and is not banned.
+	user, err := s.userRepo.GetUser(ctx, tokenDetails.UserID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", "", "", errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user during magic link login", "userId", tokenDetails.UserID, "error", err) // Use slog.ErrorContext
+		return "", "", "", fmt.Errorf("database error: %w", err)
+	}
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", "magic_link", err, client)
+		return "", "", "", err
+	}
+	if user.Email == nil || *user.Email == "" {
+		return "", "", "", errors.ErrInvalidToken
+	}
+
+	// 3. Consume the link so it cannot be used again. A link sent to a previous email address is rejected.
+	valid, err := s.verificationService.ConsumeMagicLinkID(ctx, *user.Email, tokenDetails.TokenID) // Pass context
+	if err != nil {
+		return "", "", "", err
+	}
+	if !valid {
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", "magic_link", errors.ErrInvalidToken, client)
+		return "", "", "", errors.ErrInvalidToken
+	}
+
+	// 4. Mark the email address as verified.
+	if !user.IsEmailVerified {
+		if err := s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"is_email_verified": true}); err != nil { // Pass context
+			logger.Error(ctx, "Failed to update email verification status", "userId", user.ID, "error", err) // Use slog.ErrorContext
+			return "", "", "", fmt.Errorf("failed to update verification status: %w", err)
+		}
+	}
+
+	logger.Info(ctx, "Magic link redeemed", "userId", user.ID) // Use slog.InfoContext
+	return s.completeFirstFactorLogin(ctx, user, "magic_link", client)
+}
+
+/*
+Phone verification related
+*/
+
+// SendPhoneCode sends a code by SMS to a registered phone number. The code can be used to verify the phone
+// number, or to log in with it once it is verified.
+func (s *userService) SendPhoneCode(ctx context.Context, phone string) error {
+	if !s.smsService.Enabled() {
+		return errors.ErrSMSDisabled
+	}
+
+	// Check if the user exists.
+	user, err := s.userRepo.GetUserByField(ctx, "phone", phone) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to find user by phone", "phone", phone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	// Generate verification code.
+	code, err := s.verificationService.GeneratePhoneVerificationCode(ctx, phone) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to generate phone verification code", "phone", phone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	// Send the code by SMS.
+	err = s.smsService.SendVerificationCode(ctx, phone, code, user.Locale) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to send verification SMS", "phone", phone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to send verification SMS: %w", err)
+	}
+
+	logger.Info(ctx, "Phone verification code sent successfully", "phone", phone) // Use slog.InfoContext
+	return nil
+}
+
+// VerifyPhone verifies a phone number.
+func (s *userService) VerifyPhone(ctx context.Context, phone, code string) error {
+	// Check if the user exists.
+	user, err := s.userRepo.GetUserByField(ctx, "phone", phone) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to find user by phone", "phone", phone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	// Check if the phone number is already verified.
+	if user.IsPhoneVerified {
+		return errors.ErrPhoneAlreadyVerified
+	}
+
+	// Validate the verification code.
+	isValid, err := s.verificationService.VerifyPhoneVerificationCode(ctx, phone, code) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to verify phone verification code", "phone", phone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to verify code: %w", err)
+	}
+
+	if !isValid {
+		return errors.ErrInvalidVerificationCode
+	}
+
+	// Update the user's phone verification status.
+	updates := map[string]interface{}{
+		"is_phone_verified": true,
+	}
+
+	if err := s.userRepo.UpdateUser(ctx, user.ID, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update phone verification status", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to update verification status: %w", err)
+	}
+
+	logger.Info(ctx, "Phone verified successfully", "phone", phone, "userId", user.ID) // Use slog.InfoContext
+	return nil
+}
+
+/*
+Phone change related
+*/
+
+// RequestPhoneChange starts a change of the user's phone number. A code is sent to the new number; the number
+// is only changed once the code is confirmed, so a user cannot take a number they do not own.
+func (s *userService) RequestPhoneChange(ctx context.Context, userID uint, req *dto.PhoneChangeRequest) error {
+	if !s.smsService.Enabled() {
+		return errors.ErrSMSDisabled
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user for phone change", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Phone != nil && *user.Phone == req.NewPhone {
+		return errors.ErrPhoneUnchanged
+	}
+
+	// Accounts with a password must confirm the change with it.
+	if user.Password != nil && *user.Password != "" {
+		if match, _ := s.passwordService.VerifyPassword(ctx, *user.Password, req.Password); !match { // Pass context
+			logger.Warn(ctx, "Password verification failed for phone change", "userId", user.ID) // Use slog.WarnContext
+			return errors.ErrInvalidPassword
+		}
+	}
+
+	if err := s.checkPhoneAvailable(ctx, user.ID, req.NewPhone); err != nil {
+		return err
+	}
+
+	code, err := s.verificationService.GeneratePhoneChangeCode(ctx, user.ID, req.NewPhone) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to generate phone change code", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	if err := s.smsService.SendVerificationCode(ctx, req.NewPhone, code, user.Locale); err != nil { // Pass context
+		logger.Error(ctx, "Failed to send phone change verification", "userId", user.ID, "newPhone", req.NewPhone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to send verification SMS: %w", err)
+	}
+
+	logger.Info(ctx, "Phone change requested", "userId", user.ID, "newPhone", req.NewPhone) // Use slog.InfoContext
+	return nil
+}
+
+// ConfirmPhoneChange swaps in the new phone number once the code sent to it is confirmed.
+// The new number is verified by the confirmation.
+func (s *userService) ConfirmPhoneChange(ctx context.Context, userID uint, req *dto.ConfirmPhoneChangeRequest) error {
+	user, err := s.userRepo.GetUser(ctx, userID) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to get user for phone change", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	isValid, err := s.verificationService.VerifyPhoneChangeCode(ctx, user.ID, req.NewPhone, req.Code) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to verify phone change code", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to verify code: %w", err)
+	}
+	if !isValid {
+		return errors.ErrInvalidVerificationCode
+	}
+
+	// The number may have been taken since the change was requested.
+	if err := s.checkPhoneAvailable(ctx, user.ID, req.NewPhone); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"phone":             req.NewPhone,
+		"is_phone_verified": true,
+	}
+	if err := s.userRepo.UpdateUser(ctx, user.ID, updates); err != nil { // Pass context
+		// A concurrent change to the same number fails on idx_phone.
+		if availableErr := s.checkPhoneAvailable(ctx, user.ID, req.NewPhone); availableErr != nil {
+			return availableErr
+		}
+		logger.Error(ctx, "Failed to change phone", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to change phone: %w", err)
+	}
+
+	logger.Info(ctx, "Phone changed successfully", "userId", user.ID, "newPhone", req.NewPhone) // Use slog.InfoContext
+	return nil
+}
+
+// checkPhoneAvailable returns errors.ErrPhoneAlreadyExists if the phone number belongs to another user.
+// Soft-deleted users are included, as they still hold their number in idx_phone.
+func (s *userService) checkPhoneAvailable(ctx context.Context, userID uint, phone string) error {
+	existingUser, err := s.userRepo.GetUserByField(ctx, "phone", phone, true) // Pass context
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to check existing phone", "phone", phone, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to check existing phone: %w", err)
+	}
+	if existingUser != nil && existingUser.ID != 0 && existingUser.ID != userID {
+		logger.Warn(ctx, "Phone already exists", "phone", phone) // Use slog.WarnContext
+		return errors.ErrPhoneAlreadyExists
+	}
+	return nil
+}
+
+/*
+Password reset related
+*/
+
+// SendPasswordReset sends a password reset email.
+func (s *userService) SendPasswordReset(ctx context.Context, email string) error {
+	// Check if the user exists.
+	user, err := s.userRepo.GetUserByField(ctx, "email", email) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to find user by email", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	// Generate reset token.
+	token, err := s.verificationService.GeneratePasswordResetToken(ctx, email) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to generate password reset token", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	// Send reset email.
+	err = s.emailService.SendPasswordReset(ctx, email, user.Name, token, user.Locale) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to send password reset email", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to send reset email: %w", err)
+	}
+
+	logger.Info(ctx, "Password reset email sent successfully", "email", email) // Use slog.InfoContext
+	return nil
+}
+
+// ResetPassword resets a user's password.
+func (s *userService) ResetPassword(ctx context.Context, email, resetToken, newPassword string) error {
+	// Check if the user exists.
+	user, err := s.userRepo.GetUserByField(ctx, "email", email) // Pass context
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.ErrUserNotFound
+		}
+		logger.Error(ctx, "Failed to find user by email", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	// Check the password policy before the reset token is used up.
+	if err := s.passwordService.CheckPolicy(ctx, newPassword); err != nil { // Pass context
+		return err
+	}
+
+	// Validate the reset token.
+	isValid, err := s.verificationService.VerifyPasswordResetToken(ctx, email, resetToken) // Pass context
+	if err != nil {
+		logger.Error(ctx, "Failed to verify password reset token", "email", email, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to verify reset token: %w", err)
+	}
+
+	if !isValid {
+		return errors.ErrInvalidVerificationCode
+	}
+
+	// Hash the new password.
+	hashedPassword, err := s.passwordService.HashPassword(ctx, newPassword) // Pass context
+	if err != nil {
+		return err
+	}
+
+	// Update the user's password.
+	updates := map[string]interface{}{
+		"password": hashedPassword,
+	}
+
+	if err := s.userRepo.UpdateUser(ctx, user.ID, updates); err != nil { // Pass context
+		logger.Error(ctx, "Failed to update user password", "userId", user.ID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	logger.Info(ctx, "Password reset successfully", "email", email, "userId", user.ID) // Use slog.InfoContext
+	return nil
+}
+
+/*
+WeChat Mini Program related
+*/
+
+// RegisterUserFromWechatMiniProgram registers a user from WeChat Mini Program.
+func (s *userService) RegisterFromWechatMiniProgram(ctx context.Context, req *dto.RegisterFromWechatMiniProgramRequest, unionID *string, openID *string) (uint, error) {
+	// Check sensitive content in production environment.
+	// if openID != nil && *openID != "" {
+	// 	// Check if user nickname contains sensitive content.
+	// 	if err := utils.CheckSensitiveContent(req.Name, *openID, utils.SecuritySceneProfile); err != nil {
+	// 		return 0, err
+	// 	}
+	// }
+
+	// Validate if openID is provided.
+	if openID == nil || *openID == "" {
+		return 0, errors.ErrOpenIDNotProvided
+	}
+
+	// Validate: if the user has already registered via WeChat Mini Program.
 	if _, err := s.userRepo.GetUserByProvider(ctx, "wechat_mini_program", *openID); err == nil { // Pass context
 		return 0, errors.ErrUserAlreadyExists
 	}
@@ -724,10 +1474,11 @@ func (s *userService) RegisterFromWechatMiniProgram(ctx context.Context, req *dt
 }
 
 // LoginFromWechatMiniProgram logs in a user via WeChat Mini Program.
-func (s *userService) LoginFromWechatMiniProgram(ctx context.Context, unionID *string, openID *string) (string, string, error) {
+// If two-factor authentication is enabled, an MFA pending token is returned instead of a session.
+func (s *userService) LoginFromWechatMiniProgram(ctx context.Context, unionID *string, openID *string, client *dto.ClientInfo) (string, string, string, error) {
 	// If both unionID and openID are nil, return an error directly.
 	if unionID == nil && openID == nil {
-		return "", "", errors.ErrUserNotFound
+		return "", "", "", errors.ErrUserNotFound
 	}
 
 	var user *models.User
@@ -739,7 +1490,7 @@ func (s *userService) LoginFromWechatMiniProgram(ctx context.Context, unionID *s
 		user, err = s.userRepo.GetUserByUnionID(ctx, *unionID) // Pass context
 		if err != nil && err != gorm.ErrRecordNotFound {
 			logger.Error(ctx, "Failed to find user by unionID", "unionID", *unionID, "error", err) // Use slog.ErrorContext
-			return "", "", fmt.Errorf("database error: %w", err)
+			return "", "", "", fmt.Errorf("database error: %w", err)
 		}
 	}
 
@@ -748,257 +1499,200 @@ func (s *userService) LoginFromWechatMiniProgram(ctx context.Context, unionID *s
 		user, err = s.userRepo.GetUserByProvider(ctx, "wechat_mini_program", *openID) // Pass context
 		if err != nil && err != gorm.ErrRecordNotFound {
 			logger.Error(ctx, "Failed to find user by provider", "provider", "wechat_mini_program", "providerUID", *openID, "error", err) // Use slog.ErrorContext
-			return "", "", fmt.Errorf("database error: %w", err)
+			return "", "", "", fmt.Errorf("database error: %w", err)
 		}
 	}
 
 	// If still not found, return user not found error.
 	if user == nil || user.ID == 0 {
-		return "", "", errors.ErrUserNotFound
+		s.loginHistoryService.RecordLoginFailure(ctx, nil, "", "wechat_mini_program", errors.ErrUserNotFound, client)
+		return "", "", "", errors.ErrUserNotFound
 	}
 
 	// Check if the user is banned.
-	if user.IsBanned {
-		return "", "", errors.ErrUserBanned
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", "wechat_mini_program", err, client)
+		return "", "", "", err
 	}
 
-	// Generate JWT access token.
-	accessToken, err := jwt.GenerateAccessToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate access token", "error", err, "userId", user.ID) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// Generate JWT refresh token.
-	refreshToken, err := jwt.GenerateRefreshToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate refresh token", "error", err, "userId", user.ID) // Use slog.ErrorContext
-		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
-	}
-
-	// Update last login time.
-	now := time.Now()
-	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
-
-	logger.Info(ctx, "WeChat mini program login successful", // Use slog.InfoContext
+	logger.Info(ctx, "WeChat mini program login verified", // Use slog.InfoContext
 		"userId", user.ID,
 		"unionID", unionID,
 		"openID", openID)
 
-	return accessToken, refreshToken, nil
+	return s.completeFirstFactorLogin(ctx, user, "wechat_mini_program", client)
 }
 
 /*
-WeChat OAuth2.0 (App/Web)
+OAuth2 / OpenID Connect (App/Web)
 */
 
-// WechatOAuthTokenResponse is the response structure for WeChat OAuth2 code exchange.
-// [Reference] WeChat Login for Mobile Apps: https://developers.weixin.qq.com/doc/oplatform/Mobile_App/WeChat_Login/Development_Guide.html
-// [Reference] WeChat Login for Web Apps: https://developers.weixin.qq.com/doc/oplatform/Website_App/WeChat_Login/Wechat_Login.html
-type WechatOAuthTokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	ExpiresIn    int    `json:"expires_in"`
-	RefreshToken string `json:"refresh_token"`
-	OpenID       string `json:"openid"`
-	Scope        string `json:"scope"`
-	UnionID      string `json:"unionid,omitempty"`
-	ErrCode      int    `json:"errcode,omitempty"`
-	ErrMsg       string `json:"errmsg,omitempty"`
-}
-
-// ExchangeWechatOAuth handles WeChat OAuth2.0 (automatically determines login/registration).
-func (s *userService) ExchangeWechatOAuth(ctx context.Context, req *dto.WechatOAuthRequest) (string, string, bool, error) {
-	// 1. Exchange code for access_token and openid/unionid.
-	appid := ""
-	secret := ""
-	if req.ClientType == "web" {
-		appid = s.config.Wechat.Web.AppID
-		secret = s.config.Wechat.Web.Secret
-	} else if req.ClientType == "app" {
-		appid = s.config.Wechat.App.AppID
-		secret = s.config.Wechat.App.Secret
-	} else {
-		return "", "", false, fmt.Errorf("invalid client_type")
+// ExchangeOAuth authenticates a user with an OAuth provider (automatically determines login/registration).
+// If two-factor authentication is enabled, an MFA pending token is returned instead of a session.
+func (s *userService) ExchangeOAuth(ctx context.Context, providerName string, req *dto.OAuthTokenRequest, client *dto.ClientInfo) (string, string, string, bool, error) {
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return "", "", "", false, errors.ErrOAuthProviderNotFound
 	}
 
-	url := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", appid, secret, req.Code)
-	client := &http.Client{Timeout: 10 * time.Second}
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil) // Use http.NewRequestWithContext
+	// 1. Exchange the authorization code or verify the ID token.
+	identity, err := provider.Authenticate(ctx, req) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to create http request for wechat oauth", "error", err, "url", url)
-		return "", "", false, fmt.Errorf("failed to create http request for wechat oauth: %w", err)
+		logger.Warn(ctx, "OAuth authentication failed", "provider", providerName, "error", err) // Use slog.WarnContext
+		s.loginHistoryService.RecordLoginFailure(ctx, nil, "", providerName, err, client)
+		return "", "", "", false, err
 	}
-	resp, err := client.Do(httpReq)
+
+	// 2. Find the user bound to this identity, or register a new user.
+	user, isNewUser, err := s.findOrCreateOAuthUser(ctx, providerName, identity) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to request wechat oauth", "error", err, "url", url)
-		return "", "", false, fmt.Errorf("failed to request wechat oauth: %w", err)
+		return "", "", "", false, err
 	}
-	defer resp.Body.Close()
 
-	var tokenResp WechatOAuthTokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		logger.Error(ctx, "Failed to decode wechat oauth response", "error", err)
-		return "", "", false, fmt.Errorf("failed to decode wechat oauth response: %w", err)
-	}
-	if tokenResp.ErrCode != 0 {
-		logger.Error(ctx, "Wechat oauth error", "errcode", tokenResp.ErrCode, "errmsg", tokenResp.ErrMsg)
-		return "", "", false, fmt.Errorf("wechat oauth error: %s (%d)", tokenResp.ErrMsg, tokenResp.ErrCode)
+	// Check if the user is banned.
+	if err := s.banService.CheckBan(ctx, user); err != nil { // Pass context
+		logger.Warn(ctx, "User is banned", "userId", user.ID, "provider", providerName) // Use slog.WarnContext
+		s.loginHistoryService.RecordLoginFailure(ctx, &user.ID, "", providerName, err, client)
+		return "", "", "", false, err
 	}
 
-	openid := tokenResp.OpenID
-	unionid := tokenResp.UnionID
-
-	// 2. Check if openid/unionid is already bound to a user.
-	var user *models.User
-	isNewUser := false
+	// 3. Start a session, or wait for the second factor.
+	accessToken, refreshToken, mfaToken, err := s.completeFirstFactorLogin(ctx, user, providerName, client)
+	return accessToken, refreshToken, mfaToken, isNewUser, err
+}
 
-	// Case 1: User found directly by openid.
-	if openid != "" {
-		user, err = s.userRepo.GetUserByProvider(ctx, "wechat", openid) // Pass context
-		if err != nil && err != gorm.ErrRecordNotFound {
-			logger.Error(ctx, "Failed to find user by openid", "openid", openid, "error", err) // Use slog.ErrorContext
-			return "", "", false, fmt.Errorf("failed to find user by openid: %w", err)
-		}
+// findOrCreateOAuthUser returns the user bound to an OAuth identity, registering a new user if there is none.
+func (s *userService) findOrCreateOAuthUser(ctx context.Context, providerName string, identity *OAuthIdentity) (*models.User, bool, error) {
+	// Case 1: User already bound to this identity.
+	user, err := s.userRepo.GetUserByProvider(ctx, providerName, identity.ProviderUID) // Pass context
+	if err != nil && err != gorm.ErrRecordNotFound {
+		logger.Error(ctx, "Failed to find user by provider", // Use slog.ErrorContext
+			"provider", providerName,
+			"providerUID", identity.ProviderUID,
+			"error", err)
+		return nil, false, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user != nil && user.ID != 0 {
+		return user, false, nil
 	}
 
-	// Case 2: If not found by openid and unionid is not empty, try finding by unionid.
-	if (user == nil || user.ID == 0) && unionid != "" {
-		user, err = s.userRepo.GetUserByUnionID(ctx, unionid) // Pass context
+	// Case 2: WeChat user known by unionid (e.g. registered from the Mini Program or another app); bind this openid.
+	if identity.UnionID != "" {
+		user, err = s.userRepo.GetUserByUnionID(ctx, identity.UnionID) // Pass context
 		if err != nil && err != gorm.ErrRecordNotFound {
-			logger.Error(ctx, "Failed to find user by unionid", "unionid", unionid, "error", err) // Use slog.ErrorContext
-			return "", "", false, fmt.Errorf("failed to find user by unionid: %w", err)
+			logger.Error(ctx, "Failed to find user by unionid", "unionid", identity.UnionID, "error", err) // Use slog.ErrorContext
+			return nil, false, fmt.Errorf("failed to find user by unionid: %w", err)
 		}
-
-		// Create UserProvider record if an existing user is found by unionid.
-		if user != nil && user.ID > 0 {
-			userProvider := models.UserProvider{
-				UserID:        user.ID,
-				Provider:      "wechat",
-				ProviderUID:   openid,
-				WechatUnionID: &unionid, // unionid might be empty, so use a pointer.
-			}
-			if err := s.userRepo.CreateUserProvider(ctx, &userProvider); err != nil { // Pass context
+		if user != nil && user.ID != 0 {
+			if err := s.userRepo.CreateUserProvider(ctx, newUserProvider(user.ID, providerName, identity)); err != nil { // Pass context
 				logger.Error(ctx, "Failed to create user provider for existing user by unionid", "error", err) // Use slog.ErrorContext
-				return "", "", false, fmt.Errorf("failed to create user provider: %w", err)
+				return nil, false, fmt.Errorf("failed to create user provider: %w", err)
 			}
 			logger.Info(ctx, "UserProvider created successfully for unionid", // Use slog.InfoContext
 				"userId", user.ID,
-				"provider", "wechat",
-				"providerUID", openid,
-				"unionID", unionid)
+				"provider", providerName,
+				"providerUID", identity.ProviderUID,
+				"unionID", identity.UnionID)
+			return user, false, nil
 		}
 	}
 
-	// Case 3: User not found by either unionid or openid; create a new user.
-	if user == nil || user.ID == 0 {
-		// Create a new user.
-		user = &models.User{
-			Name:   fmt.Sprintf("微信用户_%s", openid[len(openid)-6:]), // WeChat User_xxxxxx
-			Locale: "zh",                                           // Default to Chinese
-		}
-		if err := s.userRepo.CreateUser(ctx, user); err != nil { // Pass context
-			logger.Error(ctx, "Failed to create new user from wechat oauth", "error", err) // Use slog.ErrorContext
-			return "", "", false, fmt.Errorf("failed to create user: %w", err)
-		}
-		userProvider := models.UserProvider{
-			UserID:      user.ID,
-			Provider:    "wechat",
-			ProviderUID: openid,
-		}
-		if unionid != "" {
-			userProvider.WechatUnionID = &unionid
-		}
-		if err := s.userRepo.CreateUserProvider(ctx, &userProvider); err != nil { // Pass context
-			logger.Error(ctx, "Failed to create user provider for new user from wechat oauth", "error", err) // Use slog.ErrorContext
-			return "", "", false, fmt.Errorf("failed to create user provider: %w", err)
-		}
-		isNewUser = true
-
-		logger.Info(ctx, "New user created from WeChat OAuth", // Use slog.InfoContext
-			"userId", user.ID,
-			"provider", "wechat",
-			"providerUID", openid,
-			"unionID", unionid)
+	// Case 3: Register a new user.
+	user = &models.User{
+		Name:   identity.Name,
+		Locale: identity.Locale,
+	}
+	if user.Name == "" {
+		user.Name = "User " + utils.RandomString(6)
+	}
+	if user.Locale == "" {
+		user.Locale = "en" // Default language
+	}
+	if identity.AvatarURL != "" {
+		user.AvatarURL = &identity.AvatarURL
 	}
 
-	// Check if the user is banned.
-	if user.IsBanned {
-		return "", "", false, errors.ErrUserBanned
+	// The provider may omit the email. A private relay address (Apple "Hide My Email") forwards to the user's
+	// real inbox but never matches an existing account, so only real addresses can conflict.
+	if identity.Email != "" {
+		if _, err := s.userRepo.GetUserByField(ctx, "email", identity.Email, true); err == nil { // Pass context
+			if !identity.IsPrivateEmail {
+				return nil, false, errors.ErrEmailAlreadyExists
+			}
+			// A relay address can only collide with a previously unbound account; register without it.
+			logger.Warn(ctx, "Private relay email already in use, registering without email", "provider", providerName, "providerUID", identity.ProviderUID) // Use slog.WarnContext
+		} else {
+			user.Email = &identity.Email
+			user.IsEmailVerified = identity.EmailVerified
+		}
 	}
 
-	// 3. Generate JWT access token.
-	accessToken, err := jwt.GenerateAccessToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate access token from wechat oauth", "error", err, "userID", user.ID) // Use slog.ErrorContext
-		return "", "", false, fmt.Errorf("failed to generate access token: %w", err)
+	// Call repository layer to create the user.
+	if err := s.userRepo.CreateUser(ctx, user); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create user from OAuth registration", // Use slog.ErrorContext
+			"provider", providerName,
+			"providerUID", identity.ProviderUID,
+			"error", err)
+		return nil, false, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// 4. Generate JWT refresh token.
-	refreshToken, err := jwt.GenerateRefreshToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate refresh token from wechat oauth", "error", err, "userID", user.ID) // Use slog.ErrorContext
-		return "", "", false, fmt.Errorf("failed to generate refresh token: %w", err)
+	// Create UserProvider record.
+	if err := s.userRepo.CreateUserProvider(ctx, newUserProvider(user.ID, providerName, identity)); err != nil { // Pass context
+		logger.Error(ctx, "Failed to create user provider for OAuth registration", // Use slog.ErrorContext
+			"userId", user.ID,
+			"provider", providerName,
+			"providerUID", identity.ProviderUID,
+			"error", err)
+		return nil, false, fmt.Errorf("failed to create user provider: %w", err)
 	}
 
-	// Update last login time.
-	now := time.Now()
-	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
+	logger.Info(ctx, "User registered successfully with OAuth provider", // Use slog.InfoContext
+		"userId", user.ID,
+		"provider", providerName,
+		"providerUID", identity.ProviderUID,
+		"unionID", identity.UnionID)
 
-	return accessToken, refreshToken, isNewUser, nil
+	return user, true, nil
 }
 
-// BindWechatAccount binds a WeChat account to an existing user.
-func (s *userService) BindWechatAccount(ctx context.Context, userID uint, req *dto.BindWechatAccountRequest, authenticatedUser *models.User) error {
+// newUserProvider builds the binding record of an OAuth identity.
+func newUserProvider(userID uint, providerName string, identity *OAuthIdentity) *models.UserProvider {
+	userProvider := &models.UserProvider{
+		UserID:      userID,
+		Provider:    providerName,
+		ProviderUID: identity.ProviderUID,
+	}
+	if identity.UnionID != "" {
+		unionID := identity.UnionID
+		userProvider.WechatUnionID = &unionID
+	}
+	return userProvider
+}
+
+// BindOAuthAccount binds an OAuth provider account to an existing user.
+func (s *userService) BindOAuthAccount(ctx context.Context, userID uint, providerName string, req *dto.BindOAuthAccountRequest, authenticatedUser *models.User) error {
 	// Permission check: ensure the user can only bind their own account.
 	if userID != authenticatedUser.ID {
-		logger.Warn(ctx, "Permission denied for WeChat account binding", "userId", userID, "requesterId", authenticatedUser.ID) // Use slog.WarnContext
+		logger.Warn(ctx, "Permission denied for account binding", "provider", providerName, "userId", userID, "requesterId", authenticatedUser.ID) // Use slog.WarnContext
 		return errors.ErrPermissionDenied
 	}
 
-	// 1. Exchange code for access_token and openid/unionid.
-	appid := ""
-	secret := ""
-	if req.ClientType == "web" {
-		appid = s.config.Wechat.Web.AppID
-		secret = s.config.Wechat.Web.Secret
-	} else if req.ClientType == "app" {
-		appid = s.config.Wechat.App.AppID
-		secret = s.config.Wechat.App.Secret
-	} else {
-		return fmt.Errorf("invalid client_type")
+	provider, ok := s.oauthProviders.Get(providerName)
+	if !ok {
+		return errors.ErrOAuthProviderNotFound
 	}
 
-	url := fmt.Sprintf("https://api.weixin.qq.com/sns/oauth2/access_token?appid=%s&secret=%s&code=%s&grant_type=authorization_code", appid, secret, req.Code)
-	client := &http.Client{Timeout: 10 * time.Second}
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil) // Use http.NewRequestWithContext
-	if err != nil {
-		logger.Error(ctx, "Failed to create http request for wechat oauth binding", "error", err, "url", url)
-		return fmt.Errorf("failed to create http request for wechat oauth binding: %w", err)
-	}
-	resp, err := client.Do(httpReq)
+	// 1. Exchange the authorization code or verify the ID token.
+	identity, err := provider.Authenticate(ctx, &req.OAuthTokenRequest) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to request wechat oauth for binding", "error", err, "url", url)
-		return fmt.Errorf("failed to request wechat oauth: %w", err)
-	}
-	defer resp.Body.Close()
-
-	var tokenResp WechatOAuthTokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		logger.Error(ctx, "Failed to decode wechat oauth response for binding", "error", err)
-		return fmt.Errorf("failed to decode wechat oauth response: %w", err)
-	}
-	if tokenResp.ErrCode != 0 {
-		logger.Error(ctx, "Wechat oauth error for binding", "errcode", tokenResp.ErrCode, "errmsg", tokenResp.ErrMsg)
-		return fmt.Errorf("wechat oauth error: %s (%d)", tokenResp.ErrMsg, tokenResp.ErrCode)
+		logger.Warn(ctx, "OAuth authentication failed for binding", "provider", providerName, "userId", userID, "error", err) // Use slog.WarnContext
+		return err
 	}
 
-	openid := tokenResp.OpenID
-	unionid := tokenResp.UnionID
-
-	// 2. Check if this WeChat account is already bound to another user.
-	existingProvider, err := s.userRepo.GetUserByProvider(ctx, "wechat", openid) // Pass context
+	// 2. Check if this provider account is already bound to another user.
+	existingProvider, err := s.userRepo.GetUserByProvider(ctx, providerName, identity.ProviderUID) // Pass context
 	if err != nil && err != gorm.ErrRecordNotFound {
-		logger.Error(ctx, "Failed to check existing WeChat provider for binding", "openId", openid, "error", err) // Use slog.ErrorContext
+		logger.Error(ctx, "Failed to check existing provider for binding", "provider", providerName, "providerUID", identity.ProviderUID, "error", err) // Use slog.ErrorContext
 		return fmt.Errorf("failed to check existing provider: %w", err)
 	}
 
@@ -1006,287 +1700,91 @@ func (s *userService) BindWechatAccount(ctx context.Context, userID uint, req *d
 		return errors.ErrProviderAlreadyBound
 	}
 
-	// 3. Check if the current user has already bound a WeChat account.
-	_, err = s.userRepo.GetUserProvider(ctx, userID, "wechat") // Pass context
+	// 3. Check if the current user has already bound an account of this provider.
+	_, err = s.userRepo.GetUserProvider(ctx, userID, providerName) // Pass context
 	if err == nil {
 		return errors.ErrProviderAlreadyBound
 	} else if err != gorm.ErrRecordNotFound {
-		logger.Error(ctx, "Failed to check user's WeChat provider for binding", "userId", userID, "error", err) // Use slog.ErrorContext
+		logger.Error(ctx, "Failed to check user's provider for binding", "provider", providerName, "userId", userID, "error", err) // Use slog.ErrorContext
 		return fmt.Errorf("failed to check user provider: %w", err)
 	}
 
 	// 4. Create the binding record.
-	userProvider := &models.UserProvider{
-		UserID:      userID,
-		Provider:    "wechat",
-		ProviderUID: openid,
-	}
-	if unionid != "" {
-		userProvider.WechatUnionID = &unionid
-	}
-
-	if err := s.userRepo.CreateUserProvider(ctx, userProvider); err != nil { // Pass context
-		logger.Error(ctx, "Failed to bind WeChat account", // Use slog.ErrorContext
+	if err := s.userRepo.CreateUserProvider(ctx, newUserProvider(userID, providerName, identity)); err != nil { // Pass context
+		logger.Error(ctx, "Failed to bind provider account", // Use slog.ErrorContext
 			"userId", userID,
-			"provider", "wechat",
-			"providerUID", openid,
-			"unionID", unionid,
+			"provider", providerName,
+			"providerUID", identity.ProviderUID,
 			"error", err)
-		return fmt.Errorf("failed to bind WeChat account: %w", err)
+		return fmt.Errorf("failed to bind %s account: %w", providerName, err)
 	}
 
-	logger.Info(ctx, "WeChat account bound successfully", // Use slog.InfoContext
+	logger.Info(ctx, "Provider account bound successfully", // Use slog.InfoContext
 		"userId", userID,
-		"provider", "wechat",
-		"providerUID", openid,
-		"unionID", unionid)
+		"provider", providerName,
+		"providerUID", identity.ProviderUID,
+		"unionID", identity.UnionID)
 
 	return nil
 }
 
-// UnbindWechatAccount unbinds a WeChat account from a user.
-func (s *userService) UnbindWechatAccount(ctx context.Context, userID uint, authenticatedUser *models.User) error {
+// UnbindOAuthAccount unbinds an OAuth provider account from a user.
+func (s *userService) UnbindOAuthAccount(ctx context.Context, userID uint, providerName string, authenticatedUser *models.User) error {
 	// Permission check: ensure the user can only unbind their own account.
 	if userID != authenticatedUser.ID {
-		logger.Warn(ctx, "Permission denied for WeChat account unbinding", "userId", userID, "requesterId", authenticatedUser.ID) // Use slog.WarnContext
+		logger.Warn(ctx, "Permission denied for account unbinding", "provider", providerName, "userId", userID, "requesterId", authenticatedUser.ID) // Use slog.WarnContext
 		return errors.ErrPermissionDenied
 	}
 
-	// Check if the user has a verified email, otherwise unbinding could lock them out.
-	user, err := s.GetUser(ctx, userID) // Pass context
-	if err != nil {
-		return err
+	if _, ok := s.oauthProviders.Get(providerName); !ok {
+		return errors.ErrOAuthProviderNotFound
 	}
 
-	if user.Email == nil || !user.IsEmailVerified {
-		return errors.ErrEmailNotVerified
-	}
-
-	// Check if a WeChat account is bound.
-	_, err = s.userRepo.GetUserProvider(ctx, userID, "wechat") // Pass context
+	// Check if an account of this provider is bound.
+	_, err := s.userRepo.GetUserProvider(ctx, userID, providerName) // Pass context
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return errors.ErrProviderNotBound
 		}
-		logger.Error(ctx, "Failed to check user's WeChat provider for unbinding", "userId", userID, "error", err) // Use slog.ErrorContext
+		logger.Error(ctx, "Failed to check user's provider for unbinding", "provider", providerName, "userId", userID, "error", err) // Use slog.ErrorContext
 		return fmt.Errorf("failed to check user provider: %w", err)
 	}
 
-	// Delete the binding record.
-	if err := s.userRepo.DeleteUserProvider(ctx, userID, "wechat"); err != nil { // Pass context
-		logger.Error(ctx, "Failed to unbind WeChat account", "userId", userID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to unbind WeChat account: %w", err)
-	}
-
-	logger.Info(ctx, "WeChat account unbound successfully", "userId", userID) // Use slog.InfoContext
-	return nil
-}
-
-/*
-Google OAuth2.0 (App/Web)
-*/
-
-// ExchangeGoogleOAuth handles Google OAuth2.0 (automatically determines login/registration).
-func (s *userService) ExchangeGoogleOAuth(ctx context.Context, req *dto.GoogleOAuthRequest) (string, string, bool, error) {
-	// 1. Exchange auth code for user information.
-	googleUserInfo, err := s.googleOAuthService.ExchangeCodeForUserInfo(
-		ctx, // Pass context
-		req.Code,
-		req.CodeVerifier,
-		req.RedirectURI,
-		req.ClientType,
-	)
-	if err != nil {
-		logger.Error(ctx, "Failed to exchange Google OAuth code", "error", err) // Use slog.ErrorContext
-		return "", "", false, err
-	}
-
-	// 2. Check if the user has already registered via Google.
-	var user *models.User
-	isNewUser := false
-
-	// Case 1: User already exists.
-	user, err = s.userRepo.GetUserByProvider(ctx, "google", googleUserInfo.ID) // Pass context
-	if err != nil && err != gorm.ErrRecordNotFound {
-		logger.Error(ctx, "Failed to find user by Google provider", // Use slog.ErrorContext
-			"providerUID", googleUserInfo.ID,
-			"error", err)
-		return "", "", false, fmt.Errorf("failed to find user: %w", err)
-	}
-
-	// Case 2: User does not exist, register a new user.
-	if user == nil || user.ID == 0 {
-		// Check if the email is already used by another user.
-		if _, err := s.userRepo.GetUserByField(ctx, "email", googleUserInfo.Email, true); err == nil { // Pass context
-			return "", "", false, errors.ErrEmailAlreadyExists
-		}
-
-		// Create new User model.
-		user = &models.User{
-			Name:            googleUserInfo.Name,
-			Email:           &googleUserInfo.Email,
-			Locale:          "en", // Default language
-			IsEmailVerified: true, // Email from Google is considered verified.
-		}
-
-		// Use avatar URL from Google if provided.
-		if googleUserInfo.Picture != "" {
-			user.AvatarURL = &googleUserInfo.Picture
-		}
-
-		// Use language preference from Google if provided.
-		if googleUserInfo.Locale != "" {
-			user.Locale = googleUserInfo.Locale
-		}
-
-		// Call repository layer to create the user.
-		if err := s.userRepo.CreateUser(ctx, user); err != nil { // Pass context
-			logger.Error(ctx, "Failed to create user from Google registration", // Use slog.ErrorContext
-				"email", googleUserInfo.Email,
-				"googleId", googleUserInfo.ID,
-				"error", err)
-			return "", "", false, fmt.Errorf("failed to create user: %w", err)
-		}
-
-		// Create UserProvider record.
-		userProvider := models.UserProvider{
-			UserID:      user.ID,
-			Provider:    "google",
-			ProviderUID: googleUserInfo.ID,
-		}
-
-		if err := s.userRepo.CreateUserProvider(ctx, &userProvider); err != nil { // Pass context
-			logger.Error(ctx, "Failed to create user provider for Google registration", // Use slog.ErrorContext
-				"userId", user.ID,
-				"provider", "google",
-				"providerUID", googleUserInfo.ID,
-				"error", err)
-			return "", "", false, fmt.Errorf("failed to create user provider: %w", err)
-		}
-		isNewUser = true
-
-		logger.Info(ctx, "User registered successfully with Google", // Use slog.InfoContext
-			"userId", user.ID,
-			"email", googleUserInfo.Email,
-			"providerUID", googleUserInfo.ID)
-	}
-
-	// Check if the user is banned.
-	if user.IsBanned {
-		logger.Warn(ctx, "User is banned", "userId", user.ID, "email", googleUserInfo.Email) // Use slog.WarnContext
-		return "", "", false, errors.ErrUserBanned
-	}
-
-	// 3. Generate JWT access token.
-	accessToken, err := jwt.GenerateAccessToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate access token from google oauth", "error", err, "userId", user.ID) // Use slog.ErrorContext
-		return "", "", false, fmt.Errorf("failed to generate access token: %w", err)
-	}
-
-	// 4. Generate JWT refresh token.
-	refreshToken, err := jwt.GenerateRefreshToken(user.ID, user.Role, s.config.JWT.Secret, s.config.JWT.ExpireHours)
-	if err != nil {
-		logger.Error(ctx, "Failed to generate refresh token from google oauth", "error", err, "userId", user.ID) // Use slog.ErrorContext
-		return "", "", false, fmt.Errorf("failed to generate refresh token: %w", err)
-	}
-
-	// Update last login time.
-	now := time.Now()
-	s.userRepo.UpdateUser(ctx, user.ID, map[string]interface{}{"last_login": now}) // Pass context
-
-	return accessToken, refreshToken, isNewUser, nil
-}
-
-// BindGoogleAccount binds a Google account to an existing user.
-func (s *userService) BindGoogleAccount(ctx context.Context, userID uint, req *dto.BindGoogleAccountRequest, authenticatedUser *models.User) error {
-	// Permission check: ensure the user can only bind their own account.
-	if userID != authenticatedUser.ID {
-		logger.Warn(ctx, "Permission denied for Google account binding", "userId", userID, "requesterId", authenticatedUser.ID) // Use slog.WarnContext
-		return errors.ErrPermissionDenied
-	}
-
-	// 1. Exchange auth code for user information.
-	googleUserInfo, err := s.googleOAuthService.ExchangeCodeForUserInfo(
-		ctx, // Pass context
-		req.Code,
-		req.CodeVerifier,
-		req.RedirectURI,
-		req.ClientType,
-	)
+	// Make sure the user can still sign in afterwards, otherwise unbinding would lock them out.
+	user, err := s.GetUser(ctx, userID) // Pass context
 	if err != nil {
-		logger.Error(ctx, "Failed to exchange Google OAuth code for binding", "error", err, "userId", userID) // Use slog.ErrorContext
 		return err
 	}
-
-	// 2. Check if this Google account is already bound to another user.
-	existingProvider, err := s.userRepo.GetUserByProvider(ctx, "google", googleUserInfo.ID) // Pass context
-	if err != nil && err != gorm.ErrRecordNotFound {
-		logger.Error(ctx, "Failed to check existing Google provider for binding", "googleId", googleUserInfo.ID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to check existing provider: %w", err)
-	}
-
-	if existingProvider != nil && existingProvider.ID != 0 {
-		return errors.ErrProviderAlreadyBound
-	}
-
-	// 3. Check if the current user has already bound a Google account.
-	_, err = s.userRepo.GetUserProvider(ctx, userID, "google") // Pass context
-	if err == nil {
-		return errors.ErrProviderAlreadyBound
-	} else if err != gorm.ErrRecordNotFound {
-		logger.Error(ctx, "Failed to check user's Google provider for binding", "userId", userID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to check user provider: %w", err)
-	}
-
-	// 4. Create the binding record.
-	userProvider := &models.UserProvider{
-		UserID:      userID,
-		Provider:    "google",
-		ProviderUID: googleUserInfo.ID,
+	if !hasOtherLoginMethod(user, providerName) {
+		return errors.ErrLastLoginMethod
 	}
 
-	if err := s.userRepo.CreateUserProvider(ctx, userProvider); err != nil { // Pass context
-		logger.Error(ctx, "Failed to bind Google account", // Use slog.ErrorContext
-			"userId", userID,
-			"provider", "google",
-			"providerUID", googleUserInfo.ID,
-			"error", err)
-		return fmt.Errorf("failed to bind Google account: %w", err)
+	// Delete the binding record.
+	if err := s.userRepo.DeleteUserProvider(ctx, userID, providerName); err != nil { // Pass context
+		logger.Error(ctx, "Failed to unbind provider account", "provider", providerName, "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to unbind %s account: %w", providerName, err)
 	}
 
-	logger.Info(ctx, "Google account bound successfully", // Use slog.InfoContext
-		"userId", userID,
-		"provider", "google",
-		"providerUID", googleUserInfo.ID)
-
+	logger.Info(ctx, "Provider account unbound successfully", "provider", providerName, "userId", userID) // Use slog.InfoContext
 	return nil
 }
 
-// UnbindGoogleAccount unbinds a Google account from a user.
-func (s *userService) UnbindGoogleAccount(ctx context.Context, userID uint, authenticatedUser *models.User) error {
-	// Permission check: ensure the user can only unbind their own account.
-	if userID != authenticatedUser.ID {
-		logger.Warn(ctx, "Permission denied for Google account unbinding", "userId", userID, "requesterId", authenticatedUser.ID) // Use slog.WarnContext
-		return errors.ErrPermissionDenied
+// hasOtherLoginMethod reports whether the user can sign in without the given provider: with a password,
+// a verified email (magic link) or phone number (SMS code), or another bound provider.
+func hasOtherLoginMethod(user *models.User, providerName string) bool {
+	if user.Password != nil {
+		return true
 	}
-
-	// Check if a Google account is bound.
-	_, err := s.userRepo.GetUserProvider(ctx, userID, "google") // Pass context
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.ErrProviderNotBound
-		}
-		logger.Error(ctx, "Failed to check user's Google provider for unbinding", "userId", userID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to check user provider: %w", err)
+	if user.Email != nil && user.IsEmailVerified {
+		return true
 	}
-
-	// Delete the binding record.
-	if err := s.userRepo.DeleteUserProvider(ctx, userID, "google"); err != nil { // Pass context
-		logger.Error(ctx, "Failed to unbind Google account", "userId", userID, "error", err) // Use slog.ErrorContext
-		return fmt.Errorf("failed to unbind Google account: %w", err)
+	if user.Phone != nil && user.IsPhoneVerified {
+		return true
 	}
-
-	logger.Info(ctx, "Google account unbound successfully", "userId", userID) // Use slog.InfoContext
-	return nil
+	for _, userProvider := range user.UserProviders {
+		if userProvider.Provider != providerName {
+			return true
+		}
+	}
+	return false
 }
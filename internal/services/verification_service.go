@@ -5,12 +5,16 @@ import (
 	"crypto/rand"
 	"fmt"
 	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/go-backend-template/pkg/logger"
 	"github.com/redis/go-redis/v9"
 )
 
+// maxVerificationAttempts is the number of wrong guesses after which a code or token is invalidated.
+const maxVerificationAttempts = 5
+
 // VerificationService defines the interface for the verification code service.
 type VerificationService interface {
 	// GenerateEmailVerificationCode generates and stores an email verification code.
@@ -21,6 +25,26 @@ type VerificationService interface {
 	GeneratePasswordResetToken(ctx context.Context, email string) (string, error)
 	// VerifyPasswordResetToken verifies a password reset token.
 	VerifyPasswordResetToken(ctx context.Context, email, token string) (bool, error)
+	// GeneratePhoneVerificationCode generates and stores a phone verification (or login) code.
+	GeneratePhoneVerificationCode(ctx context.Context, phone string) (string, error)
+	// VerifyPhoneVerificationCode verifies a phone verification (or login) code.
+	VerifyPhoneVerificationCode(ctx context.Context, phone, code string) (bool, error)
+	// GenerateMagicLinkID generates and stores the one-time ID of an email login link.
+	GenerateMagicLinkID(ctx context.Context, email string, ttl time.Duration) (string, error)
+	// ConsumeMagicLinkID verifies and invalidates the ID of an email login link.
+	ConsumeMagicLinkID(ctx context.Context, email, linkID string) (bool, error)
+	// StoreMFAPendingTokenID stores the ID (jti) of an MFA pending token for the lifetime of the token.
+	StoreMFAPendingTokenID(ctx context.Context, userID uint, tokenID string, ttl time.Duration) error
+	// ConsumeMFAPendingTokenID verifies and invalidates the ID of an MFA pending token.
+	ConsumeMFAPendingTokenID(ctx context.Context, userID uint, tokenID string) (bool, error)
+	// GenerateEmailChangeCode generates and stores the code confirming a user's change to a new email address.
+	GenerateEmailChangeCode(ctx context.Context, userID uint, newEmail string) (string, error)
+	// VerifyEmailChangeCode verifies and invalidates the code confirming a user's change to a new email address.
+	VerifyEmailChangeCode(ctx context.Context, userID uint, newEmail, code string) (bool, error)
+	// GeneratePhoneChangeCode generates and stores the code confirming a user's change to a new phone number.
+	GeneratePhoneChangeCode(ctx context.Context, userID uint, newPhone string) (string, error)
+	// VerifyPhoneChangeCode verifies and invalidates the code confirming a user's change to a new phone number.
+	VerifyPhoneChangeCode(ctx context.Context, userID uint, newPhone, code string) (bool, error)
 }
 
 // verificationService is the implementation of the VerificationService.
@@ -47,7 +71,11 @@ func (s *verificationService) GenerateEmailVerificationCode(ctx context.Context,
 	key := fmt.Sprintf("email_verification:%s", email)
 	// ctx := context.Background() // Use passed context
 
-	err = s.redis.Set(ctx, key, code, 10*time.Minute).Err()
+	// A new code resets the wrong attempts counter.
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, key, code, 10*time.Minute)
+	pipe.Del(ctx, attemptsKey(key))
+	_, err = pipe.Exec(ctx)
 	if err != nil {
 		logger.Error(ctx, "Failed to store verification code in Redis", "email", email, "error", err) // Use slog.ErrorContext
 		return "", fmt.Errorf("failed to store verification code: %w", err)
@@ -62,25 +90,23 @@ func (s *verificationService) VerifyEmailVerificationCode(ctx context.Context, e
 	key := fmt.Sprintf("email_verification:%s", email)
 	// ctx := context.Background() // Use passed context
 
-	storedCode, err := s.redis.Get(ctx, key).Result()
+	// The code is deleted after successful verification (one-time use).
+	result, err := s.checkCode(ctx, key, code)
 	if err != nil {
-		if err == redis.Nil {
-			logger.Warn(ctx, "Email verification code not found or expired", "email", email) // Use slog.WarnContext
-			return false, nil
-		}
-		logger.Error(ctx, "Failed to get verification code from Redis", "email", email, "error", err) // Use slog.ErrorContext
+		logger.Error(ctx, "Failed to verify verification code in Redis", "email", email, "error", err) // Use slog.ErrorContext
 		return false, fmt.Errorf("failed to verify code: %w", err)
 	}
-
-	// Delete the code after successful verification (one-time use).
-	if storedCode == code {
-		s.redis.Del(ctx, key)
-		logger.Info(ctx, "Email verification code verified successfully", "email", email) // Use slog.InfoContext
-		return true, nil
+	switch result {
+	case codeNotFound:
+		logger.Warn(ctx, "Email verification code not found or expired", "email", email) // Use slog.WarnContext
+		return false, nil
+	case codeMismatch:
+		logger.Warn(ctx, "Invalid email verification code", "email", email) // Use slog.WarnContext
+		return false, nil
 	}
 
-	logger.Warn(ctx, "Invalid email verification code", "email", email) // Use slog.WarnContext
-	return false, nil
+	logger.Info(ctx, "Email verification code verified successfully", "email", email) // Use slog.InfoContext
+	return true, nil
 }
 
 // GeneratePasswordResetToken generates and stores a password reset token.
@@ -95,7 +121,11 @@ func (s *verificationService) GeneratePasswordResetToken(ctx context.Context, em
 	key := fmt.Sprintf("password_reset:%s", email)
 	// ctx := context.Background() // Use passed context
 
-	err = s.redis.Set(ctx, key, token, 30*time.Minute).Err()
+	// A new token resets the wrong attempts counter.
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, key, token, 30*time.Minute)
+	pipe.Del(ctx, attemptsKey(key))
+	_, err = pipe.Exec(ctx)
 	if err != nil {
 		logger.Error(ctx, "Failed to store password reset token in Redis", "email", email, "error", err) // Use slog.ErrorContext
 		return "", fmt.Errorf("failed to store reset token: %w", err)
@@ -110,25 +140,336 @@ func (s *verificationService) VerifyPasswordResetToken(ctx context.Context, emai
 	key := fmt.Sprintf("password_reset:%s", email)
 	// ctx := context.Background() // Use passed context
 
-	storedToken, err := s.redis.Get(ctx, key).Result()
+	// The token is deleted after successful verification (one-time use).
+	result, err := s.checkCode(ctx, key, token)
+	if err != nil {
+		logger.Error(ctx, "Failed to verify reset token in Redis", "email", email, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to verify token: %w", err)
+	}
+	switch result {
+	case codeNotFound:
+		logger.Warn(ctx, "Password reset token not found or expired", "email", email) // Use slog.WarnContext
+		return false, nil
+	case codeMismatch:
+		logger.Warn(ctx, "Invalid password reset token", "email", email) // Use slog.WarnContext
+		return false, nil
+	}
+
+	logger.Info(ctx, "Password reset token verified successfully", "email", email) // Use slog.InfoContext
+	return true, nil
+}
+
+// GeneratePhoneVerificationCode generates and stores a phone verification (or login) code.
+func (s *verificationService) GeneratePhoneVerificationCode(ctx context.Context, phone string) (string, error) {
+	// Generate a 6-digit numeric code.
+	code, err := s.generateNumericCode(6)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+
+	// Store in Redis with an expiration of 10 minutes.
+	key := fmt.Sprintf("phone_verification:%s", phone)
+
+	// A new code resets the wrong attempts counter.
+	pipe := s.redis.TxPipeline()
+	pipe.Set(ctx, key, code, 10*time.Minute)
+	pipe.Del(ctx, attemptsKey(key))
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "Failed to store phone verification code in Redis", "phone", phone, "error", err) // Use slog.ErrorContext
+		return "", fmt.Errorf("failed to store verification code: %w", err)
+	}
+
+	logger.Info(ctx, "Phone verification code generated", "phone", phone) // Use slog.InfoContext
+	return code, nil
+}
+
+// VerifyPhoneVerificationCode verifies a phone verification (or login) code.
+func (s *verificationService) VerifyPhoneVerificationCode(ctx context.Context, phone, code string) (bool, error) {
+	key := fmt.Sprintf("phone_verification:%s", phone)
+
+	// The code is deleted after successful verification (one-time use).
+	result, err := s.checkCode(ctx, key, code)
+	if err != nil {
+		logger.Error(ctx, "Failed to verify phone verification code in Redis", "phone", phone, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to verify code: %w", err)
+	}
+	switch result {
+	case codeNotFound:
+		logger.Warn(ctx, "Phone verification code not found or expired", "phone", phone) // Use slog.WarnContext
+		return false, nil
+	case codeMismatch:
+		logger.Warn(ctx, "Invalid phone verification code", "phone", phone) // Use slog.WarnContext
+		return false, nil
+	}
+
+	logger.Info(ctx, "Phone verification code verified successfully", "phone", phone) // Use slog.InfoContext
+	return true, nil
+}
+
+// GenerateMagicLinkID generates and stores the one-time ID of an email login link.
+// Only the latest link sent to an email address is valid.
+func (s *verificationService) GenerateMagicLinkID(ctx context.Context, email string, ttl time.Duration) (string, error) {
+	// Generate a 32-character alphanumeric ID.
+	linkID, err := s.generateAlphanumericToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate magic link ID: %w", err)
+	}
+
+	key := fmt.Sprintf("magic_link:%s", email)
+	if err := s.redis.Set(ctx, key, linkID, ttl).Err(); err != nil {
+		logger.Error(ctx, "Failed to store magic link ID in Redis", "email", email, "error", err) // Use slog.ErrorContext
+		return "", fmt.Errorf("failed to store magic link ID: %w", err)
+	}
+
+	logger.Info(ctx, "Magic link ID generated", "email", email) // Use slog.InfoContext
+	return linkID, nil
+}
+
+// ConsumeMagicLinkID verifies and invalidates the ID of an email login link.
+func (s *verificationService) ConsumeMagicLinkID(ctx context.Context, email, linkID string) (bool, error) {
+	key := fmt.Sprintf("magic_link:%s", email)
+
+	// The ID is compared and deleted in one step, so the link can only be used once, even by concurrent
+	// requests, and a newer link is never deleted by a request with an older one.
+	result, err := consumeScript.Run(ctx, s.redis, []string{key}, linkID).Int64()
+	if err != nil {
+		logger.Error(ctx, "Failed to consume magic link ID in Redis", "email", email, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to verify magic link: %w", err)
+	}
+	switch codeCheck(result) {
+	case codeNotFound:
+		logger.Warn(ctx, "Magic link not found, expired or already used", "email", email) // Use slog.WarnContext
+		return false, nil
+	case codeMismatch:
+		logger.Warn(ctx, "Magic link has been superseded by a newer one", "email", email) // Use slog.WarnContext
+		return false, nil
+	}
+
+	logger.Info(ctx, "Magic link redeemed successfully", "email", email) // Use slog.InfoContext
+	return true, nil
+}
+
+// StoreMFAPendingTokenID stores the ID of an MFA pending token, so that the token can only be exchanged once.
+func (s *verificationService) StoreMFAPendingTokenID(ctx context.Context, userID uint, tokenID string, ttl time.Duration) error {
+	key := fmt.Sprintf("mfa_pending:%s", tokenID)
+	if err := s.redis.Set(ctx, key, userID, ttl).Err(); err != nil {
+		logger.Error(ctx, "Failed to store MFA pending token ID in Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return fmt.Errorf("failed to store MFA pending token ID: %w", err)
+	}
+	return nil
+}
+
+// ConsumeMFAPendingTokenID verifies and invalidates the ID of an MFA pending token. GETDEL makes it atomic:
+// of several concurrent requests with the same token, only one can consume it.
+func (s *verificationService) ConsumeMFAPendingTokenID(ctx context.Context, userID uint, tokenID string) (bool, error) {
+	key := fmt.Sprintf("mfa_pending:%s", tokenID)
+
+	storedUserID, err := s.redis.GetDel(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			logger.Warn(ctx, "Password reset token not found or expired", "email", email) // Use slog.WarnContext
+			logger.Warn(ctx, "MFA pending token not found, expired or already used", "userId", userID) // Use slog.WarnContext
 			return false, nil
 		}
-		logger.Error(ctx, "Failed to get reset token from Redis", "email", email, "error", err) // Use slog.ErrorContext
-		return false, fmt.Errorf("failed to verify token: %w", err)
+		logger.Error(ctx, "Failed to consume MFA pending token ID in Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to verify MFA pending token: %w", err)
+	}
+	return storedUserID == strconv.FormatUint(uint64(userID), 10), nil
+}
+
+// GenerateEmailChangeCode generates and stores the code confirming an email change.
+// Only the latest requested address of a user can be confirmed.
+func (s *verificationService) GenerateEmailChangeCode(ctx context.Context, userID uint, newEmail string) (string, error) {
+	// Generate a 6-digit numeric code.
+	code, err := s.generateNumericCode(6)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate email change code: %w", err)
+	}
+
+	// Store the code together with the new address, with an expiration of 10 minutes.
+	// A new code resets the wrong attempts counter.
+	key := fmt.Sprintf("email_change:%d", userID)
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, key, attemptsKey(key))
+	pipe.HSet(ctx, key, "email", newEmail, "code", code)
+	pipe.Expire(ctx, key, 10*time.Minute)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "Failed to store email change code in Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return "", fmt.Errorf("failed to store email change code: %w", err)
+	}
+
+	logger.Info(ctx, "Email change code generated", "userId", userID, "newEmail", newEmail) // Use slog.InfoContext
+	return code, nil
+}
+
+// VerifyEmailChangeCode verifies an email change code for the requested address.
+func (s *verificationService) VerifyEmailChangeCode(ctx context.Context, userID uint, newEmail, code string) (bool, error) {
+	key := fmt.Sprintf("email_change:%d", userID)
+
+	// The code is deleted after successful verification (one-time use).
+	result, err := s.checkHashCode(ctx, key, "email", newEmail, "code", code)
+	if err != nil {
+		logger.Error(ctx, "Failed to verify email change code in Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to verify email change code: %w", err)
+	}
+	switch result {
+	case codeNotFound:
+		logger.Warn(ctx, "Email change code not found or expired", "userId", userID) // Use slog.WarnContext
+		return false, nil
+	case codeMismatch:
+		logger.Warn(ctx, "Invalid email change code", "userId", userID, "newEmail", newEmail) // Use slog.WarnContext
+		return false, nil
+	}
+
+	logger.Info(ctx, "Email change code verified successfully", "userId", userID, "newEmail", newEmail) // Use slog.InfoContext
+	return true, nil
+}
+
+// GeneratePhoneChangeCode generates and stores the code confirming a phone number change.
+// Only the latest requested number of a user can be confirmed.
+func (s *verificationService) GeneratePhoneChangeCode(ctx context.Context, userID uint, newPhone string) (string, error) {
+	// Generate a 6-digit numeric code.
+	code, err := s.generateNumericCode(6)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate phone change code: %w", err)
+	}
+
+	// Store the code together with the new number, with an expiration of 10 minutes.
+	// A new code resets the wrong attempts counter.
+	key := fmt.Sprintf("phone_change:%d", userID)
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, key, attemptsKey(key))
+	pipe.HSet(ctx, key, "phone", newPhone, "code", code)
+	pipe.Expire(ctx, key, 10*time.Minute)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "Failed to store phone change code in Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return "", fmt.Errorf("failed to store phone change code: %w", err)
+	}
+
+	logger.Info(ctx, "Phone change code generated", "userId", userID, "newPhone", newPhone) // Use slog.InfoContext
+	return code, nil
+}
+
+// VerifyPhoneChangeCode verifies a phone change code for the requested number.
+func (s *verificationService) VerifyPhoneChangeCode(ctx context.Context, userID uint, newPhone, code string) (bool, error) {
+	key := fmt.Sprintf("phone_change:%d", userID)
+
+	// The code is deleted after successful verification (one-time use).
+	result, err := s.checkHashCode(ctx, key, "phone", newPhone, "code", code)
+	if err != nil {
+		logger.Error(ctx, "Failed to verify phone change code in Redis", "userId", userID, "error", err) // Use slog.ErrorContext
+		return false, fmt.Errorf("failed to verify phone change code: %w", err)
+	}
+	switch result {
+	case codeNotFound:
+		logger.Warn(ctx, "Phone change code not found or expired", "userId", userID) // Use slog.WarnContext
+		return false, nil
+	case codeMismatch:
+		logger.Warn(ctx, "Invalid phone change code", "userId", userID, "newPhone", newPhone) // Use slog.WarnContext
+		return false, nil
+	}
+
+	logger.Info(ctx, "Phone change code verified successfully", "userId", userID, "newPhone", newPhone) // Use slog.InfoContext
+	return true, nil
+}
+
+// codeCheck is the result of checking a code against the one stored in Redis.
+type codeCheck int64
+
+const (
+	codeNotFound codeCheck = -1 // No code, or it expired or was used
+	codeMismatch codeCheck = 0  // Wrong code
+	codeMatched  codeCheck = 1  // Right code, now deleted
+)
+
+// failedAttemptLua counts a wrong guess and invalidates the code after ARGV[1] wrong guesses, so a short code
+// cannot be brute-forced within its lifetime. The counter lives as long as the code it belongs to.
+const failedAttemptLua = `
+local attempts = redis.call('INCR', KEYS[2])
+if attempts >= tonumber(ARGV[1]) then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 0
+end
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+	redis.call('PEXPIRE', KEYS[2], ttl)
+end
+return 0
+`
+
+// checkCodeScript checks the code ARGV[2] stored at KEYS[1], with its attempts counter at KEYS[2]. Running
+// the check, the deletion and the attempts count as one script means concurrent requests can neither redeem
+// a code twice nor guess more than maxVerificationAttempts times.
+var checkCodeScript = redis.NewScript(`
+local stored = redis.call('GET', KEYS[1])
+if not stored then
+	return -1
+end
+if stored == ARGV[2] then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 1
+end
+` + failedAttemptLua)
+
+// checkHashCodeScript is checkCodeScript for codes stored as hashes: all of the field and value pairs
+// ARGV[2..] must match.
+var checkHashCodeScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local matched = true
+for i = 2, #ARGV, 2 do
+	if redis.call('HGET', KEYS[1], ARGV[i]) ~= ARGV[i + 1] then
+		matched = false
+	end
+end
+if matched then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 1
+end
+` + failedAttemptLua)
+
+// consumeScript deletes the value at KEYS[1] if it is ARGV[1], without counting wrong attempts.
+var consumeScript = redis.NewScript(`
+local stored = redis.call('GET', KEYS[1])
+if not stored then
+	return -1
+end
+if stored ~= ARGV[1] then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+// checkCode checks a code stored as a string, deleting it if it matches and counting the attempt if not.
+func (s *verificationService) checkCode(ctx context.Context, key, code string) (codeCheck, error) {
+	result, err := checkCodeScript.Run(ctx, s.redis, []string{key, attemptsKey(key)}, maxVerificationAttempts, code).Int64()
+	if err != nil {
+		return codeNotFound, err
 	}
+	return codeCheck(result), nil
+}
 
-	// Delete the token after successful verification (one-time use).
-	if storedToken == token {
-		s.redis.Del(ctx, key)
-		logger.Info(ctx, "Password reset token verified successfully", "email", email) // Use slog.InfoContext
-		return true, nil
+// checkHashCode checks a code stored as a hash with field and value pairs, like checkCode.
+func (s *verificationService) checkHashCode(ctx context.Context, key string, fieldValues ...string) (codeCheck, error) {
+	args := make([]interface{}, 0, len(fieldValues)+1)
+	args = append(args, maxVerificationAttempts)
+	for _, fieldValue := range fieldValues {
+		args = append(args, fieldValue)
 	}
+	result, err := checkHashCodeScript.Run(ctx, s.redis, []string{key, attemptsKey(key)}, args...).Int64()
+	if err != nil {
+		return codeNotFound, err
+	}
+	return codeCheck(result), nil
+}
 
-	logger.Warn(ctx, "Invalid password reset token", "email", email) // Use slog.WarnContext
-	return false, nil
+// attemptsKey returns the Redis key counting wrong attempts for a code.
+func attemptsKey(key string) string {
+	return key + ":attempts"
 }
 
 // generateNumericCode generates a numeric verification code of a given length.
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-backend-template/config"
+	"github.com/go-backend-template/internal/dto"
+	"github.com/go-backend-template/internal/errors"
+	"github.com/go-backend-template/pkg/logger"
+)
+
+// wechatOAuthTokenURL is the WeChat OAuth2 code exchange endpoint.
+const wechatOAuthTokenURL = "https://api.weixin.qq.com/sns/oauth2/access_token"
+
+// WechatOAuthTokenResponse is the response structure for WeChat OAuth2 code exchange.
+// [Reference] WeChat Login for Mobile Apps: https://developers.weixin.qq.com/doc/oplatform/Mobile_App/WeChat_Login/Development_Guide.html
+// [Reference] WeChat Login for Web Apps: https://developers.weixin.qq.com/doc/oplatform/Website_App/WeChat_Login/Wechat_Login.html
+type WechatOAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	OpenID       string `json:"openid"`
+	Scope        string `json:"scope"`
+	UnionID      string `json:"unionid,omitempty"`
+	ErrCode      int    `json:"errcode,omitempty"`
+	ErrMsg       string `json:"errmsg,omitempty"`
+}
+
+// wechatOAuthProvider implements WeChat login for Apps and websites. WeChat is not OpenID Connect compliant:
+// the code is exchanged for an openid (per app) and, if the app belongs to an Open Platform account, a unionid
+// shared with the other apps and the Mini Program.
+type wechatOAuthProvider struct {
+	name       string
+	config     config.OAuthProviderConfig
+	httpClient *http.Client
+}
+
+// newWechatOAuthProvider creates a WeChat OAuth2 provider. Each client's client_id and client_secret are
+// the AppID and AppSecret of the corresponding WeChat app.
+func newWechatOAuthProvider(name string, cfg config.OAuthProviderConfig) *wechatOAuthProvider {
+	return &wechatOAuthProvider{
+		name:       name,
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the provider name.
+func (p *wechatOAuthProvider) Name() string {
+	return p.name
+}
+
+// Authenticate exchanges the authorization code for the openid/unionid of the user.
+func (p *wechatOAuthProvider) Authenticate(ctx context.Context, req *dto.OAuthTokenRequest) (*OAuthIdentity, error) {
+	client, ok := p.config.Clients[req.ClientType]
+	if !ok {
+		return nil, errors.ErrInvalidClientType
+	}
+	if req.Code == "" {
+		return nil, errors.ErrInvalidOAuthCode
+	}
+
+	query := url.Values{}
+	query.Set("appid", client.ClientID)
+	query.Set("secret", client.ClientSecret)
+	query.Set("code", req.Code)
+	query.Set("grant_type", "authorization_code")
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, wechatOAuthTokenURL+"?"+query.Encode(), nil) // Use http.NewRequestWithContext
+	if err != nil {
+		logger.Error(ctx, "Failed to create http request for wechat oauth", "error", err)
+		return nil, fmt.Errorf("failed to create http request for wechat oauth: %w", err)
+	}
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		logger.Error(ctx, "Failed to request wechat oauth", "error", err)
+		return nil, fmt.Errorf("failed to request wechat oauth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp WechatOAuthTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		logger.Error(ctx, "Failed to decode wechat oauth response", "error", err)
+		return nil, fmt.Errorf("failed to decode wechat oauth response: %w", err)
+	}
+	if tokenResp.ErrCode != 0 || tokenResp.OpenID == "" {
+		logger.Error(ctx, "Wechat oauth error", "errcode", tokenResp.ErrCode, "errmsg", tokenResp.ErrMsg)
+		return nil, errors.ErrOAuthTokenExchange
+	}
+
+	openid := tokenResp.OpenID
+	suffix := openid
+	if len(suffix) > 6 {
+		suffix = suffix[len(suffix)-6:]
+	}
+
+	return &OAuthIdentity{
+		ProviderUID: openid,
+		UnionID:     tokenResp.UnionID,
+		Name:        fmt.Sprintf("微信用户_%s", suffix), // WeChat User_xxxxxx
+		Locale:      "zh",                           // Default to Chinese
+	}, nil
+}
@@ -5,53 +5,110 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // TokenType represents the type of JWT token
 type TokenType string
 
 const (
-	AccessToken  TokenType = "access"
-	RefreshToken TokenType = "refresh"
+	AccessToken     TokenType = "access"
+	RefreshToken    TokenType = "refresh"
+	MFAPendingToken TokenType = "mfa_pending" // Issued after the password step when a second factor is still required
+	MagicLinkToken  TokenType = "magic_link"  // Embedded in a one-time email login link
 )
 
+// MFAPendingTokenDuration is the lifetime of an MFA pending token
+const MFAPendingTokenDuration = 5 * time.Minute
+
+// MagicLinkTokenDuration is the lifetime of an email login link
+const MagicLinkTokenDuration = 15 * time.Minute
+
+// ImpersonationTokenDuration is the lifetime of an access token issued to an admin impersonating a user
+const ImpersonationTokenDuration = 15 * time.Minute
+
 // Claims represents the JWT claims structure
 type Claims struct {
-	UserID    uint      `json:"user_id"`
-	Role      string    `json:"role"`
-	TokenType TokenType `json:"token_type"` // Added field to distinguish token type
+	UserID         uint      `json:"user_id"`
+	Role           string    `json:"role"`                      // Legacy users.role column; admin access is granted by RBAC roles
+	TokenType      TokenType `json:"token_type"`                // Added field to distinguish token type
+	SessionID      string    `json:"sid,omitempty"`             // Login session the token belongs to
+	MFA            bool      `json:"mfa,omitempty"`             // Whether the login was completed with a second factor
+	Provider       string    `json:"provider,omitempty"`        // Login method of an MFA pending token, e.g. password or google
+	ImpersonatorID uint      `json:"impersonator_id,omitempty"` // Admin acting as the user, only set on impersonation tokens
+	ClientID       string    `json:"client_id,omitempty"`       // Third-party OAuth client the token was issued to
+	Scope          string    `json:"scope,omitempty"`           // Space-separated scopes granted to the OAuth client
 	jwt.RegisteredClaims
 }
 
+// Subject describes who a token is issued for
+type Subject struct {
+	UserID         uint
+	Role           string
+	SessionID      string
+	MFA            bool
+	Provider       string // Only set for MFA pending tokens
+	ImpersonatorID uint   // Only set for impersonation tokens
+	ClientID       string // Only set for tokens issued to OAuth clients
+	Scope          string // Only set for tokens issued to OAuth clients
+}
+
 // TokenDetails contains decoded token information
 type TokenDetails struct {
-	UserID    uint
-	Role      string
-	TokenType TokenType
+	UserID         uint
+	Role           string
+	TokenType      TokenType
+	SessionID      string    // Login session ID (sid claim), empty for tokens issued before sessions existed
+	MFA            bool      // Whether the login was completed with a second factor
+	Provider       string    // Login method the first factor was verified with (MFA pending tokens only)
+	TokenID        string    // Unique token identifier (jti claim)
+	ExpiresAt      time.Time // Expiration time (exp claim)
+	ImpersonatorID uint      // Admin acting as the user (impersonator_id claim), 0 unless issued for impersonation
+	ClientID       string    // OAuth client the token was issued to (client_id claim), empty for first-party tokens
+	Scope          string    // Space-separated scopes granted to the OAuth client (scope claim)
+}
+
+// NewTokenID generates a new unique token identifier for the jti claim
+func NewTokenID() string {
+	return uuid.NewString()
+}
+
+// RefreshTokenDuration returns the lifetime of a refresh token for the given access token lifetime
+func RefreshTokenDuration(jwt_expiration_hours int) time.Duration {
+	// Refresh token typically expires in 30 days (720 hours) or longer
+	refreshExpirationHours := jwt_expiration_hours * 10 // 10x longer than access token
+	if refreshExpirationHours < 720 {                   // minimum 30 days
+		refreshExpirationHours = 720
+	}
+	return time.Hour * time.Duration(refreshExpirationHours)
 }
 
 // generateTokenWithDuration creates a JWT token with custom expiration duration
-func generateTokenWithDuration(userID uint, role string, tokenType TokenType, jwt_secret string, duration time.Duration) (string, error) {
+func generateTokenWithDuration(subject Subject, tokenType TokenType, tokenID string, keys *KeySet, duration time.Duration) (string, error) {
 	// Set token expiration time
 	expirationTime := time.Now().Add(duration)
 
 	// Create claims with user ID, role, token type and expiration time
 	claims := &Claims{
-		UserID:    userID,
-		Role:      role,
-		TokenType: tokenType,
+		UserID:         subject.UserID,
+		Role:           subject.Role,
+		TokenType:      tokenType,
+		SessionID:      subject.SessionID,
+		MFA:            subject.MFA,
+		Provider:       subject.Provider,
+		ImpersonatorID: subject.ImpersonatorID,
+		ClientID:       subject.ClientID,
+		Scope:          subject.Scope,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			Issuer:    "go-backend-template",
 		},
 	}
 
-	// Create token with claims and signing method
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token with our secret
-	tokenString, err := token.SignedString([]byte(jwt_secret))
+	// Sign the token with the active key of the key set
+	tokenString, err := keys.sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -59,35 +116,50 @@ func generateTokenWithDuration(userID uint, role string, tokenType TokenType, jw
 	return tokenString, nil
 }
 
-// GenerateAccessToken creates a new JWT access token for a user
-func GenerateAccessToken(userID uint, role string, jwt_secret string, jwt_expiration_hours int) (string, error) {
+// GenerateAccessToken creates a new JWT access token for a user's login session
+func GenerateAccessToken(subject Subject, keys *KeySet, jwt_expiration_hours int) (string, error) {
 	duration := time.Hour * time.Duration(jwt_expiration_hours)
-	return generateTokenWithDuration(userID, role, AccessToken, jwt_secret, duration)
+	return generateTokenWithDuration(subject, AccessToken, NewTokenID(), keys, duration)
 }
 
-// GenerateRefreshToken creates a new JWT refresh token for a user
-func GenerateRefreshToken(userID uint, role string, jwt_secret string, jwt_expiration_hours int) (string, error) {
-	// Refresh token typically expires in 30 days (720 hours) or longer
-	refreshExpirationHours := jwt_expiration_hours * 10 // 10x longer than access token
-	if refreshExpirationHours < 720 {                   // minimum 30 days
-		refreshExpirationHours = 720
-	}
+// GenerateRefreshToken creates a new JWT refresh token for a user's login session.
+// The tokenID is stored as the jti claim so the token can be tracked server-side.
+func GenerateRefreshToken(subject Subject, tokenID string, keys *KeySet, jwt_expiration_hours int) (string, error) {
+	return generateTokenWithDuration(subject, RefreshToken, tokenID, keys, RefreshTokenDuration(jwt_expiration_hours))
+}
+
+// GenerateMFAPendingToken creates a short-lived token proving that the first login factor succeeded.
+// It can only be exchanged for an access token together with a valid second factor.
+// provider records the login method of the first factor, e.g. password or google.
+// The tokenID must also be stored server-side so the token can only be exchanged once.
+func GenerateMFAPendingToken(userID uint, role, provider, tokenID string, keys *KeySet) (string, error) {
+	return generateTokenWithDuration(Subject{UserID: userID, Role: role, Provider: provider}, MFAPendingToken, tokenID, keys, MFAPendingTokenDuration)
+}
+
+// GenerateMagicLinkToken creates a signed token for an email login link.
+// The tokenID must also be stored server-side so the link can only be redeemed once.
+func GenerateMagicLinkToken(userID uint, role, tokenID string, keys *KeySet) (string, error) {
+	return generateTokenWithDuration(Subject{UserID: userID, Role: role}, MagicLinkToken, tokenID, keys, MagicLinkTokenDuration)
+}
 
-	duration := time.Hour * time.Duration(refreshExpirationHours)
-	return generateTokenWithDuration(userID, role, RefreshToken, jwt_secret, duration)
+// GenerateImpersonationToken creates a short-lived access token that lets an admin act as a user.
+// The token carries the impersonator_id claim, has no login session and cannot be refreshed.
+func GenerateImpersonationToken(userID uint, role string, impersonatorID uint, tokenID string, keys *KeySet) (string, error) {
+	subject := Subject{UserID: userID, Role: role, ImpersonatorID: impersonatorID}
+	return generateTokenWithDuration(subject, AccessToken, tokenID, keys, ImpersonationTokenDuration)
+}
+
+// GenerateOAuthAccessToken creates an access token for a third-party OAuth client.
+// The subject must carry the client ID and the granted scopes; the token belongs to the session of the grant.
+func GenerateOAuthAccessToken(subject Subject, keys *KeySet, duration time.Duration) (string, error) {
+	return generateTokenWithDuration(subject, AccessToken, NewTokenID(), keys, duration)
 }
 
 // ValidateToken validates the JWT token and returns the user details
-func ValidateToken(tokenString string, jwt_secret string) (*TokenDetails, error) {
-	// Parse token
+func ValidateToken(tokenString string, keys *KeySet) (*TokenDetails, error) {
+	// Parse token, resolving the verification key (and validating the signing method) from the key set
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(jwt_secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.keyFunc)
 
 	// Check parsing errors
 	if err != nil {
@@ -100,9 +172,20 @@ func ValidateToken(tokenString string, jwt_secret string) (*TokenDetails, error)
 	}
 
 	// Return user details from claims
-	return &TokenDetails{
-		UserID:    claims.UserID,
-		Role:      claims.Role,
-		TokenType: claims.TokenType,
-	}, nil
+	details := &TokenDetails{
+		UserID:         claims.UserID,
+		Role:           claims.Role,
+		TokenType:      claims.TokenType,
+		SessionID:      claims.SessionID,
+		MFA:            claims.MFA,
+		Provider:       claims.Provider,
+		TokenID:        claims.ID,
+		ImpersonatorID: claims.ImpersonatorID,
+		ClientID:       claims.ClientID,
+		Scope:          claims.Scope,
+	}
+	if claims.ExpiresAt != nil {
+		details.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return details, nil
 }
@@ -0,0 +1,311 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// Key is a single asymmetric key identified by its key ID (kid)
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey // nil for verification-only keys
+	PublicKey  crypto.PublicKey
+}
+
+// KeySet holds the keys used to sign and verify tokens.
+// Tokens are signed with the active asymmetric key if one is set, otherwise with the HS256 secret.
+// Tokens are verified by their kid header against all known keys, so retired keys keep
+// verifying tokens issued before a rotation. Once an active key is set, HS256 tokens are only
+// accepted if legacy HS256 verification has been enabled.
+type KeySet struct {
+	secret      []byte
+	legacyHS256 bool
+	signingKey  *Key
+	keys        map[string]*Key
+	keyOrder    []string
+}
+
+// JWK represents a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA public exponent
+	Crv string `json:"crv,omitempty"` // OKP curve
+	X   string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSet represents a JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeySet creates a key set. A non-empty secret enables HS256 signing and verification while no
+// active key is set (see SetAcceptLegacyHS256 for HS256 tokens after switching to an active key).
+func NewKeySet(secret string) *KeySet {
+	ks := &KeySet{keys: make(map[string]*Key)}
+	if secret != "" {
+		ks.secret = []byte(secret)
+	}
+	return ks
+}
+
+// AddKey adds a signing or verification key to the key set
+func (ks *KeySet) AddKey(key *Key) error {
+	if key.ID == "" {
+		return fmt.Errorf("key id is required")
+	}
+	if _, exists := ks.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate key id: %s", key.ID)
+	}
+	if _, err := signingMethod(key.Algorithm); err != nil {
+		return err
+	}
+	ks.keys[key.ID] = key
+	ks.keyOrder = append(ks.keyOrder, key.ID)
+	return nil
+}
+
+// SetSigningKey selects the key used to sign new tokens
+func (ks *KeySet) SetSigningKey(kid string) error {
+	key, ok := ks.keys[kid]
+	if !ok {
+		return fmt.Errorf("unknown key id: %s", kid)
+	}
+	if key.PrivateKey == nil {
+		return fmt.Errorf("key %s has no private key and cannot sign tokens", kid)
+	}
+	ks.signingKey = key
+	return nil
+}
+
+// SetAcceptLegacyHS256 sets whether HS256 tokens signed with the secret are still accepted once an
+// active key is set. It is meant for the transition after switching to asymmetric keys: anyone holding
+// the secret can mint tokens for as long as it is accepted.
+func (ks *KeySet) SetAcceptLegacyHS256(accept bool) {
+	ks.legacyHS256 = accept
+}
+
+// acceptsHS256 reports whether tokens signed with the HS256 secret are verified.
+func (ks *KeySet) acceptsHS256() bool {
+	return ks.secret != nil && (ks.signingKey == nil || ks.legacyHS256)
+}
+
+// sign signs the token with the active key, or with the HS256 secret if no key is active
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	if ks.signingKey == nil {
+		if ks.secret == nil {
+			return "", fmt.Errorf("no signing key configured")
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(ks.secret)
+	}
+
+	method, err := signingMethod(ks.signingKey.Algorithm)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = ks.signingKey.ID
+	return token.SignedString(ks.signingKey.PrivateKey)
+}
+
+// keyFunc resolves the verification key for a parsed token
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	// Legacy tokens signed with the shared secret.
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if !ks.acceptsHS256() || token.Method.Alg() != AlgorithmHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return ks.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+	// The algorithm must match the key, never trust the alg header alone.
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public keys of the key set in JWK Set format
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, kid := range ks.keyOrder {
+		if jwk, ok := toJWK(ks.keys[kid]); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded private key (PKCS#1 or PKCS#8)
+func ParsePrivateKeyPEM(kid, algorithm string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM private key for key %s", kid)
+	}
+
+	var privateKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key for key %s: %w", kid, err)
+	}
+
+	key := &Key{ID: kid, Algorithm: algorithm, PrivateKey: privateKey}
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		key.PublicKey = &k.PublicKey
+	case ed25519.PrivateKey:
+		key.PublicKey = k.Public()
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T for key %s", privateKey, kid)
+	}
+
+	if err := checkKeyAlgorithm(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParsePublicKeyPEM parses a PEM encoded public key (PKIX or PKCS#1)
+func ParsePublicKeyPEM(kid, algorithm string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM public key for key %s", kid)
+	}
+
+	var publicKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key for key %s: %w", kid, err)
+	}
+
+	key := &Key{ID: kid, Algorithm: algorithm, PublicKey: publicKey}
+	if err := checkKeyAlgorithm(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// signingMethod maps a supported algorithm name to its signing method
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case AlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+}
+
+// checkKeyAlgorithm ensures the key type matches the configured algorithm
+func checkKeyAlgorithm(key *Key) error {
+	switch key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if key.Algorithm == AlgorithmRS256 {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if key.Algorithm == AlgorithmEdDSA {
+			return nil
+		}
+	}
+	return fmt.Errorf("key %s of type %T cannot be used with algorithm %s", key.ID, key.PublicKey, key.Algorithm)
+}
+
+// toJWK converts a public key to JWK format
+func toJWK(key *Key) (JWK, bool) {
+	switch pub := key.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: key.Algorithm,
+			Kid: key.ID,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: key.Algorithm,
+			Kid: key.ID,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// ParseJWK parses a public key in JWK format (RSA or Ed25519) into a verification-only key
+func ParseJWK(jwk JWK) (*Key, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("invalid RSA modulus for key %s", jwk.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA exponent for key %s", jwk.Kid)
+		}
+		algorithm := jwk.Alg
+		if algorithm == "" {
+			algorithm = AlgorithmRS256
+		}
+		key := &Key{
+			ID:        jwk.Kid,
+			Algorithm: algorithm,
+			PublicKey: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())},
+		}
+		if err := checkKeyAlgorithm(key); err != nil {
+			return nil, err
+		}
+		return key, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if jwk.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key for key %s", jwk.Kid)
+		}
+		return &Key{ID: jwk.Kid, Algorithm: AlgorithmEdDSA, PublicKey: ed25519.PublicKey(x)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q for key %s", jwk.Kty, jwk.Kid)
+	}
+}
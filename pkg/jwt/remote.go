@@ -0,0 +1,171 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultJWKSCacheDuration is how long fetched remote keys are cached
+const DefaultJWKSCacheDuration = time.Hour
+
+// minJWKSRefreshInterval limits refetching when tokens reference an unknown key id
+const minJWKSRefreshInterval = time.Minute
+
+// FlexibleBool is a boolean claim that some identity providers (e.g. Apple) encode as the string "true"/"false"
+type FlexibleBool bool
+
+// UnmarshalJSON accepts both JSON booleans and the strings "true" and "false"
+func (b *FlexibleBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", `"true"`:
+		*b = true
+	case "false", `"false"`, "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean value: %s", data)
+	}
+	return nil
+}
+
+// IDTokenClaims represents the claims of an OpenID Connect ID token issued by an external identity provider
+type IDTokenClaims struct {
+	Email          string       `json:"email"`
+	EmailVerified  FlexibleBool `json:"email_verified"`
+	IsPrivateEmail FlexibleBool `json:"is_private_email"` // Apple: the email is a private relay address
+	Name           string       `json:"name"`
+	GivenName      string       `json:"given_name"`
+	FamilyName     string       `json:"family_name"`
+	Picture        string       `json:"picture"`
+	Locale         string       `json:"locale"`
+	Nonce          string       `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// JWKSClient verifies tokens signed by an external identity provider against its published JWKS.
+// Keys are cached and refetched when they expire or a token references an unknown key id (key rotation).
+type JWKSClient struct {
+	url        string
+	cacheTTL   time.Duration
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	keys      *KeySet
+	fetchedAt time.Time
+}
+
+// NewJWKSClient creates a client for the JWKS published at url
+func NewJWKSClient(url string, cacheTTL time.Duration) *JWKSClient {
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultJWKSCacheDuration
+	}
+	return &JWKSClient{
+		url:        url,
+		cacheTTL:   cacheTTL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ValidateIDToken verifies the signature, issuer, audience and expiry of an ID token and returns its claims.
+// The token is accepted if its audience contains any of the given audiences.
+func (c *JWKSClient) ValidateIDToken(ctx context.Context, tokenString, issuer string, audiences []string) (*IDTokenClaims, error) {
+	// Resolve the key id from the unverified header, refreshing the keys if it is unknown.
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, &IDTokenClaims{})
+	if err != nil {
+		return nil, err
+	}
+	kid, _ := unverified.Header["kid"].(string)
+	keys, err := c.keySet(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &IDTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.keyFunc,
+		jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	for _, aud := range claims.Audience {
+		for _, allowed := range audiences {
+			if aud == allowed {
+				return claims, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("token audience %v is not allowed", []string(claims.Audience))
+}
+
+// keySet returns the cached keys, fetching them if they are stale or do not contain kid
+func (c *JWKSClient) keySet(ctx context.Context, kid string) (*KeySet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	age := time.Since(c.fetchedAt)
+	if c.keys != nil && age < c.cacheTTL {
+		if _, ok := c.keys.keys[kid]; ok || age < minJWKSRefreshInterval {
+			return c.keys, nil
+		}
+	}
+
+	keys, err := c.fetch(ctx)
+	if err != nil {
+		// Keep using the previous keys if the provider is temporarily unavailable.
+		if c.keys != nil {
+			return c.keys, nil
+		}
+		return nil, err
+	}
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	return keys, nil
+}
+
+// fetch downloads and parses the JWKS. Keys of unsupported types are skipped.
+func (c *JWKSClient) fetch(ctx context.Context) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := NewKeySet("")
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := ParseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		if err := keys.AddKey(key); err != nil {
+			continue
+		}
+	}
+	return keys, nil
+}
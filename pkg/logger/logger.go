@@ -95,6 +95,12 @@ func WithUserID(ctx context.Context, userID interface{}) context.Context {
 	return WithContext(ctx, logger)
 }
 
+// WithImpersonatorID adds the ID of the admin impersonating the user to the logger in context
+func WithImpersonatorID(ctx context.Context, impersonatorID interface{}) context.Context {
+	logger := FromContext(ctx).With("impersonator_id", impersonatorID)
+	return WithContext(ctx, logger)
+}
+
 // Convenience functions for common logging patterns
 func Info(ctx context.Context, msg string, args ...any) {
 	FromContext(ctx).InfoContext(ctx, msg, args...)
@@ -0,0 +1,163 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hash algorithms
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// ErrInvalidHash is returned when a stored hash is in an unknown or malformed format
+var ErrInvalidHash = errors.New("invalid password hash")
+
+// Params are the parameters used for new hashes
+type Params struct {
+	Algorithm   string // AlgorithmArgon2id or AlgorithmBcrypt
+	Memory      uint32 // argon2id memory in KiB
+	Iterations  uint32 // argon2id passes over the memory
+	Parallelism uint8  // argon2id lanes
+	SaltLength  uint32 // argon2id salt length in bytes
+	KeyLength   uint32 // argon2id hash length in bytes
+	BcryptCost  int    // bcrypt cost
+}
+
+// DefaultParams returns argon2id parameters following the OWASP recommendation (64 MiB, 3 iterations)
+func DefaultParams() Params {
+	return Params{
+		Algorithm:   AlgorithmArgon2id,
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+		BcryptCost:  12,
+	}
+}
+
+// Hasher hashes passwords with the configured parameters and verifies hashes created with any parameters
+type Hasher struct {
+	params Params
+}
+
+// NewHasher creates a hasher for the given parameters
+func NewHasher(params Params) (*Hasher, error) {
+	switch params.Algorithm {
+	case AlgorithmArgon2id:
+		if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 || params.SaltLength == 0 || params.KeyLength == 0 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+	case AlgorithmBcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost: %d", params.BcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm: %s", params.Algorithm)
+	}
+	return &Hasher{params: params}, nil
+}
+
+// Hash hashes a password with a random salt.
+// argon2id hashes use the PHC string format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.params.Algorithm == AlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.params.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks a password against a stored argon2id or bcrypt hash.
+// needsRehash reports whether the hash was created with another algorithm or other parameters than the
+// current ones, in which case the caller should store a new hash after a successful verification.
+func (h *Hasher) Verify(password, encoded string) (match bool, needsRehash bool, err error) {
+	if strings.HasPrefix(encoded, "$argon2id$") {
+		return h.verifyArgon2id(password, encoded)
+	}
+	if strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$") {
+		return h.verifyBcrypt(password, encoded)
+	}
+	return false, false, ErrInvalidHash
+}
+
+// verifyBcrypt verifies a bcrypt hash.
+func (h *Hasher) verifyBcrypt(password, encoded string) (bool, bool, error) {
+	if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		return false, false, ErrInvalidHash
+	}
+
+	if h.params.Algorithm != AlgorithmBcrypt {
+		return true, true, nil
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true, true, nil
+	}
+	return true, cost != h.params.BcryptCost, nil
+}
+
+// verifyArgon2id verifies an argon2id hash in the PHC string format.
+func (h *Hasher) verifyArgon2id(password, encoded string) (bool, bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return false, false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrInvalidHash
+	}
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil || memory == 0 || iterations == 0 || parallelism == 0 {
+		return false, false, ErrInvalidHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrInvalidHash
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false, nil
+	}
+
+	needsRehash := h.params.Algorithm != AlgorithmArgon2id ||
+		memory != h.params.Memory ||
+		iterations != h.params.Iterations ||
+		parallelism != h.params.Parallelism ||
+		uint32(len(salt)) != h.params.SaltLength ||
+		uint32(len(key)) != h.params.KeyLength
+	return true, needsRehash, nil
+}
@@ -0,0 +1,178 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Cheap parameters, so that the tests run fast.
+var (
+	argon2idParams = Params{Algorithm: AlgorithmArgon2id, Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	bcryptParams   = Params{Algorithm: AlgorithmBcrypt, BcryptCost: 4}
+)
+
+// mustHasher creates a hasher for valid parameters.
+func mustHasher(t *testing.T, params Params) *Hasher {
+	t.Helper()
+	h, err := NewHasher(params)
+	if err != nil {
+		t.Fatalf("NewHasher() error = %v", err)
+	}
+	return h
+}
+
+// mustHash hashes a password with the given parameters.
+func mustHash(t *testing.T, params Params, password string) string {
+	t.Helper()
+	hash, err := mustHasher(t, params).Hash(password)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	return hash
+}
+
+// withParams returns a copy of params changed by change.
+func withParams(params Params, change func(*Params)) Params {
+	change(&params)
+	return params
+}
+
+func TestNewHasher(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  Params
+		wantErr bool
+	}{
+		{"default", DefaultParams(), false},
+		{"argon2id", argon2idParams, false},
+		{"bcrypt", bcryptParams, false},
+		{"no algorithm", Params{}, true},
+		{"unknown algorithm", withParams(argon2idParams, func(p *Params) { p.Algorithm = "scrypt" }), true},
+		{"no memory", withParams(argon2idParams, func(p *Params) { p.Memory = 0 }), true},
+		{"no iterations", withParams(argon2idParams, func(p *Params) { p.Iterations = 0 }), true},
+		{"no parallelism", withParams(argon2idParams, func(p *Params) { p.Parallelism = 0 }), true},
+		{"no salt", withParams(argon2idParams, func(p *Params) { p.SaltLength = 0 }), true},
+		{"no key", withParams(argon2idParams, func(p *Params) { p.KeyLength = 0 }), true},
+		{"bcrypt cost too low", withParams(bcryptParams, func(p *Params) { p.BcryptCost = 3 }), true},
+		{"bcrypt cost too high", withParams(bcryptParams, func(p *Params) { p.BcryptCost = 32 }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewHasher(tt.params); (err != nil) != tt.wantErr {
+				t.Errorf("NewHasher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     Params
+		wantPrefix string
+	}{
+		{"argon2id", argon2idParams, "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{"bcrypt", bcryptParams, "$2a$04$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := mustHasher(t, tt.params)
+			hash, err := h.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("Hash() = %s, want prefix %s", hash, tt.wantPrefix)
+			}
+
+			// Salts are random.
+			other, err := h.Hash("correct horse")
+			if err != nil {
+				t.Fatalf("Hash() error = %v", err)
+			}
+			if other == hash {
+				t.Errorf("Hash() returned the same hash twice: %s", hash)
+			}
+
+			match, needsRehash, err := h.Verify("correct horse", hash)
+			if !match || needsRehash || err != nil {
+				t.Errorf("Verify() = %v, %v, %v, want true, false, nil", match, needsRehash, err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name            string
+		hashParams      Params // Parameters the hash was created with
+		params          Params // Current parameters
+		password        string
+		wantMatch       bool
+		wantNeedsRehash bool
+	}{
+		{"argon2id", argon2idParams, argon2idParams, "correct horse", true, false},
+		{"bcrypt", bcryptParams, bcryptParams, "correct horse", true, false},
+		{"bcrypt to argon2id", bcryptParams, argon2idParams, "correct horse", true, true},
+		{"argon2id to bcrypt", argon2idParams, bcryptParams, "correct horse", true, true},
+		{"bcrypt cost changed", bcryptParams, withParams(bcryptParams, func(p *Params) { p.BcryptCost = 5 }), "correct horse", true, true},
+		{"memory changed", argon2idParams, withParams(argon2idParams, func(p *Params) { p.Memory = 2048 }), "correct horse", true, true},
+		{"iterations changed", argon2idParams, withParams(argon2idParams, func(p *Params) { p.Iterations = 2 }), "correct horse", true, true},
+		{"parallelism changed", argon2idParams, withParams(argon2idParams, func(p *Params) { p.Parallelism = 2 }), "correct horse", true, true},
+		{"salt length changed", argon2idParams, withParams(argon2idParams, func(p *Params) { p.SaltLength = 24 }), "correct horse", true, true},
+		{"key length changed", argon2idParams, withParams(argon2idParams, func(p *Params) { p.KeyLength = 64 }), "correct horse", true, true},
+		{"argon2id wrong password", argon2idParams, argon2idParams, "wrong horse", false, false},
+		{"bcrypt wrong password", bcryptParams, bcryptParams, "wrong horse", false, false},
+		{"wrong password with old parameters", bcryptParams, argon2idParams, "wrong horse", false, false},
+		{"empty password", argon2idParams, argon2idParams, "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := mustHash(t, tt.hashParams, "correct horse")
+			match, needsRehash, err := mustHasher(t, tt.params).Verify(tt.password, hash)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if match != tt.wantMatch || needsRehash != tt.wantNeedsRehash {
+				t.Errorf("Verify() = %v, %v, want %v, %v", match, needsRehash, tt.wantMatch, tt.wantNeedsRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyInvalidHash(t *testing.T) {
+	valid := mustHash(t, argon2idParams, "correct horse")
+	parts := strings.Split(valid, "$") // "", "argon2id", "v=19", "m=1024,t=1,p=1", salt, key
+	salt, key := parts[4], parts[5]
+
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"plain text", "correct horse"},
+		{"unknown algorithm", "$argon2i$v=19$m=1024,t=1,p=1$" + salt + "$" + key},
+		{"missing key", "$argon2id$v=19$m=1024,t=1,p=1$" + salt},
+		{"extra field", valid + "$x"},
+		{"missing version", "$argon2id$m=1024,t=1,p=1$" + salt + "$" + key + "$x"},
+		{"other version", "$argon2id$v=16$m=1024,t=1,p=1$" + salt + "$" + key},
+		{"malformed parameters", "$argon2id$v=19$m=1024;t=1;p=1$" + salt + "$" + key},
+		{"zero memory", "$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key},
+		{"zero iterations", "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key},
+		{"zero parallelism", "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key},
+		{"salt not base64", "$argon2id$v=19$m=1024,t=1,p=1$!!!$" + key},
+		{"key not base64", "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$!!!"},
+		{"padded key", "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$" + key + "="},
+		{"empty key", "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$"},
+		{"truncated bcrypt", "$2a$04$abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, needsRehash, err := mustHasher(t, argon2idParams).Verify("correct horse", tt.hash)
+			if !errors.Is(err, ErrInvalidHash) || match || needsRehash {
+				t.Errorf("Verify() = %v, %v, %v, want false, false, ErrInvalidHash", match, needsRehash, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package pkce
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// MethodS256 is the only supported code challenge method of Proof Key for Code Exchange (RFC 7636):
+// code_challenge = BASE64URL(SHA256(code_verifier))
+const MethodS256 = "S256"
+
+// ValidVerifier reports whether a code verifier has the format required by RFC 7636:
+// 43 to 128 characters of [A-Z] / [a-z] / [0-9] / "-" / "." / "_" / "~"
+func ValidVerifier(verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for i := 0; i < len(verifier); i++ {
+		if !isUnreserved(verifier[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidChallenge reports whether a code challenge is a well-formed S256 challenge
+func ValidChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// ChallengeS256 derives the S256 code challenge of a code verifier
+func ChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Verify checks a code verifier against the S256 code challenge sent in the authorization request
+func Verify(verifier, challenge string) bool {
+	if !ValidVerifier(verifier) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(ChallengeS256(verifier)), []byte(challenge)) == 1
+}
+
+// isUnreserved reports whether c is an unreserved URI character (RFC 3986)
+func isUnreserved(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,53 @@
+package query_params
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	// ErrInvalidCursor is returned for a cursor that is malformed or does not belong to the list and sort
+	// it is used with.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort is returned for a sort on a field the list cannot be sorted by.
+	ErrInvalidSort = errors.New("invalid sort")
+)
+
+// Cursor is a position in a list for keyset pagination: the values of the sort keys of the last item of the
+// previous page. Clients get it as an opaque string (next_cursor) and send it back unchanged.
+type Cursor struct {
+	Sort   string            `json:"s"` // The sort parameter of the list the cursor was created for
+	Values []json.RawMessage `json:"v"` // The sort key values of the last item, ending with its ID
+}
+
+// EncodeCursor encodes the position after an item of a list with the given sort, from the values of its
+// sort keys.
+func EncodeCursor(sort string, values []interface{}) (string, error) {
+	cursor := Cursor{Sort: sort, Values: make([]json.RawMessage, 0, len(values))}
+	for _, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		cursor.Values = append(cursor.Values, raw)
+	}
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// DecodeCursor decodes a cursor string. The values are decoded by the list, which knows their types.
+func DecodeCursor(value string) (*Cursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || len(cursor.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
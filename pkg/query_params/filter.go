@@ -0,0 +1,128 @@
+package query_params
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrInvalidFilter is returned for a filter on a field the list cannot be filtered by, with an unknown or
+// unsupported operator, or with values of the wrong type.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Operator is a filter operator.
+type Operator string
+
+const (
+	OpEq       Operator = "eq"       // Equal to the value
+	OpNe       Operator = "ne"       // Not equal to the value
+	OpIn       Operator = "in"       // Equal to one of the values (a non-empty array)
+	OpGt       Operator = "gt"       // Greater than the value
+	OpLt       Operator = "lt"       // Less than the value
+	OpBetween  Operator = "between"  // Between two values, inclusive (an array of two values)
+	OpContains Operator = "contains" // Contains the text
+	OpIsNull   Operator = "is_null"  // NULL if true, not NULL if false
+)
+
+// Condition is one condition of a filter on a field. The value is as decoded from JSON: a string, number
+// (float64) or bool; a []interface{} for OpIn and OpBetween; a bool for OpIsNull.
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    interface{}
+}
+
+// ParseFilter parses the filter parameter into conditions, all of which must match. The value of each field
+// is either a plain value:
+//
+//	{"role": "admin"}          eq
+//	{"categories": [1, 2]}     in (an empty array does not filter)
+//	{"parent_id": null}        is_null
+//
+// or an object of operators and their values:
+//
+//	{"created_at": {"gt": "2025-01-01T00:00:00Z"}, "name": {"contains": "milk"}}
+//
+// Only the syntax is checked here; the fields and value types are checked by the list against its schema.
+func ParseFilter(filter map[string]interface{}) ([]Condition, error) {
+	fields := make([]string, 0, len(filter))
+	for field := range filter {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields) // Same filter, same SQL.
+
+	var conditions []Condition
+	for _, field := range fields {
+		switch value := filter[field].(type) {
+		case nil:
+			conditions = append(conditions, Condition{Field: field, Operator: OpIsNull, Value: true})
+		case []interface{}:
+			if len(value) == 0 {
+				continue // An empty list does not filter, as clients send it when nothing is selected.
+			}
+			condition := Condition{Field: field, Operator: OpIn, Value: value}
+			if !validOperand(condition) {
+				return nil, ErrInvalidFilter
+			}
+			conditions = append(conditions, condition)
+		case map[string]interface{}:
+			if len(value) == 0 {
+				return nil, ErrInvalidFilter
+			}
+			operators := make([]string, 0, len(value))
+			for operator := range value {
+				operators = append(operators, operator)
+			}
+			sort.Strings(operators)
+			for _, operator := range operators {
+				condition := Condition{Field: field, Operator: Operator(operator), Value: value[operator]}
+				if !validOperand(condition) {
+					return nil, ErrInvalidFilter
+				}
+				conditions = append(conditions, condition)
+			}
+		default:
+			conditions = append(conditions, Condition{Field: field, Operator: OpEq, Value: value})
+		}
+	}
+	return conditions, nil
+}
+
+// validOperand reports whether the operator is known and its value has the shape the operator takes.
+func validOperand(condition Condition) bool {
+	switch condition.Operator {
+	case OpEq, OpNe, OpGt, OpLt:
+		return isScalar(condition.Value)
+	case OpIn:
+		values, ok := condition.Value.([]interface{})
+		return ok && len(values) > 0 && allScalars(values)
+	case OpBetween:
+		values, ok := condition.Value.([]interface{})
+		return ok && len(values) == 2 && allScalars(values)
+	case OpContains:
+		text, ok := condition.Value.(string)
+		return ok && text != ""
+	case OpIsNull:
+		_, ok := condition.Value.(bool)
+		return ok
+	}
+	return false
+}
+
+// isScalar reports whether a JSON value is a string, number or bool.
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+// allScalars reports whether all JSON values are strings, numbers or bools.
+func allScalars(values []interface{}) bool {
+	for _, value := range values {
+		if !isScalar(value) {
+			return false
+		}
+	}
+	return true
+}
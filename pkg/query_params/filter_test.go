@@ -0,0 +1,74 @@
+package query_params
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string // As sent in the filter parameter
+		want    []Condition
+		wantErr bool
+	}{
+		{"empty", `{}`, nil, false},
+		{"plain value", `{"role": "admin"}`, []Condition{{"role", OpEq, "admin"}}, false},
+		{"plain number", `{"user_id": 42}`, []Condition{{"user_id", OpEq, 42.0}}, false},
+		{"plain bool", `{"success": false}`, []Condition{{"success", OpEq, false}}, false},
+		{"plain null", `{"parent_id": null}`, []Condition{{"parent_id", OpIsNull, true}}, false},
+		{"plain list", `{"categories": [1, 2]}`, []Condition{{"categories", OpIn, []interface{}{1.0, 2.0}}}, false},
+		{"plain empty list", `{"categories": []}`, nil, false},
+		{"operators", `{"created_at": {"lt": "2025-02-01", "gt": "2025-01-01"}}`, []Condition{
+			{"created_at", OpGt, "2025-01-01"},
+			{"created_at", OpLt, "2025-02-01"},
+		}, false},
+		{"fields in order", `{"name": {"contains": "milk"}, "id": {"ne": 3}}`, []Condition{
+			{"id", OpNe, 3.0},
+			{"name", OpContains, "milk"},
+		}, false},
+		{"in", `{"id": {"in": [1, "2"]}}`, []Condition{{"id", OpIn, []interface{}{1.0, "2"}}}, false},
+		{"between", `{"id": {"between": [1, 9]}}`, []Condition{{"id", OpBetween, []interface{}{1.0, 9.0}}}, false},
+		{"is_null", `{"phone": {"is_null": false}}`, []Condition{{"phone", OpIsNull, false}}, false},
+
+		{"unknown operator", `{"id": {"like": "1%"}}`, nil, true},
+		{"uppercase operator", `{"id": {"EQ": 1}}`, nil, true},
+		{"no operators", `{"id": {}}`, nil, true},
+		{"eq with list", `{"id": {"eq": [1]}}`, nil, true},
+		{"eq with null", `{"id": {"eq": null}}`, nil, true},
+		{"gt with object", `{"id": {"gt": {"eq": 1}}}`, nil, true},
+		{"in with empty list", `{"id": {"in": []}}`, nil, true},
+		{"in with scalar", `{"id": {"in": 1}}`, nil, true},
+		{"in with null", `{"id": {"in": [1, null]}}`, nil, true},
+		{"plain list with object", `{"id": [{"eq": 1}]}`, nil, true},
+		{"between with one value", `{"id": {"between": [1]}}`, nil, true},
+		{"between with three values", `{"id": {"between": [1, 2, 3]}}`, nil, true},
+		{"contains with number", `{"name": {"contains": 1}}`, nil, true},
+		{"contains with empty text", `{"name": {"contains": ""}}`, nil, true},
+		{"is_null with string", `{"phone": {"is_null": "true"}}`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.filter), &filter); err != nil {
+				t.Fatalf("invalid test filter: %v", err)
+			}
+
+			got, err := ParseFilter(filter)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidFilter) {
+					t.Errorf("ParseFilter() error = %v, want ErrInvalidFilter", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFilter() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,14 +11,16 @@ import (
 
 type QueryParams struct {
 	Search string
-	Filter map[string]interface{} // Changed to interface{} to support arrays and nested structures
+	Filter map[string]interface{} // Changed to interface{} to support arrays and nested structures; see ParseFilter
 	Sort   string
 	Page   int
 	Limit  int
+	Cursor *Cursor // Keyset pagination position; when set, Page is ignored
 }
 
 // ParseQueryParams parses common query parameters for list APIs.
 // Example: /products?search=detergent&filter={"categories":[1,2]}&sort=updated_at:desc&page=1&limit=10
+// or, for the following pages: /products?search=detergent&sort=updated_at:desc&cursor=<next_cursor>&limit=10
 func ParseQueryParams(c *gin.Context) (*QueryParams, error) {
 	// Initialize query parameters.
 	q := &QueryParams{
@@ -83,5 +85,14 @@ func ParseQueryParams(c *gin.Context) (*QueryParams, error) {
 	}
 	q.Limit = limit
 
+	// 6. Parse 'cursor' parameter.
+	if cursor := c.Query("cursor"); cursor != "" {
+		q.Cursor, err = DecodeCursor(cursor)
+		if err != nil {
+			slog.Warn("Invalid cursor parameter", "cursor", cursor, "error", err)
+			return nil, err
+		}
+	}
+
 	return q, nil
 }
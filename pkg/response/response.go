@@ -6,13 +6,16 @@ type Response struct {
 	Message    string      `json:"message,omitempty"`
 	Data       interface{} `json:"data,omitempty"`
 	Pagination *Pagination `json:"pagination,omitempty"`
+	Facets     interface{} `json:"facets,omitempty"` // Counts of the matching items by facet, on list APIs that support them
 }
 
 type Pagination struct {
-	TotalCount  int `json:"total_count"`
-	PageSize    int `json:"page_size"`
-	CurrentPage int `json:"current_page"`
-	TotalPages  int `json:"total_pages"`
+	TotalCount  int    `json:"total_count"`
+	PageSize    int    `json:"page_size"`
+	CurrentPage int    `json:"current_page"`
+	TotalPages  int    `json:"total_pages"`
+	NextCursor  string `json:"next_cursor,omitempty"` // Cursor of the next page for keyset pagination, empty on the last page
+	Truncated   bool   `json:"truncated,omitempty"`   // The search matched more items than it returns, so the list and total_count are incomplete
 }
 
 // NewSuccessResponse creates a success response.
@@ -40,3 +43,12 @@ func NewErrorResponse(message string) Response {
 		Message: message,
 	}
 }
+
+// NewErrorResponseWithData creates an error response with extra details.
+func NewErrorResponseWithData(message string, data interface{}) Response {
+	return Response{
+		Status:  "error",
+		Message: message,
+		Data:    data,
+	}
+}
@@ -0,0 +1,127 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 test vectors, "12345678901234567890", base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 Appendix B test vectors for SHA-1. The RFC lists 8-digit codes; 6-digit codes are their last
+// six digits, as both are the truncated value modulo a power of ten.
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},          // 94287082
+	{1111111109, "081804"},  // 07081804
+	{1111111111, "050471"},  // 14050471
+	{1234567890, "005924"},  // 89005924
+	{2000000000, "279037"},  // 69279037
+	{20000000000, "353130"}, // 65353130
+}
+
+func TestGenerateCode(t *testing.T) {
+	for _, tt := range rfc6238Vectors {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			code, err := GenerateCode(rfc6238Secret, time.Unix(tt.unix, 0))
+			if err != nil {
+				t.Fatalf("GenerateCode() error = %v", err)
+			}
+			if code != tt.code {
+				t.Errorf("GenerateCode() = %s, want %s", code, tt.code)
+			}
+		})
+	}
+
+	if _, err := GenerateCode("not base32!", time.Unix(59, 0)); err == nil {
+		t.Error("GenerateCode() accepted an invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	at := time.Unix(1111111111, 0) // Time step 37037037
+	step := at.Unix() / Period
+
+	tests := []struct {
+		name     string
+		code     string
+		secret   string
+		at       time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", "050471", rfc6238Secret, at, step, true},
+		{"lowercase secret", "050471", strings.ToLower(rfc6238Secret), at, step, true},
+		{"surrounding spaces", " 050471 ", rfc6238Secret, at, step, true},
+		{"previous step", "081804", rfc6238Secret, at, step - 1, true},
+		{"next step", "050471", rfc6238Secret, at.Add(-Period * time.Second), step, true},
+		{"two steps late", "050471", rfc6238Secret, at.Add(2 * Period * time.Second), 0, false},
+		{"two steps early", "050471", rfc6238Secret, at.Add(-2 * Period * time.Second), 0, false},
+		{"wrong code", "050472", rfc6238Secret, at, 0, false},
+		{"8-digit code", "14050471", rfc6238Secret, at, 0, false},
+		{"short code", "05047", rfc6238Secret, at, 0, false},
+		{"empty code", "", rfc6238Secret, at, 0, false},
+		{"invalid secret", "050471", "not base32!", at, 0, false},
+		{"other secret", "050471", "JBSWY3DPEHPK3PXP", at, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := Validate(tt.code, tt.secret, tt.at)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate() = %d, %v, want %d, %v", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("GenerateSecret() = %q, want 20 base32 encoded bytes", secret)
+	}
+
+	now := time.Now()
+	code, err := GenerateCode(secret, now)
+	if err != nil {
+		t.Fatalf("GenerateCode() error = %v", err)
+	}
+	if _, ok := Validate(code, secret, now); !ok {
+		t.Error("Validate() rejected a code generated for a new secret")
+	}
+
+	if other, _ := GenerateSecret(); other == secret {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI("Example App", "user@example.com", rfc6238Secret))
+	if err != nil {
+		t.Fatalf("ProvisioningURI() is not a valid URI: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Example App:user@example.com" {
+		t.Errorf("ProvisioningURI() = %s", uri)
+	}
+
+	want := map[string]string{
+		"secret":    rfc6238Secret,
+		"issuer":    "Example App",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	query := uri.Query()
+	for param, value := range want {
+		if got := query.Get(param); got != value {
+			t.Errorf("ProvisioningURI() %s = %q, want %q", param, got, value)
+		}
+	}
+}
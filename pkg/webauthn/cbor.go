@@ -0,0 +1,143 @@
+package webauthn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth limits nesting so malformed input cannot exhaust the stack
+const maxCBORDepth = 16
+
+var errCBORUnexpectedEnd = errors.New("cbor: unexpected end of data")
+
+// decodeCBOR decodes a single CBOR data item (RFC 8949) and returns it together with the remaining bytes.
+//
+// Only the subset used by WebAuthn is supported: integers (decoded as int64), byte strings ([]byte),
+// text strings (string), arrays ([]interface{}), maps (map[interface{}]interface{}) and the simple values
+// false, true and null. Indefinite-length items and floats are rejected.
+func decodeCBOR(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("cbor: nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, nil, errCBORUnexpectedEnd
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	// Simple values
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		default:
+			return nil, nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+		}
+	}
+
+	n, data, err := readCBORArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0: // Unsigned integer
+		if n > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return int64(n), data, nil
+	case 1: // Negative integer
+		if n > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(n), data, nil
+	case 2: // Byte string
+		if uint64(len(data)) < n {
+			return nil, nil, errCBORUnexpectedEnd
+		}
+		b := make([]byte, n)
+		copy(b, data[:n])
+		return b, data[n:], nil
+	case 3: // Text string
+		if uint64(len(data)) < n {
+			return nil, nil, errCBORUnexpectedEnd
+		}
+		return string(data[:n]), data[n:], nil
+	case 4: // Array
+		if n > uint64(len(data)) {
+			return nil, nil, errCBORUnexpectedEnd
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var item interface{}
+			item, data, err = decodeCBOR(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+	case 5: // Map
+		if n > uint64(len(data)) {
+			return nil, nil, errCBORUnexpectedEnd
+		}
+		m := make(map[interface{}]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			var key, value interface{}
+			key, data, err = decodeCBOR(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errors.New("cbor: unsupported map key type")
+			}
+			value, data, err = decodeCBOR(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, data, nil
+	case 6: // Tag: the tag number is ignored and the tagged item returned
+		return decodeCBOR(data, depth+1)
+	}
+
+	return nil, nil, fmt.Errorf("cbor: unsupported major type %d", major)
+}
+
+// readCBORArgument reads the argument (length or value) that follows the initial byte
+func readCBORArgument(info byte, data []byte) (uint64, []byte, error) {
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, errors.New("cbor: indefinite length items are not supported")
+	}
+
+	if len(data) < size {
+		return 0, nil, errCBORUnexpectedEnd
+	}
+	var n uint64
+	for i := 0; i < size; i++ {
+		n = n<<8 | uint64(data[i])
+	}
+	return n, data[size:], nil
+}
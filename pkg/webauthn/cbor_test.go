@@ -0,0 +1,155 @@
+package webauthn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// encodeCBOR encodes the values decodeCBOR supports, to build test inputs
+func encodeCBOR(v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return []byte{0xf6}
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case int:
+		return encodeCBOR(int64(v))
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []interface{}:
+		out := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	case map[interface{}]interface{}:
+		out := cborHead(5, uint64(len(v)))
+		for key, value := range v {
+			out = append(out, encodeCBOR(key)...)
+			out = append(out, encodeCBOR(value)...)
+		}
+		return out
+	}
+	panic("encodeCBOR: unsupported type")
+}
+
+// cborHead encodes the initial byte and argument of a data item
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 1<<8:
+		return []byte{major<<5 | 24, byte(n)}
+	case n < 1<<16:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	case n < 1<<32:
+		return []byte{major<<5 | 26, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+	}
+	return []byte{major<<5 | 27, byte(n >> 56), byte(n >> 48), byte(n >> 40), byte(n >> 32), byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want interface{}
+		rest []byte
+	}{
+		{"small unsigned", []byte{0x17}, int64(23), []byte{}},
+		{"one byte unsigned", []byte{0x18, 0x18}, int64(24), []byte{}},
+		{"two byte unsigned", []byte{0x19, 0x01, 0x00}, int64(256), []byte{}},
+		{"four byte unsigned", []byte{0x1a, 0x00, 0x01, 0x00, 0x00}, int64(65536), []byte{}},
+		{"eight byte unsigned", []byte{0x1b, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, int64(1<<63 - 1), []byte{}},
+		{"negative", []byte{0x20}, int64(-1), []byte{}},
+		{"one byte negative", []byte{0x38, 0x63}, int64(-100), []byte{}},
+		{"COSE RS256", []byte{0x39, 0x01, 0x00}, AlgRS256, []byte{}},
+		{"byte string", []byte{0x43, 0x01, 0x02, 0x03}, []byte{0x01, 0x02, 0x03}, []byte{}},
+		{"empty byte string", []byte{0x40}, []byte{}, []byte{}},
+		{"text string", []byte{0x63, 'a', 'b', 'c'}, "abc", []byte{}},
+		{"array", []byte{0x82, 0x01, 0x61, 'x'}, []interface{}{int64(1), "x"}, []byte{}},
+		{"map", []byte{0xa2, 0x01, 0x02, 0x61, 'a', 0x20}, map[interface{}]interface{}{int64(1): int64(2), "a": int64(-1)}, []byte{}},
+		{"nested", []byte{0xa1, 0x61, 'k', 0x81, 0xa0}, map[interface{}]interface{}{"k": []interface{}{map[interface{}]interface{}{}}}, []byte{}},
+		{"false", []byte{0xf4}, false, []byte{}},
+		{"true", []byte{0xf5}, true, []byte{}},
+		{"null", []byte{0xf6}, nil, []byte{}},
+		{"undefined", []byte{0xf7}, nil, []byte{}},
+		{"tag", []byte{0xc2, 0x41, 0x01}, []byte{0x01}, []byte{}},
+		{"trailing bytes", []byte{0x01, 0x02, 0x03}, int64(1), []byte{0x02, 0x03}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := decodeCBOR(tt.data, 0)
+			if err != nil {
+				t.Fatalf("decodeCBOR() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeCBOR() = %#v, want %#v", got, tt.want)
+			}
+			if !bytes.Equal(rest, tt.rest) {
+				t.Errorf("decodeCBOR() rest = %x, want %x", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	tooDeep := append(bytes.Repeat([]byte{0x81}, maxCBORDepth+1), 0x00)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"truncated argument", []byte{0x19, 0x01}},
+		{"truncated eight byte argument", []byte{0x1b, 0x00, 0x00}},
+		{"truncated byte string", []byte{0x43, 0x01, 0x02}},
+		{"truncated text string", []byte{0x63, 'a'}},
+		{"truncated array", []byte{0x82, 0x01}},
+		{"truncated map value", []byte{0xa1, 0x01}},
+		{"truncated map", []byte{0xa2, 0x01, 0x02}},
+		{"truncated tag", []byte{0xc2}},
+		{"huge byte string length", []byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"huge array length", []byte{0x9b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+		{"huge map length", []byte{0xba, 0xff, 0xff, 0xff, 0xff}},
+		{"unsigned overflow", []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"negative overflow", []byte{0x3b, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"indefinite byte string", []byte{0x5f, 0x41, 0x01, 0xff}},
+		{"indefinite array", []byte{0x9f, 0x01, 0xff}},
+		{"reserved argument", []byte{0x1c}},
+		{"float", []byte{0xfa, 0x3f, 0x80, 0x00, 0x00}},
+		{"unassigned simple value", []byte{0xf0}},
+		{"byte string map key", []byte{0xa1, 0x41, 0x00, 0x01}},
+		{"array map key", []byte{0xa1, 0x80, 0x01}},
+		{"nesting too deep", tooDeep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _, err := decodeCBOR(tt.data, 0); err == nil {
+				t.Errorf("decodeCBOR(%x) = %#v, want error", tt.data, got)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORCopiesByteStrings(t *testing.T) {
+	data := []byte{0x42, 0x01, 0x02}
+	got, _, err := decodeCBOR(data, 0)
+	if err != nil {
+		t.Fatalf("decodeCBOR() error = %v", err)
+	}
+	data[1] = 0xff
+	if !bytes.Equal(got.([]byte), []byte{0x01, 0x02}) {
+		t.Errorf("decoded byte string changed with the input: %x", got)
+	}
+}
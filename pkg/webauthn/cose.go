@@ -0,0 +1,110 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers (https://www.iana.org/assignments/cose)
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// COSE key parameters (RFC 9053)
+const (
+	coseKeyType     int64 = 1
+	coseKeyAlg      int64 = 3
+	coseKeyCurve    int64 = -1 // EC2/OKP curve
+	coseKeyX        int64 = -2 // EC2/OKP x coordinate
+	coseKeyY        int64 = -3 // EC2 y coordinate
+	coseKeyModulus  int64 = -1 // RSA n
+	coseKeyExponent int64 = -2 // RSA e
+
+	coseKeyTypeOKP int64 = 1
+	coseKeyTypeEC2 int64 = 2
+	coseKeyTypeRSA int64 = 3
+
+	coseCurveP256    int64 = 1
+	coseCurveEd25519 int64 = 6
+)
+
+// parsePublicKey parses a COSE encoded public key and returns it with its algorithm
+func parsePublicKey(coseKey []byte) (crypto.PublicKey, int64, error) {
+	value, _, err := decodeCBOR(coseKey, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	m, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, errors.New("webauthn: public key is not a COSE key")
+	}
+
+	kty, _ := m[coseKeyType].(int64)
+	alg, _ := m[coseKeyAlg].(int64)
+
+	switch alg {
+	case AlgES256:
+		crv, _ := m[coseKeyCurve].(int64)
+		x, _ := m[coseKeyX].([]byte)
+		y, _ := m[coseKeyY].([]byte)
+		if kty != coseKeyTypeEC2 || crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("webauthn: invalid ES256 public key")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, 0, errors.New("webauthn: ES256 public key is not on the curve")
+		}
+		return pub, alg, nil
+	case AlgEdDSA:
+		crv, _ := m[coseKeyCurve].(int64)
+		x, _ := m[coseKeyX].([]byte)
+		if kty != coseKeyTypeOKP || crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("webauthn: invalid EdDSA public key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case AlgRS256:
+		n, _ := m[coseKeyModulus].([]byte)
+		e, _ := m[coseKeyExponent].([]byte)
+		if kty != coseKeyTypeRSA || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("webauthn: invalid RS256 public key")
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, alg, nil
+	}
+
+	return nil, 0, fmt.Errorf("webauthn: unsupported public key algorithm %d", alg)
+}
+
+// verifySignature verifies a signature over data with a COSE encoded public key
+func verifySignature(coseKey, data, signature []byte) error {
+	pub, alg, err := parsePublicKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+	switch alg {
+	case AlgES256:
+		if !ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest[:], signature) {
+			return errors.New("webauthn: invalid signature")
+		}
+	case AlgEdDSA:
+		if !ed25519.Verify(pub.(ed25519.PublicKey), data, signature) {
+			return errors.New("webauthn: invalid signature")
+		}
+	case AlgRS256:
+		if err := rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("webauthn: invalid signature")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// es256COSEKey encodes an ECDSA P-256 public key as a COSE key
+func es256COSEKey(pub *ecdsa.PublicKey) []byte {
+	return encodeCBOR(map[interface{}]interface{}{
+		coseKeyType:  coseKeyTypeEC2,
+		coseKeyAlg:   AlgES256,
+		coseKeyCurve: coseCurveP256,
+		coseKeyX:     pub.X.FillBytes(make([]byte, 32)),
+		coseKeyY:     pub.Y.FillBytes(make([]byte, 32)),
+	})
+}
+
+// eddsaCOSEKey encodes an Ed25519 public key as a COSE key
+func eddsaCOSEKey(pub ed25519.PublicKey) []byte {
+	return encodeCBOR(map[interface{}]interface{}{
+		coseKeyType:  coseKeyTypeOKP,
+		coseKeyAlg:   AlgEdDSA,
+		coseKeyCurve: coseCurveEd25519,
+		coseKeyX:     []byte(pub),
+	})
+}
+
+// rs256COSEKey encodes an RSA public key as a COSE key
+func rs256COSEKey(pub *rsa.PublicKey) []byte {
+	return encodeCBOR(map[interface{}]interface{}{
+		coseKeyType:     coseKeyTypeRSA,
+		coseKeyAlg:      AlgRS256,
+		coseKeyModulus:  pub.N.Bytes(),
+		coseKeyExponent: big.NewInt(int64(pub.E)).Bytes(),
+	})
+}
+
+func newES256Key(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+	return key
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, data []byte) []byte {
+	t.Helper()
+	digest := sha256.Sum256(data)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return signature
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte("authenticator data and client data hash")
+	digest := sha256.Sum256(data)
+
+	ecKey := newES256Key(t)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		coseKey   []byte
+		alg       int64
+		signature []byte
+	}{
+		{"ES256", es256COSEKey(&ecKey.PublicKey), AlgES256, signES256(t, ecKey, data)},
+		{"EdDSA", eddsaCOSEKey(edPub), AlgEdDSA, ed25519.Sign(edKey, data)},
+		{"RS256", rs256COSEKey(&rsaKey.PublicKey), AlgRS256, rsaSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, alg, err := parsePublicKey(tt.coseKey); err != nil || alg != tt.alg {
+				t.Fatalf("parsePublicKey() = %d, %v, want %d", alg, err, tt.alg)
+			}
+			if err := verifySignature(tt.coseKey, data, tt.signature); err != nil {
+				t.Errorf("verifySignature() error = %v", err)
+			}
+			if err := verifySignature(tt.coseKey, []byte("other data"), tt.signature); err == nil {
+				t.Error("verifySignature() accepted a signature over other data")
+			}
+			tampered := append([]byte{}, tt.signature...)
+			tampered[len(tampered)-1] ^= 0x01
+			if err := verifySignature(tt.coseKey, data, tampered); err == nil {
+				t.Error("verifySignature() accepted a tampered signature")
+			}
+		})
+	}
+}
+
+func TestParsePublicKeyInvalid(t *testing.T) {
+	ecKey := newES256Key(t)
+	x := ecKey.X.FillBytes(make([]byte, 32))
+	y := ecKey.Y.FillBytes(make([]byte, 32))
+	offCurve := append([]byte{}, y...)
+	offCurve[31] ^= 0x01
+
+	es256 := func(kty, crv int64, x, y []byte) []byte {
+		return encodeCBOR(map[interface{}]interface{}{coseKeyType: kty, coseKeyAlg: AlgES256, coseKeyCurve: crv, coseKeyX: x, coseKeyY: y})
+	}
+
+	tests := []struct {
+		name    string
+		coseKey []byte
+	}{
+		{"empty", []byte{}},
+		{"truncated", es256COSEKey(&ecKey.PublicKey)[:40]},
+		{"not a map", encodeCBOR([]interface{}{int64(1), int64(2)})},
+		{"no algorithm", encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeEC2})},
+		{"unsupported algorithm", encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeEC2, coseKeyAlg: int64(-35)})},
+		{"ES256 wrong key type", es256(coseKeyTypeRSA, coseCurveP256, x, y)},
+		{"ES256 wrong curve", es256(coseKeyTypeEC2, int64(2), x, y)},
+		{"ES256 short coordinate", es256(coseKeyTypeEC2, coseCurveP256, x[:31], y)},
+		{"ES256 point not on curve", es256(coseKeyTypeEC2, coseCurveP256, x, offCurve)},
+		{"EdDSA short key", encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeOKP, coseKeyAlg: AlgEdDSA, coseKeyCurve: coseCurveEd25519, coseKeyX: make([]byte, 31)})},
+		{"EdDSA wrong curve", encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeOKP, coseKeyAlg: AlgEdDSA, coseKeyCurve: int64(4), coseKeyX: make([]byte, 32)})},
+		{"RS256 short modulus", encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeRSA, coseKeyAlg: AlgRS256, coseKeyModulus: make([]byte, 128), coseKeyExponent: []byte{0x01, 0x00, 0x01}})},
+		{"RS256 no exponent", encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeRSA, coseKeyAlg: AlgRS256, coseKeyModulus: make([]byte, 256)})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := parsePublicKey(tt.coseKey); err == nil {
+				t.Error("parsePublicKey() accepted an invalid key")
+			}
+			if err := verifySignature(tt.coseKey, []byte("data"), []byte("signature")); err == nil {
+				t.Error("verifySignature() accepted an invalid key")
+			}
+		})
+	}
+}
@@ -0,0 +1,445 @@
+// Package webauthn implements the server side of WebAuthn (passkey) registration and authentication
+// ceremonies (https://www.w3.org/TR/webauthn-3/).
+//
+// Attestation statements are not verified: credentials are requested with attestation "none", so the
+// authenticator model is not trusted and only the credential public key is used.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagBackupEligible         = 0x08
+	flagAttestedCredentialData = 0x40
+)
+
+// DefaultTimeout is the time the client has to complete a ceremony
+const DefaultTimeout = 5 * time.Minute
+
+// RelyingParty holds the relying party (this server) settings.
+type RelyingParty struct {
+	ID      string   // RP ID, the registrable domain, e.g. example.com
+	Name    string   // Human-readable name shown by the authenticator
+	Origins []string // Allowed origins, e.g. https://example.com
+	Timeout time.Duration
+}
+
+// NewRelyingParty creates a new RelyingParty
+func NewRelyingParty(id, name string, origins []string) *RelyingParty {
+	return &RelyingParty{
+		ID:      id,
+		Name:    name,
+		Origins: origins,
+		Timeout: DefaultTimeout,
+	}
+}
+
+/* Options sent to the client (JSON format accepted by PublicKeyCredential.parse*OptionsFromJSON) */
+
+// RelyingPartyEntity describes the relying party in creation options
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity describes the user account in creation options
+type UserEntity struct {
+	ID          string `json:"id"` // base64url encoded user handle
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter is an accepted credential type and algorithm
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor identifies an existing credential
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"` // base64url encoded credential ID
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection describes the required authenticator capabilities
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CredentialCreationOptions are the options for navigator.credentials.create()
+type CredentialCreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// CredentialRequestOptions are the options for navigator.credentials.get()
+type CredentialRequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+/* Responses sent by the client (PublicKeyCredential.toJSON()) */
+
+// AttestationResponse is the result of navigator.credentials.create()
+type AttestationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse is the result of navigator.credentials.get()
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+/* Verification results */
+
+// Credential is a newly registered credential
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // COSE encoded public key
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool // Synced passkey (e.g. iCloud Keychain, Google Password Manager)
+}
+
+// AssertionResult is the result of a verified authentication
+type AssertionResult struct {
+	SignCount    uint32
+	UserVerified bool
+}
+
+// clientData is the parsed clientDataJSON
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// authenticatorData is the parsed authenticator data
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// NewChallenge generates a random base64url encoded challenge
+func NewChallenge() (string, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+	return EncodeBase64URL(challenge), nil
+}
+
+// EncodeBase64URL encodes bytes as unpadded base64url, the encoding used for binary values in WebAuthn JSON
+func EncodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeBase64URL decodes base64url, with or without padding
+func DecodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// CreationOptions builds the options for registering a new discoverable credential
+func (rp *RelyingParty) CreationOptions(challenge string, user UserEntity, exclude []CredentialDescriptor) *CredentialCreationOptions {
+	if exclude == nil {
+		exclude = []CredentialDescriptor{}
+	}
+	return &CredentialCreationOptions{
+		Challenge: challenge,
+		RP:        RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:      user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:            rp.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "preferred",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions builds the options for authenticating with a discoverable credential
+func (rp *RelyingParty) RequestOptions(challenge string) *CredentialRequestOptions {
+	return &CredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.Timeout.Milliseconds(),
+		RPID:             rp.ID,
+		AllowCredentials: []CredentialDescriptor{},
+		UserVerification: "preferred",
+	}
+}
+
+// VerifyRegistration verifies a registration response against the expected challenge
+func (rp *RelyingParty) VerifyRegistration(resp *AttestationResponse, challenge string) (*Credential, error) {
+	if resp.Type != "public-key" {
+		return nil, errors.New("webauthn: unsupported credential type")
+	}
+
+	rawClientData, err := DecodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid clientDataJSON encoding")
+	}
+	if err := rp.verifyClientData(rawClientData, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAttestation, err := DecodeBase64URL(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid attestationObject encoding")
+	}
+	value, _, err := decodeCBOR(rawAttestation, 0)
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: invalid attestationObject: %w", err)
+	}
+	attestation, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("webauthn: invalid attestationObject")
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("webauthn: attestationObject has no authData")
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.Flags&flagAttestedCredentialData == 0 || authData.PublicKey == nil {
+		return nil, errors.New("webauthn: no attested credential data")
+	}
+
+	// The credential ID reported by the client must match the authenticator data.
+	rawID, err := resp.credentialID()
+	if err != nil || !bytes.Equal(rawID, authData.CredentialID) {
+		return nil, errors.New("webauthn: credential ID mismatch")
+	}
+
+	// Make sure the public key is usable before storing it.
+	if _, _, err := parsePublicKey(authData.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             authData.CredentialID,
+		PublicKey:      authData.PublicKey,
+		SignCount:      authData.SignCount,
+		AAGUID:         authData.AAGUID,
+		Transports:     resp.Response.Transports,
+		UserVerified:   authData.Flags&flagUserVerified != 0,
+		BackupEligible: authData.Flags&flagBackupEligible != 0,
+	}, nil
+}
+
+// VerifyAssertion verifies an authentication response against the expected challenge and the stored credential
+func (rp *RelyingParty) VerifyAssertion(resp *AssertionResponse, challenge string, publicKey []byte, storedSignCount uint32) (*AssertionResult, error) {
+	if resp.Type != "public-key" {
+		return nil, errors.New("webauthn: unsupported credential type")
+	}
+
+	rawClientData, err := DecodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid clientDataJSON encoding")
+	}
+	if err := rp.verifyClientData(rawClientData, "webauthn.get", challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := DecodeBase64URL(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid authenticatorData encoding")
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	// The signature covers authenticatorData || SHA-256(clientDataJSON).
+	signature, err := DecodeBase64URL(resp.Response.Signature)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid signature encoding")
+	}
+	clientDataHash := sha256.Sum256(rawClientData)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if err := verifySignature(publicKey, signed, signature); err != nil {
+		return nil, err
+	}
+
+	// A non-increasing signature counter indicates a cloned authenticator.
+	// Most passkeys always report 0, in which case the check does not apply.
+	if (authData.SignCount != 0 || storedSignCount != 0) && authData.SignCount <= storedSignCount {
+		return nil, errors.New("webauthn: signature counter did not increase")
+	}
+
+	return &AssertionResult{
+		SignCount:    authData.SignCount,
+		UserVerified: authData.Flags&flagUserVerified != 0,
+	}, nil
+}
+
+// Challenge returns the challenge the client signed, so the server can look up the ceremony it belongs to
+func (resp *AssertionResponse) Challenge() (string, error) {
+	rawClientData, err := DecodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return "", errors.New("webauthn: invalid clientDataJSON encoding")
+	}
+	var cd clientData
+	if err := json.Unmarshal(rawClientData, &cd); err != nil {
+		return "", errors.New("webauthn: invalid clientDataJSON")
+	}
+	return cd.Challenge, nil
+}
+
+// CredentialID returns the base64url encoded credential ID used to authenticate
+func (resp *AssertionResponse) CredentialID() (string, error) {
+	id := resp.RawID
+	if id == "" {
+		id = resp.ID
+	}
+	raw, err := DecodeBase64URL(id)
+	if err != nil || len(raw) == 0 {
+		return "", errors.New("webauthn: invalid credential ID")
+	}
+	return EncodeBase64URL(raw), nil
+}
+
+// credentialID returns the raw credential ID of a registration response
+func (resp *AttestationResponse) credentialID() ([]byte, error) {
+	id := resp.RawID
+	if id == "" {
+		id = resp.ID
+	}
+	return DecodeBase64URL(id)
+}
+
+// verifyClientData checks the ceremony type, challenge and origin in clientDataJSON
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremonyType, challenge string) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return errors.New("webauthn: invalid clientDataJSON")
+	}
+	if cd.Type != ceremonyType {
+		return fmt.Errorf("webauthn: unexpected ceremony type %q", cd.Type)
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(cd.Challenge), []byte(challenge)) != 1 {
+		return errors.New("webauthn: challenge mismatch")
+	}
+	if cd.CrossOrigin {
+		return errors.New("webauthn: cross-origin requests are not allowed")
+	}
+	for _, origin := range rp.Origins {
+		if cd.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("webauthn: origin %q is not allowed", cd.Origin)
+}
+
+// verifyAuthenticatorData checks the RP ID hash and that the user was present
+func (rp *RelyingParty) verifyAuthenticatorData(authData *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(authData.RPIDHash, rpIDHash[:]) != 1 {
+		return errors.New("webauthn: RP ID mismatch")
+	}
+	if authData.Flags&flagUserPresent == 0 {
+		return errors.New("webauthn: user not present")
+	}
+	return nil
+}
+
+// parseAuthenticatorData parses the binary authenticator data structure
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("webauthn: authenticator data too short")
+	}
+
+	authData := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.Flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	// Attested credential data: aaguid (16) | credentialIdLength (2) | credentialId | credentialPublicKey
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("webauthn: attested credential data too short")
+	}
+	authData.AAGUID = rest[:16]
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLen == 0 || len(rest) < idLen {
+		return nil, errors.New("webauthn: invalid credential ID length")
+	}
+	authData.CredentialID = rest[:idLen]
+	rest = rest[idLen:]
+
+	// The public key is a CBOR item; extensions may follow it.
+	_, remaining, err := decodeCBOR(rest, 0)
+	if err != nil {
+		return nil, fmt.Errorf("webauthn: invalid credential public key: %w", err)
+	}
+	authData.PublicKey = rest[:len(rest)-len(remaining)]
+	return authData, nil
+}
@@ -0,0 +1,310 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const (
+	testRPID      = "example.com"
+	testOrigin    = "https://example.com"
+	testChallenge = "dGVzdC1jaGFsbGVuZ2UtMzItYnl0ZXMtbG9uZy4uLi4"
+)
+
+var testCredentialID = []byte("credential-id-0001")
+
+func newTestRelyingParty() *RelyingParty {
+	return NewRelyingParty(testRPID, "Example", []string{testOrigin})
+}
+
+// makeAuthData builds authenticator data, with attested credential data when publicKey is set
+func makeAuthData(rpID string, flags byte, signCount uint32, credentialID, publicKey []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	if publicKey != nil {
+		flags |= flagAttestedCredentialData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if publicKey == nil {
+		return data
+	}
+	data = append(data, make([]byte, 16)...) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(credentialID)))
+	data = append(data, credentialID...)
+	return append(data, publicKey...)
+}
+
+// makeClientData builds clientDataJSON
+func makeClientData(ceremonyType, challenge, origin string, crossOrigin bool) []byte {
+	data, _ := json.Marshal(clientData{Type: ceremonyType, Challenge: challenge, Origin: origin, CrossOrigin: crossOrigin})
+	return data
+}
+
+// makeAttestationObject builds a "none" attestation object
+func makeAttestationObject(authData []byte) []byte {
+	return encodeCBOR(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+}
+
+func TestParseAuthenticatorData(t *testing.T) {
+	publicKey := es256COSEKey(&newES256Key(t).PublicKey)
+	withKey := makeAuthData(testRPID, flagUserPresent|flagUserVerified, 7, testCredentialID, publicKey)
+
+	t.Run("without attested credential data", func(t *testing.T) {
+		authData, err := parseAuthenticatorData(makeAuthData(testRPID, flagUserPresent|flagBackupEligible, 42, nil, nil))
+		if err != nil {
+			t.Fatalf("parseAuthenticatorData() error = %v", err)
+		}
+		rpIDHash := sha256.Sum256([]byte(testRPID))
+		if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) || authData.Flags != flagUserPresent|flagBackupEligible || authData.SignCount != 42 {
+			t.Errorf("parseAuthenticatorData() = %+v", authData)
+		}
+		if authData.CredentialID != nil || authData.PublicKey != nil {
+			t.Errorf("parseAuthenticatorData() returned credential data without the AT flag")
+		}
+	})
+
+	t.Run("with attested credential data", func(t *testing.T) {
+		authData, err := parseAuthenticatorData(withKey)
+		if err != nil {
+			t.Fatalf("parseAuthenticatorData() error = %v", err)
+		}
+		if authData.SignCount != 7 || !bytes.Equal(authData.CredentialID, testCredentialID) || !bytes.Equal(authData.PublicKey, publicKey) || len(authData.AAGUID) != 16 {
+			t.Errorf("parseAuthenticatorData() = %+v", authData)
+		}
+	})
+
+	t.Run("with extensions after the public key", func(t *testing.T) {
+		extensions := encodeCBOR(map[interface{}]interface{}{"credProps": true})
+		authData, err := parseAuthenticatorData(append(append([]byte{}, withKey...), extensions...))
+		if err != nil {
+			t.Fatalf("parseAuthenticatorData() error = %v", err)
+		}
+		if !bytes.Equal(authData.PublicKey, publicKey) {
+			t.Errorf("parseAuthenticatorData() public key = %x, want %x", authData.PublicKey, publicKey)
+		}
+	})
+
+	malformed := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated header", withKey[:36]},
+		{"truncated AAGUID", withKey[:37+10]},
+		{"truncated credential ID length", withKey[:37+17]},
+		{"zero credential ID length", makeAuthData(testRPID, flagUserPresent, 0, nil, publicKey)},
+		{"truncated credential ID", withKey[:37+18+len(testCredentialID)-1]},
+		{"no public key", withKey[:37+18+len(testCredentialID)]},
+		{"truncated public key", withKey[:len(withKey)-1]},
+	}
+	for _, tt := range malformed {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseAuthenticatorData(tt.data); err == nil {
+				t.Error("parseAuthenticatorData() accepted malformed data")
+			}
+		})
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	rp := newTestRelyingParty()
+	publicKey := es256COSEKey(&newES256Key(t).PublicKey)
+	validAuthData := makeAuthData(testRPID, flagUserPresent|flagUserVerified|flagBackupEligible, 0, testCredentialID, publicKey)
+
+	newResponse := func(clientData, attestationObject []byte) *AttestationResponse {
+		resp := &AttestationResponse{ID: EncodeBase64URL(testCredentialID), RawID: EncodeBase64URL(testCredentialID), Type: "public-key"}
+		resp.Response.ClientDataJSON = EncodeBase64URL(clientData)
+		resp.Response.AttestationObject = EncodeBase64URL(attestationObject)
+		resp.Response.Transports = []string{"internal", "hybrid"}
+		return resp
+	}
+	validClientData := makeClientData("webauthn.create", testChallenge, testOrigin, false)
+
+	t.Run("valid", func(t *testing.T) {
+		credential, err := rp.VerifyRegistration(newResponse(validClientData, makeAttestationObject(validAuthData)), testChallenge)
+		if err != nil {
+			t.Fatalf("VerifyRegistration() error = %v", err)
+		}
+		if !bytes.Equal(credential.ID, testCredentialID) || !bytes.Equal(credential.PublicKey, publicKey) {
+			t.Errorf("VerifyRegistration() credential = %+v", credential)
+		}
+		if !credential.UserVerified || !credential.BackupEligible || len(credential.Transports) != 2 {
+			t.Errorf("VerifyRegistration() credential = %+v", credential)
+		}
+	})
+
+	unsupportedKey := encodeCBOR(map[interface{}]interface{}{coseKeyType: coseKeyTypeEC2, coseKeyAlg: int64(-35)})
+	otherID := newResponse(validClientData, makeAttestationObject(validAuthData))
+	otherID.ID, otherID.RawID = EncodeBase64URL([]byte("other")), EncodeBase64URL([]byte("other"))
+	wrongType := newResponse(validClientData, makeAttestationObject(validAuthData))
+	wrongType.Type = "password"
+	badClientDataEncoding := newResponse(validClientData, makeAttestationObject(validAuthData))
+	badClientDataEncoding.Response.ClientDataJSON = "not base64url!"
+	badAttestationEncoding := newResponse(validClientData, makeAttestationObject(validAuthData))
+	badAttestationEncoding.Response.AttestationObject = "not base64url!"
+
+	tests := []struct {
+		name      string
+		resp      *AttestationResponse
+		challenge string
+	}{
+		{"wrong credential type", wrongType, testChallenge},
+		{"invalid clientDataJSON encoding", badClientDataEncoding, testChallenge},
+		{"invalid clientDataJSON", newResponse([]byte("{"), makeAttestationObject(validAuthData)), testChallenge},
+		{"wrong ceremony type", newResponse(makeClientData("webauthn.get", testChallenge, testOrigin, false), makeAttestationObject(validAuthData)), testChallenge},
+		{"wrong challenge", newResponse(makeClientData("webauthn.create", "other-challenge", testOrigin, false), makeAttestationObject(validAuthData)), testChallenge},
+		{"no expected challenge", newResponse(makeClientData("webauthn.create", "", testOrigin, false), makeAttestationObject(validAuthData)), ""},
+		{"wrong origin", newResponse(makeClientData("webauthn.create", testChallenge, "https://evil.example", false), makeAttestationObject(validAuthData)), testChallenge},
+		{"cross origin", newResponse(makeClientData("webauthn.create", testChallenge, testOrigin, true), makeAttestationObject(validAuthData)), testChallenge},
+		{"invalid attestationObject encoding", badAttestationEncoding, testChallenge},
+		{"truncated attestationObject", newResponse(validClientData, makeAttestationObject(validAuthData)[:20]), testChallenge},
+		{"attestationObject not a map", newResponse(validClientData, encodeCBOR([]interface{}{validAuthData})), testChallenge},
+		{"attestationObject without authData", newResponse(validClientData, encodeCBOR(map[interface{}]interface{}{"fmt": "none"})), testChallenge},
+		{"truncated authData", newResponse(validClientData, makeAttestationObject(validAuthData[:len(validAuthData)-1])), testChallenge},
+		{"wrong RP ID hash", newResponse(validClientData, makeAttestationObject(makeAuthData("evil.example", flagUserPresent, 0, testCredentialID, publicKey))), testChallenge},
+		{"user not present", newResponse(validClientData, makeAttestationObject(makeAuthData(testRPID, flagUserVerified, 0, testCredentialID, publicKey))), testChallenge},
+		{"no attested credential data", newResponse(validClientData, makeAttestationObject(makeAuthData(testRPID, flagUserPresent, 0, nil, nil))), testChallenge},
+		{"credential ID mismatch", otherID, testChallenge},
+		{"unsupported public key", newResponse(validClientData, makeAttestationObject(makeAuthData(testRPID, flagUserPresent, 0, testCredentialID, unsupportedKey))), testChallenge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if credential, err := rp.VerifyRegistration(tt.resp, tt.challenge); err == nil {
+				t.Errorf("VerifyRegistration() = %+v, want error", credential)
+			}
+		})
+	}
+}
+
+// assertion is an authentication response signed by a test key
+type assertion struct {
+	clientData []byte
+	authData   []byte
+	signer     *ecdsa.PrivateKey
+}
+
+func (a assertion) response(t *testing.T) *AssertionResponse {
+	t.Helper()
+	clientDataHash := sha256.Sum256(a.clientData)
+	signed := append(append([]byte{}, a.authData...), clientDataHash[:]...)
+
+	resp := &AssertionResponse{ID: EncodeBase64URL(testCredentialID), RawID: EncodeBase64URL(testCredentialID), Type: "public-key"}
+	resp.Response.ClientDataJSON = EncodeBase64URL(a.clientData)
+	resp.Response.AuthenticatorData = EncodeBase64URL(a.authData)
+	resp.Response.Signature = EncodeBase64URL(signES256(t, a.signer, signed))
+	return resp
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	rp := newTestRelyingParty()
+	key := newES256Key(t)
+	otherKey := newES256Key(t)
+	publicKey := es256COSEKey(&key.PublicKey)
+	validClientData := makeClientData("webauthn.get", testChallenge, testOrigin, false)
+
+	valid := []struct {
+		name            string
+		flags           byte
+		signCount       uint32
+		storedSignCount uint32
+		userVerified    bool
+	}{
+		{"user verified", flagUserPresent | flagUserVerified, 0, 0, true},
+		{"user not verified", flagUserPresent, 0, 0, false},
+		{"counter increased", flagUserPresent | flagUserVerified, 6, 5, true},
+		{"first counter", flagUserPresent, 1, 0, false},
+	}
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := assertion{validClientData, makeAuthData(testRPID, tt.flags, tt.signCount, nil, nil), key}.response(t)
+			result, err := rp.VerifyAssertion(resp, testChallenge, publicKey, tt.storedSignCount)
+			if err != nil {
+				t.Fatalf("VerifyAssertion() error = %v", err)
+			}
+			if result.SignCount != tt.signCount || result.UserVerified != tt.userVerified {
+				t.Errorf("VerifyAssertion() = %+v, want sign count %d and user verified %v", result, tt.signCount, tt.userVerified)
+			}
+		})
+	}
+
+	validAuthData := makeAuthData(testRPID, flagUserPresent|flagUserVerified, 0, nil, nil)
+	tamperedAuthData := assertion{validClientData, validAuthData, key}.response(t)
+	tamperedAuthData.Response.AuthenticatorData = EncodeBase64URL(makeAuthData(testRPID, flagUserPresent|flagUserVerified, 9, nil, nil))
+	tamperedClientData := assertion{validClientData, validAuthData, key}.response(t)
+	tamperedClientData.Response.ClientDataJSON = EncodeBase64URL(makeClientData("webauthn.get", testChallenge, testOrigin+"/", false))
+	wrongType := assertion{validClientData, validAuthData, key}.response(t)
+	wrongType.Type = "password"
+	badSignatureEncoding := assertion{validClientData, validAuthData, key}.response(t)
+	badSignatureEncoding.Response.Signature = "not base64url!"
+	badAuthDataEncoding := assertion{validClientData, validAuthData, key}.response(t)
+	badAuthDataEncoding.Response.AuthenticatorData = "not base64url!"
+
+	tests := []struct {
+		name            string
+		resp            *AssertionResponse
+		challenge       string
+		storedSignCount uint32
+	}{
+		{"wrong credential type", wrongType, testChallenge, 0},
+		{"wrong ceremony type", assertion{makeClientData("webauthn.create", testChallenge, testOrigin, false), validAuthData, key}.response(t), testChallenge, 0},
+		{"wrong challenge", assertion{makeClientData("webauthn.get", "other-challenge", testOrigin, false), validAuthData, key}.response(t), testChallenge, 0},
+		{"wrong origin", assertion{makeClientData("webauthn.get", testChallenge, "https://evil.example", false), validAuthData, key}.response(t), testChallenge, 0},
+		{"cross origin", assertion{makeClientData("webauthn.get", testChallenge, testOrigin, true), validAuthData, key}.response(t), testChallenge, 0},
+		{"wrong RP ID hash", assertion{validClientData, makeAuthData("evil.example", flagUserPresent|flagUserVerified, 0, nil, nil), key}.response(t), testChallenge, 0},
+		{"user not present", assertion{validClientData, makeAuthData(testRPID, flagUserVerified, 0, nil, nil), key}.response(t), testChallenge, 0},
+		{"truncated authenticator data", assertion{validClientData, validAuthData[:36], key}.response(t), testChallenge, 0},
+		{"invalid authenticator data encoding", badAuthDataEncoding, testChallenge, 0},
+		{"invalid signature encoding", badSignatureEncoding, testChallenge, 0},
+		{"signed by another key", assertion{validClientData, validAuthData, otherKey}.response(t), testChallenge, 0},
+		{"tampered authenticator data", tamperedAuthData, testChallenge, 0},
+		{"tampered client data", tamperedClientData, testChallenge, 0},
+		{"counter not increased", assertion{validClientData, makeAuthData(testRPID, flagUserPresent, 5, nil, nil), key}.response(t), testChallenge, 5},
+		{"counter decreased", assertion{validClientData, makeAuthData(testRPID, flagUserPresent, 3, nil, nil), key}.response(t), testChallenge, 5},
+		{"counter reset to zero", assertion{validClientData, makeAuthData(testRPID, flagUserPresent, 0, nil, nil), key}.response(t), testChallenge, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result, err := rp.VerifyAssertion(tt.resp, tt.challenge, publicKey, tt.storedSignCount); err == nil {
+				t.Errorf("VerifyAssertion() = %+v, want error", result)
+			}
+		})
+	}
+}
+
+func TestAssertionResponseAccessors(t *testing.T) {
+	resp := assertion{makeClientData("webauthn.get", testChallenge, testOrigin, false), makeAuthData(testRPID, flagUserPresent, 0, nil, nil), newES256Key(t)}.response(t)
+
+	if challenge, err := resp.Challenge(); err != nil || challenge != testChallenge {
+		t.Errorf("Challenge() = %q, %v, want %q", challenge, err, testChallenge)
+	}
+
+	// The padded ID is normalized to unpadded base64url; the raw ID takes precedence over the ID.
+	resp.RawID = ""
+	resp.ID = EncodeBase64URL(testCredentialID) + strings.Repeat("=", (4-len(EncodeBase64URL(testCredentialID))%4)%4)
+	if id, err := resp.CredentialID(); err != nil || id != EncodeBase64URL(testCredentialID) {
+		t.Errorf("CredentialID() = %q, %v, want %q", id, err, EncodeBase64URL(testCredentialID))
+	}
+	resp.RawID = EncodeBase64URL([]byte("raw"))
+	if id, err := resp.CredentialID(); err != nil || id != EncodeBase64URL([]byte("raw")) {
+		t.Errorf("CredentialID() = %q, %v, want the raw ID", id, err)
+	}
+
+	resp.RawID, resp.ID = "", ""
+	if _, err := resp.CredentialID(); err == nil {
+		t.Error("CredentialID() accepted an empty ID")
+	}
+	resp.Response.ClientDataJSON = EncodeBase64URL([]byte("not json"))
+	if _, err := resp.Challenge(); err == nil {
+		t.Error("Challenge() accepted invalid clientDataJSON")
+	}
+}